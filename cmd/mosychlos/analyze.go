@@ -2,12 +2,17 @@ package mosychlos
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
+	"time"
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
 	"github.com/amaurybrisou/mosychlos/internal/engine"
+	"github.com/amaurybrisou/mosychlos/internal/portfolio"
+	"github.com/amaurybrisou/mosychlos/internal/transcript"
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
 	"github.com/spf13/cobra"
 )
 
@@ -21,8 +26,11 @@ func NewAnalyzeCommand(cfg *config.Config) *cobra.Command {
 		Examples:
 		mosychlos analyze              # Interactive mode - select analysis type
 		mosychlos analyze risk         # Direct risk analysis
-		mosychlos analyze investment_research # In-depth analysis of investment opportunities`,
-		Args: cobra.MaximumNArgs(1),
+		mosychlos analyze investment_research # In-depth analysis of investment opportunities
+
+		Exits with code 1 when batch items fail and 2 when the portfolio triggers a critical concentration alert.`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeAnalysisTypes,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runAnalyzeCommand(cmd, args, cfg)
 		},
@@ -40,6 +48,8 @@ func NewAnalyzeCommand(cfg *config.Config) *cobra.Command {
 	analyzeCmd.Flags().Bool("markdown", false, "Generate markdown reports")
 	analyzeCmd.Flags().Bool("pdf", false, "Generate PDF reports")
 	analyzeCmd.Flags().Bool("json", false, "Generate JSON reports")
+	analyzeCmd.Flags().String("manifest", "", "Analyze the consolidation of the portfolio files listed in a manifest")
+	analyzeCmd.Flags().String("transcript", "", "Write the redacted LLM conversation to a file (.json for JSON, markdown otherwise)")
 
 	return analyzeCmd
 }
@@ -61,15 +71,25 @@ func runAnalyzeCommand(cmd *cobra.Command, _ []string, cfg *config.Config) error
 		builder = engine.DefaultRegistry()
 	}
 
+	ctx := context.Background()
+
+	sharedBag := bag.NewSharedBag()
+	if manifest, _ := cmd.Flags().GetString("manifest"); manifest != "" {
+		p, err := portfolio.ManifestFetcher{FS: fs.OS{}, Path: manifest}.Fetch(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load manifest: %w", err)
+		}
+		preloadPortfolio(cfg, sharedBag, p, time.Now())
+	}
+
 	// Recreate orchestrator with builder injected
 	o := engine.New(
 		cfg,
-		engine.WithBag(bag.NewSharedBag()),
+		engine.WithBag(sharedBag),
 		engine.WithFS(fs.OS{}),
 		engine.WithBuilder(builder),
 	)
 
-	ctx := context.Background()
 	err := o.Init(ctx)
 	if err != nil {
 		slog.Error("failed to initialize engine orchestrator", "error", err)
@@ -77,10 +97,60 @@ func runAnalyzeCommand(cmd *cobra.Command, _ []string, cfg *config.Config) error
 	}
 
 	err = o.ExecutePipeline(ctx)
+	// the transcript is written even when the pipeline fails, to help diagnose it
+	if path, _ := cmd.Flags().GetString("transcript"); path != "" {
+		if terr := transcript.Export(fs.OS{}, path, transcript.FromBag(sharedBag), transcript.NewRedactor(cfg.Secrets()...)); terr != nil {
+			slog.Error("failed to export transcript", "path", path, "error", terr)
+		} else {
+			slog.Info("Transcript exported", "path", path)
+		}
+	}
 	if err != nil {
 		slog.Error("failed to execute engine pipeline", "error", err)
 		return err
 	}
 
-	return nil
+	return summaryError(analysisSummary(sharedBag))
+}
+
+// summaryError returns the error carrying the exit code of summary, nil when
+// the run succeeded without critical alert
+func summaryError(summary models.RunSummary) error {
+	switch code := summary.ExitCode(); code {
+	case 0:
+		return nil
+	case models.ExitCodeFailed:
+		return &exitCodeError{code: code, err: summary.Err()}
+	default:
+		return &exitCodeError{code: code, err: fmt.Errorf("%d critical concentration alert(s) triggered", countCritical(summary.Alerts))}
+	}
+}
+
+// analysisSummary combines the batch engine summaries and the concentration
+// alerts of a run
+func analysisSummary(sharedBag bag.SharedBag) models.RunSummary {
+	summary := models.RunSummary{Engine: "analyze"}
+	if v, ok := sharedBag.Get(bag.KBatchRunSummaries); ok {
+		if summaries, ok := v.(map[string]models.RunSummary); ok {
+			for _, s := range summaries {
+				summary.Iterations += s.Iterations
+				summary.Completed += s.Completed
+				summary.ItemErrors = append(summary.ItemErrors, s.ItemErrors...)
+			}
+		}
+	}
+	if v, ok := sharedBag.Get(bag.KConcentrationAlerts); ok {
+		summary.Alerts, _ = v.([]models.Alert)
+	}
+	return summary
+}
+
+func countCritical(alerts []models.Alert) int {
+	n := 0
+	for _, a := range alerts {
+		if a.Severity == models.AlertCritical {
+			n++
+		}
+	}
+	return n
 }
@@ -0,0 +1,57 @@
+package mosychlos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestSummaryError(t *testing.T) {
+	critical := []models.Alert{{Severity: models.AlertCritical}}
+	itemErrors := []models.BatchItemError{{CustomID: "risk-1", Message: "boom"}}
+
+	cases := []struct {
+		name     string
+		summary  models.RunSummary
+		wantCode int
+		wantErr  error
+		wantMsg  string
+	}{
+		{name: "success", summary: models.RunSummary{}},
+		{
+			name:     "critical alert",
+			summary:  models.RunSummary{Alerts: critical},
+			wantCode: models.ExitCodeCriticalAlert,
+			wantMsg:  "1 critical concentration alert(s) triggered",
+		},
+		{
+			name:     "failed items win over alerts",
+			summary:  models.RunSummary{ItemErrors: itemErrors, Alerts: critical},
+			wantCode: models.ExitCodeFailed,
+			wantErr:  models.ErrBatchItemsFailed,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := summaryError(c.summary)
+			if c.wantCode == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			var exitErr *exitCodeError
+			require.True(t, errors.As(err, &exitErr))
+			assert.Equal(t, c.wantCode, exitErr.code)
+			if c.wantErr != nil {
+				assert.ErrorIs(t, err, c.wantErr)
+			}
+			if c.wantMsg != "" {
+				assert.EqualError(t, err, c.wantMsg)
+			}
+		})
+	}
+}
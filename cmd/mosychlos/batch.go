@@ -3,12 +3,14 @@ package mosychlos
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
 	"github.com/amaurybrisou/mosychlos/internal/llm"
+	"github.com/amaurybrisou/mosychlos/internal/llm/batch"
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/fs"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
@@ -33,7 +35,8 @@ Examples:
   mosychlos batch submit risk
   mosychlos batch submit allocation *.json           # All JSON portfolios
   mosychlos batch submit --wait performance p1.json p2.json  # Wait for completion`,
-		Args: cobra.MinimumNArgs(1),
+		Args:              cobra.MinimumNArgs(1),
+		ValidArgsFunction: completeAnalysisTypes,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBatchSubmit(cmd, args, cfg)
 		},
@@ -41,10 +44,11 @@ Examples:
 
 	// Status command
 	var statusCmd = &cobra.Command{
-		Use:   "status [job-id]",
-		Short: "Check batch job status",
-		Long:  `Check the status of a batch processing job.`,
-		Args:  cobra.ExactArgs(1),
+		Use:               "status [job-id]",
+		Short:             "Check batch job status",
+		Long:              `Check the status of a batch processing job.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBatchJobIDs(cfg),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBatchStatus(cmd, args, cfg)
 		},
@@ -54,8 +58,13 @@ Examples:
 	var resultsCmd = &cobra.Command{
 		Use:   "results [job-id]",
 		Short: "Retrieve batch job results",
-		Long:  `Retrieve and display results from a completed batch job.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Retrieve and display results from a completed batch job.
+
+Examples:
+  mosychlos batch results batch_abc123                           # Print aggregated results
+  mosychlos batch results batch_abc123 --output results.jsonl    # Stream raw results to a file`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBatchJobIDs(cfg),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBatchResults(cmd, args, cfg)
 		},
@@ -67,7 +76,8 @@ Examples:
 		Short: "Retrieve batch job errors",
 		Long: `Retrieve and display errors from a batch job.
 Useful when a batch job fails or has no output file.`,
-		Args: cobra.ExactArgs(1),
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBatchJobIDs(cfg),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBatchErrors(cmd, args, cfg)
 		},
@@ -75,10 +85,11 @@ Useful when a batch job fails or has no output file.`,
 
 	// Wait command
 	var waitCmd = &cobra.Command{
-		Use:   "wait [job-id]",
-		Short: "Wait for batch job completion",
-		Long:  `Wait for a batch job to complete and retrieve results.`,
-		Args:  cobra.ExactArgs(1),
+		Use:               "wait [job-id]",
+		Short:             "Wait for batch job completion",
+		Long:              `Wait for a batch job to complete and retrieve results.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBatchJobIDs(cfg),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBatchWait(cmd, args, cfg)
 		},
@@ -86,10 +97,11 @@ Useful when a batch job fails or has no output file.`,
 
 	// Cancel command
 	var cancelCmd = &cobra.Command{
-		Use:   "cancel [job-id]",
-		Short: "Cancel a batch job",
-		Long:  `Cancel a running or queued batch job.`,
-		Args:  cobra.ExactArgs(1),
+		Use:               "cancel [job-id]",
+		Short:             "Cancel a batch job",
+		Long:              `Cancel a running or queued batch job.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeBatchJobIDs(cfg),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBatchCancel(cmd, args, cfg)
 		},
@@ -104,7 +116,9 @@ Useful when a batch job fails or has no output file.`,
 Examples:
   mosychlos batch list                    # List all jobs
   mosychlos batch list --limit 10         # Limit to 10 most recent jobs
-  mosychlos batch list --status completed # Only show completed jobs`,
+  mosychlos batch list --status completed # Only show completed jobs
+  mosychlos batch list --next             # Continue from where the previous listing stopped
+  mosychlos batch list --all --limit 50   # Walk every page, 50 jobs per request`,
 		Args: cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return runBatchList(cmd, args, cfg)
@@ -116,6 +130,9 @@ Examples:
 	submitCmd.Flags().Duration("timeout", 30*time.Minute, "Timeout for waiting")
 	submitCmd.Flags().StringSlice("types", []string{"risk"}, "Analysis types to perform")
 
+	// Add flags for results command
+	resultsCmd.Flags().StringP("output", "o", "", "Stream the raw results JSONL to this file instead of printing them")
+
 	// Add timeout flag for wait command
 	waitCmd.Flags().Duration("timeout", 30*time.Minute, "Timeout for waiting")
 
@@ -123,6 +140,9 @@ Examples:
 	listCmd.Flags().Int("limit", 20, "Maximum number of jobs to return")
 	listCmd.Flags().String("status", "", "Filter by status (validating, in_progress, completed, failed, etc.)")
 	listCmd.Flags().String("after", "", "Show jobs created after this job ID (pagination)")
+	listCmd.Flags().Bool("next", false, "Fetch the page following the last listing (cursor persisted in cache_dir)")
+	listCmd.Flags().Bool("all", false, "Iterate over every page, using --limit as the page size")
+	listCmd.MarkFlagsMutuallyExclusive("next", "after")
 
 	batchCmd.AddCommand(submitCmd)
 	batchCmd.AddCommand(statusCmd)
@@ -200,15 +220,25 @@ func runBatchStatus(_ *cobra.Command, args []string, cfg *config.Config) error {
 	return nil
 }
 
-func runBatchResults(_ *cobra.Command, args []string, cfg *config.Config) error {
+func runBatchResults(cmd *cobra.Command, args []string, cfg *config.Config) error {
 	jobID := args[0]
 	ctx := context.Background()
+	output, _ := cmd.Flags().GetString("output")
 
 	bm, err := getBatchManager(cfg)
 	if err != nil {
 		return fmt.Errorf("failed to get batch manager: %w", err)
 	}
 
+	if output != "" {
+		n, err := bm.DownloadResults(ctx, jobID, fs.OS{}, output)
+		if err != nil {
+			return fmt.Errorf("failed to download job results: %w", err)
+		}
+		fmt.Printf("Job results written to %s (%d bytes)\n", output, n)
+		return nil
+	}
+
 	resp, err := bm.GetResults(ctx, jobID)
 	if err != nil {
 		return fmt.Errorf("failed to get job results: %w", err)
@@ -290,6 +320,20 @@ func runBatchList(cmd *cobra.Command, _ []string, cfg *config.Config) error {
 	limit, _ := cmd.Flags().GetInt("limit")
 	status, _ := cmd.Flags().GetString("status")
 	after, _ := cmd.Flags().GetString("after")
+	next, _ := cmd.Flags().GetBool("next")
+	all, _ := cmd.Flags().GetBool("all")
+
+	cursors := batch.NewCursorStore(fs.OS{}, cfg.CacheDir)
+	if next {
+		cursor, err := cursors.Load()
+		if err != nil {
+			return err
+		}
+		if cursor == "" {
+			fmt.Println("No previous listing found, starting from the most recent jobs")
+		}
+		after = cursor
+	}
 
 	// Create batch service
 	bm, err := getBatchManager(cfg)
@@ -309,38 +353,51 @@ func runBatchList(cmd *cobra.Command, _ []string, cfg *config.Config) error {
 		filters["after"] = after
 	}
 
-	jobs, err := bm.ListBatches(ctx, filters)
+	var jobs []models.BatchJob
+	if all {
+		jobs, err = bm.ListAllBatches(ctx, filters, limit)
+	} else {
+		jobs, err = bm.ListBatches(ctx, filters)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to list batch jobs: %w", err)
 	}
 
-	// Iterate over jobs and print each one nicely
+	if err := cursors.Save(batch.LastJobID(jobs)); err != nil {
+		slog.Warn("Failed to persist batch list cursor", "error", err)
+	}
+
 	for _, job := range jobs {
-		fmt.Println("--------------------------------------------------")
-		fmt.Printf("Job ID          : %s\n", job.ID)
-		fmt.Printf("Status          : %s\n", job.Status)
-		fmt.Printf("Input File ID   : %s\n", job.InputFileID)
-		if job.OutputFileID != nil {
-			fmt.Printf("Output File ID  : %s\n", *job.OutputFileID)
-		} else {
-			fmt.Printf("Output File ID  : none\n")
-		}
-		if job.ErrorFileID != nil {
-			fmt.Printf("Error File ID   : %s\n", *job.ErrorFileID)
-		} else {
-			fmt.Printf("Error File ID   : none\n")
-		}
-		createdAt := time.Unix(job.CreatedAt, 0)
-		fmt.Printf("Created At      : %s\n", createdAt.Format(time.RFC3339))
-		if job.CompletedAt != nil {
-			completedAt := time.Unix(*job.CompletedAt, 0)
-			fmt.Printf("Completed At    : %s\n", completedAt.Format(time.RFC3339))
-		} else {
-			fmt.Printf("Completed At    : not completed\n")
-		}
-		fmt.Printf("Request Counts  : Total: %d, Completed: %d, Failed: %d\n",
-			job.RequestCounts.Total, job.RequestCounts.Completed, job.RequestCounts.Failed)
+		printBatchJob(job)
 	}
 
 	return nil
 }
+
+// printBatchJob prints a single batch job in a human readable block
+func printBatchJob(job models.BatchJob) {
+	fmt.Println("--------------------------------------------------")
+	fmt.Printf("Job ID          : %s\n", job.ID)
+	fmt.Printf("Status          : %s\n", job.Status)
+	fmt.Printf("Input File ID   : %s\n", job.InputFileID)
+	if job.OutputFileID != nil {
+		fmt.Printf("Output File ID  : %s\n", *job.OutputFileID)
+	} else {
+		fmt.Printf("Output File ID  : none\n")
+	}
+	if job.ErrorFileID != nil {
+		fmt.Printf("Error File ID   : %s\n", *job.ErrorFileID)
+	} else {
+		fmt.Printf("Error File ID   : none\n")
+	}
+	createdAt := time.Unix(job.CreatedAt, 0)
+	fmt.Printf("Created At      : %s\n", createdAt.Format(time.RFC3339))
+	if job.CompletedAt != nil {
+		completedAt := time.Unix(*job.CompletedAt, 0)
+		fmt.Printf("Completed At    : %s\n", completedAt.Format(time.RFC3339))
+	} else {
+		fmt.Printf("Completed At    : not completed\n")
+	}
+	fmt.Printf("Request Counts  : Total: %d, Completed: %d, Failed: %d\n",
+		job.RequestCounts.Total, job.RequestCounts.Completed, job.RequestCounts.Failed)
+}
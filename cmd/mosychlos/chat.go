@@ -0,0 +1,149 @@
+package mosychlos
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaurybrisou/mosychlos/internal/budget"
+	"github.com/amaurybrisou/mosychlos/internal/chat"
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/engine"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// chatMaxToolCalls caps the calls of each tool over a chat session
+const chatMaxToolCalls = 10
+
+// chatEnv is what a chat needs from the rest of the application
+type chatEnv struct {
+	ai     chat.Asker
+	tools  []models.ToolDef
+	system string
+}
+
+// chatSetup prepares the AI client, tools and portfolio context of a chat
+type chatSetup func(ctx context.Context) (*chatEnv, error)
+
+func NewChatCommand(cfg *config.Config) *cobra.Command {
+	return newChatCommand(cfg, orchestratorChat(cfg))
+}
+
+func newChatCommand(cfg *config.Config, setup chatSetup) *cobra.Command {
+	var resume string
+
+	cmd := &cobra.Command{
+		Use:   "chat",
+		Short: "Chat with the AI about your portfolio",
+		Long: `Start an interactive session where the portfolio and the data tools are in context,
+to ask follow-up questions. Conversations are saved under <data_dir>/chat after every answer
+and can be resumed with --resume.
+
+Examples:
+  mosychlos chat                  # new conversation
+  mosychlos chat --resume latest  # continue the most recent conversation
+  mosychlos chat --resume <id>    # continue a given conversation`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx := cmd.Context()
+			store := chat.NewStore(fs.OS{}, filepath.Join(cfg.DataDir, "chat"))
+
+			conversation := chat.NewConversation(time.Now())
+			if resume != "" {
+				c, err := store.Load(resume)
+				if err != nil {
+					return err
+				}
+				conversation = c
+			}
+
+			env, err := setup(ctx)
+			if err != nil {
+				return err
+			}
+
+			repl := &chat.REPL{
+				AI:           env.ai,
+				Store:        store,
+				Conversation: conversation,
+				System:       env.system,
+				Tools:        env.tools,
+			}
+			return repl.Run(ctx, cmd.InOrStdin(), cmd.OutOrStdout())
+		},
+	}
+
+	cmd.Flags().StringVar(&resume, "resume", "", `Resume a saved conversation by ID ("latest" for the most recent)`)
+	return cmd
+}
+
+// orchestratorChat loads the portfolio, tools and AI client through the Engine
+// Orchestrator, without building the analysis engines
+func orchestratorChat(cfg *config.Config) chatSetup {
+	return func(ctx context.Context) (*chatEnv, error) {
+		orch := engine.New(cfg, engine.WithInitSteps(
+			engine.StepInitToolManager,
+			engine.StepLoadProfile,
+			engine.StepLoadRegionalSettings,
+			engine.StepLoadPortfolio,
+			engine.StepEvaluateAlerts,
+			engine.StepInitAIClient,
+		))
+		if err := orch.Init(ctx); err != nil {
+			return nil, fmt.Errorf("orchestrator init: %w", err)
+		}
+
+		// the same tool budget guard as the analysis engines
+		tools := orch.Tools()
+		maxCalls := make(map[bag.Key]int)
+		for _, t := range tools.List() {
+			maxCalls[t.Key()] = chatMaxToolCalls
+		}
+		constraints := models.BaseToolConstraints{
+			Tools:           tools.Defs(),
+			MaxCallsPerTool: maxCalls,
+		}
+		ai := orch.AI()
+		ai.SetToolConsumer(budget.NewToolConsumer(&constraints))
+		ai.RegisterTool(tools.List()...)
+
+		system, err := chat.SystemPrompt(chatPortfolio(cfg, orch.Bag()), chatAlerts(orch.Bag()))
+		if err != nil {
+			return nil, err
+		}
+		return &chatEnv{ai: ai, tools: constraints.Tools, system: system}, nil
+	}
+}
+
+// chatPortfolio returns the normalized portfolio of the bag, normalizing the raw
+// one when needed
+func chatPortfolio(cfg *config.Config, sharedBag bag.SharedBag) *models.NormalizedPortfolio {
+	if v, ok := sharedBag.Get(bag.KPortfolioNormalizedForAI); ok {
+		if n, ok := v.(*models.NormalizedPortfolio); ok && n != nil {
+			return n
+		}
+	}
+	v, _ := sharedBag.Get(bag.KPortfolio)
+	p, _ := v.(*models.Portfolio)
+	if p == nil {
+		return nil
+	}
+	n, err := p.NormalizeWith(cfg.Portfolio.NormalizeOptions())
+	if err != nil {
+		slog.Warn("Chat starts without the portfolio", "error", err)
+		return nil
+	}
+	return n
+}
+
+func chatAlerts(sharedBag bag.SharedBag) []models.Alert {
+	v, _ := sharedBag.Get(bag.KConcentrationAlerts)
+	alerts, _ := v.([]models.Alert)
+	return alerts
+}
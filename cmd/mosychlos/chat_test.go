@@ -0,0 +1,89 @@
+package mosychlos
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/chat"
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/models/mocks"
+)
+
+// runChat runs the chat command with a scripted stdin against ai
+func runChat(t *testing.T, cfg *config.Config, ai models.AiClient, stdin string, args ...string) (string, error) {
+	t.Helper()
+	cmd := newChatCommand(cfg, func(context.Context) (*chatEnv, error) {
+		return &chatEnv{ai: ai, system: "Portfolio: VOO 18%"}, nil
+	})
+	var out bytes.Buffer
+	cmd.SetIn(strings.NewReader(stdin))
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs(args)
+	err := cmd.Execute()
+	return out.String(), err
+}
+
+func TestChatCommand(t *testing.T) {
+	cfg := &config.Config{DataDir: t.TempDir()}
+	ctrl := gomock.NewController(t)
+	ai := mocks.NewMockAiClient(ctrl)
+
+	var requests []models.PromptRequest
+	answer := func(content string) func(context.Context, models.PromptRequest) (*models.LLMResponse, error) {
+		return func(_ context.Context, req models.PromptRequest) (*models.LLMResponse, error) {
+			requests = append(requests, req)
+			return &models.LLMResponse{Content: content}, nil
+		}
+	}
+
+	// first session: one answer, a failed question, then /exit
+	gomock.InOrder(
+		ai.EXPECT().Ask(gomock.Any(), gomock.Any()).DoAndReturn(answer(`{"output_text_1_0":"VOO is your largest position at 18%."}`)),
+		ai.EXPECT().Ask(gomock.Any(), gomock.Any()).Return(nil, errors.New("rate limited")),
+	)
+	out, err := runChat(t, cfg, ai, "What is my largest position?\n\nAnd the news?\n/exit\nignored\n")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Type /exit to quit.")
+	assert.Contains(t, out, "> VOO is your largest position at 18%.\n")
+	assert.Contains(t, out, "error: rate limited")
+
+	require.Len(t, requests, 1)
+	assert.Equal(t, []map[string]any{
+		{"role": "system", "content": "Portfolio: VOO 18%"},
+		{"role": "user", "content": "What is my largest position?"},
+	}, requests[0].Messages)
+
+	// the failed question is not saved
+	saved, err := chat.NewStore(fs.OS{}, filepath.Join(cfg.DataDir, "chat")).Load("latest")
+	require.NoError(t, err)
+	assert.Len(t, saved.Messages, 2)
+
+	// second session resumes the conversation until the end of the input
+	ai.EXPECT().Ask(gomock.Any(), gomock.Any()).DoAndReturn(answer("Trim it below 15%."))
+	out, err = runChat(t, cfg, ai, "Should I trim it?\n", "--resume", "latest")
+	require.NoError(t, err)
+	assert.Contains(t, out, "Resumed with 2 previous messages.")
+	assert.Contains(t, out, "Trim it below 15%.")
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, []map[string]any{
+		{"role": "system", "content": "Portfolio: VOO 18%"},
+		{"role": "user", "content": "What is my largest position?"},
+		{"role": "assistant", "content": "VOO is your largest position at 18%."},
+		{"role": "user", "content": "Should I trim it?"},
+	}, requests[1].Messages)
+
+	_, err = runChat(t, cfg, ai, "", "--resume", "unknown")
+	assert.ErrorContains(t, err, "conversation unknown not found")
+}
@@ -0,0 +1,114 @@
+package mosychlos
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/tools"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// completionTimeout bounds dynamic completions that call the API
+const completionTimeout = 5 * time.Second
+
+// completionJobLimit is the number of recent batch jobs offered as completions
+const completionJobLimit = 50
+
+// NewCompletionCommand creates the completion command generating shell scripts for root
+func NewCompletionCommand(root *cobra.Command) *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion [bash|zsh|fish]",
+		Short: "Generate shell completion scripts",
+		Long: `Generate a shell completion script for mosychlos.
+
+Besides commands and flags, the scripts complete batch job IDs, tool names,
+analysis types and report types/formats.
+
+Examples:
+  source <(mosychlos completion bash)                                  # Current bash session
+  mosychlos completion zsh > "${fpath[1]}/_mosychlos"                  # zsh, new sessions
+  mosychlos completion fish > ~/.config/fish/completions/mosychlos.fish # fish`,
+		ValidArgs:             []string{"bash", "zsh", "fish"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := cmd.OutOrStdout()
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletionV2(out, true)
+			case "zsh":
+				return root.GenZshCompletion(out)
+			case "fish":
+				return root.GenFishCompletion(out, true)
+			default:
+				return fmt.Errorf("unsupported shell: %s", args[0])
+			}
+		},
+	}
+}
+
+// completeBatchJobIDs completes the first argument with recent batch job IDs,
+// described by their status
+func completeBatchJobIDs(cfg *config.Config) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		bm, err := getBatchManager(cfg)
+		if err != nil {
+			cobra.CompDebugln(fmt.Sprintf("batch manager: %v", err), true)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+		defer cancel()
+		jobs, err := bm.ListBatches(ctx, map[string]string{"limit": fmt.Sprintf("%d", completionJobLimit)})
+		if err != nil {
+			cobra.CompDebugln(fmt.Sprintf("list batches: %v", err), true)
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		completions := make([]cobra.Completion, 0, len(jobs))
+		for _, job := range jobs {
+			if strings.HasPrefix(job.ID, toComplete) {
+				completions = append(completions, cobra.CompletionWithDesc(job.ID, string(job.Status)))
+			}
+		}
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+// completeToolNames completes the first argument with the registered tool names
+func completeToolNames(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return tools.Registered(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeAnalysisTypes completes the first argument with the supported analysis types
+func completeAnalysisTypes(_ *cobra.Command, args []string, _ string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return []cobra.Completion{
+		string(models.AnalysisRisk),
+		string(models.AnalysisAllocation),
+		string(models.AnalysisPerformance),
+		string(models.AnalysisCompliance),
+		string(models.AnalysisReallocation),
+		string(models.AnalysisInvestmentResearch),
+	}, cobra.ShellCompDirectiveNoFileComp
+}
+
+// reportFormats and reportTypes are the values completed for report flags
+var (
+	reportFormats = []cobra.Completion{string(models.FormatMarkdown), string(models.FormatPDF), string(models.FormatJSON)}
+	reportTypes   = []cobra.Completion{string(models.TypeFull), string(models.TypeCustomer), string(models.TypeSystem)}
+)
@@ -0,0 +1,72 @@
+package mosychlos
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/tools"
+)
+
+func runRoot(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+	root := newRootCmd(&config.Config{})
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetErr(&out)
+	root.SetArgs(args)
+	err := root.Execute()
+	return out.String(), err
+}
+
+func TestCompletionCommand(t *testing.T) {
+	cases := []struct {
+		shell string
+		want  string
+	}{
+		{shell: "bash", want: "__start_mosychlos"},
+		{shell: "zsh", want: "#compdef mosychlos"},
+		{shell: "fish", want: "complete -c mosychlos"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.shell, func(t *testing.T) {
+			out, err := runRoot(t, "completion", c.shell)
+			require.NoError(t, err)
+			assert.NotEmpty(t, out)
+			assert.Contains(t, out, c.want)
+		})
+	}
+
+	t.Run("unknown shell", func(t *testing.T) {
+		_, err := runRoot(t, "completion", "powershell-ish")
+		require.Error(t, err)
+	})
+}
+
+func TestDynamicCompletions(t *testing.T) {
+	cases := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "report types", args: []string{"__complete", "report", "--type", ""}, want: []string{"full", "customer", "system"}},
+		{name: "report formats", args: []string{"__complete", "report", "--format", ""}, want: []string{"markdown", "pdf", "json"}},
+		{name: "analysis types", args: []string{"__complete", "analyze", ""}, want: []string{"risk", "investment_research"}},
+		{name: "schedule types", args: []string{"__complete", "schedule", "add", "--type", ""}, want: []string{"risk"}},
+		{name: "tool names", args: []string{"__complete", "tools", ""}, want: tools.Registered()},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			out, err := runRoot(t, c.args...)
+			require.NoError(t, err)
+			for _, w := range c.want {
+				assert.Contains(t, out, w+"\n")
+			}
+		})
+	}
+}
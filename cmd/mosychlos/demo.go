@@ -0,0 +1,123 @@
+package mosychlos
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/engine"
+	"github.com/amaurybrisou/mosychlos/internal/report"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/demo"
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+	"github.com/spf13/cobra"
+)
+
+// NewDemoCommand runs the analysis pipeline on a generated portfolio with the
+// network off, so the tool can be tried without data files or API keys
+func NewDemoCommand(cfg *config.Config) *cobra.Command {
+	var (
+		seed      int64
+		holdings  int
+		outputDir string
+		formats   []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "demo",
+		Short: "Run an offline analysis of a sample portfolio",
+		Long: `Analyze a built-in sample portfolio and investor profile end to end, then write the reports.
+Sandbox mode is forced on: the LLM answers from canned data and no request leaves the machine.
+The same seed always yields the same portfolio.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if holdings <= 0 {
+				return fmt.Errorf("--holdings must be positive, got %d", holdings)
+			}
+			if outputDir == "" {
+				outputDir = filepath.Join(cfg.DataDir, cmp.Or(cfg.Report.OutputDir, "reports"), "demo")
+			}
+			if err := runDemo(cmd.Context(), demoConfig(cfg), seed, holdings, outputDir, formats); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Demo reports generated in %s\n", outputDir)
+			return nil
+		},
+	}
+
+	cmd.Flags().Int64Var(&seed, "seed", 1, "Seed of the sample portfolio (same seed, same portfolio)")
+	cmd.Flags().IntVar(&holdings, "holdings", 15, "Number of holdings in the sample portfolio")
+	cmd.Flags().StringVar(&outputDir, "output", "", "Output directory for reports (default <data_dir>/reports/demo)")
+	// no pdf by default: the report writer would fill it with the markdown
+	cmd.Flags().StringSliceVar(&formats, "format", []string{"markdown", "json"}, "Report formats (markdown, pdf, json)")
+
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(reportFormats, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// demoConfig returns a copy of cfg that runs offline: sandbox on, no data tools
+// (they would only hit the network) and the demo investor's localization
+func demoConfig(cfg *config.Config) *config.Config {
+	c := *cfg
+	c.Sandbox = true
+	c.Tools.EnabledTools = nil
+	c.Tools.Exec = nil
+	c.Tools.HTTP = nil
+	c.LLM.Provider = cmp.Or(c.LLM.Provider, "openai")
+	c.LLM.APIKey = cmp.Or(c.LLM.APIKey, config.SandboxAPIKey)
+	c.Portfolio.BaseCurrency = ""
+
+	profile := demo.Profile()
+	c.Localization.Country = profile.RegionalContext.Country
+	c.Localization.Language = profile.RegionalContext.Language
+	c.Localization.Currency = profile.RegionalContext.Currency
+	c.Localization.Timezone = profile.RegionalContext.Timezone
+	return &c
+}
+
+// runDemo analyzes the sample portfolio of seed and writes the full report in
+// each format to outputDir
+func runDemo(ctx context.Context, cfg *config.Config, seed int64, holdings int, outputDir string, formats []string) error {
+	sandbox.Enable()
+
+	sharedBag := bag.NewSharedBag()
+	preloadPortfolio(cfg, sharedBag, demo.GeneratePortfolio(seed, holdings, demo.Options{}), time.Now())
+	// the profile and localization services keep what is already in the bag, so
+	// the demo needs no profile or template files
+	sharedBag.Set(bag.KProfile, demo.Profile())
+	sharedBag.Set(bag.KRegionalConfig, &models.RegionalConfig{LocalizationConfig: cfg.Localization})
+
+	o := engine.New(
+		cfg,
+		engine.WithBag(sharedBag),
+		engine.WithFS(fs.OS{}),
+		engine.WithBuilder(engine.DefaultRegistry()),
+	)
+	if err := o.Init(ctx); err != nil {
+		return fmt.Errorf("failed to initialize engine orchestrator: %w", err)
+	}
+	if err := o.ExecutePipeline(ctx); err != nil {
+		return fmt.Errorf("demo analysis failed: %w", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+	fullData, err := report.NewBagLoader(fs.New(outputDir)).LoadFullData(ctx, sharedBag)
+	if err != nil {
+		return fmt.Errorf("failed to extract report data: %w", err)
+	}
+	for _, format := range formats {
+		opts := report.Options{IncludeReasoning: cfg.Report.IncludeReasoning, HoldingsSort: cfg.Report.HoldingsSort}
+		if err := report.GenerateReportWithOptions(fullData, outputDir, format, models.TypeFull, opts); err != nil {
+			return fmt.Errorf("failed to generate %s report: %w", format, err)
+		}
+	}
+	return nil
+}
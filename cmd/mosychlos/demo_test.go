@@ -0,0 +1,79 @@
+package mosychlos
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+func TestDemoCommand(t *testing.T) {
+	t.Cleanup(sandbox.Disable)
+
+	// no API key, no config files and tools that would need the network
+	cfg := &config.Config{DataDir: t.TempDir(), ConfigDir: t.TempDir()}
+	cfg.Tools.EnabledTools = []string{"news_api", "fmp"}
+	outputDir := filepath.Join(t.TempDir(), "reports")
+
+	cmd := NewDemoCommand(cfg)
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetErr(&out)
+	cmd.SetArgs([]string{"--seed", "7", "--holdings", "10", "--output", outputDir})
+	require.NoError(t, cmd.Execute())
+
+	assert.True(t, sandbox.Enabled(), "the demo must run with the network off")
+	assert.Contains(t, out.String(), "Demo reports generated in "+outputDir)
+	assert.Empty(t, cfg.LLM.APIKey, "the demo must not alter the loaded config")
+	assert.Equal(t, []string{"news_api", "fmp"}, cfg.Tools.EnabledTools)
+
+	reports := map[string]string{}
+	entries, err := os.ReadDir(outputDir)
+	require.NoError(t, err)
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join(outputDir, e.Name()))
+		require.NoError(t, err)
+		reports[filepath.Ext(e.Name())] = string(data)
+	}
+	require.Len(t, reports, 2)
+	assert.Contains(t, reports[".md"], "Demo Brokerage 1 (10 holdings)")
+	assert.Contains(t, reports[".md"], "### Rebalancing Plan")
+	// the analysis reached the LLM client, answered by the sandbox stub
+	assert.Contains(t, reports[".md"], "**openai_api**")
+
+	var full map[string]any
+	require.NoError(t, json.Unmarshal([]byte(reports[".json"]), &full))
+	assert.Contains(t, full, "customer")
+	assert.Contains(t, full, "system")
+}
+
+func TestDemoCommand_InvalidHoldings(t *testing.T) {
+	cmd := NewDemoCommand(&config.Config{DataDir: t.TempDir()})
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--holdings", "0"})
+	assert.ErrorContains(t, cmd.Execute(), "--holdings must be positive")
+}
+
+func TestIsDemo(t *testing.T) {
+	cases := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"demo"}, true},
+		{[]string{"--sandbox", "demo", "--seed", "3"}, true},
+		{[]string{"portfolio", "--demo"}, false},
+		{[]string{"--sandbox"}, false},
+		{nil, false},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.want, isDemo(c.args), c.args)
+	}
+}
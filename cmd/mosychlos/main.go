@@ -2,29 +2,91 @@
 package mosychlos
 
 import (
+	"cmp"
+	"errors"
 	"log"
+	"os"
+	"strings"
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
+	pkgagents "github.com/amaurybrisou/mosychlos/pkg/agents"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
 	"github.com/spf13/cobra"
 )
 
 func RootCmd() {
+	// the demo runs offline, so its config must load without an API key
+	if isDemo(os.Args[1:]) {
+		_ = os.Setenv("MOSYCHLOS_SANDBOX", "true")
+	}
+	cfg := config.MustLoadConfig()
+
+	if err := newRootCmd(cfg).Execute(); err != nil {
+		var exitErr *exitCodeError
+		if errors.As(err, &exitErr) {
+			log.Print(err)
+			os.Exit(exitErr.code)
+		}
+		log.Fatal(err)
+	}
+}
+
+// isDemo reports whether the command line runs the demo command
+func isDemo(args []string) bool {
+	for _, a := range args {
+		if !strings.HasPrefix(a, "-") {
+			return a == "demo"
+		}
+	}
+	return false
+}
+
+// exitCodeError makes the process exit with a specific code
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// newRootCmd builds the command tree for the given configuration
+func newRootCmd(cfg *config.Config) *cobra.Command {
 	var rootCmd = &cobra.Command{
 		Use:   "mosychlos",
 		Short: "Interactive portfolio management CLI",
 		Long:  `An interactive command-line interface for managing and analyzing your portfolio.`,
 	}
+	// replaced by our completion command, which also completes job IDs and tool names
+	rootCmd.CompletionOptions.DisableDefaultCmd = true
+
+	rootCmd.PersistentFlags().Bool("sandbox", cfg.Sandbox, "Block all outbound network calls and use canned LLM/news data")
+	rootCmd.PersistentPreRunE = func(cmd *cobra.Command, _ []string) error {
+		if enabled, _ := cmd.Flags().GetBool("sandbox"); enabled {
+			cfg.Sandbox = true
+		}
+		if cfg.Sandbox {
+			sandbox.Enable()
+			// agent engines call OpenAI through the SDK's own client
+			pkgagents.UseSandboxClient(cfg.LLM.BaseURL, cmp.Or(cfg.LLM.APIKey, config.SandboxAPIKey))
+		}
+		return nil
+	}
 
-	cfg := config.MustLoadConfig()
 	rootCmd.AddCommand(NewPortfolioCommand(cfg))
 	rootCmd.AddCommand(NewAnalyzeCommand(cfg))
+	rootCmd.AddCommand(NewChatCommand(cfg))
 	rootCmd.AddCommand(CreateToolsCommand(cfg))
 	// Add batch processing command
 	rootCmd.AddCommand(CreateBatchCommand(cfg))
 	rootCmd.AddCommand(NewReportCommand(cfg))
 	rootCmd.AddCommand(NewMarkdownToPDFCommand(cfg))
+	rootCmd.AddCommand(NewMetricsCommand(cfg))
+	rootCmd.AddCommand(NewServeCommand(cfg))
+	rootCmd.AddCommand(NewScheduleCommand(cfg))
+	rootCmd.AddCommand(NewDemoCommand(cfg))
+	rootCmd.AddCommand(NewVersionCommand())
+	rootCmd.AddCommand(NewCompletionCommand(rootCmd))
 
-	if err := rootCmd.Execute(); err != nil {
-		log.Fatal(err)
-	}
+	return rootCmd
 }
@@ -0,0 +1,25 @@
+package mosychlos
+
+import (
+	"testing"
+
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+func TestRootCmd_SandboxGuardsAgents(t *testing.T) {
+	t.Cleanup(sandbox.Disable)
+	t.Cleanup(agents.ClearOpenaiSettings)
+
+	_, err := runRoot(t, "version")
+	require.NoError(t, err)
+	assert.Nil(t, agents.GetDefaultOpenaiClient(), "the SDK keeps its own client outside the sandbox")
+
+	_, err = runRoot(t, "--sandbox", "version")
+	require.NoError(t, err)
+	assert.True(t, sandbox.Enabled())
+	assert.NotNil(t, agents.GetDefaultOpenaiClient(), "agent runs must go through the sandbox guard")
+}
@@ -0,0 +1,106 @@
+package mosychlos
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/health"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/spf13/cobra"
+)
+
+// metricsFormats lists the formats supported by metrics dump
+var metricsFormats = []string{"prometheus", "json"}
+
+// NewMetricsCommand creates the metrics command
+func NewMetricsCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "metrics",
+		Short: "Export tool and provider metrics",
+	}
+	cmd.AddCommand(newMetricsDumpCommand(cfg))
+	return cmd
+}
+
+func newMetricsDumpCommand(cfg *config.Config) *cobra.Command {
+	var (
+		input  string
+		format string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "dump",
+		Short: "Print the metrics of a saved run",
+		Long: `Print tool call counts, durations, success rates, token usage, cost and
+provider health recorded in a saved bag file (the latest run by default).`,
+		Example: "  mosychlos metrics dump --format prometheus > mosychlos.prom",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if input == "" {
+				latest, err := latestBagFile(filepath.Join(cfg.DataDir, "bag"))
+				if err != nil {
+					return err
+				}
+				input = latest
+			}
+
+			file, err := os.Open(input)
+			if err != nil {
+				return fmt.Errorf("failed to open bag file: %w", err)
+			}
+			defer file.Close()
+
+			sharedBag, err := bag.LoadSharedBagFromJSON(file)
+			if err != nil {
+				return fmt.Errorf("failed to load bag: %w", err)
+			}
+
+			out := cmd.OutOrStdout()
+			switch strings.ToLower(format) {
+			case "prometheus":
+				return health.WritePrometheus(out, sharedBag)
+			case "json":
+				metrics := map[string]any{}
+				for _, k := range []bag.Key{bag.KToolMetrics, bag.KExternalDataHealth} {
+					if v, ok := sharedBag.Get(k); ok {
+						metrics[k.String()] = v
+					}
+				}
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(metrics)
+			default:
+				return fmt.Errorf("unsupported format %q (use %s)", format, strings.Join(metricsFormats, " or "))
+			}
+		},
+	}
+
+	cmd.Flags().StringVar(&input, "input", "", "Path to a saved bag JSON file (default: latest run)")
+	cmd.Flags().StringVar(&format, "format", "prometheus", "Output format (prometheus, json)")
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(metricsFormats, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+// latestBagFile returns the most recent bag dump in dir. Dumps are named
+// <start time>_<run id>.json, so the last name in order is the latest run.
+func latestBagFile(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read bag directory: %w", err)
+	}
+
+	latest := ""
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".json") && e.Name() > latest {
+			latest = e.Name()
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no saved run in %s", dir)
+	}
+	return filepath.Join(dir, latest), nil
+}
@@ -0,0 +1,51 @@
+package mosychlos
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestMetricsDump(t *testing.T) {
+	sb := bag.NewSharedBag()
+	sb.Set(bag.KToolMetrics, models.ToolMetrics{
+		TotalCalls: 2,
+		ByTool:     map[string]models.ToolStats{"fred": {Calls: 2, Successes: 2}},
+	})
+	raw, err := json.Marshal(sb)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "20260101_090000_a.json"), []byte("{}"), 0o644))
+	latest := filepath.Join(dir, "20260102_090000_b.json")
+	require.NoError(t, os.WriteFile(latest, raw, 0o644))
+
+	got, err := latestBagFile(dir)
+	require.NoError(t, err)
+	assert.Equal(t, latest, got)
+
+	cases := []struct {
+		format string
+		want   string
+	}{
+		{format: "prometheus", want: `mosychlos_tool_calls_total{tool="fred"} 2`},
+		{format: "json", want: `"tool_metrics"`},
+	}
+	for _, c := range cases {
+		t.Run(c.format, func(t *testing.T) {
+			out, err := runRoot(t, "metrics", "dump", "--input", latest, "--format", c.format)
+			require.NoError(t, err)
+			assert.Contains(t, out, c.want)
+		})
+	}
+
+	_, err = runRoot(t, "metrics", "dump", "--input", latest, "--format", "csv")
+	assert.ErrorContains(t, err, "unsupported format")
+}
@@ -12,7 +12,10 @@ import (
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
 	"github.com/amaurybrisou/mosychlos/internal/engine"
+	"github.com/amaurybrisou/mosychlos/internal/portfolio"
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/demo"
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
 	"github.com/spf13/cobra"
 )
@@ -28,6 +31,10 @@ func NewPortfolioCommand(cfg *config.Config) *cobra.Command {
 	var (
 		mode           string
 		nonInteractive bool
+		useDemo        bool
+		demoSeed       int64
+		demoHoldings   int
+		manifest       string
 	)
 
 	cmd := &cobra.Command{
@@ -37,31 +44,81 @@ func NewPortfolioCommand(cfg *config.Config) *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
-			return runPortfolioUI(ctx, cfg, mode, nonInteractive)
+			load := orchestratorPortfolio(cfg)
+			switch {
+			case useDemo:
+				load = demoPortfolio(demoSeed, demoHoldings)
+			case manifest != "":
+				load = manifestPortfolio(manifest)
+			}
+			return runPortfolioUI(ctx, cfg, load, mode, nonInteractive)
 		},
 	}
 
 	cmd.Flags().StringVarP(&mode, "mode", "m", "", "Display mode: summary | detailed | accounts | compliance")
 	cmd.Flags().BoolVar(&nonInteractive, "no-input", false, "Non-interactive mode (requires --mode)")
+	cmd.Flags().BoolVar(&useDemo, "demo", false, "Show a generated demo portfolio instead of your own")
+	cmd.Flags().Int64Var(&demoSeed, "demo-seed", 1, "Seed of the demo portfolio (same seed, same portfolio)")
+	cmd.Flags().IntVar(&demoHoldings, "demo-holdings", 15, "Number of holdings in the demo portfolio")
+	cmd.Flags().StringVar(&manifest, "manifest", "", "Manifest listing portfolio files to consolidate into one portfolio")
+	cmd.MarkFlagsMutuallyExclusive("demo", "manifest")
 
 	return cmd
 }
 
-func runPortfolioUI(ctx context.Context, cfg *config.Config, mode string, nonInteractive bool) error {
-	// 1) Build orchestrator (single source of truth)
-	// If you have options/registry, pass them here (e.g., engine.WithFS(...), engine.WithRegistry(...))
-	orch := engine.New(cfg)
+// portfolioLoader returns the portfolio to display
+type portfolioLoader func(ctx context.Context) (*models.Portfolio, error)
+
+// orchestratorPortfolio loads the user's portfolio through the Engine Orchestrator
+func orchestratorPortfolio(cfg *config.Config) portfolioLoader {
+	return func(ctx context.Context) (*models.Portfolio, error) {
+		// build orchestrator (single source of truth) and initialize it (sets up tools, portfolio, profile, LLM, etc.)
+		orch := engine.New(cfg)
+		if err := orch.Init(ctx); err != nil {
+			return nil, fmt.Errorf("orchestrator init: %w", err)
+		}
+
+		// Pull portfolio from the orchestrator's shared state
+		portfolioData, ok := orch.Bag().Get(bag.KPortfolio)
+		if !ok || portfolioData == nil {
+			return nil, errors.New("portfolio not available in orchestrator state (keys.KPortfolio)")
+		}
 
-	// 2) Initialize once (sets up tools, portfolio, profile, LLM, etc.)
-	if err := orch.Init(ctx); err != nil {
-		return fmt.Errorf("orchestrator init: %w", err)
+		portfolio, ok := portfolioData.(*models.Portfolio)
+		if !ok {
+			return nil, errors.New("unexpected portfolio type in bag")
+		}
+		return portfolio, nil
+	}
+}
+
+// manifestPortfolio consolidates the portfolio files listed in a manifest
+func manifestPortfolio(path string) portfolioLoader {
+	return func(ctx context.Context) (*models.Portfolio, error) {
+		return portfolio.ManifestFetcher{FS: fs.OS{}, Path: path}.Fetch(ctx)
+	}
+}
+
+// demoPortfolio serves a deterministic generated portfolio, no data files or API keys needed
+func demoPortfolio(seed int64, holdings int) portfolioLoader {
+	return func(context.Context) (*models.Portfolio, error) {
+		if holdings <= 0 {
+			return nil, fmt.Errorf("--demo-holdings must be positive, got %d", holdings)
+		}
+		return demo.GeneratePortfolio(seed, holdings, demo.Options{}), nil
+	}
+}
+
+func runPortfolioUI(ctx context.Context, cfg *config.Config, load portfolioLoader, mode string, nonInteractive bool) error {
+	portfolio, err := load(ctx)
+	if err != nil {
+		return err
 	}
 
 	reader := bufio.NewReader(os.Stdin)
 
 	for {
 		// Resolve mode (flag or prompt)
-		var err error
 		if mode == "" {
 			if nonInteractive {
 				return errors.New("non-interactive mode requires --mode")
@@ -72,20 +129,6 @@ func runPortfolioUI(ctx context.Context, cfg *config.Config, mode string, nonInt
 			}
 		}
 
-		// Pull portfolio from the orchestrator's shared state
-		// NOTE: Adjust if your orchestrator exposes the bag differently (e.g., orch.Bag(), orch.SharedBag(), orch.Snapshot()).
-		shareBag := orch.Bag() // <- common pattern; replace with your actual accessor if different
-		portfolioData, ok := shareBag.Get(bag.KPortfolio)
-		if !ok || portfolioData == nil {
-			return errors.New("portfolio not available in orchestrator state (keys.KPortfolio)")
-		}
-
-		// If you have a concrete type, assert it here (example):
-		portfolio, ok := portfolioData.(*models.Portfolio)
-		if !ok {
-			return errors.New("unexpected portfolio type in bag")
-		}
-
 		// Dispatch rendering by mode — plug your real renderers in place of the fmt.Println
 		switch mode {
 		case modeSummary:
@@ -10,6 +10,7 @@ import (
 	"github.com/amaurybrisou/mosychlos/internal/report"
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +20,8 @@ func NewReportCommand(cfg *config.Config) *cobra.Command {
 	inputDir := filepath.Join(cfg.DataDir, "bag")
 	outputDir := filepath.Join(cfg.DataDir, "reports")
 	var formats []string
+	reportType := string(models.TypeFull)
+	explain := false
 
 	cmd := &cobra.Command{
 		Use:   "report",
@@ -91,7 +94,7 @@ func NewReportCommand(cfg *config.Config) *cobra.Command {
 			}
 
 			for _, format := range formats {
-				if err := report.GenerateReport(fullData, outputDir, format); err != nil {
+				if err := report.GenerateReportWithOptions(fullData, outputDir, format, models.ReportType(reportType), report.Options{Explain: explain, IncludeReasoning: cfg.Report.IncludeReasoning, HoldingsSort: cfg.Report.HoldingsSort}); err != nil {
 					return fmt.Errorf("failed to generate %s report: %w", format, err)
 				}
 			}
@@ -104,6 +107,11 @@ func NewReportCommand(cfg *config.Config) *cobra.Command {
 	cmd.Flags().StringVar(&inputPath, "input", "", "Path to saved bag JSON file")
 	cmd.Flags().StringVar(&outputDir, "output", "mosychlos-data/reports", "Output directory for reports")
 	cmd.Flags().StringSliceVar(&formats, "format", []string{"markdown"}, "Report formats (markdown, pdf, json)")
+	cmd.Flags().StringVar(&reportType, "type", reportType, "Report type (full, customer, system)")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Annotate figures with the tool and fetch time they came from")
+
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(reportFormats, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("type", cobra.FixedCompletions(reportTypes, cobra.ShellCompDirectiveNoFileComp))
 
 	return cmd
 }
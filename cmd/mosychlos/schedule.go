@@ -0,0 +1,262 @@
+package mosychlos
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/engine"
+	"github.com/amaurybrisou/mosychlos/internal/report"
+	"github.com/amaurybrisou/mosychlos/internal/schedule"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/spf13/cobra"
+)
+
+// NewScheduleCommand creates the schedule command
+func NewScheduleCommand(cfg *config.Config) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "schedule",
+		Short: "Manage recurring analyses",
+		Long: `Persist recurring analyses and run the ones that are due.
+
+Schedules are stored in <data_dir>/schedules.json. Run "mosychlos schedule run"
+periodically (e.g. every few minutes from cron or a systemd timer); it executes
+every analysis due since its last run and stores the reports.`,
+	}
+	cmd.AddCommand(
+		newScheduleAddCommand(cfg),
+		newScheduleListCommand(cfg),
+		newScheduleRemoveCommand(cfg),
+		newScheduleRunCommand(cfg),
+	)
+	return cmd
+}
+
+func scheduleStore(cfg *config.Config) *schedule.Store {
+	return schedule.NewStore(fs.OS{}, filepath.Join(cfg.DataDir, "schedules.json"))
+}
+
+func newScheduleAddCommand(cfg *config.Config) *cobra.Command {
+	var (
+		cronExpr      string
+		analysisType  string
+		portfolioPath string
+		formats       []string
+	)
+
+	cmd := &cobra.Command{
+		Use:     "add",
+		Short:   "Add a recurring analysis",
+		Example: `  mosychlos schedule add --cron "0 8 * * 1" --type risk --portfolio p.json`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			// the scheduled run may start from another working directory
+			abs, err := filepath.Abs(portfolioPath)
+			if err != nil {
+				return fmt.Errorf("invalid portfolio path: %w", err)
+			}
+			if _, err := os.Stat(abs); err != nil {
+				return fmt.Errorf("portfolio file: %w", err)
+			}
+
+			sch, err := schedule.New(scheduleStore(cfg), nil).Add(schedule.Schedule{
+				Cron:      cronExpr,
+				Type:      models.AnalysisType(analysisType),
+				Portfolio: abs,
+				Formats:   formats,
+			})
+			if err != nil {
+				return err
+			}
+
+			next, _ := sch.NextRun()
+			fmt.Fprintf(cmd.OutOrStdout(), "Added schedule %s, next run %s\n", sch.ID, next.Format(time.RFC3339))
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&cronExpr, "cron", "", `Cron expression: minute hour day-of-month month day-of-week (e.g. "0 8 * * 1")`)
+	cmd.Flags().StringVar(&analysisType, "type", string(models.AnalysisRisk), "Analysis type")
+	cmd.Flags().StringVar(&portfolioPath, "portfolio", "", "Portfolio file (YAML or JSON)")
+	cmd.Flags().StringSliceVar(&formats, "format", []string{"markdown"}, "Report formats (markdown, pdf, json)")
+	_ = cmd.MarkFlagRequired("cron")
+	_ = cmd.MarkFlagRequired("portfolio")
+	_ = cmd.RegisterFlagCompletionFunc("type", func(*cobra.Command, []string, string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		types := make([]cobra.Completion, 0, len(schedule.AnalysisTypes))
+		for _, t := range schedule.AnalysisTypes {
+			types = append(types, string(t))
+		}
+		return types, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("format", cobra.FixedCompletions(reportFormats, cobra.ShellCompDirectiveNoFileComp))
+
+	return cmd
+}
+
+func newScheduleListCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List recurring analyses",
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			schedules, err := schedule.New(scheduleStore(cfg), nil).List()
+			if err != nil {
+				return err
+			}
+			if len(schedules) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No schedules")
+				return nil
+			}
+
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tCRON\tTYPE\tPORTFOLIO\tLAST RUN\tNEXT RUN\tLAST ERROR")
+			for _, sch := range schedules {
+				last := "-"
+				if !sch.LastRun.IsZero() {
+					last = sch.LastRun.Format(time.RFC3339)
+				}
+				next := "-"
+				if t, err := sch.NextRun(); err == nil && !t.IsZero() {
+					next = t.Format(time.RFC3339)
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", sch.ID, sch.Cron, sch.Type, sch.Portfolio, last, next, sch.LastError)
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func newScheduleRemoveCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <id>",
+		Short: "Remove a recurring analysis",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := schedule.New(scheduleStore(cfg), nil).Remove(args[0]); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Removed schedule %s\n", args[0])
+			return nil
+		},
+	}
+}
+
+func newScheduleRunCommand(cfg *config.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "run",
+		Short: "Run the analyses that are due",
+		Long: `Run every scheduled analysis due since its last run, then exit.
+Intended to be invoked periodically by cron or a systemd timer.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			results, err := schedule.New(scheduleStore(cfg), runScheduledAnalysis(cfg)).RunDue(cmd.Context())
+			if err != nil {
+				return err
+			}
+
+			var failed []error
+			for _, r := range results {
+				if r.Err != nil {
+					fmt.Fprintf(cmd.OutOrStdout(), "Schedule %s failed: %v\n", r.Schedule.ID, r.Err)
+					failed = append(failed, fmt.Errorf("schedule %s: %w", r.Schedule.ID, r.Err))
+					continue
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Schedule %s completed\n", r.Schedule.ID)
+			}
+			if len(results) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No analysis due")
+			}
+			return errors.Join(failed...)
+		},
+	}
+}
+
+// runScheduledAnalysis runs the engine pipeline on the schedule's portfolio file
+// and writes the reports to the configured report directory
+func runScheduledAnalysis(cfg *config.Config) schedule.RunFunc {
+	return func(ctx context.Context, sch schedule.Schedule) error {
+		builder, err := scheduleRegistry(sch.Type)
+		if err != nil {
+			return err
+		}
+		portfolio, err := readPortfolioFile(sch.Portfolio)
+		if err != nil {
+			return err
+		}
+
+		sharedBag := bag.NewSharedBag()
+		preloadPortfolio(cfg, sharedBag, portfolio, time.Now())
+
+		o := engine.New(
+			cfg,
+			engine.WithBag(sharedBag),
+			engine.WithFS(fs.OS{}),
+			engine.WithBuilder(builder),
+		)
+		if err := o.Init(ctx); err != nil {
+			return fmt.Errorf("failed to initialize engine orchestrator: %w", err)
+		}
+		if err := o.ExecutePipeline(ctx); err != nil {
+			return fmt.Errorf("%s analysis failed: %w", sch.Type, err)
+		}
+
+		outputDir := filepath.Join(cfg.DataDir, cfg.Report.OutputDir)
+		fullData, err := report.NewBagLoader(fs.New(outputDir)).LoadFullData(ctx, sharedBag)
+		if err != nil {
+			return fmt.Errorf("failed to extract report data: %w", err)
+		}
+
+		formats := sch.Formats
+		if len(formats) == 0 {
+			formats = []string{"markdown"}
+		}
+		for _, format := range formats {
+			opts := report.Options{IncludeReasoning: cfg.Report.IncludeReasoning, HoldingsSort: cfg.Report.HoldingsSort}
+			if err := report.GenerateReportWithOptions(fullData, outputDir, format, models.TypeFull, opts); err != nil {
+				return fmt.Errorf("failed to generate %s report: %w", format, err)
+			}
+		}
+		return nil
+	}
+}
+
+// scheduleRegistry returns the engine registry running analyses of type t
+func scheduleRegistry(t models.AnalysisType) (*engine.RegistryBuilder, error) {
+	switch t {
+	case models.AnalysisRisk:
+		return engine.DefaultRegistry(), nil
+	default:
+		return nil, fmt.Errorf("unsupported analysis type: %s", t)
+	}
+}
+
+// readPortfolioFile parses a portfolio from a YAML or JSON file
+func readPortfolioFile(path string) (*models.Portfolio, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read portfolio file: %w", err)
+	}
+	var p models.Portfolio
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse portfolio file %s: %w", path, err)
+	}
+	if len(p.Accounts) == 0 {
+		return nil, fmt.Errorf("portfolio file %s has no accounts", path)
+	}
+	return &p, nil
+}
+
+// preloadPortfolio seeds the bag so the portfolio init step uses p instead of fetching
+func preloadPortfolio(cfg *config.Config, sb bag.SharedBag, p *models.Portfolio, now time.Time) {
+	sb.Set(bag.KPortfolio, p)
+	sb.Set(bag.KPortfolioLastFetched, now)
+	if normalized, err := p.NormalizeWith(cfg.Portfolio.NormalizeOptions()); err == nil {
+		sb.Set(bag.KPortfolioNormalizedForAI, normalized)
+	}
+}
@@ -0,0 +1,58 @@
+package mosychlos
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/schedule"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestScheduleAdd_Type(t *testing.T) {
+	portfolio := filepath.Join(t.TempDir(), "p.json")
+	require.NoError(t, os.WriteFile(portfolio, []byte(`{"accounts": []}`), 0o644))
+
+	cases := []struct {
+		name    string
+		typ     string
+		wantErr string
+	}{
+		{name: "risk", typ: "risk"},
+		{name: "no registry yet", typ: "allocation", wantErr: "unsupported analysis type: allocation"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := &config.Config{DataDir: t.TempDir()}
+			cmd := NewScheduleCommand(cfg)
+			cmd.SetOut(&bytes.Buffer{})
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs([]string{"add", "--cron", "@daily", "--type", c.typ, "--portfolio", portfolio})
+			err := cmd.Execute()
+			if c.wantErr != "" {
+				assert.EqualError(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+
+			schedules, err := scheduleStore(cfg).Load()
+			require.NoError(t, err)
+			require.Len(t, schedules, 1)
+			assert.Equal(t, models.AnalysisRisk, schedules[0].Type)
+		})
+	}
+}
+
+func TestRunScheduledAnalysis_UnsupportedType(t *testing.T) {
+	// schedules saved before the type was restricted fail instead of running a risk analysis
+	run := runScheduledAnalysis(&config.Config{DataDir: t.TempDir()})
+	err := run(context.Background(), schedule.Schedule{Type: models.AnalysisAllocation, Portfolio: "missing.json"})
+	assert.EqualError(t, err, "unsupported analysis type: allocation")
+}
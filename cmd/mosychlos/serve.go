@@ -0,0 +1,59 @@
+package mosychlos
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/server"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/spf13/cobra"
+)
+
+// NewServeCommand creates the serve command
+func NewServeCommand(cfg *config.Config) *cobra.Command {
+	addr := "127.0.0.1:8080"
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve health, metrics and the latest report over HTTP",
+		Long: `Start a local HTTP server for dashboards and monitoring:
+
+  /healthz        overall health derived from the external data providers
+  /metrics        tool and provider metrics in Prometheus format
+  /report/latest  the most recent generated report (?format=md|json|pdf)
+
+Health and metrics are read from the latest saved run on every request.
+The server shuts down gracefully on SIGINT/SIGTERM.`,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			srv := server.New(addr, latestRunBag(cfg), filepath.Join(cfg.DataDir, cfg.Report.OutputDir))
+			fmt.Fprintf(cmd.OutOrStdout(), "Serving on http://%s (Ctrl+C to stop)\n", addr)
+			return srv.Run(ctx)
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", addr, "Address to listen on")
+	return cmd
+}
+
+// latestRunBag loads the bag of the latest saved run
+func latestRunBag(cfg *config.Config) server.BagSource {
+	return func() (bag.SharedBag, error) {
+		path, err := latestBagFile(filepath.Join(cfg.DataDir, "bag"))
+		if err != nil {
+			return nil, err
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open bag file: %w", err)
+		}
+		defer file.Close()
+		return bag.LoadSharedBagFromJSON(file)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"text/tabwriter"
@@ -12,10 +13,12 @@ import (
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
 	"github.com/amaurybrisou/mosychlos/internal/engine"
+	"github.com/amaurybrisou/mosychlos/internal/tools"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
 )
 
 // toolsCommand handles the tools command
-func toolsCommand(cmd *cobra.Command, _ []string, cfg *config.Config) error {
+func toolsCommand(cmd *cobra.Command, args []string, cfg *config.Config) error {
 	// Initialize shared bag for metrics tracking
 	orch := engine.New(cfg)
 
@@ -28,18 +31,33 @@ func toolsCommand(cmd *cobra.Command, _ []string, cfg *config.Config) error {
 	// Get verbose flag
 	verbose, _ := cmd.Flags().GetBool("verbose")
 
+	// a single tool is always shown in detail
+	name := ""
+	if len(args) > 0 {
+		name = args[0]
+		verbose = true
+	}
+
 	// Display tools information
-	return displayTools(orch, verbose)
+	return displayTools(orch, verbose, name)
 }
 
-// displayTools shows all available tools
-func displayTools(orch engine.Orchestrator, verbose bool) error {
+// displayTools shows all available tools, or only the one called name when set
+func displayTools(orch engine.Orchestrator, verbose bool, name string) error {
 	fmt.Println("📊 Mosychlos Financial Data Tools")
 	fmt.Println("=================================")
 	fmt.Println()
 
 	// Get all registered tools
 	allTools := orch.Tools().List()
+	if name != "" {
+		allTools = slices.DeleteFunc(allTools, func(t models.Tool) bool {
+			return t.Name() != name && t.Key().String() != name
+		})
+		if len(allTools) == 0 {
+			return fmt.Errorf("tool %q is not enabled (registered tools: %s)", name, strings.Join(tools.Registered(), ", "))
+		}
+	}
 	if len(allTools) == 0 {
 		fmt.Println("❌ No tools are currently registered.")
 		fmt.Println("   Check your configuration file and ensure tool API keys are set.")
@@ -110,7 +128,10 @@ Tools provide access to various financial data sources including:
 
 Examples:
   mosychlos tools              # Show compact list of all tools
-  mosychlos tools --verbose    # Show detailed information for each tool`,
+  mosychlos tools --verbose    # Show detailed information for each tool
+  mosychlos tools fred         # Show detailed information for a single tool`,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: completeToolNames,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return toolsCommand(cmd, args, cfg)
 		},
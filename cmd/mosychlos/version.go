@@ -0,0 +1,52 @@
+package mosychlos
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"github.com/amaurybrisou/mosychlos/pkg/version"
+)
+
+// NewVersionCommand creates the version command
+func NewVersionCommand() *cobra.Command {
+	var asJSON bool
+
+	cmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show binary, engine and SDK versions",
+		Long: `Show the mosychlos binary version, the analysis engine version stamped in
+reports, and the OpenAI SDK version. Include this output in support requests.
+
+Examples:
+  mosychlos version          # Human readable
+  mosychlos version --json   # Machine readable`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			info := version.Get()
+			out := cmd.OutOrStdout()
+
+			if asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(info)
+			}
+
+			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "mosychlos\t%s\n", info.Binary)
+			fmt.Fprintf(w, "engine\t%s\n", info.Engine)
+			fmt.Fprintf(w, "openai-go\t%s\n", info.OpenAISDK)
+			fmt.Fprintf(w, "go\t%s\n", info.GoVersion)
+			if info.Commit != "" {
+				fmt.Fprintf(w, "commit\t%s\n", info.Commit)
+			}
+			return w.Flush()
+		},
+	}
+
+	cmd.Flags().BoolVar(&asJSON, "json", false, "Print versions as JSON")
+
+	return cmd
+}
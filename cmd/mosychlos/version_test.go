@@ -0,0 +1,44 @@
+package mosychlos
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/version"
+)
+
+func TestVersionCommand(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		out, err := runRoot(t, "version")
+		require.NoError(t, err)
+		for _, field := range []string{"mosychlos", "engine", "openai-go", "go"} {
+			assert.Contains(t, out, field+" ")
+		}
+		assert.Contains(t, out, version.Engine)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		out, err := runRoot(t, "version", "--json")
+		require.NoError(t, err)
+
+		var got map[string]string
+		require.NoError(t, json.Unmarshal([]byte(out), &got))
+		for _, field := range []string{"binary", "engine", "openai_sdk", "go_version"} {
+			assert.NotEmpty(t, got[field], field)
+		}
+		assert.Equal(t, version.Engine, got["engine"])
+	})
+
+	t.Run("ldflags override", func(t *testing.T) {
+		old := version.Version
+		version.Version = "v9.9.9"
+		t.Cleanup(func() { version.Version = old })
+
+		out, err := runRoot(t, "version", "--json")
+		require.NoError(t, err)
+		assert.Contains(t, out, `"binary": "v9.9.9"`)
+	})
+}
@@ -0,0 +1,42 @@
+package chat
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// systemInstructions frame every chat turn
+const systemInstructions = `You are Mosychlos, a portfolio assistant answering follow-up questions about the user's portfolio.
+Base your answers on the portfolio below and on the tools you are given; call a tool when current market,
+news or macro data is needed. Do not invent figures: if information is missing, say so.
+Keep answers short and actionable.`
+
+// SystemPrompt builds the system message of a chat from the normalized portfolio
+// and its concentration alerts; either may be empty
+func SystemPrompt(p *models.NormalizedPortfolio, alerts []models.Alert) (string, error) {
+	var b strings.Builder
+	b.WriteString(systemInstructions)
+
+	if p != nil {
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			return "", fmt.Errorf("failed to marshal portfolio: %w", err)
+		}
+		b.WriteString("\n\nPortfolio:\n```json\n")
+		b.Write(data)
+		b.WriteString("\n```")
+	} else {
+		b.WriteString("\n\nNo portfolio is loaded.")
+	}
+
+	if len(alerts) > 0 {
+		b.WriteString("\n\nConcentration alerts:")
+		for _, a := range alerts {
+			fmt.Fprintf(&b, "\n- %s: %s", a.Severity, a.Message)
+		}
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,111 @@
+// Package chat runs an interactive, resumable conversation over the portfolio.
+package chat
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// latestFile holds the ID of the most recently saved conversation
+const latestFile = "latest"
+
+// Message is one turn of a conversation
+type Message struct {
+	Role      models.Role `json:"role"`
+	Content   string      `json:"content"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// Conversation is a persisted chat, resumable by ID
+type Conversation struct {
+	ID        string    `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Messages  []Message `json:"messages"`
+}
+
+// NewConversation starts an empty conversation
+func NewConversation(now time.Time) *Conversation {
+	return &Conversation{ID: uuid.NewString(), CreatedAt: now, UpdatedAt: now}
+}
+
+// Store persists conversations as one JSON file each in a directory
+type Store struct {
+	fs  fs.FS
+	dir string
+}
+
+// NewStore creates a store backed by dir
+func NewStore(fsys fs.FS, dir string) *Store {
+	return &Store{fs: fsys, dir: dir}
+}
+
+// Load returns the conversation with the given ID; "latest" resumes the most
+// recently saved one
+func (st *Store) Load(id string) (*Conversation, error) {
+	if id == latestFile {
+		data, err := st.fs.ReadFile(filepath.Join(st.dir, latestFile))
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errors.New("no conversation to resume")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read latest conversation: %w", err)
+		}
+		id = strings.TrimSpace(string(data))
+	}
+	if id == "" || strings.ContainsAny(id, `/\`) {
+		return nil, fmt.Errorf("invalid conversation ID %q", id)
+	}
+
+	data, err := st.fs.ReadFile(st.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("conversation %s not found", id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversation %s: %w", id, err)
+	}
+
+	var c Conversation
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse conversation %s: %w", id, err)
+	}
+	return &c, nil
+}
+
+// Save writes the conversation through a temp file and marks it as the latest
+func (st *Store) Save(c *Conversation) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation: %w", err)
+	}
+	if err := fs.EnsureDir(st.fs, st.dir); err != nil {
+		return fmt.Errorf("failed to create chat directory: %w", err)
+	}
+
+	path := st.path(c.ID)
+	tmp := path + ".tmp"
+	if err := st.fs.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	if err := st.fs.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	if err := st.fs.WriteFile(filepath.Join(st.dir, latestFile), []byte(c.ID), 0o644); err != nil {
+		return fmt.Errorf("failed to mark latest conversation: %w", err)
+	}
+	return nil
+}
+
+func (st *Store) path(id string) string {
+	return filepath.Join(st.dir, id+".json")
+}
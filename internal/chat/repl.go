@@ -0,0 +1,182 @@
+package chat
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// maxHistory bounds the number of past messages sent with each question
+const maxHistory = 40
+
+// Asker answers a prompt; models.AiClient satisfies it
+type Asker interface {
+	Ask(ctx context.Context, req models.PromptRequest) (*models.LLMResponse, error)
+}
+
+// REPL asks the model the questions read from its input, with the portfolio
+// context and the conversation so far, and saves the conversation after every
+// answer
+type REPL struct {
+	AI           Asker
+	Store        *Store
+	Conversation *Conversation
+	// System is sent first with every question and never persisted, so a resumed
+	// conversation always sees the current portfolio
+	System string
+	Tools  []models.ToolDef
+	Model  string
+	Now    func() time.Time
+}
+
+// Run reads questions line by line until /exit, /quit or the end of the input
+func (r *REPL) Run(ctx context.Context, in io.Reader, out io.Writer) error {
+	fmt.Fprintf(out, "Chatting about your portfolio (conversation %s). Type /exit to quit.\n", r.Conversation.ID)
+	if n := len(r.Conversation.Messages); n > 0 {
+		fmt.Fprintf(out, "Resumed with %d previous messages.\n", n)
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for {
+		fmt.Fprint(out, "> ")
+		if !scanner.Scan() {
+			fmt.Fprintln(out)
+			return scanner.Err()
+		}
+
+		question := strings.TrimSpace(scanner.Text())
+		switch question {
+		case "":
+			continue
+		case "/exit", "/quit":
+			return nil
+		}
+
+		answer, err := r.Ask(ctx, question)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			// a failed question is not recorded, the user can ask again
+			fmt.Fprintf(out, "error: %v\n", err)
+			continue
+		}
+		fmt.Fprintln(out, answer)
+	}
+}
+
+// Ask sends one question and records the exchange
+func (r *REPL) Ask(ctx context.Context, question string) (string, error) {
+	if r.AI == nil {
+		return "", errors.New("chat: no AI client")
+	}
+
+	messages := make([]map[string]any, 0, maxHistory+2)
+	if r.System != "" {
+		messages = append(messages, map[string]any{"role": string(models.RoleSystem), "content": r.System})
+	}
+	history := r.Conversation.Messages
+	if len(history) > maxHistory {
+		history = history[len(history)-maxHistory:]
+	}
+	for _, m := range history {
+		messages = append(messages, map[string]any{"role": string(m.Role), "content": m.Content})
+	}
+	messages = append(messages, map[string]any{"role": string(models.RoleUser), "content": question})
+
+	resp, err := r.AI.Ask(ctx, models.PromptRequest{Model: r.Model, Messages: messages, Tools: r.Tools})
+	if err != nil {
+		return "", err
+	}
+	answer := Text(resp.Content)
+
+	now := r.now()
+	r.Conversation.Messages = append(r.Conversation.Messages,
+		Message{Role: models.RoleUser, Content: question, CreatedAt: now},
+		Message{Role: models.RoleAssistant, Content: answer, CreatedAt: now},
+	)
+	r.Conversation.UpdatedAt = now
+	if r.Store != nil {
+		if err := r.Store.Save(r.Conversation); err != nil {
+			return answer, err
+		}
+	}
+	return answer, nil
+}
+
+func (r *REPL) now() time.Time {
+	if r.Now != nil {
+		return r.Now()
+	}
+	return time.Now()
+}
+
+// Text returns the text of an answer. The Responses runner returns its output
+// items as a JSON object keyed output_text_<item>_<part>; any other content is
+// returned as is.
+func Text(content string) string {
+	var parts map[string]any
+	if err := json.Unmarshal([]byte(content), &parts); err != nil {
+		return content
+	}
+
+	type outputText struct {
+		item, part int
+		text       string
+	}
+	var outputs []outputText
+	for k, v := range parts {
+		item, part, ok := outputTextIndex(k)
+		if !ok {
+			continue
+		}
+		if s, ok := v.(string); ok && s != "" {
+			outputs = append(outputs, outputText{item: item, part: part, text: s})
+		}
+	}
+	// keys sort by their numeric indices: output_text_2_0 comes before output_text_10_0
+	slices.SortFunc(outputs, func(a, b outputText) int {
+		return cmp.Or(cmp.Compare(a.item, b.item), cmp.Compare(a.part, b.part))
+	})
+
+	texts := make([]string, 0, len(outputs))
+	for _, o := range outputs {
+		texts = append(texts, o.text)
+	}
+	if len(texts) == 0 {
+		return content
+	}
+	return strings.Join(texts, "\n")
+}
+
+// outputTextIndex parses the item and part indices of an output_text_<item>_<part> key
+func outputTextIndex(key string) (item, part int, ok bool) {
+	rest, found := strings.CutPrefix(key, "output_text_")
+	if !found {
+		return 0, 0, false
+	}
+	itemStr, partStr, found := strings.Cut(rest, "_")
+	if !found {
+		return 0, 0, false
+	}
+	item, err := strconv.Atoi(itemStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	part, err = strconv.Atoi(partStr)
+	if err != nil {
+		return 0, 0, false
+	}
+	return item, part, true
+}
@@ -0,0 +1,30 @@
+package chat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{name: "plain text", content: "Hold.", want: "Hold."},
+		{name: "responses output", content: `{"output_text_1_0":"Trim VOO.","output_text_1_1":"Keep cash."}`, want: "Trim VOO.\nKeep cash."},
+		{
+			name:    "indices sort numerically",
+			content: `{"output_text_10_0":"Third.","output_text_2_1":"Second.","output_text_2_0":"First."}`,
+			want:    "First.\nSecond.\nThird.",
+		},
+		{name: "json answer without text", content: `{"weights":{"VOO":18}}`, want: `{"weights":{"VOO":18}}`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, Text(c.content))
+		})
+	}
+}
@@ -2,15 +2,24 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"math"
+	"net/url"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/rebalance"
 )
 
+// SandboxAPIKey stands in for the LLM API key in sandbox mode, where no request
+// reaches the provider
+const SandboxAPIKey = "sk-sandbox"
+
 type Config struct {
 	// CacheDir is the base directory where the app persists data/cache artifacts
 	CacheDir string `mapstructure:"cache_dir" yaml:"cache_dir"`
@@ -20,6 +29,9 @@ type Config struct {
 	// ConfigDir is the base directory where the app stores configuration files
 	ConfigDir string `mapstructure:"config_dir" yaml:"config_dir"`
 
+	// Sandbox blocks all outbound network calls; external clients answer from canned stubs or fail
+	Sandbox bool `mapstructure:"sandbox" yaml:"sandbox"`
+
 	// Centralized localization configuration
 	Localization models.LocalizationConfig `mapstructure:"localization" yaml:"localization"`
 
@@ -28,6 +40,7 @@ type Config struct {
 	Binance      BinanceConfig      `mapstructure:"binance" yaml:"binance"`
 	Jurisdiction JurisdictionConfig `mapstructure:"jurisdiction" yaml:"jurisdiction"`
 	Report       ReportConfig       `mapstructure:"report" yaml:"report"`
+	Portfolio    PortfolioConfig    `mapstructure:"portfolio" yaml:"portfolio"`
 
 	Logging LoggingConfig `mapstructure:"logging" yaml:"logging"`
 
@@ -46,6 +59,285 @@ type LoggingConfig struct {
 	// End of LoggingConfig struct
 }
 
+// PortfolioConfig holds the configuration of portfolio analytics
+type PortfolioConfig struct {
+	// EquivalentTickers groups economically identical instruments (e.g. VOO, IVV
+	// and SPY) that count as one position in concentration metrics
+	EquivalentTickers [][]string `mapstructure:"equivalent_tickers" yaml:"equivalent_tickers"`
+	// Alerts are the concentration thresholds (position, sector, currency) that
+	// trigger alerts; the investment profile's concentration_limits override them
+	Alerts models.AlertThresholds `mapstructure:"alerts" yaml:"alerts"`
+	// MaxPriceAge flags holdings priced longer than this before the portfolio's
+	// as_of as stale, so reports caveat their valuation (0 = never)
+	MaxPriceAge time.Duration `mapstructure:"max_price_age" yaml:"max_price_age"`
+	// BaseCurrency is the reporting currency of normalized values; defaults to
+	// the portfolio's base currency, then USD
+	BaseCurrency string `mapstructure:"base_currency" yaml:"base_currency"`
+	// FXRates convert holdings held in other currencies into BaseCurrency
+	FXRates []FXRate `mapstructure:"fx_rates" yaml:"fx_rates"`
+	// MaxFXAge flags the rates used for conversion dated longer than this before
+	// the portfolio's as_of as stale (0 = never)
+	MaxFXAge time.Duration `mapstructure:"max_fx_age" yaml:"max_fx_age"`
+	// FXStalePolicy is what stale rates do: warn (default) or error
+	FXStalePolicy models.FXStalePolicy `mapstructure:"fx_stale_policy" yaml:"fx_stale_policy"`
+	// Sleeves lists the asset classes presented in each sleeve of the customer
+	// report; unlisted classes fall into alternatives (default sleeves when empty)
+	Sleeves map[string][]string `mapstructure:"sleeves" yaml:"sleeves"`
+	// HealthWeights weigh the components of the portfolio health score (default
+	// weights when all zero)
+	HealthWeights models.HealthWeights `mapstructure:"health_weights" yaml:"health_weights"`
+	// RecommendedAllocations are the sleeve weights (percent) recommended by risk
+	// tolerance, or risk tolerance and investment style ("moderate/income"); they
+	// override the default entries of the same key
+	RecommendedAllocations map[string]map[string]float64 `mapstructure:"recommended_allocations" yaml:"recommended_allocations"`
+	// Rebalance tunes the trade plan toward the recommended allocation
+	Rebalance RebalanceConfig `mapstructure:"rebalance" yaml:"rebalance"`
+}
+
+// RebalanceConfig holds the rebalancing plan settings
+type RebalanceConfig struct {
+	// MinTradeValue drops trades smaller than this value in the base currency
+	MinTradeValue float64 `mapstructure:"min_trade_value" yaml:"min_trade_value"`
+	// MinTradePct drops trades smaller than this share (percent) of the portfolio
+	MinTradePct float64 `mapstructure:"min_trade_pct" yaml:"min_trade_pct"`
+	// RoundLots trades stocks and ETFs in whole shares
+	RoundLots bool `mapstructure:"round_lots" yaml:"round_lots"`
+	// DefaultCost is the trading cost of the instruments missing from Costs
+	DefaultCost TradeCost `mapstructure:"default_cost" yaml:"default_cost"`
+	// Costs are the trading costs by ticker
+	Costs map[string]TradeCost `mapstructure:"costs" yaml:"costs"`
+	// TrackingErrorPct is the yearly cost (percent) expected of a position off
+	// target; trades closing less drift than they cost are skipped (0 ignores costs)
+	TrackingErrorPct float64 `mapstructure:"tracking_error_pct" yaml:"tracking_error_pct"`
+	// MinNetBenefit is the least a trade must be worth over its cost
+	MinNetBenefit float64 `mapstructure:"min_net_benefit" yaml:"min_net_benefit"`
+	// TaxAware sells the tax lots realizing the least capital gains tax first
+	// (losses first) rather than the oldest
+	TaxAware bool `mapstructure:"tax_aware" yaml:"tax_aware"`
+	// DoNotSell are the tickers never traded (inherited stock, employer shares);
+	// the rest of the portfolio is rebalanced around them
+	DoNotSell []string `mapstructure:"do_not_sell" yaml:"do_not_sell"`
+	// AssetLocation plans portfolios of several accounts account by account,
+	// placing bonds in the tax-advantaged ones
+	AssetLocation bool `mapstructure:"asset_location" yaml:"asset_location"`
+}
+
+// TradeCost is the cost assumption of trading an instrument
+type TradeCost struct {
+	// SpreadBps is the half spread paid on the traded value, in basis points
+	SpreadBps float64 `mapstructure:"spread_bps" yaml:"spread_bps"`
+	// Commission is the fixed fee per trade in the base currency
+	Commission float64 `mapstructure:"commission" yaml:"commission"`
+}
+
+// Validate validates the rebalancing configuration
+func (rc *RebalanceConfig) Validate() error {
+	return rc.Options().Validate()
+}
+
+// Options returns the rebalancing calculator options
+func (rc *RebalanceConfig) Options() rebalance.Options {
+	opts := rebalance.Options{
+		MinTradeValue:    rc.MinTradeValue,
+		MinTradePct:      rc.MinTradePct,
+		RoundLots:        rc.RoundLots,
+		DefaultCost:      rebalance.Cost(rc.DefaultCost),
+		TrackingErrorPct: rc.TrackingErrorPct,
+		MinNetBenefit:    rc.MinNetBenefit,
+		TaxAware:         rc.TaxAware,
+	}
+	if len(rc.Costs) > 0 {
+		opts.Costs = make(map[string]rebalance.Cost, len(rc.Costs))
+		for ticker, c := range rc.Costs {
+			// viper lowercases map keys
+			opts.Costs[strings.ToUpper(ticker)] = rebalance.Cost(c)
+		}
+	}
+	for _, ticker := range rc.DoNotSell {
+		opts.DoNotSell = append(opts.DoNotSell, strings.ToUpper(strings.TrimSpace(ticker)))
+	}
+	return opts
+}
+
+// FXRate is an exchange rate: one unit of From buys Rate units of To
+type FXRate struct {
+	From string  `mapstructure:"from" yaml:"from"`
+	To   string  `mapstructure:"to" yaml:"to"`
+	Rate float64 `mapstructure:"rate" yaml:"rate"`
+	// AsOf is when the rate was quoted (YYYY-MM-DD or RFC3339); undated rates
+	// are never stale
+	AsOf string `mapstructure:"as_of" yaml:"as_of"`
+}
+
+// AsOfTime parses AsOf; the zero time when unset
+func (r FXRate) AsOfTime() (time.Time, error) {
+	if r.AsOf == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse("2006-01-02", r.AsOf); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, r.AsOf)
+}
+
+// Validate validates the portfolio configuration
+func (pc *PortfolioConfig) Validate() error {
+	seen := make(map[string]int)
+	for i, group := range pc.EquivalentTickers {
+		if len(group) < 2 {
+			return fmt.Errorf("equivalent_tickers group %d must list at least 2 tickers", i)
+		}
+		for _, ticker := range group {
+			t := strings.ToUpper(strings.TrimSpace(ticker))
+			if t == "" {
+				return fmt.Errorf("equivalent_tickers group %d has an empty ticker", i)
+			}
+			if j, ok := seen[t]; ok && j != i {
+				return fmt.Errorf("ticker %s is in equivalent_tickers groups %d and %d", t, j, i)
+			}
+			seen[t] = i
+		}
+	}
+	if err := pc.Alerts.Validate(); err != nil {
+		return fmt.Errorf("alerts: %w", err)
+	}
+	if pc.MaxPriceAge < 0 {
+		return fmt.Errorf("max_price_age must be non-negative, got: %s", pc.MaxPriceAge)
+	}
+	if pc.BaseCurrency != "" && !isCurrencyCode(pc.BaseCurrency) {
+		return fmt.Errorf("base_currency must be a 3-letter currency code, got: %q", pc.BaseCurrency)
+	}
+	for i, r := range pc.FXRates {
+		if !isCurrencyCode(r.From) || !isCurrencyCode(r.To) {
+			return fmt.Errorf("fx_rates[%d] needs 3-letter from and to currency codes, got: %q/%q", i, r.From, r.To)
+		}
+		if r.Rate <= 0 {
+			return fmt.Errorf("fx_rates[%d] rate must be positive, got: %g", i, r.Rate)
+		}
+		if _, err := r.AsOfTime(); err != nil {
+			return fmt.Errorf("fx_rates[%d] as_of must be YYYY-MM-DD or RFC3339, got: %q", i, r.AsOf)
+		}
+	}
+	if pc.MaxFXAge < 0 {
+		return fmt.Errorf("max_fx_age must be non-negative, got: %s", pc.MaxFXAge)
+	}
+	switch pc.FXStalePolicy {
+	case "", models.FXStaleWarn, models.FXStaleError:
+	default:
+		return fmt.Errorf("fx_stale_policy must be %s or %s, got: %q", models.FXStaleWarn, models.FXStaleError, pc.FXStalePolicy)
+	}
+	sleeveOf := make(map[string]string)
+	for _, sleeve := range slices.Sorted(maps.Keys(pc.Sleeves)) {
+		for _, class := range pc.Sleeves[sleeve] {
+			if !slices.Contains(models.AssetClasses, class) {
+				return fmt.Errorf("sleeve %s: unknown asset class %q, expected one of %v", sleeve, class, models.AssetClasses)
+			}
+			if other, ok := sleeveOf[class]; ok {
+				return fmt.Errorf("asset class %s is in sleeves %s and %s", class, other, sleeve)
+			}
+			sleeveOf[class] = sleeve
+		}
+	}
+	if err := pc.HealthWeights.Validate(); err != nil {
+		return fmt.Errorf("health_weights: %w", err)
+	}
+	// unmapped asset classes fall into alternatives
+	sleeves := []string{models.SleeveAlternatives}
+	mapping := pc.SleeveMapping()
+	if mapping == nil {
+		mapping = models.DefaultSleeves()
+	}
+	for _, sleeve := range mapping {
+		sleeves = append(sleeves, sleeve)
+	}
+	for _, key := range slices.Sorted(maps.Keys(pc.RecommendedAllocations)) {
+		total := 0.0
+		for sleeve, pct := range pc.RecommendedAllocations[key] {
+			if !slices.Contains(sleeves, sleeve) {
+				return fmt.Errorf("recommended_allocations %s: unknown sleeve %q", key, sleeve)
+			}
+			if pct < 0 {
+				return fmt.Errorf("recommended_allocations %s: %s weight must be non-negative, got: %g", key, sleeve, pct)
+			}
+			total += pct
+		}
+		if math.Abs(total-100) > 0.5 {
+			return fmt.Errorf("recommended_allocations %s must sum to 100, got: %g", key, total)
+		}
+	}
+	if err := pc.Rebalance.Validate(); err != nil {
+		return fmt.Errorf("rebalance: %w", err)
+	}
+	return nil
+}
+
+func isCurrencyCode(s string) bool {
+	if len(s) != 3 {
+		return false
+	}
+	for _, r := range s {
+		if (r < 'A' || r > 'Z') && (r < 'a' || r > 'z') {
+			return false
+		}
+	}
+	return true
+}
+
+// TickerEquivalence returns the configured equivalent tickers as a lookup
+func (pc *PortfolioConfig) TickerEquivalence() models.TickerEquivalence {
+	return models.NewTickerEquivalence(pc.EquivalentTickers)
+}
+
+// NormalizeOptions returns the options normalizing portfolios per the configuration
+func (pc *PortfolioConfig) NormalizeOptions() models.NormalizeOptions {
+	return models.NormalizeOptions{
+		Equivalence:   pc.TickerEquivalence(),
+		MaxPriceAge:   pc.MaxPriceAge,
+		BaseCurrency:  pc.BaseCurrency,
+		CurrencyRates: pc.CurrencyRates(),
+		MaxFXAge:      pc.MaxFXAge,
+		FXStalePolicy: pc.FXStalePolicy,
+		Sleeves:       pc.SleeveMapping(),
+	}
+}
+
+// SleeveMapping returns the configured sleeves by asset class, nil for the defaults
+func (pc *PortfolioConfig) SleeveMapping() models.SleeveMapping {
+	if len(pc.Sleeves) == 0 {
+		return nil
+	}
+	mapping := make(models.SleeveMapping)
+	for sleeve, classes := range pc.Sleeves {
+		for _, class := range classes {
+			mapping[class] = sleeve
+		}
+	}
+	return mapping
+}
+
+// AllocationTable returns the default recommended allocations overridden by the
+// configured ones
+func (pc *PortfolioConfig) AllocationTable() models.AllocationTable {
+	table := models.DefaultAllocationTable()
+	for key, weights := range pc.RecommendedAllocations {
+		table[strings.ToLower(key)] = models.SleeveAllocation(weights)
+	}
+	return table
+}
+
+// CurrencyRates returns the configured exchange rates
+func (pc *PortfolioConfig) CurrencyRates() []models.NormalizedCurrencyRate {
+	if len(pc.FXRates) == 0 {
+		return nil
+	}
+	rates := make([]models.NormalizedCurrencyRate, 0, len(pc.FXRates))
+	for _, r := range pc.FXRates {
+		asOf, _ := r.AsOfTime() // checked by Validate
+		rates = append(rates, models.NormalizedCurrencyRate{FromCurrency: r.From, ToCurrency: r.To, ExchangeRate: r.Rate, AsOf: asOf})
+	}
+	return rates
+}
+
 // ReportConfig holds the configuration for report generation
 type ReportConfig struct {
 	// OutputDir is the directory where reports are saved (relative to DataDir)
@@ -64,6 +356,16 @@ type ReportConfig struct {
 	ArchiveReports bool `mapstructure:"archive_reports" yaml:"archive_reports"`
 	// MaxArchivedReports maximum number of reports to keep in archive (0 = unlimited)
 	MaxArchivedReports int `mapstructure:"max_archived_reports" yaml:"max_archived_reports"`
+	// NewsRelevanceThreshold drops news headlines scored below it (0-1, default 0.3)
+	NewsRelevanceThreshold *float64 `mapstructure:"news_relevance_threshold" yaml:"news_relevance_threshold"`
+	// MaxHeadlines caps the number of news headlines shown in reports (0 = unlimited)
+	MaxHeadlines int `mapstructure:"max_headlines" yaml:"max_headlines"`
+	// IncludeReasoning adds the reasoning summaries of reasoning models to the
+	// system report (never to the customer report)
+	IncludeReasoning bool `mapstructure:"include_reasoning" yaml:"include_reasoning"`
+	// HoldingsSort is the display order of the holdings: weight_desc (default),
+	// alpha, value_desc or sector
+	HoldingsSort models.HoldingsSort `mapstructure:"holdings_sort" yaml:"holdings_sort"`
 	// End of ReportConfig struct
 }
 
@@ -113,6 +415,23 @@ func (rc *ReportConfig) Validate(dataDir string) error {
 		return fmt.Errorf("MaxArchivedReports must be non-negative, got: %d", rc.MaxArchivedReports)
 	}
 
+	// validate news relevance threshold range
+	if rc.NewsRelevanceThreshold != nil && (*rc.NewsRelevanceThreshold < 0 || *rc.NewsRelevanceThreshold > 1) {
+		return fmt.Errorf("NewsRelevanceThreshold must be between 0 and 1, got: %f", *rc.NewsRelevanceThreshold)
+	}
+
+	// validate MaxHeadlines is non-negative
+	if rc.MaxHeadlines < 0 {
+		return fmt.Errorf("MaxHeadlines must be non-negative, got: %d", rc.MaxHeadlines)
+	}
+
+	if rc.HoldingsSort == "" {
+		rc.HoldingsSort = models.HoldingsSortWeightDesc
+	}
+	if !slices.Contains(models.HoldingsSorts, rc.HoldingsSort) {
+		return fmt.Errorf("HoldingsSort must be one of %v, got: %s", models.HoldingsSorts, rc.HoldingsSort)
+	}
+
 	// construct absolute output directory path
 	var outputPath string
 	if filepath.IsAbs(rc.OutputDir) {
@@ -146,6 +465,15 @@ func (rc *ReportConfig) GetReportOutputDir(dataDir string) string {
 	return filepath.Join(dataDir, rc.OutputDir)
 }
 
+// Warnings reports settings that pass validation but are probably not intended
+func (c *Config) Warnings() []string {
+	var warnings []string
+	for _, w := range c.LLM.OpenAI.Warnings() {
+		warnings = append(warnings, "llm.openai: "+w)
+	}
+	return warnings
+}
+
 // Validate performs strict validation on the configuration
 func (c *Config) Validate() error {
 	if c.validated {
@@ -194,16 +522,31 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("binance config validation failed: %w", err)
 	}
 
+	// the sandbox answers LLM calls from canned stubs, so no API key is needed
+	if c.Sandbox && strings.TrimSpace(c.LLM.APIKey) == "" {
+		c.LLM.APIKey = SandboxAPIKey
+	}
+
 	// validate LLM config
 	if err := c.LLM.Validate(); err != nil {
 		return fmt.Errorf("LLM config validation failed: %w", err)
 	}
 
+	// validate tools config
+	if err := c.Tools.Validate(); err != nil {
+		return fmt.Errorf("tools config validation failed: %w", err)
+	}
+
 	// validate report config
 	if err := c.Report.Validate(c.DataDir); err != nil {
 		return fmt.Errorf("report config validation failed: %w", err)
 	}
 
+	// validate portfolio config
+	if err := c.Portfolio.Validate(); err != nil {
+		return fmt.Errorf("portfolio config validation failed: %w", err)
+	}
+
 	// mark as validated
 	c.validated = true
 	return nil
@@ -228,6 +571,8 @@ func (c *Config) populateComputedFields() {
 	// Populate tools configs from centralized localization
 	if c.Tools.NewsAPI != nil {
 		c.Tools.NewsAPI.Locale = c.Localization.Language
+		c.Tools.NewsAPI.CacheDir = c.CacheDir
+		c.Tools.NewsAPI.LLM = &c.LLM
 	}
 	if c.Tools.FRED != nil {
 		c.Tools.FRED.Country = c.Localization.Country
@@ -276,6 +621,8 @@ type ToolsConfig struct {
 	FMPAnalystEstimates *FMPAnalystEstimatesConfig `mapstructure:"fmp_analyst_estimates" yaml:"fmp_analyst_estimates"`
 	YFinance            *YFinanceConfig            `mapstructure:"yfinance" yaml:"yfinance"`
 	SECEdgar            *SECEdgarConfig            `mapstructure:"sec_edgar" yaml:"sec_edgar"`
+	Exec                []ExecToolConfig           `mapstructure:"exec" yaml:"exec"`
+	HTTP                []HTTPToolConfig           `mapstructure:"http" yaml:"http"`
 }
 
 func (c *Config) GetToolConfig(name string) any {
@@ -303,6 +650,29 @@ func (c *Config) GetToolConfig(name string) any {
 	}
 }
 
+// Secrets returns the configured credentials, so that exports can redact them
+func (c *Config) Secrets() []string {
+	secrets := []string{c.LLM.APIKey, c.Binance.APIKey, c.Binance.APISecret}
+	if c.Tools.NewsAPI != nil {
+		secrets = append(secrets, c.Tools.NewsAPI.APIKey)
+	}
+	if c.Tools.FRED != nil {
+		secrets = append(secrets, c.Tools.FRED.APIKey)
+	}
+	if c.Tools.FMP != nil {
+		secrets = append(secrets, c.Tools.FMP.APIKey)
+	}
+	if c.Tools.FMPAnalystEstimates != nil {
+		secrets = append(secrets, c.Tools.FMPAnalystEstimates.APIKey)
+	}
+	for _, hc := range c.Tools.HTTP {
+		if hc.Auth != nil {
+			secrets = append(secrets, hc.Auth.Token, hc.Auth.Password)
+		}
+	}
+	return secrets
+}
+
 type NewsAPIConfig struct {
 	APIKey   string `mapstructure:"api_key"`
 	BaseURL  string `mapstructure:"base_url"`
@@ -311,6 +681,13 @@ type NewsAPIConfig struct {
 	Locale      string
 	CacheEnable bool `mapstructure:"cache_enable"`
 	Persisting  bool `mapstructure:"persisting" yaml:"persisting"`
+	// RelatedTopK attaches to each fetch the previously seen articles most similar
+	// to the topics, ranked by embedding similarity (0 = disabled)
+	RelatedTopK int `mapstructure:"related_top_k" yaml:"related_top_k"`
+	// CacheDir and LLM are computed at runtime; the related-articles index is kept
+	// under CacheDir and embedded with the LLM provider
+	CacheDir string
+	LLM      *LLMConfig
 }
 
 type FREDConfig struct {
@@ -375,6 +752,143 @@ type SECEdgarConfig struct {
 	Persisting  bool   `mapstructure:"persisting" yaml:"persisting"`
 }
 
+// ExecToolConfig declares an external tool backed by a user command. The command
+// receives the tool arguments as JSON on stdin and must print a JSON result on stdout.
+type ExecToolConfig struct {
+	Name        string         `mapstructure:"name" yaml:"name"`
+	Description string         `mapstructure:"description" yaml:"description"`
+	Command     string         `mapstructure:"command" yaml:"command"`
+	Args        []string       `mapstructure:"args" yaml:"args"`
+	Parameters  map[string]any `mapstructure:"parameters" yaml:"parameters"` // JSON schema of the arguments
+	Timeout     time.Duration  `mapstructure:"timeout" yaml:"timeout"`       // 0 = default timeout
+}
+
+// HTTPToolConfig declares an external tool backed by a REST endpoint. URL, headers
+// and body are Go templates over the tool arguments; Output maps each output field
+// to a JSONPath into the response (empty = the whole response).
+type HTTPToolConfig struct {
+	Name        string                   `mapstructure:"name" yaml:"name"`
+	Description string                   `mapstructure:"description" yaml:"description"`
+	Method      string                   `mapstructure:"method" yaml:"method"` // default GET
+	URL         string                   `mapstructure:"url" yaml:"url"`
+	Headers     map[string]string        `mapstructure:"headers" yaml:"headers"`
+	Body        string                   `mapstructure:"body" yaml:"body"`
+	Auth        *HTTPToolAuthConfig      `mapstructure:"auth" yaml:"auth"`
+	Parameters  map[string]any           `mapstructure:"parameters" yaml:"parameters"` // JSON schema of the arguments
+	Output      map[string]string        `mapstructure:"output" yaml:"output"`
+	Timeout     time.Duration            `mapstructure:"timeout" yaml:"timeout"` // 0 = default timeout
+	CacheEnable bool                     `mapstructure:"cache_enable" yaml:"cache_enable"`
+	CacheTTL    time.Duration            `mapstructure:"cache_ttl" yaml:"cache_ttl"` // 0 = default TTL
+	RateLimit   *HTTPToolRateLimitConfig `mapstructure:"rate_limit" yaml:"rate_limit"`
+	Persisting  bool                     `mapstructure:"persisting" yaml:"persisting"`
+}
+
+// HTTPToolAuthConfig holds the credentials sent with every HTTP tool request
+type HTTPToolAuthConfig struct {
+	Type     string `mapstructure:"type" yaml:"type"` // bearer, basic, header or query
+	Token    string `mapstructure:"token" yaml:"token"`
+	Username string `mapstructure:"username" yaml:"username"`
+	Password string `mapstructure:"password" yaml:"password"`
+	Name     string `mapstructure:"name" yaml:"name"` // header or query parameter name for header/query auth
+}
+
+// HTTPToolRateLimitConfig limits how often an HTTP tool may call its endpoint
+type HTTPToolRateLimitConfig struct {
+	RequestsPerSecond int `mapstructure:"requests_per_second" yaml:"requests_per_second"`
+	RequestsPerDay    int `mapstructure:"requests_per_day" yaml:"requests_per_day"`
+	Burst             int `mapstructure:"burst" yaml:"burst"`
+}
+
+// Validate validates the tools configuration
+func (tc *ToolsConfig) Validate() error {
+	seen := make(map[string]bool, len(tc.Exec)+len(tc.HTTP))
+	for i, ec := range tc.Exec {
+		if strings.TrimSpace(ec.Name) == "" {
+			return fmt.Errorf("exec tool #%d: name cannot be empty", i)
+		}
+		if seen[ec.Name] {
+			return fmt.Errorf("exec tool %s: duplicate name", ec.Name)
+		}
+		seen[ec.Name] = true
+		if slices.Contains(tc.EnabledTools, ec.Name) {
+			return fmt.Errorf("exec tool %s: name conflicts with a built-in tool", ec.Name)
+		}
+		if strings.TrimSpace(ec.Command) == "" {
+			return fmt.Errorf("exec tool %s: command cannot be empty", ec.Name)
+		}
+		if ec.Timeout < 0 {
+			return fmt.Errorf("exec tool %s: timeout cannot be negative", ec.Name)
+		}
+	}
+
+	for i, hc := range tc.HTTP {
+		if strings.TrimSpace(hc.Name) == "" {
+			return fmt.Errorf("http tool #%d: name cannot be empty", i)
+		}
+		if seen[hc.Name] {
+			return fmt.Errorf("http tool %s: duplicate name", hc.Name)
+		}
+		seen[hc.Name] = true
+		if slices.Contains(tc.EnabledTools, hc.Name) {
+			return fmt.Errorf("http tool %s: name conflicts with a built-in tool", hc.Name)
+		}
+		if err := hc.Validate(); err != nil {
+			return fmt.Errorf("http tool %s: %w", hc.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate validates an HTTP tool definition
+func (hc *HTTPToolConfig) Validate() error {
+	if strings.TrimSpace(hc.URL) == "" {
+		return fmt.Errorf("url cannot be empty")
+	}
+	switch strings.ToUpper(hc.Method) {
+	case "", "GET", "POST", "PUT", "PATCH", "DELETE":
+	default:
+		return fmt.Errorf("unsupported method %q", hc.Method)
+	}
+	if hc.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if hc.CacheTTL < 0 {
+		return fmt.Errorf("cache_ttl cannot be negative")
+	}
+	for field, path := range hc.Output {
+		if !strings.HasPrefix(strings.TrimSpace(path), "$") {
+			return fmt.Errorf("output %s: JSONPath must start with $, got %q", field, path)
+		}
+	}
+	if hc.Auth != nil {
+		switch hc.Auth.Type {
+		case "bearer":
+			if hc.Auth.Token == "" {
+				return fmt.Errorf("bearer auth requires a token")
+			}
+		case "basic":
+			if hc.Auth.Username == "" {
+				return fmt.Errorf("basic auth requires a username")
+			}
+		case "header", "query":
+			if hc.Auth.Name == "" || hc.Auth.Token == "" {
+				return fmt.Errorf("%s auth requires a name and a token", hc.Auth.Type)
+			}
+		default:
+			return fmt.Errorf("unsupported auth type %q", hc.Auth.Type)
+		}
+	}
+	if rl := hc.RateLimit; rl != nil {
+		if rl.RequestsPerSecond <= 0 || rl.RequestsPerDay <= 0 {
+			return fmt.Errorf("rate_limit requires positive requests_per_second and requests_per_day")
+		}
+		if rl.Burst < 0 {
+			return fmt.Errorf("rate_limit burst cannot be negative")
+		}
+	}
+	return nil
+}
+
 // Validate validates the Binance configuration
 func (bc *BinanceConfig) Validate() error {
 	// if all fields are empty, consider it as not configured (optional)
@@ -475,10 +989,15 @@ type LLMConfig struct {
 	Provider string `mapstructure:"provider" yaml:"provider"`
 	// Model specifies the model to use for completions
 	Model LLMModel `mapstructure:"model" yaml:"model"`
+	// FallbackModels are tried in order when the model is unavailable or rate limited
+	FallbackModels []LLMModel `mapstructure:"fallback_models" yaml:"fallback_models"`
 	// APIKey for the provider API
 	APIKey string `mapstructure:"api_key" yaml:"api_key"`
 	// BaseURL for custom API endpoints or proxies
 	BaseURL string `mapstructure:"base_url" yaml:"base_url"`
+	// RoleMap rewrites message roles a custom BaseURL backend does not accept
+	// (e.g. tool or system for some local LLMs); ignored without BaseURL
+	RoleMap map[string]RoleMapping `mapstructure:"role_map" yaml:"role_map"`
 	// Locale is computed at runtime from centralized localization.language
 	Locale string
 	// OpenAI contains OpenAI-specific configuration
@@ -509,6 +1028,11 @@ func (lc *LLMConfig) Validate() error {
 	if !valid {
 		return fmt.Errorf("model must be one of %v, got: %s", validModels, lc.Model)
 	}
+	for _, m := range lc.FallbackModels {
+		if !slices.Contains(validModels, m) {
+			return fmt.Errorf("fallback model must be one of %v, got: %s", validModels, m)
+		}
+	}
 
 	if strings.TrimSpace(lc.APIKey) == "" {
 		return fmt.Errorf("APIKey cannot be empty")
@@ -519,6 +1043,15 @@ func (lc *LLMConfig) Validate() error {
 		return fmt.Errorf("BaseURL must be a valid HTTP(S) URL, got: %s", lc.BaseURL)
 	}
 
+	for from, m := range lc.RoleMap {
+		if !slices.Contains(messageRoles, from) {
+			return fmt.Errorf("role_map: role must be one of %v, got: %s", messageRoles, from)
+		}
+		if !slices.Contains(messageRoles, m.Role) {
+			return fmt.Errorf("role_map.%s: role must be one of %v, got: %s", from, messageRoles, m.Role)
+		}
+	}
+
 	// validate OpenAI config if provider is openai
 	if strings.ToLower(lc.Provider) == "openai" {
 		if err := lc.OpenAI.Validate(); err != nil {
@@ -529,6 +1062,40 @@ func (lc *LLMConfig) Validate() error {
 	return nil
 }
 
+// messageRoles are the roles a role mapping can rewrite from and to
+var messageRoles = []string{"system", "developer", "user", "assistant", "tool"}
+
+// RoleMapping rewrites the messages of a role
+type RoleMapping struct {
+	// Role sent instead of the original one
+	Role string `mapstructure:"role" yaml:"role"`
+	// Prefix prepended to the text content, to keep the original intent visible
+	Prefix string `mapstructure:"prefix" yaml:"prefix"`
+}
+
+// RoleMapping returns the role mapping to apply to the messages sent; nil when
+// the official endpoint is used
+func (lc LLMConfig) RoleMapping() map[string]RoleMapping {
+	if lc.BaseURL == "" {
+		return nil
+	}
+	return lc.RoleMap
+}
+
+const (
+	// SessionAPIResponses routes interactive sessions through the Responses API
+	SessionAPIResponses = "responses"
+	// SessionAPIChatCompletions routes interactive sessions through Chat Completions
+	SessionAPIChatCompletions = "chat_completions"
+)
+
+const (
+	// SamplingParamTemperature sends temperature when both sampling parameters are set
+	SamplingParamTemperature = "temperature"
+	// SamplingParamTopP sends top_p when both sampling parameters are set
+	SamplingParamTopP = "top_p"
+)
+
 // OpenAIConfig holds OpenAI-specific configuration parameters
 type OpenAIConfig struct {
 	// OrganizationID for API requests
@@ -541,8 +1108,14 @@ type OpenAIConfig struct {
 	Temperature *float64 `mapstructure:"temperature" yaml:"temperature"`
 	// TopP controls nucleus sampling (0-1), alternative to temperature
 	TopP *float64 `mapstructure:"top_p" yaml:"top_p"`
+	// SamplingParam picks the parameter sent when both temperature and top_p are set:
+	// temperature (default) or top_p
+	SamplingParam string `mapstructure:"sampling_param" yaml:"sampling_param"`
 	// ReasoningEffort for reasoning models: minimal, low, medium, high
 	ReasoningEffort *string `mapstructure:"reasoning_effort" yaml:"reasoning_effort"`
+	// ReasoningSummary asks reasoning models for a summary of their reasoning:
+	// auto, concise, detailed (unset = no summary)
+	ReasoningSummary *string `mapstructure:"reasoning_summary" yaml:"reasoning_summary"`
 	// Verbosity controls response length: low, medium, high
 	Verbosity *string `mapstructure:"verbosity" yaml:"verbosity"`
 	// ParallelToolCalls enables parallel function calling
@@ -557,16 +1130,93 @@ type OpenAIConfig struct {
 	Seed *int64 `mapstructure:"seed" yaml:"seed"`
 	// PromptCacheKey for response caching optimization
 	PromptCacheKey *string `mapstructure:"prompt_cache_key" yaml:"prompt_cache_key"`
+	// SessionAPI selects the endpoint used by interactive sessions: responses (default) or chat_completions
+	SessionAPI string `mapstructure:"session_api" yaml:"session_api"`
 	// WebSearch enables OpenAI's web search capability (uses Responses API)
 	WebSearch bool `mapstructure:"web_search" yaml:"web_search"`
 	// WebSearchContextSize controls web search context: low, medium, high
 	WebSearchContextSize string `mapstructure:"web_search_context_size" yaml:"web_search_context_size"`
 	// WebSearchUserLocation is computed at runtime from centralized localization
 	WebSearchUserLocation *WebSearchUserLocationConfig
+	// BatchUploadContentType is the content-type of the uploaded batch input file
+	// (some OpenAI-compatible gateways reject application/jsonl)
+	BatchUploadContentType string `mapstructure:"batch_upload_content_type" yaml:"batch_upload_content_type"`
+	// BatchUploadFilename is the filename of the uploaded batch input file
+	BatchUploadFilename string `mapstructure:"batch_upload_filename" yaml:"batch_upload_filename"`
+	// EmbeddingModel is the model used for embeddings (default text-embedding-3-small)
+	EmbeddingModel string `mapstructure:"embedding_model" yaml:"embedding_model"`
+	// EmbeddingTimeout bounds a single embedding call (default 30s)
+	EmbeddingTimeout time.Duration `mapstructure:"embedding_timeout" yaml:"embedding_timeout"`
+	// BatchPollJitter spreads batch status polling by ±factor (0-1) so concurrent waits don't hit the API in sync
+	BatchPollJitter *float64 `mapstructure:"batch_poll_jitter" yaml:"batch_poll_jitter"`
+	// BatchWebhook is notified when a waited batch job reaches a terminal status
+	BatchWebhook BatchWebhookConfig `mapstructure:"batch_webhook" yaml:"batch_webhook"`
 	// RateLimit holds rate limiting configuration
-	RateLimit models.RateLimitConfig `yaml:"rate_limit"`
+	RateLimit models.RateLimitConfig `mapstructure:"rate_limit" yaml:"rate_limit"`
 	// Retry holds retry configuration
-	Retry models.RetryConfig `yaml:"retry"`
+	Retry models.RetryConfig `mapstructure:"retry" yaml:"retry"`
+}
+
+// BatchWebhookConfig holds the webhook posted on batch completion (disabled when URL is empty)
+type BatchWebhookConfig struct {
+	// URL receives a POST with the job ID, status, request counts and estimated cost
+	URL string `mapstructure:"url" yaml:"url"`
+	// Format is the payload format: json (default) or slack (Slack incoming webhook text)
+	Format string `mapstructure:"format" yaml:"format"`
+	// Timeout bounds each delivery attempt (default 10s)
+	Timeout time.Duration `mapstructure:"timeout" yaml:"timeout"`
+	// MaxAttempts is the number of delivery attempts with exponential backoff (default 4)
+	MaxAttempts int `mapstructure:"max_attempts" yaml:"max_attempts"`
+}
+
+// Validate validates the batch webhook configuration
+func (wc *BatchWebhookConfig) Validate() error {
+	if wc.URL == "" {
+		return nil // not configured
+	}
+	u, err := url.Parse(wc.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL, got: %s", wc.URL)
+	}
+	switch wc.Format {
+	case "", "json", "slack":
+	default:
+		return fmt.Errorf("format must be json or slack, got: %s", wc.Format)
+	}
+	if wc.Timeout < 0 {
+		return fmt.Errorf("timeout cannot be negative")
+	}
+	if wc.MaxAttempts < 0 {
+		return fmt.Errorf("max_attempts cannot be negative")
+	}
+	return nil
+}
+
+// Sampling returns the sampling parameters to send. OpenAI recommends altering
+// temperature or top_p, not both, so when both are set only the one selected by
+// SamplingParam is returned.
+func (oc *OpenAIConfig) Sampling() (temperature, topP *float64) {
+	if oc.Temperature != nil && oc.TopP != nil {
+		if oc.SamplingParam == SamplingParamTopP {
+			return nil, oc.TopP
+		}
+		return oc.Temperature, nil
+	}
+	return oc.Temperature, oc.TopP
+}
+
+// Warnings reports settings that are valid but probably not intended
+func (oc *OpenAIConfig) Warnings() []string {
+	var warnings []string
+	if oc.Temperature != nil && oc.TopP != nil {
+		sent := SamplingParamTemperature
+		if oc.SamplingParam == SamplingParamTopP {
+			sent = SamplingParamTopP
+		}
+		warnings = append(warnings, fmt.Sprintf(
+			"both temperature and top_p are set; OpenAI recommends altering only one, only %s is sent (see sampling_param)", sent))
+	}
+	return warnings
 }
 
 // Validate validates the OpenAI configuration
@@ -581,6 +1231,10 @@ func (oc *OpenAIConfig) Validate() error {
 		return fmt.Errorf("TopP must be between 0 and 1, got: %f", *oc.TopP)
 	}
 
+	if oc.SamplingParam != "" && oc.SamplingParam != SamplingParamTemperature && oc.SamplingParam != SamplingParamTopP {
+		return fmt.Errorf("SamplingParam must be one of [%s %s], got: %s", SamplingParamTemperature, SamplingParamTopP, oc.SamplingParam)
+	}
+
 	// validate reasoning effort values
 	if oc.ReasoningEffort != nil {
 		validReasoningEfforts := []string{"minimal", "low", "medium", "high"}
@@ -596,6 +1250,13 @@ func (oc *OpenAIConfig) Validate() error {
 		}
 	}
 
+	if oc.ReasoningSummary != nil {
+		validReasoningSummaries := []string{"auto", "concise", "detailed"}
+		if !slices.Contains(validReasoningSummaries, *oc.ReasoningSummary) {
+			return fmt.Errorf("ReasoningSummary must be one of %v, got: %s", validReasoningSummaries, *oc.ReasoningSummary)
+		}
+	}
+
 	// validate verbosity values
 	if oc.Verbosity != nil {
 		validVerbosities := []string{"low", "medium", "high"}
@@ -655,5 +1316,56 @@ func (oc *OpenAIConfig) Validate() error {
 		}
 	}
 
+	// validate session api
+	if oc.SessionAPI != "" {
+		validSessionAPIs := []string{SessionAPIResponses, SessionAPIChatCompletions}
+		valid := false
+		for _, v := range validSessionAPIs {
+			if oc.SessionAPI == v {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("SessionAPI must be one of %v, got: %s", validSessionAPIs, oc.SessionAPI)
+		}
+	}
+
+	if oc.WebSearch && oc.SessionAPI == SessionAPIChatCompletions {
+		return fmt.Errorf("web_search requires session_api %q", SessionAPIResponses)
+	}
+
+	// validate batch upload content type
+	if oc.BatchUploadContentType != "" {
+		validContentTypes := []string{"application/jsonl", "application/json", "application/x-ndjson", "text/plain"}
+		valid := false
+		for _, v := range validContentTypes {
+			if oc.BatchUploadContentType == v {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("BatchUploadContentType must be one of %v, got: %s", validContentTypes, oc.BatchUploadContentType)
+		}
+	}
+
+	if oc.BatchUploadFilename != "" && filepath.Base(oc.BatchUploadFilename) != oc.BatchUploadFilename {
+		return fmt.Errorf("BatchUploadFilename must be a bare file name, got: %s", oc.BatchUploadFilename)
+	}
+
+	if oc.EmbeddingTimeout < 0 {
+		return fmt.Errorf("EmbeddingTimeout must be non-negative, got: %v", oc.EmbeddingTimeout)
+	}
+
+	// validate batch poll jitter range
+	if oc.BatchPollJitter != nil && (*oc.BatchPollJitter < 0 || *oc.BatchPollJitter > 1) {
+		return fmt.Errorf("BatchPollJitter must be between 0 and 1, got: %f", *oc.BatchPollJitter)
+	}
+
+	if err := oc.BatchWebhook.Validate(); err != nil {
+		return fmt.Errorf("BatchWebhook: %w", err)
+	}
+
 	return nil
 }
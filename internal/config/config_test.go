@@ -3,10 +3,14 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/amaurybrisou/mosychlos/pkg/models"
 	"github.com/amaurybrisou/mosychlos/pkg/nativeutils"
+	"github.com/amaurybrisou/mosychlos/pkg/rebalance"
+	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestConfig_Validate(t *testing.T) {
@@ -47,6 +51,43 @@ func TestConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "sandbox without API key",
+			config: Config{
+				DataDir:  "/tmp/data",
+				CacheDir: "/tmp/cache",
+				Sandbox:  true,
+				Localization: models.LocalizationConfig{
+					Country:  "US",
+					Language: "en",
+					Timezone: "America/New_York",
+					Currency: "USD",
+				},
+				LLM: LLMConfig{Provider: "openai", Model: "gpt-4o"},
+				Jurisdiction: JurisdictionConfig{
+					Rules: models.ComplianceRules{MaxLeverage: 1},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "API key required outside the sandbox",
+			config: Config{
+				DataDir:  "/tmp/data",
+				CacheDir: "/tmp/cache",
+				Localization: models.LocalizationConfig{
+					Country:  "US",
+					Language: "en",
+					Timezone: "America/New_York",
+					Currency: "USD",
+				},
+				LLM: LLMConfig{Provider: "openai", Model: "gpt-4o"},
+				Jurisdiction: JurisdictionConfig{
+					Rules: models.ComplianceRules{MaxLeverage: 1},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "empty DataDir",
 			config: Config{
@@ -230,6 +271,319 @@ func TestBinanceConfig_Validate(t *testing.T) {
 	}
 }
 
+func TestPortfolioConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		config  PortfolioConfig
+		wantErr bool
+	}{
+		{
+			name:    "empty config",
+			config:  PortfolioConfig{},
+			wantErr: false,
+		},
+		{
+			name:    "valid groups",
+			config:  PortfolioConfig{EquivalentTickers: [][]string{{"VOO", "IVV", "SPY"}, {"VWCE", "IWDA"}}},
+			wantErr: false,
+		},
+		{
+			name:    "single ticker group",
+			config:  PortfolioConfig{EquivalentTickers: [][]string{{"VOO"}}},
+			wantErr: true,
+		},
+		{
+			name:    "ticker in two groups",
+			config:  PortfolioConfig{EquivalentTickers: [][]string{{"VOO", "IVV"}, {"ivv", "SPY"}}},
+			wantErr: true,
+		},
+		{
+			name:    "valid alert thresholds",
+			config:  PortfolioConfig{Alerts: models.AlertThresholds{Position: models.AlertLimit{WarningPct: 10, CriticalPct: 20}}},
+			wantErr: false,
+		},
+		{
+			name:    "critical alert threshold below warning",
+			config:  PortfolioConfig{Alerts: models.AlertThresholds{Sector: models.AlertLimit{WarningPct: 40, CriticalPct: 30}}},
+			wantErr: true,
+		},
+		{
+			name:    "empty ticker",
+			config:  PortfolioConfig{EquivalentTickers: [][]string{{"VOO", " "}}},
+			wantErr: true,
+		},
+		{
+			name:    "base currency and fx rates",
+			config:  PortfolioConfig{BaseCurrency: "EUR", FXRates: []FXRate{{From: "EUR", To: "USD", Rate: 1.08}}},
+			wantErr: false,
+		},
+		{
+			name:    "invalid base currency",
+			config:  PortfolioConfig{BaseCurrency: "EURO"},
+			wantErr: true,
+		},
+		{
+			name:    "dated fx rate with staleness policy",
+			config:  PortfolioConfig{FXRates: []FXRate{{From: "EUR", To: "USD", Rate: 1.08, AsOf: "2026-10-16"}}, MaxFXAge: 72 * time.Hour, FXStalePolicy: models.FXStaleError},
+			wantErr: false,
+		},
+		{
+			name:    "invalid fx rate date",
+			config:  PortfolioConfig{FXRates: []FXRate{{From: "EUR", To: "USD", Rate: 1.08, AsOf: "16/10/2026"}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown fx stale policy",
+			config:  PortfolioConfig{FXStalePolicy: "ignore"},
+			wantErr: true,
+		},
+		{
+			name:    "custom sleeves",
+			config:  PortfolioConfig{Sleeves: map[string][]string{"growth": {"stock", "etf", "crypto"}, "defensive": {"bond", "cash"}}},
+			wantErr: false,
+		},
+		{
+			name:    "unknown sleeve asset class",
+			config:  PortfolioConfig{Sleeves: map[string][]string{"growth": {"equities"}}},
+			wantErr: true,
+		},
+		{
+			name:    "asset class in two sleeves",
+			config:  PortfolioConfig{Sleeves: map[string][]string{"growth": {"etf"}, "core": {"etf"}}},
+			wantErr: true,
+		},
+		{
+			name:    "recommended allocations",
+			config:  PortfolioConfig{RecommendedAllocations: map[string]map[string]float64{"moderate/income": {"equity": 40, "fixed_income": 50, "cash": 10}}},
+			wantErr: false,
+		},
+		{
+			name:    "recommended allocation not summing to 100",
+			config:  PortfolioConfig{RecommendedAllocations: map[string]map[string]float64{"moderate": {"equity": 60, "fixed_income": 30}}},
+			wantErr: true,
+		},
+		{
+			name: "recommended allocation of an unknown sleeve",
+			config: PortfolioConfig{
+				Sleeves:                map[string][]string{"growth": {"stock", "etf"}},
+				RecommendedAllocations: map[string]map[string]float64{"aggressive": {"equity": 100}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "rebalance minimum trade size",
+			config:  PortfolioConfig{Rebalance: RebalanceConfig{MinTradeValue: 100, MinTradePct: 0.5, RoundLots: true}},
+			wantErr: false,
+		},
+		{
+			name: "cost-aware rebalance",
+			config: PortfolioConfig{Rebalance: RebalanceConfig{
+				DefaultCost: TradeCost{SpreadBps: 5, Commission: 1}, Costs: map[string]TradeCost{"btc": {SpreadBps: 50}},
+				TrackingErrorPct: 2, MinNetBenefit: 5,
+			}},
+			wantErr: false,
+		},
+		{
+			name:    "negative commission",
+			config:  PortfolioConfig{Rebalance: RebalanceConfig{Costs: map[string]TradeCost{"btc": {Commission: -1}}}},
+			wantErr: true,
+		},
+		{
+			name:    "negative minimum trade value",
+			config:  PortfolioConfig{Rebalance: RebalanceConfig{MinTradeValue: -1}},
+			wantErr: true,
+		},
+		{
+			name:    "non-positive fx rate",
+			config:  PortfolioConfig{FXRates: []FXRate{{From: "EUR", To: "USD"}}},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.config.Validate()
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPortfolioConfig_DecodeDefaults(t *testing.T) {
+	t.Parallel()
+
+	v := viper.New()
+	v.SetConfigFile("../../config/config.default.yaml")
+	require.NoError(t, v.ReadInConfig())
+	var cfg Config
+	require.NoError(t, v.Unmarshal(&cfg))
+
+	// nested models types are decoded too
+	assert.Equal(t, models.AlertLimit{WarningPct: 10, CriticalPct: 20}, cfg.Portfolio.Alerts.Position)
+	assert.Equal(t, models.AlertLimit{WarningPct: 30, CriticalPct: 40}, cfg.Portfolio.Alerts.Sector)
+	assert.Equal(t, 72*time.Hour, cfg.Portfolio.MaxPriceAge)
+	assert.Equal(t, models.DefaultHealthWeights(), cfg.Portfolio.HealthWeights)
+	assert.Equal(t, RebalanceConfig{
+		MinTradeValue: 100, MinTradePct: 0.5, RoundLots: true,
+		DefaultCost: TradeCost{SpreadBps: 5, Commission: 1},
+		DoNotSell:   []string{}, AssetLocation: true,
+	}, cfg.Portfolio.Rebalance)
+	assert.NoError(t, cfg.Portfolio.Validate())
+}
+
+func TestOpenAIConfig_DecodeDefaults(t *testing.T) {
+	t.Parallel()
+
+	v := viper.New()
+	v.SetConfigFile("../../config/config.default.yaml")
+	require.NoError(t, v.ReadInConfig())
+	var cfg Config
+	require.NoError(t, v.Unmarshal(&cfg))
+
+	assert.Equal(t, models.RetryConfig{
+		MaxRetries: 3, BaseDelay: time.Second, MaxDelay: 30 * time.Second,
+		ExponentialBase: 2, JitterFactor: 0.1, RunBudget: 20,
+	}, cfg.LLM.OpenAI.Retry)
+	assert.Equal(t, models.RateLimitConfig{
+		Enabled: true, BaseDelay: time.Second, MaxDelay: 30 * time.Second, JitterFactor: 0.1,
+	}, cfg.LLM.OpenAI.RateLimit)
+
+	// the run budget can be set on its own
+	v.Set("llm.openai.retry.run_budget", 5)
+	require.NoError(t, v.Unmarshal(&cfg))
+	assert.Equal(t, 5, cfg.LLM.OpenAI.Retry.RunBudget)
+}
+
+func TestRebalanceConfig_Options(t *testing.T) {
+	t.Parallel()
+
+	rc := RebalanceConfig{
+		DefaultCost: TradeCost{SpreadBps: 5, Commission: 1},
+		Costs:       map[string]TradeCost{"btc": {SpreadBps: 50}},
+		DoNotSell:   []string{" aapl"},
+	}
+	opts := rc.Options()
+	assert.Equal(t, rebalance.Cost{SpreadBps: 5, Commission: 1}, opts.DefaultCost)
+	// tickers are matched in upper case whatever the decoder did to the keys
+	assert.Equal(t, map[string]rebalance.Cost{"BTC": {SpreadBps: 50}}, opts.Costs)
+	assert.Equal(t, []string{"AAPL"}, opts.DoNotSell)
+}
+
+func TestToolsConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name    string
+		config  ToolsConfig
+		wantErr bool
+	}{
+		{
+			name:    "no exec tools",
+			config:  ToolsConfig{EnabledTools: []string{"fred"}},
+			wantErr: false,
+		},
+		{
+			name: "valid exec tool",
+			config: ToolsConfig{Exec: []ExecToolConfig{
+				{Name: "private_quotes", Command: "/usr/local/bin/quotes", Timeout: 10 * time.Second},
+			}},
+			wantErr: false,
+		},
+		{
+			name:    "missing name",
+			config:  ToolsConfig{Exec: []ExecToolConfig{{Command: "quotes"}}},
+			wantErr: true,
+		},
+		{
+			name:    "missing command",
+			config:  ToolsConfig{Exec: []ExecToolConfig{{Name: "private_quotes"}}},
+			wantErr: true,
+		},
+		{
+			name: "duplicate names",
+			config: ToolsConfig{Exec: []ExecToolConfig{
+				{Name: "private_quotes", Command: "a"},
+				{Name: "private_quotes", Command: "b"},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "shadows a built-in tool",
+			config: ToolsConfig{
+				EnabledTools: []string{"fred"},
+				Exec:         []ExecToolConfig{{Name: "fred", Command: "a"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "negative timeout",
+			config:  ToolsConfig{Exec: []ExecToolConfig{{Name: "private_quotes", Command: "a", Timeout: -time.Second}}},
+			wantErr: true,
+		},
+		{
+			name: "valid http tool",
+			config: ToolsConfig{HTTP: []HTTPToolConfig{{
+				Name:      "private_quote",
+				URL:       "https://pricing.example.com/v1/quotes/{{.symbol}}",
+				Auth:      &HTTPToolAuthConfig{Type: "bearer", Token: "t"},
+				Output:    map[string]string{"price": "$.data.last"},
+				RateLimit: &HTTPToolRateLimitConfig{RequestsPerSecond: 1, RequestsPerDay: 100},
+			}}},
+			wantErr: false,
+		},
+		{
+			name:    "http tool without url",
+			config:  ToolsConfig{HTTP: []HTTPToolConfig{{Name: "private_quote"}}},
+			wantErr: true,
+		},
+		{
+			name:    "http tool with invalid JSONPath",
+			config:  ToolsConfig{HTTP: []HTTPToolConfig{{Name: "q", URL: "https://x", Output: map[string]string{"price": "data.last"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "http tool with unknown auth",
+			config:  ToolsConfig{HTTP: []HTTPToolConfig{{Name: "q", URL: "https://x", Auth: &HTTPToolAuthConfig{Type: "oauth"}}}},
+			wantErr: true,
+		},
+		{
+			name:    "http tool with incomplete rate limit",
+			config:  ToolsConfig{HTTP: []HTTPToolConfig{{Name: "q", URL: "https://x", RateLimit: &HTTPToolRateLimitConfig{RequestsPerSecond: 1}}}},
+			wantErr: true,
+		},
+		{
+			name: "exec and http tools share names",
+			config: ToolsConfig{
+				Exec: []ExecToolConfig{{Name: "q", Command: "a"}},
+				HTTP: []HTTPToolConfig{{Name: "q", URL: "https://x"}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := c.config.Validate()
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
 func TestConfig_MustValidate(t *testing.T) {
 	t.Parallel()
 
@@ -409,6 +763,57 @@ func TestLLMConfig_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid fallback models",
+			config: LLMConfig{
+				Provider:       "openai",
+				Model:          "gpt-5-mini",
+				FallbackModels: []LLMModel{LLMModelGPT4oMini, LLMModelGPT4o},
+				APIKey:         "test-api-key",
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown fallback model",
+			config: LLMConfig{
+				Provider:       "openai",
+				Model:          "gpt-5-mini",
+				FallbackModels: []LLMModel{"gpt-3"},
+				APIKey:         "test-api-key",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid role map",
+			config: LLMConfig{
+				Provider: "openai",
+				Model:    "gpt-4o",
+				APIKey:   "test-api-key",
+				BaseURL:  "http://localhost:11434",
+				RoleMap:  map[string]RoleMapping{"tool": {Role: "user", Prefix: "Tool result: "}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "role map from unknown role",
+			config: LLMConfig{
+				Provider: "openai",
+				Model:    "gpt-4o",
+				APIKey:   "test-api-key",
+				RoleMap:  map[string]RoleMapping{"function": {Role: "user"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "role map to unknown role",
+			config: LLMConfig{
+				Provider: "openai",
+				Model:    "gpt-4o",
+				APIKey:   "test-api-key",
+				RoleMap:  map[string]RoleMapping{"system": {Role: ""}},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, c := range cases {
@@ -481,6 +886,22 @@ func TestOpenAIConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "both sampling params with a choice",
+			config: OpenAIConfig{
+				Temperature:   nativeutils.Ptr(0.2),
+				TopP:          nativeutils.Ptr(0.9),
+				SamplingParam: SamplingParamTopP,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid sampling param",
+			config: OpenAIConfig{
+				SamplingParam: "min_p",
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid reasoning effort",
 			config: OpenAIConfig{
@@ -495,6 +916,20 @@ func TestOpenAIConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid reasoning summary",
+			config: OpenAIConfig{
+				ReasoningSummary: nativeutils.Ptr("detailed"),
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid reasoning summary",
+			config: OpenAIConfig{
+				ReasoningSummary: nativeutils.Ptr("verbose"),
+			},
+			wantErr: true,
+		},
 		{
 			name: "valid verbosity",
 			config: OpenAIConfig{
@@ -579,6 +1014,85 @@ func TestOpenAIConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "valid batch upload content type",
+			config: OpenAIConfig{
+				BatchUploadContentType: "text/plain",
+				BatchUploadFilename:    "input.txt",
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid batch upload content type",
+			config: OpenAIConfig{
+				BatchUploadContentType: "application/xml",
+			},
+			wantErr: true,
+		},
+		{
+			name: "batch upload filename with directory",
+			config: OpenAIConfig{
+				BatchUploadFilename: "../batch_input.jsonl",
+			},
+			wantErr: true,
+		},
+		{
+			name: "chat completions session api",
+			config: OpenAIConfig{
+				SessionAPI: SessionAPIChatCompletions,
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid session api",
+			config: OpenAIConfig{
+				SessionAPI: "assistants",
+			},
+			wantErr: true,
+		},
+		{
+			name: "web search requires responses session api",
+			config: OpenAIConfig{
+				SessionAPI: SessionAPIChatCompletions,
+				WebSearch:  true,
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative embedding timeout",
+			config: OpenAIConfig{
+				EmbeddingTimeout: -time.Second,
+			},
+			wantErr: true,
+		},
+		{
+			name: "batch poll jitter out of range",
+			config: OpenAIConfig{
+				BatchPollJitter: nativeutils.Ptr(1.5),
+			},
+			wantErr: true,
+		},
+		{
+			name: "batch webhook slack",
+			config: OpenAIConfig{
+				BatchWebhook: BatchWebhookConfig{URL: "https://hooks.slack.com/services/T/B/X", Format: "slack"},
+			},
+			wantErr: false,
+		},
+		{
+			name: "batch webhook invalid url",
+			config: OpenAIConfig{
+				BatchWebhook: BatchWebhookConfig{URL: "hooks.example.com/batch"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "batch webhook invalid format",
+			config: OpenAIConfig{
+				BatchWebhook: BatchWebhookConfig{URL: "https://example.com/hook", Format: "xml"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, c := range cases {
@@ -597,6 +1111,63 @@ func TestOpenAIConfig_Validate(t *testing.T) {
 }
 
 // cleanup test directories
+func TestOpenAIConfig_Sampling(t *testing.T) {
+	t.Parallel()
+
+	temperature, topP := nativeutils.Ptr(0.2), nativeutils.Ptr(0.9)
+	cases := []struct {
+		name            string
+		config          OpenAIConfig
+		wantTemperature *float64
+		wantTopP        *float64
+		wantWarning     bool
+	}{
+		{
+			name:            "temperature only",
+			config:          OpenAIConfig{Temperature: temperature},
+			wantTemperature: temperature,
+		},
+		{
+			name:     "top_p only",
+			config:   OpenAIConfig{TopP: topP},
+			wantTopP: topP,
+		},
+		{
+			name:            "both default to temperature",
+			config:          OpenAIConfig{Temperature: temperature, TopP: topP},
+			wantTemperature: temperature,
+			wantWarning:     true,
+		},
+		{
+			name:        "both with top_p chosen",
+			config:      OpenAIConfig{Temperature: temperature, TopP: topP, SamplingParam: SamplingParamTopP},
+			wantTopP:    topP,
+			wantWarning: true,
+		},
+	}
+
+	for _, c := range cases {
+		c := c
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			gotTemperature, gotTopP := c.config.Sampling()
+			assert.Equal(t, c.wantTemperature, gotTemperature)
+			assert.Equal(t, c.wantTopP, gotTopP)
+
+			warnings := c.config.Warnings()
+			if !c.wantWarning {
+				assert.Empty(t, warnings)
+				return
+			}
+			require.Len(t, warnings, 1)
+			assert.Contains(t, warnings[0], "both temperature and top_p are set")
+			cfg := Config{LLM: LLMConfig{OpenAI: c.config}}
+			assert.Equal(t, []string{"llm.openai: " + warnings[0]}, cfg.Warnings())
+		})
+	}
+}
+
 func init() {
 	os.RemoveAll("/tmp/data")
 	os.RemoveAll("/tmp/cache")
@@ -77,5 +77,8 @@ func MustLoadConfig() *Config {
 	pkglog.InitWithConfig(logCfg)
 
 	slog.Debug("Configuration loaded successfully", slog.Any("config", cfg))
+	for _, w := range cfg.Warnings() {
+		slog.Warn("Configuration warning", "warning", w)
+	}
 	return cfg
 }
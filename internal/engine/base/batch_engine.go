@@ -19,6 +19,7 @@ type BaseBatchEngine struct {
 	model       config.LLMModel
 	hooks       models.BatchEngineHooks
 	tools       models.ToolProvider
+	summary     *models.RunSummary
 }
 
 var _ models.Engine = &BaseBatchEngine{}
@@ -55,7 +56,14 @@ func (b *BaseBatchEngine) ResultKey() bag.Key {
 	return b.hooks.ResultKey()
 }
 
-// Execute implements the template method pattern with hooks for customization
+// Summary returns the summary of the last run, or nil if the engine has not run yet
+func (b *BaseBatchEngine) Summary() *models.RunSummary {
+	return b.summary
+}
+
+// Execute implements the template method pattern with hooks for customization.
+// When the run completes but some items failed, the returned error wraps
+// models.ErrBatchItemsFailed and the full detail is available via Summary.
 func (b *BaseBatchEngine) Execute(ctx context.Context, aiClient models.AiClient, sharedBag bag.SharedBag) error {
 	// Set up tool consumer
 	aiClient.SetToolConsumer(budget.NewToolConsumer(&b.constraints))
@@ -81,6 +89,7 @@ func (b *BaseBatchEngine) Execute(ctx context.Context, aiClient models.AiClient,
 
 	maxIterations := 20
 	iteration := 0
+	summary := &models.RunSummary{Engine: b.name}
 
 	// Main batch processing loop
 	for len(currentJobs) > 0 && iteration < maxIterations {
@@ -109,7 +118,7 @@ func (b *BaseBatchEngine) Execute(ctx context.Context, aiClient models.AiClient,
 		}
 
 		// Process individual job results
-		nextJobs, err := b.processJobResults(ctx, currentJobs, results, iteration, sharedBag)
+		nextJobs, err := b.processJobResults(ctx, currentJobs, results, iteration, summary, sharedBag)
 		if err != nil {
 			return err
 		}
@@ -136,11 +145,32 @@ func (b *BaseBatchEngine) Execute(ctx context.Context, aiClient models.AiClient,
 			"max_iterations", maxIterations)
 	}
 
+	summary.Iterations = iteration
+	b.recordSummary(summary, sharedBag)
+
 	slog.Info("Batch processing completed",
 		"engine", b.name,
-		"total_iterations", iteration)
+		"total_iterations", iteration,
+		"completed_items", summary.Completed,
+		"failed_items", len(summary.ItemErrors))
+
+	return summary.Err()
+}
 
-	return nil
+// recordSummary keeps the run summary on the engine and publishes it in the shared bag
+func (b *BaseBatchEngine) recordSummary(summary *models.RunSummary, sharedBag bag.SharedBag) {
+	if alerts, ok := sharedBag.Get(bag.KConcentrationAlerts); ok {
+		summary.Alerts, _ = alerts.([]models.Alert)
+	}
+	b.summary = summary
+	sharedBag.Update(bag.KBatchRunSummaries, func(current any) any {
+		summaries, ok := current.(map[string]models.RunSummary)
+		if !ok {
+			summaries = make(map[string]models.RunSummary)
+		}
+		summaries[b.name] = *summary
+		return summaries
+	})
 }
 
 // submitAndWaitForBatch submits jobs to AI client and waits for completion
@@ -191,6 +221,7 @@ func (b *BaseBatchEngine) processJobResults(
 	jobs []models.BatchJob,
 	results *models.BatchResult,
 	iteration int,
+	summary *models.RunSummary,
 	sharedBag bag.SharedBag,
 ) ([]models.BatchJob, error) {
 	var nextJobs []models.BatchJob
@@ -215,8 +246,10 @@ func (b *BaseBatchEngine) processJobResults(
 		if errStr, hasError := results.Errors[customID]; hasError && errStr != "" {
 			slog.Error("Batch item failed",
 				"engine", b.name,
+				"iteration", iteration,
 				"custom_id", customID,
 				"error", errStr)
+			summary.AddItemError(iteration, customID, errStr)
 			continue
 		}
 
@@ -234,6 +267,7 @@ func (b *BaseBatchEngine) processJobResults(
 				if err := b.hooks.ProcessFinalResult(customID, content, sharedBag); err != nil {
 					return nil, fmt.Errorf("process final result: %w", err)
 				}
+				summary.Completed++
 			}
 			continue
 		}
@@ -1,6 +1,11 @@
 package base
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
@@ -81,3 +86,165 @@ func TestBatchEngine_Interface(t *testing.T) {
 		t.Error("ResultKey() returned empty key")
 	}
 }
+
+func TestBatchEngine_ExecuteAggregatesItemErrors(t *testing.T) {
+	cases := []struct {
+		name          string
+		iterations    []*models.BatchResult
+		wantErrors    []models.BatchItemError
+		wantCompleted int
+	}{
+		{
+			name: "all items succeed",
+			iterations: []*models.BatchResult{
+				{Content: map[string]string{"it0": "done"}},
+			},
+			wantCompleted: 1,
+		},
+		{
+			name: "item fails after a tool round",
+			iterations: []*models.BatchResult{
+				{ToolCalls: map[string][]models.ToolCall{"it0": {{ID: "call_1", Function: models.ToolCallFunction{Name: "fmp"}}}}},
+				{Errors: map[string]string{"it2": `{"code":"rate_limit_exceeded"}`}},
+			},
+			wantErrors: []models.BatchItemError{
+				{Iteration: 2, CustomID: "it2", Message: `{"code":"rate_limit_exceeded"}`},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			hooks := mocks.NewMockBatchEngineHooks(ctrl)
+			hooks.EXPECT().GetInitialPrompt(gomock.Any()).Return("analyze", nil)
+			hooks.EXPECT().GenerateCustomID(gomock.Any(), gomock.Any()).DoAndReturn(func(iteration, _ int) string {
+				return fmt.Sprintf("it%d", iteration)
+			}).AnyTimes()
+			hooks.EXPECT().PreIteration(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			hooks.EXPECT().PostIteration(gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			hooks.EXPECT().ProcessToolResult(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			hooks.EXPECT().ProcessFinalResult(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+			hooks.EXPECT().ShouldContinueIteration(gomock.Any(), gomock.Any()).Return(true).AnyTimes()
+
+			tools := mocks.NewMockToolProvider(ctrl)
+			tools.EXPECT().Get(gomock.Any()).Return(nil).AnyTimes()
+			tools.EXPECT().List().Return(nil).AnyTimes()
+
+			manager := mocks.NewMockBatchManager(ctrl)
+			aiClient := mocks.NewMockAiClient(ctrl)
+			aiClient.EXPECT().SetToolConsumer(gomock.Any())
+			aiClient.EXPECT().BatchManager().Return(manager).AnyTimes()
+			aiClient.EXPECT().DoBatch(gomock.Any(), gomock.Any()).Return(&models.BatchJob{ID: "batch"}, nil).Times(len(c.iterations))
+			manager.EXPECT().WaitForCompletion(gomock.Any(), "batch").
+				Return(&models.BatchJob{ID: "batch", Status: models.BatchStatusCompleted}, nil).Times(len(c.iterations))
+			for _, r := range c.iterations {
+				manager.EXPECT().GetResults(gomock.Any(), "batch").Return(r, nil)
+			}
+
+			engine := NewBatchEngine("test-engine", BaseBatchEngineConfig{
+				Model: config.LLMModelGPT4o,
+				Hooks: hooks,
+				Tools: tools,
+			})
+			sharedBag := bag.NewSharedBag()
+
+			err := engine.Execute(context.Background(), aiClient, sharedBag)
+
+			summary := engine.Summary()
+			if summary == nil {
+				t.Fatal("expected a run summary")
+			}
+			if !reflect.DeepEqual(summary.ItemErrors, c.wantErrors) {
+				t.Errorf("ItemErrors = %+v, want %+v", summary.ItemErrors, c.wantErrors)
+			}
+			if summary.Completed != c.wantCompleted {
+				t.Errorf("Completed = %d, want %d", summary.Completed, c.wantCompleted)
+			}
+			if summary.Iterations != len(c.iterations) {
+				t.Errorf("Iterations = %d, want %d", summary.Iterations, len(c.iterations))
+			}
+
+			if len(c.wantErrors) == 0 && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(c.wantErrors) > 0 && !errors.Is(err, models.ErrBatchItemsFailed) {
+				t.Fatalf("expected ErrBatchItemsFailed, got %v", err)
+			}
+
+			stored, ok := sharedBag.Get(bag.KBatchRunSummaries)
+			if !ok {
+				t.Fatal("expected summaries in shared bag")
+			}
+			if _, ok := stored.(map[string]models.RunSummary)["test-engine"]; !ok {
+				t.Errorf("expected summary for test-engine, got %v", stored)
+			}
+		})
+	}
+}
+
+func TestBatchEngine_ProcessJobResultsAccumulatesAcrossIterations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	hooks := mocks.NewMockBatchEngineHooks(ctrl)
+	hooks.EXPECT().ProcessFinalResult(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).AnyTimes()
+
+	engine := NewBatchEngine("test-engine", BaseBatchEngineConfig{Hooks: hooks})
+	summary := &models.RunSummary{Engine: "test-engine"}
+	jobs := []models.BatchJob{{CustomID: "ok"}, {CustomID: "flaky"}, {CustomID: "broken"}}
+
+	cases := []struct {
+		name      string
+		iteration int
+		results   *models.BatchResult
+	}{
+		{
+			name:      "first iteration",
+			iteration: 1,
+			results: &models.BatchResult{
+				Content: map[string]string{"ok": "fine"},
+				Errors:  map[string]string{"flaky": "timeout", "broken": "invalid"},
+			},
+		},
+		{
+			name:      "second iteration",
+			iteration: 2,
+			results: &models.BatchResult{
+				Content: map[string]string{"ok": "fine"},
+				Errors:  map[string]string{"flaky": "timeout", "broken": "invalid"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := engine.processJobResults(context.Background(), jobs, c.results, c.iteration, summary, bag.NewSharedBag()); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+
+	want := []models.BatchItemError{
+		{Iteration: 1, CustomID: "flaky", Message: "timeout"},
+		{Iteration: 1, CustomID: "broken", Message: "invalid"},
+		{Iteration: 2, CustomID: "flaky", Message: "timeout"},
+		{Iteration: 2, CustomID: "broken", Message: "invalid"},
+	}
+	if !reflect.DeepEqual(summary.ItemErrors, want) {
+		t.Errorf("ItemErrors = %+v, want %+v", summary.ItemErrors, want)
+	}
+	if summary.Completed != 2 {
+		t.Errorf("Completed = %d, want 2", summary.Completed)
+	}
+
+	err := summary.Err()
+	if !errors.Is(err, models.ErrBatchItemsFailed) {
+		t.Fatalf("expected ErrBatchItemsFailed, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "iteration 2: item broken: invalid") {
+		t.Errorf("aggregated error missing item context: %v", err)
+	}
+}
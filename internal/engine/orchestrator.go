@@ -3,6 +3,7 @@ package engine
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -26,6 +27,7 @@ type Orchestrator interface {
 	ExecutePipeline(ctx context.Context) error
 	Bag() bag.SharedBag
 	Tools() models.ToolProvider
+	AI() models.AiClient
 }
 
 // engineOrchestrator owns shared state (SharedBag), builds shared services, and wires engines via a Builder.
@@ -98,6 +100,14 @@ func (o *engineOrchestrator) Tools() models.ToolProvider {
 	return o.toolManager
 }
 
+// AI returns the AI client set up by Init, nil before
+func (o *engineOrchestrator) AI() models.AiClient {
+	if o.aiClient == nil {
+		return nil
+	}
+	return o.aiClient
+}
+
 // UseBuilder lets you choose to wire engines inside the orchestrator.
 func (o *engineOrchestrator) UseBuilder(b Builder) {
 	o.builder = b
@@ -169,8 +179,12 @@ func (o *engineOrchestrator) ExecutePipeline(ctx context.Context) error {
 		slog.Info("engine: start", "name", eng.Name())
 
 		if err := eng.Execute(ctx, o.aiClient, o.sharedBag); err != nil {
-			slog.Error("engine: failed", "name", eng.Name(), "err", err)
-			return err
+			// partial batch failures still produce a result; surface them without aborting
+			if !errors.Is(err, models.ErrBatchItemsFailed) {
+				slog.Error("engine: failed", "name", eng.Name(), "err", err)
+				return err
+			}
+			slog.Warn("engine: completed with failed items", "name", eng.Name(), "err", err)
 		}
 
 		if _, ok := o.sharedBag.Get(eng.ResultKey()); !ok {
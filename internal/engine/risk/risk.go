@@ -74,15 +74,8 @@ Constraints:
 		// You can also set MaxTokens / Temperature from engine config if you want:
 		MaxTokens: 2000,
 		// Temperature: ptr.To(0.2),
-		Tools: r.constraints.Tools, // use the tools defined in constraints,
-		ResponseFormat: &models.ResponseFormat{
-			Format: models.Format{
-				Type:      bag.ResponseFormatJSON,
-				Name:      bag.KRiskAnalysisResult.String(),
-				Schema:    pkgopenai.BuildSchema[models.InvestmentResearchResult](),
-				Verbosity: "	",
-			},
-		},
+		Tools:          r.constraints.Tools, // use the tools defined in constraints,
+		ResponseFormat: responseFormat(),
 	}
 
 	resp, err := client.DoSync(ctx, req)
@@ -105,3 +98,15 @@ Constraints:
 
 	return nil
 }
+
+// responseFormat is the structured output asked of every risk engine
+func responseFormat() *models.ResponseFormat {
+	return &models.ResponseFormat{
+		Format: models.Format{
+			Type:      bag.ResponseFormatJSON,
+			Name:      bag.KRiskAnalysisResult.String(),
+			Schema:    pkgopenai.BuildSchema[models.InvestmentResearchResult](),
+			Verbosity: "	",
+		},
+	}
+}
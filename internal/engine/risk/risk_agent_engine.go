@@ -14,9 +14,10 @@ import (
 )
 
 type RiskAgentEngine struct {
-	agent         *agents.Agent
-	promptBuilder models.PromptBuilder
-	resultKey     bag.Key
+	agent          *agents.Agent
+	promptBuilder  models.PromptBuilder
+	responseFormat *models.ResponseFormat
+	resultKey      bag.Key
 }
 
 func NewRiskAgentEngine(sharedBag bag.SharedBag, pb models.PromptBuilder, toolProvider models.ToolProvider) *RiskAgentEngine {
@@ -31,9 +32,10 @@ func NewRiskAgentEngine(sharedBag bag.SharedBag, pb models.PromptBuilder, toolPr
 		WithTools(agentTools...)
 
 	return &RiskAgentEngine{
-		agent:         agent,
-		promptBuilder: pb,
-		resultKey:     bag.KRiskAnalysisResult,
+		agent:          agent,
+		promptBuilder:  pb,
+		responseFormat: responseFormat(),
+		resultKey:      bag.KRiskAnalysisResult,
 	}
 }
 
@@ -49,7 +51,8 @@ func (r *RiskAgentEngine) Execute(ctx context.Context, _ models.AiClient, sb bag
 		},
 	}
 
-	result, err := runner.Run(ctx, r.agent, prompt)
+	// same structured output as the risk engine; checked against the agent's output type
+	result, err := pkgagents.Run(ctx, runner, r.agent, prompt, r.responseFormat)
 	if err != nil {
 		return fmt.Errorf("failed to run agent: %w", err)
 	}
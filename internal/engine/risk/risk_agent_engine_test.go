@@ -0,0 +1,61 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	pkgagents "github.com/amaurybrisou/mosychlos/pkg/agents"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/models/mocks"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+type riskScore struct {
+	Score float64 `json:"score"`
+}
+
+func TestRiskAgentEngine_ChecksOutputSchema(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	tp := mocks.NewMockToolProvider(ctrl)
+	tp.EXPECT().List().Return(nil)
+	pb := mocks.NewMockPromptBuilder(ctrl)
+	pb.EXPECT().BuildPrompt(gomock.Any(), models.AnalysisRisk).Return("assess the risk", nil)
+
+	sb := bag.NewSharedBag()
+	e := NewRiskAgentEngine(sb, pb, tp)
+	// an output type disagreeing with the risk response format
+	e.agent = e.agent.WithOutputType(agents.OutputType[riskScore]())
+
+	// the schema check fails before the model is called
+	err := e.Execute(context.Background(), nil, sb)
+	require.ErrorIs(t, err, pkgagents.ErrSchemaMismatch)
+	_, ok := sb.Get(bag.KRiskAnalysisResult)
+	assert.False(t, ok)
+}
+
+func TestRiskAgentEngine_Sandbox(t *testing.T) {
+	sandbox.Enable()
+	t.Cleanup(sandbox.Disable)
+	t.Cleanup(agents.ClearOpenaiSettings)
+	// a host without sandbox stub, as with a custom llm.base_url
+	pkgagents.UseSandboxClient("https://llm.internal.example/v1", "sk-test")
+
+	ctrl := gomock.NewController(t)
+	tp := mocks.NewMockToolProvider(ctrl)
+	tp.EXPECT().List().Return(nil)
+	pb := mocks.NewMockPromptBuilder(ctrl)
+	pb.EXPECT().BuildPrompt(gomock.Any(), models.AnalysisRisk).Return("assess the risk", nil)
+
+	sb := bag.NewSharedBag()
+	err := NewRiskAgentEngine(sb, pb, tp).Execute(context.Background(), nil, sb)
+	require.ErrorIs(t, err, sandbox.ErrNetworkDisabled)
+	_, ok := sb.Get(bag.KRiskAnalysisResult)
+	assert.False(t, ok)
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/amaurybrisou/mosychlos/internal/adapters"
 	"github.com/amaurybrisou/mosychlos/internal/health"
+	"github.com/amaurybrisou/mosychlos/internal/jurisdiction"
 	"github.com/amaurybrisou/mosychlos/internal/llm"
 	"github.com/amaurybrisou/mosychlos/internal/localization"
 	"github.com/amaurybrisou/mosychlos/internal/portfolio"
@@ -18,6 +19,7 @@ import (
 	"github.com/amaurybrisou/mosychlos/pkg/binance"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
 	"github.com/amaurybrisou/mosychlos/pkg/normalize"
+	"github.com/amaurybrisou/mosychlos/pkg/rebalance"
 )
 
 // WithInitSteps replaces the default init steps with custom steps.
@@ -36,6 +38,10 @@ func defaultInitSteps() []InitStep {
 		StepLoadProfile,
 		StepLoadRegionalSettings,
 		StepLoadPortfolio,
+		StepEvaluateAlerts,
+		StepScoreHealth,
+		StepCompareAllocation,
+		StepPlanRebalance,
 		StepInitAIClient,
 		StepInitPromptManager,
 	}
@@ -80,6 +86,161 @@ func StepLoadPortfolio(ctx context.Context, o *engineOrchestrator) error {
 	return nil
 }
 
+// StepEvaluateAlerts checks the portfolio against the concentration thresholds of
+// the profile (or the config) and stores the triggered alerts in the shared bag
+func StepEvaluateAlerts(_ context.Context, o *engineOrchestrator) error {
+	thresholds := o.cfg.Portfolio.Alerts
+	if v, ok := o.sharedBag.Get(bag.KProfile); ok {
+		if p, ok := v.(*models.InvestmentProfile); ok && p != nil && p.ConcentrationLimits != nil {
+			thresholds = *p.ConcentrationLimits
+		}
+	}
+
+	normalized, err := o.normalizedPortfolio()
+	if err != nil {
+		slog.Warn("Skipping concentration alerts", "error", err)
+		return nil
+	}
+	if normalized == nil {
+		return nil
+	}
+
+	alerts := models.EvaluateAlerts(normalized, thresholds)
+	for _, a := range alerts {
+		slog.Warn("Concentration alert", "severity", a.Severity, "type", a.Type, "subject", a.Subject,
+			"weight_pct", a.WeightPct, "threshold_pct", a.ThresholdPct)
+	}
+	o.sharedBag.Set(bag.KConcentrationAlerts, alerts)
+	return nil
+}
+
+// StepScoreHealth scores the portfolio health against the jurisdiction rules and
+// the profile and stores the score in the shared bag
+func StepScoreHealth(_ context.Context, o *engineOrchestrator) error {
+	normalized, err := o.normalizedPortfolio()
+	if err != nil {
+		slog.Warn("Skipping health score", "error", err)
+		return nil
+	}
+	if normalized == nil {
+		return nil
+	}
+
+	var profile *models.InvestmentProfile
+	if v, ok := o.sharedBag.Get(bag.KProfile); ok {
+		profile, _ = v.(*models.InvestmentProfile)
+	}
+	// empty rules would score every portfolio fully compliant; leave the component out
+	var rules *models.ComplianceRules
+	if o.cfg.Jurisdiction.Rules.ScreensHoldings() {
+		rules = &o.cfg.Jurisdiction.Rules
+	}
+	health := models.ComputeHealthScoreWith(o.cfg.Portfolio.HealthWeights, normalized, rules, profile)
+	slog.Info("Portfolio health scored", "score", health.Score, "components", health.Components)
+	o.sharedBag.Set(bag.KHealthScore, health)
+	return nil
+}
+
+// StepCompareAllocation compares the portfolio sleeves to the allocation the
+// profile's risk tolerance recommends and stores the gaps in the shared bag
+func StepCompareAllocation(_ context.Context, o *engineOrchestrator) error {
+	normalized, err := o.normalizedPortfolio()
+	if err != nil {
+		slog.Warn("Skipping allocation comparison", "error", err)
+		return nil
+	}
+	if normalized == nil {
+		return nil
+	}
+
+	var profile *models.InvestmentProfile
+	if v, ok := o.sharedBag.Get(bag.KProfile); ok {
+		profile, _ = v.(*models.InvestmentProfile)
+	}
+	target, key, ok := o.cfg.Portfolio.AllocationTable().Recommended(profile)
+	if !ok {
+		slog.Warn("No recommended allocation for the profile", "profile", key)
+		return nil
+	}
+	comparison := &models.AllocationComparison{Profile: key, Gaps: models.CompareAllocation(normalized, target)}
+	for _, g := range comparison.OffTarget() {
+		slog.Info("Allocation off target", "profile", key, "sleeve", g.Sleeve, "status", g.Status,
+			"actual_pct", g.ActualPct, "target_pct", g.TargetPct)
+	}
+	o.sharedBag.Set(bag.KAllocationGaps, comparison)
+	return nil
+}
+
+// StepPlanRebalance plans the trades moving the holdings to the recommended
+// allocation and stores the plan in the shared bag
+func StepPlanRebalance(_ context.Context, o *engineOrchestrator) error {
+	v, _ := o.sharedBag.Get(bag.KAllocationGaps)
+	comparison, _ := v.(*models.AllocationComparison)
+	if comparison == nil {
+		return nil
+	}
+	normalized, err := o.normalizedPortfolio()
+	if err != nil || normalized == nil {
+		return nil
+	}
+
+	targets, unreachable := rebalance.SleeveTargets(normalized, comparison.Target(), o.cfg.Portfolio.SleeveMapping())
+	opts := o.cfg.Portfolio.Rebalance.Options()
+	opts.AsOf = normalized.AsOfDate
+	opts.Sleeves = o.cfg.Portfolio.SleeveMapping()
+	if rules, ok := jurisdiction.TaxRules(o.cfg.Jurisdiction.Country); ok {
+		opts.Tax = rules
+	} else if opts.TaxAware {
+		slog.Warn("No capital gains rules for the jurisdiction, tax-aware rebalancing only orders lots by gain", "country", o.cfg.Jurisdiction.Country)
+	}
+
+	v, _ = o.sharedBag.Get(bag.KMarketPrices)
+	prices, _ := v.(map[string]float64)
+	if len(prices) == 0 {
+		slog.Debug("No market prices, positions priced at their portfolio valuation")
+	}
+
+	var plan *models.RebalancePlan
+	if accounts := rebalance.Accounts(normalized, opts.Tax, prices); o.cfg.Portfolio.Rebalance.AssetLocation && len(accounts) > 1 {
+		for _, a := range accounts {
+			if a.Wrapper.Name != "" && !a.Wrapper.Treatment.Advantaged() {
+				slog.Warn("Unknown tax wrapper, account planned as taxable", "account", a.Name, "wrapper", a.Wrapper.Name, "country", o.cfg.Jurisdiction.Country)
+			}
+		}
+		plan, err = rebalance.CalculateAccounts(accounts, targets, opts)
+	} else {
+		positions, cash := rebalance.Positions(normalized, prices)
+		plan, err = rebalance.Calculate(positions, cash, targets, opts)
+	}
+	if err != nil {
+		slog.Warn("Skipping rebalancing plan", "error", err)
+		return nil
+	}
+	plan.Unreachable = unreachable
+	slog.Info("Rebalancing planned", "trades", len(plan.Trades), "suppressed", len(plan.Suppressed),
+		"turnover", plan.Turnover(), "max_drift_pct", plan.MaxDrift(), "realized_gain", plan.RealizedGain, "tax", plan.Tax,
+		"tax_warnings", len(plan.TaxWarnings()), "locked_pct", plan.LockedWeight(), "accounts", len(plan.Accounts))
+	o.sharedBag.Set(bag.KRebalancePlan, plan)
+	return nil
+}
+
+// normalizedPortfolio returns the normalized portfolio of the shared bag,
+// normalizing the raw one when needed; nil without a portfolio
+func (o *engineOrchestrator) normalizedPortfolio() (*models.NormalizedPortfolio, error) {
+	if v, ok := o.sharedBag.Get(bag.KPortfolioNormalizedForAI); ok {
+		if n, ok := v.(*models.NormalizedPortfolio); ok && n != nil {
+			return n, nil
+		}
+	}
+	// the portfolio was reused from an earlier run today without normalization
+	v, _ := o.sharedBag.Get(bag.KPortfolio)
+	p, _ := v.(*models.Portfolio)
+	if p == nil {
+		return nil, nil
+	}
+	return p.NormalizeWith(o.cfg.Portfolio.NormalizeOptions())
+}
+
 // StepLoadProfile loads investment profile and regional configuration
 func StepLoadProfile(ctx context.Context, o *engineOrchestrator) error {
 	// Create profile manager using os.DirFS for the filesystem interface
@@ -0,0 +1,165 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// PrometheusContentType is the content type of the Prometheus text exposition format
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// metricFamily is one Prometheus metric with its samples
+type metricFamily struct {
+	name    string
+	help    string
+	kind    string // counter or gauge
+	samples []sample
+}
+
+type sample struct {
+	labels map[string]string
+	value  float64
+}
+
+// WritePrometheus writes the tool metrics and external data health found in the
+// bag in Prometheus text exposition format. Bags loaded back from JSON work too.
+func WritePrometheus(w io.Writer, sb bag.SharedBag) error {
+	var families []metricFamily
+
+	if v, ok := sb.Get(bag.KToolMetrics); ok {
+		if tm, ok := decodeBagValue[models.ToolMetrics](v); ok {
+			families = append(families, toolMetricFamilies(tm)...)
+		}
+	}
+	if v, ok := sb.Get(bag.KExternalDataHealth); ok {
+		if h, ok := decodeBagValue[models.ExternalDataHealth](v); ok {
+			families = append(families, providerMetricFamilies(h)...)
+		}
+	}
+
+	var b bytes.Buffer
+	for _, f := range families {
+		if len(f.samples) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, f.help)
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.kind)
+		for _, s := range f.samples {
+			b.WriteString(f.name)
+			b.WriteString(formatLabels(s.labels))
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(s.value, 'g', -1, 64))
+			b.WriteByte('\n')
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// PrometheusHandler serves the bag metrics on a /metrics style endpoint
+func PrometheusHandler(sb bag.SharedBag) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", PrometheusContentType)
+		if err := WritePrometheus(w, sb); err != nil {
+			slog.Error("Failed to write Prometheus metrics", "error", err)
+		}
+	})
+}
+
+func toolMetricFamilies(tm *models.ToolMetrics) []metricFamily {
+	calls := metricFamily{name: "mosychlos_tool_calls_total", help: "Tool calls, including LLM API calls.", kind: "counter"}
+	errs := metricFamily{name: "mosychlos_tool_errors_total", help: "Tool calls that returned an error.", kind: "counter"}
+	duration := metricFamily{name: "mosychlos_tool_duration_seconds_total", help: "Time spent in tool calls.", kind: "counter"}
+	avg := metricFamily{name: "mosychlos_tool_average_duration_seconds", help: "Average tool call duration.", kind: "gauge"}
+	success := metricFamily{name: "mosychlos_tool_success_ratio", help: "Share of tool calls that succeeded (0-1).", kind: "gauge"}
+	tokens := metricFamily{name: "mosychlos_tool_tokens_total", help: "Tokens consumed by tool calls.", kind: "counter"}
+	cost := metricFamily{name: "mosychlos_tool_cost_usd_total", help: "Estimated cost of tool calls in USD.", kind: "counter"}
+
+	for _, name := range slices.Sorted(maps.Keys(tm.ByTool)) {
+		s := tm.ByTool[name]
+		labels := map[string]string{"tool": name}
+		calls.samples = append(calls.samples, sample{labels, float64(s.Calls)})
+		errs.samples = append(errs.samples, sample{labels, float64(s.Errors)})
+		duration.samples = append(duration.samples, sample{labels, s.Duration.Seconds()})
+		avg.samples = append(avg.samples, sample{labels, s.AverageDuration.Seconds()})
+		if s.Calls > 0 {
+			success.samples = append(success.samples, sample{labels, float64(s.Successes) / float64(s.Calls)})
+		}
+		tokens.samples = append(tokens.samples, sample{labels, float64(s.Tokens)})
+		cost.samples = append(cost.samples, sample{labels, s.Cost})
+	}
+
+	return []metricFamily{
+		calls, errs, duration, avg, success, tokens, cost,
+		{name: "mosychlos_tokens_total", help: "Tokens consumed across all tools.", kind: "counter", samples: []sample{{nil, float64(tm.TotalTokens)}}},
+		{name: "mosychlos_cost_usd_total", help: "Estimated cost across all tools in USD.", kind: "counter", samples: []sample{{nil, tm.TotalCost}}},
+	}
+}
+
+func providerMetricFamilies(h *models.ExternalDataHealth) []metricFamily {
+	up := metricFamily{name: "mosychlos_provider_healthy", help: "1 when the external data provider is healthy, 0 otherwise.", kind: "gauge"}
+	success := metricFamily{name: "mosychlos_provider_success_ratio", help: "Share of successful calls to the provider (0-1).", kind: "gauge"}
+	latency := metricFamily{name: "mosychlos_provider_average_latency_seconds", help: "Average provider call latency.", kind: "gauge"}
+	lastSuccess := metricFamily{name: "mosychlos_provider_last_success_timestamp_seconds", help: "Unix time of the last successful provider call.", kind: "gauge"}
+
+	for _, name := range slices.Sorted(maps.Keys(h.Providers)) {
+		p := h.Providers[name]
+		labels := map[string]string{"provider": name}
+		healthy := 0.0
+		if p.Status == "healthy" {
+			healthy = 1
+		}
+		up.samples = append(up.samples, sample{map[string]string{"provider": name, "status": p.Status}, healthy})
+		success.samples = append(success.samples, sample{labels, p.SuccessRate})
+		latency.samples = append(latency.samples, sample{labels, p.AverageLatency.Seconds()})
+		if !p.LastSuccess.IsZero() {
+			lastSuccess.samples = append(lastSuccess.samples, sample{labels, float64(p.LastSuccess.Unix())})
+		}
+	}
+	return []metricFamily{up, success, latency, lastSuccess}
+}
+
+// decodeBagValue returns v as a *T, converting through JSON when the bag was
+// loaded from a file and holds generic maps
+func decodeBagValue[T any](v any) (*T, bool) {
+	switch x := v.(type) {
+	case T:
+		return &x, true
+	case *T:
+		return x, x != nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var out T
+	if err := json.Unmarshal(b, &out); err != nil {
+		return nil, false
+	}
+	return &out, true
+}
+
+// formatLabels renders {k="v",...} with sorted keys and escaped values
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels))
+	for _, k := range slices.Sorted(maps.Keys(labels)) {
+		parts = append(parts, fmt.Sprintf(`%s="%s"`, k, labelEscaper.Replace(labels[k])))
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,98 @@
+package health
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func metricsBag() bag.SharedBag {
+	sb := bag.NewSharedBag()
+	sb.Set(bag.KToolMetrics, models.ToolMetrics{
+		TotalCalls:  5,
+		TotalTokens: 1200,
+		TotalCost:   0.042,
+		ByTool: map[string]models.ToolStats{
+			"fmp":    {Calls: 4, Successes: 3, Errors: 1, Duration: 2 * time.Second, AverageDuration: 500 * time.Millisecond},
+			"openai": {Calls: 1, Successes: 1, Duration: 1500 * time.Millisecond, AverageDuration: 1500 * time.Millisecond, Tokens: 1200, Cost: 0.042},
+		},
+	})
+	sb.Set(bag.KExternalDataHealth, &models.ExternalDataHealth{
+		Providers: map[string]models.DataProviderHealth{
+			"fmp": {Name: "fmp", Status: "degraded", SuccessRate: 0.75, AverageLatency: 500 * time.Millisecond, LastSuccess: time.Unix(1760000000, 0)},
+		},
+	})
+	return sb
+}
+
+func TestWritePrometheus(t *testing.T) {
+	// a bag dumped to disk and loaded back holds generic maps instead of structs
+	raw, err := json.Marshal(metricsBag())
+	require.NoError(t, err)
+	reloaded, err := bag.LoadSharedBagFromJSON(bytes.NewReader(raw))
+	require.NoError(t, err)
+
+	cases := []struct {
+		name string
+		bag  bag.SharedBag
+	}{
+		{name: "live bag", bag: metricsBag()},
+		{name: "bag loaded from JSON", bag: reloaded},
+	}
+
+	want := []string{
+		"# TYPE mosychlos_tool_calls_total counter",
+		`mosychlos_tool_calls_total{tool="fmp"} 4`,
+		`mosychlos_tool_calls_total{tool="openai"} 1`,
+		`mosychlos_tool_errors_total{tool="fmp"} 1`,
+		`mosychlos_tool_duration_seconds_total{tool="fmp"} 2`,
+		`mosychlos_tool_average_duration_seconds{tool="openai"} 1.5`,
+		"# TYPE mosychlos_tool_success_ratio gauge",
+		`mosychlos_tool_success_ratio{tool="fmp"} 0.75`,
+		`mosychlos_tool_tokens_total{tool="openai"} 1200`,
+		`mosychlos_tool_cost_usd_total{tool="openai"} 0.042`,
+		"mosychlos_tokens_total 1200",
+		"mosychlos_cost_usd_total 0.042",
+		`mosychlos_provider_healthy{provider="fmp",status="degraded"} 0`,
+		`mosychlos_provider_success_ratio{provider="fmp"} 0.75`,
+		`mosychlos_provider_average_latency_seconds{provider="fmp"} 0.5`,
+		`mosychlos_provider_last_success_timestamp_seconds{provider="fmp"} 1.76e+09`,
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var out bytes.Buffer
+			require.NoError(t, WritePrometheus(&out, c.bag))
+			for _, line := range want {
+				assert.Contains(t, out.String(), line+"\n")
+			}
+		})
+	}
+}
+
+func TestWritePrometheus_EmptyBag(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, WritePrometheus(&out, bag.NewSharedBag()))
+	assert.Empty(t, out.String())
+}
+
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, `{a="1",b="say \"hi\"\\n\n"}`, formatLabels(map[string]string{"b": "say \"hi\"\\n\n", "a": "1"}))
+	assert.Equal(t, "", formatLabels(nil))
+}
+
+func TestPrometheusHandler(t *testing.T) {
+	rec := httptest.NewRecorder()
+	PrometheusHandler(metricsBag()).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	assert.Equal(t, PrometheusContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `mosychlos_tool_calls_total{tool="fmp"} 4`)
+}
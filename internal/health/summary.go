@@ -0,0 +1,53 @@
+package health
+
+import (
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// Overall statuses reported by Summarize
+const (
+	StatusHealthy  = "healthy"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
+)
+
+// Summary is the overall health derived from the external data providers
+type Summary struct {
+	Status    string            `json:"status"`
+	Providers map[string]string `json:"providers,omitempty"`
+	LastCheck time.Time         `json:"last_check,omitzero"`
+}
+
+// Summarize derives the overall health from the bag's ExternalDataHealth: down
+// when any provider is down, degraded when any is degraded, healthy otherwise
+// (including when no provider health was recorded yet)
+func Summarize(sb bag.SharedBag) Summary {
+	summary := Summary{Status: StatusHealthy}
+
+	v, ok := sb.Get(bag.KExternalDataHealth)
+	if !ok {
+		return summary
+	}
+	h, ok := decodeBagValue[models.ExternalDataHealth](v)
+	if !ok {
+		return summary
+	}
+
+	summary.LastCheck = h.LastCheck
+	summary.Providers = make(map[string]string, len(h.Providers))
+	for name, p := range h.Providers {
+		summary.Providers[name] = p.Status
+		switch p.Status {
+		case StatusDown:
+			summary.Status = StatusDown
+		case StatusDegraded:
+			if summary.Status == StatusHealthy {
+				summary.Status = StatusDegraded
+			}
+		}
+	}
+	return summary
+}
@@ -123,3 +123,29 @@ func TestNew(t *testing.T) {
 	require.NoError(t, err)
 	assert.NotNil(t, service2)
 }
+
+func TestTaxRules(t *testing.T) {
+	t.Parallel()
+
+	us, ok := TaxRules("us")
+	require.True(t, ok)
+	assert.Equal(t, 365, us.LongTermAfterDays)
+	assert.Greater(t, us.ShortTermRate, us.LongTermRate)
+	assert.Equal(t, 30, us.WashSaleDays)
+	require.Len(t, us.HoldingPeriods, 1)
+	assert.Equal(t, us.LongTermAfterDays, us.HoldingPeriods[0].Days)
+	assert.Equal(t, models.TaxDeferred, us.Wrapper("IRA").Treatment)
+	assert.Equal(t, models.TaxTaxable, us.Wrapper("pea").Treatment)
+
+	fr, ok := TaxRules("FR")
+	require.True(t, ok)
+	assert.Equal(t, fr.ShortTermRate, fr.LongTermRate)
+	require.Len(t, fr.HoldingPeriods, 1)
+	assert.Equal(t, "pea", fr.HoldingPeriods[0].Wrapper)
+	// bonds are not eligible to a PEA
+	assert.Equal(t, models.TaxExempt, fr.Wrapper("pea").Treatment)
+	assert.False(t, fr.Wrapper("pea").Holds("bond"))
+
+	_, ok = TaxRules("JP")
+	assert.False(t, ok)
+}
@@ -0,0 +1,33 @@
+package jurisdiction
+
+import (
+	_ "embed"
+	"encoding/json"
+	"strings"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// taxRulesData holds the capital gains rules by country: top federal rates, the
+// one-year holding period and the 30-day wash-sale rule in the US, the flat tax
+// (PFU) and the five-year PEA rule in France, and the tax wrappers of each
+// country (IRA, PEA...)
+//
+//go:embed tax_rules.json
+var taxRulesData []byte
+
+// TaxRules returns the capital gains rules of a country
+func TaxRules(country models.CountryCode) (models.TaxRules, bool) {
+	var data struct {
+		Countries []models.TaxRules `json:"countries"`
+	}
+	if err := json.Unmarshal(taxRulesData, &data); err != nil {
+		return models.TaxRules{}, false
+	}
+	for _, r := range data.Countries {
+		if strings.EqualFold(string(r.Country), string(country)) {
+			return r, true
+		}
+	}
+	return models.TaxRules{}, false
+}
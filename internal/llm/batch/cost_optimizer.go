@@ -72,12 +72,7 @@ func (co *CostOptimizer) estimateRequestCost(req models.BatchRequest) *models.Co
 	outputTokens := co.estimateOutputTokens(req.Body)
 
 	// Calculate cost
-	inputPrice := co.getInputPrice(model)
-	outputPrice := co.getOutputPrice(model)
-
-	inputCost := float64(inputTokens) / 1000.0 * inputPrice
-	outputCost := float64(outputTokens) / 1000.0 * outputPrice
-	totalCost := inputCost + outputCost
+	totalCost := co.UsageCost(model, inputTokens, outputTokens)
 
 	return &models.CostEstimate{
 		EstimatedCost:      totalCost,
@@ -87,6 +82,13 @@ func (co *CostOptimizer) estimateRequestCost(req models.BatchRequest) *models.Co
 	}
 }
 
+// UsageCost prices a synchronous call from its token counts, without batch discount
+func (co *CostOptimizer) UsageCost(model string, inputTokens, outputTokens int) float64 {
+	inputCost := float64(inputTokens) / 1000.0 * co.getInputPrice(model)
+	outputCost := float64(outputTokens) / 1000.0 * co.getOutputPrice(model)
+	return inputCost + outputCost
+}
+
 // extractModel extracts the model name from request body
 func (co *CostOptimizer) extractModel(body map[string]any) string {
 	if model, ok := body["model"].(string); ok {
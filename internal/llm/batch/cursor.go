@@ -0,0 +1,83 @@
+package batch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// cursorFile is the cache-relative location of the persisted batch list cursor
+const cursorFile = "batch/list_cursor.json"
+
+// listCursor is the on-disk representation of the last-seen batch list position
+type listCursor struct {
+	After     string    `json:"after"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CursorStore persists the last job ID returned by a batch listing so the next
+// invocation can resume from the following page
+type CursorStore struct {
+	fs   fs.FS
+	path string
+}
+
+// NewCursorStore creates a cursor store rooted in the given cache directory
+func NewCursorStore(filesystem fs.FS, cacheDir string) *CursorStore {
+	return &CursorStore{
+		fs:   filesystem,
+		path: filepath.Join(cacheDir, cursorFile),
+	}
+}
+
+// Load returns the persisted cursor, or an empty string if none was saved yet
+func (s *CursorStore) Load() (string, error) {
+	data, err := s.fs.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read batch list cursor: %w", err)
+	}
+
+	var c listCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return "", fmt.Errorf("failed to decode batch list cursor: %w", err)
+	}
+	return c.After, nil
+}
+
+// Save persists the given cursor; an empty cursor is ignored so that a final
+// empty page does not reset the position
+func (s *CursorStore) Save(after string) error {
+	if after == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(listCursor{After: after, UpdatedAt: time.Now().UTC()})
+	if err != nil {
+		return fmt.Errorf("failed to encode batch list cursor: %w", err)
+	}
+
+	if err := s.fs.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create cursor directory: %w", err)
+	}
+	if err := s.fs.WriteFile(s.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write batch list cursor: %w", err)
+	}
+	return nil
+}
+
+// LastJobID returns the ID of the last job of a page, to be used as the next cursor
+func LastJobID(jobs []models.BatchJob) string {
+	if len(jobs) == 0 {
+		return ""
+	}
+	return jobs[len(jobs)-1].ID
+}
@@ -0,0 +1,117 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/models/mocks"
+)
+
+func TestCursorStore_SaveLoad(t *testing.T) {
+	cases := []struct {
+		name  string
+		saves []string
+		want  string
+	}{
+		{name: "no cursor yet", saves: nil, want: ""},
+		{name: "single save", saves: []string{"batch_3"}, want: "batch_3"},
+		{name: "latest save wins", saves: []string{"batch_3", "batch_6"}, want: "batch_6"},
+		{name: "empty page keeps previous cursor", saves: []string{"batch_3", ""}, want: "batch_3"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cacheDir := t.TempDir()
+			store := NewCursorStore(fs.OS{}, cacheDir)
+			for _, s := range c.saves {
+				require.NoError(t, store.Save(s))
+			}
+
+			// a fresh store simulates the next CLI invocation
+			got, err := NewCursorStore(fs.OS{}, cacheDir).Load()
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+// pagedJobs builds n jobs with sequential IDs
+func pagedJobs(n int) []models.BatchJob {
+	jobs := make([]models.BatchJob, 0, n)
+	for i := range n {
+		jobs = append(jobs, models.BatchJob{ID: fmt.Sprintf("batch_%d", i), Status: models.BatchStatusCompleted})
+	}
+	return jobs
+}
+
+// pageAfter mimics the API cursor semantics over an in-memory job list
+func pageAfter(jobs []models.BatchJob, filters map[string]string) []models.BatchJob {
+	start := 0
+	if after := filters["after"]; after != "" {
+		for i, j := range jobs {
+			if j.ID == after {
+				start = i + 1
+				break
+			}
+		}
+	}
+	limit, _ := strconv.Atoi(filters["limit"])
+	end := min(start+limit, len(jobs))
+	return jobs[start:end]
+}
+
+func TestManager_ListAllBatches(t *testing.T) {
+	cases := []struct {
+		name      string
+		total     int
+		pageSize  int
+		after     string
+		wantCalls int
+		wantJobs  int
+		wantErr   bool
+	}{
+		{name: "multiple full pages plus a short one", total: 7, pageSize: 3, wantCalls: 3, wantJobs: 7},
+		{name: "exact multiple needs a trailing empty page", total: 6, pageSize: 3, wantCalls: 3, wantJobs: 6},
+		{name: "single short page", total: 2, pageSize: 5, wantCalls: 1, wantJobs: 2},
+		{name: "resume from cursor", total: 7, pageSize: 3, after: "batch_2", wantCalls: 2, wantJobs: 4},
+		{name: "invalid page size", total: 7, pageSize: 0, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockAiBatchClient(ctrl)
+			jobs := pagedJobs(c.total)
+
+			client.EXPECT().ListBatches(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, filters map[string]string) ([]models.BatchJob, error) {
+					assert.Equal(t, "completed", filters["status"])
+					return pageAfter(jobs, filters), nil
+				}).Times(c.wantCalls)
+
+			filters := map[string]string{"status": "completed"}
+			if c.after != "" {
+				filters["after"] = c.after
+			}
+
+			got, err := NewManager(client).ListAllBatches(context.Background(), filters, c.pageSize)
+			if c.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Len(t, got, c.wantJobs)
+			assert.Equal(t, jobs[len(jobs)-1].ID, LastJobID(got))
+			// caller filters must not be mutated by paging
+			assert.Equal(t, c.after, filters["after"])
+		})
+	}
+}
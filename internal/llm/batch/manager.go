@@ -7,17 +7,29 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
+	"math/rand/v2"
+	"path/filepath"
 	"time"
 
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
 )
 
+// downloadChunkSize is the buffer size used when streaming result files to disk
+const downloadChunkSize = 32 * 1024
+
 // Manager orchestrates batch processing workflows with engine support
 type Manager struct {
 	client        models.AiBatchClient
 	aggregator    *ResultParser
 	costOptimizer *CostOptimizer
 	pollDelay     time.Duration
+	pollJitter    float64
+	rand          func() float64
+	statusLimiter *statusLimiter
+	notifier      *Notifier
 }
 
 // NewManager creates a new batch processing manager
@@ -33,6 +45,9 @@ func NewManager(client models.AiBatchClient) *Manager {
 		aggregator:    aggregator,
 		costOptimizer: NewCostOptimizer(),
 		pollDelay:     30 * time.Second, // Default polling interval
+		pollJitter:    defaultPollJitter,
+		rand:          rand.Float64,
+		statusLimiter: sharedStatusLimiter,
 	}
 }
 
@@ -41,6 +56,26 @@ func (m *Manager) SetPollDelay(delay time.Duration) {
 	m.pollDelay = delay
 }
 
+// SetPollJitter configures the random spread (0-1) applied to the polling interval
+func (m *Manager) SetPollJitter(factor float64) {
+	m.pollJitter = factor
+}
+
+// SetNotifier configures the webhook notified when a waited job reaches a terminal status
+func (m *Manager) SetNotifier(n *Notifier) {
+	m.notifier = n
+}
+
+// notify posts a terminal job status to the webhook, if any; failures are logged only
+func (m *Manager) notify(ctx context.Context, job *models.BatchJob) {
+	if m.notifier == nil {
+		return
+	}
+	if err := m.notifier.Notify(ctx, job); err != nil {
+		slog.Error("Failed to notify batch completion", "job_id", job.ID, "error", err)
+	}
+}
+
 // EstimateCost provides cost estimation for batch requests without submitting
 func (m *Manager) EstimateCost(requests []models.BatchRequest) *models.CostEstimate {
 	return m.costOptimizer.EstimateCost(requests)
@@ -108,6 +143,53 @@ func (m *Manager) GetResults(ctx context.Context, jobID string) (*models.BatchRe
 	return m.aggregator.AggregateBatchResult(ctx, jobID)
 }
 
+// DownloadResults streams the success results of a completed job to dst on fsys in fixed-size
+// chunks, so that large result files never have to be held in memory. The file is written to a
+// temporary sibling and renamed once complete. It returns the number of bytes written.
+func (m *Manager) DownloadResults(ctx context.Context, jobID string, fsys fs.FS, dst string) (int64, error) {
+	job, err := m.client.GetBatchStatus(ctx, jobID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to check job status: %w", err)
+	}
+
+	if job.Status != models.BatchStatusCompleted {
+		return 0, fmt.Errorf("batch job not completed (status: %s)", job.Status)
+	}
+
+	reader, err := m.client.GetBatchResults(ctx, jobID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get results: %w", err)
+	}
+	defer reader.Close()
+
+	if dir := filepath.Dir(dst); dir != "." {
+		if err := fsys.MkdirAll(dir, 0o755); err != nil {
+			return 0, fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+
+	tmp := dst + ".part"
+	w, err := fsys.Create(tmp)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create output file: %w", err)
+	}
+
+	n, err := io.CopyBuffer(w, reader, make([]byte, downloadChunkSize))
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = fsys.Remove(tmp)
+		return n, fmt.Errorf("failed to download results for job %s: %w", jobID, err)
+	}
+
+	if err := fsys.Rename(tmp, dst); err != nil {
+		return n, fmt.Errorf("failed to finalize output file: %w", err)
+	}
+
+	return n, nil
+}
+
 // CancelJob cancels a running or queued batch job
 func (m *Manager) CancelJob(ctx context.Context, jobID string) error {
 	return m.client.CancelBatch(ctx, jobID)
@@ -118,6 +200,33 @@ func (m *Manager) ListBatches(ctx context.Context, filters map[string]string) ([
 	return m.client.ListBatches(ctx, filters)
 }
 
+// ListAllBatches iterates over every page of batch jobs, using pageSize as the page limit and
+// the last job ID of each page as the cursor for the next one
+func (m *Manager) ListAllBatches(ctx context.Context, filters map[string]string, pageSize int) ([]models.BatchJob, error) {
+	if pageSize <= 0 {
+		return nil, fmt.Errorf("page size must be positive, got %d", pageSize)
+	}
+
+	pageFilters := make(map[string]string, len(filters)+2)
+	maps.Copy(pageFilters, filters)
+	pageFilters["limit"] = fmt.Sprintf("%d", pageSize)
+
+	var all []models.BatchJob
+	for {
+		page, err := m.client.ListBatches(ctx, pageFilters)
+		if err != nil {
+			return all, fmt.Errorf("failed to list batches after %q: %w", pageFilters["after"], err)
+		}
+		all = append(all, page...)
+
+		// a short page means there is nothing left to fetch
+		if len(page) < pageSize {
+			return all, nil
+		}
+		pageFilters["after"] = LastJobID(page)
+	}
+}
+
 // GetError retrieves error information for a given job ID
 func (m *Manager) GetError(ctx context.Context, jobID string) (map[string]string, error) {
 	// Check if job exists and get its status
@@ -184,23 +293,24 @@ func (m *Manager) parseErrors(reader io.Reader, errors map[string]string) error
 
 // waitForCompletion implements the polling logic for job completion
 func (m *Manager) waitForCompletion(ctx context.Context, jobID string) (*models.BatchJob, error) {
-	ticker := time.NewTicker(m.pollDelay)
-	defer ticker.Stop()
-
 	for {
+		t := time.NewTimer(m.nextPollDelay())
 		select {
 		case <-ctx.Done():
+			t.Stop()
 			return nil, ctx.Err()
-		case <-ticker.C:
-			job, err := m.client.GetBatchStatus(ctx, jobID)
+		case <-t.C:
+			job, err := m.pollStatus(ctx, jobID)
 			if err != nil {
 				return nil, fmt.Errorf("failed to check job status: %w", err)
 			}
 
 			switch job.Status {
 			case models.BatchStatusCompleted:
+				m.notify(ctx, job)
 				return job, nil
 			case models.BatchStatusFailed, models.BatchStatusExpired, models.BatchStatusCancelled:
+				m.notify(ctx, job)
 				return job, fmt.Errorf("batch job failed with status: %s", job.Status)
 			case models.BatchStatusValidating, models.BatchStatusInProgress, models.BatchStatusFinalizing:
 				// Continue polling
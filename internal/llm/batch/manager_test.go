@@ -0,0 +1,162 @@
+package batch
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/models/mocks"
+)
+
+// syntheticResults lazily generates a large results JSONL stream without materializing it
+type syntheticResults struct {
+	lines   int
+	emitted int
+	pending []byte
+}
+
+func (s *syntheticResults) Read(p []byte) (int, error) {
+	if len(s.pending) == 0 {
+		if s.emitted >= s.lines {
+			return 0, io.EOF
+		}
+		s.pending = []byte(fmt.Sprintf(
+			`{"custom_id":"req_%d","response":{"body":{"choices":[{"message":{"content":"analysis %d"}}],`+
+				`"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}}}`+"\n",
+			s.emitted, s.emitted))
+		s.emitted++
+	}
+	n := copy(p, s.pending)
+	s.pending = s.pending[n:]
+	return n, nil
+}
+
+// Size returns the total byte size of the stream by generating it once more
+func (s *syntheticResults) Size() int64 {
+	n, _ := io.Copy(io.Discard, &syntheticResults{lines: s.lines})
+	return n
+}
+
+// countingReader records how many bytes were read and the largest single read request
+type countingReader struct {
+	r       io.Reader
+	total   int64
+	maxRead int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	if len(p) > c.maxRead {
+		c.maxRead = len(p)
+	}
+	n, err := c.r.Read(p)
+	c.total += int64(n)
+	return n, err
+}
+
+func (c *countingReader) Close() error { return nil }
+
+func TestManager_DownloadResults(t *testing.T) {
+	const lines = 50_000
+
+	cases := []struct {
+		name      string
+		status    models.BatchStatus
+		resultErr error
+		wantErr   bool
+	}{
+		{name: "completed job streams to disk", status: models.BatchStatusCompleted},
+		{name: "job not completed", status: models.BatchStatusInProgress, wantErr: true},
+		{name: "results unavailable", status: models.BatchStatusCompleted, resultErr: errors.New("boom"), wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			client := mocks.NewMockAiBatchClient(ctrl)
+
+			src := &syntheticResults{lines: lines}
+			counter := &countingReader{r: src}
+
+			client.EXPECT().GetBatchStatus(gomock.Any(), "job_1").
+				Return(&models.BatchJob{ID: "job_1", Status: c.status}, nil)
+			if c.status == models.BatchStatusCompleted {
+				if c.resultErr != nil {
+					client.EXPECT().GetBatchResults(gomock.Any(), "job_1").Return(nil, c.resultErr)
+				} else {
+					client.EXPECT().GetBatchResults(gomock.Any(), "job_1").Return(counter, nil)
+				}
+			}
+
+			dir := t.TempDir()
+			m := NewManager(client)
+			n, err := m.DownloadResults(context.Background(), "job_1", fs.OS{RootPath: dir}, "out/results.jsonl")
+			if c.wantErr {
+				require.Error(t, err)
+				_, statErr := os.Stat(filepath.Join(dir, "out", "results.jsonl"))
+				assert.True(t, os.IsNotExist(statErr))
+				return
+			}
+			require.NoError(t, err)
+
+			wantSize := src.Size()
+			assert.Equal(t, wantSize, n)
+			assert.Equal(t, wantSize, counter.total)
+			// the source must only ever be asked for one chunk at a time
+			assert.LessOrEqual(t, counter.maxRead, downloadChunkSize)
+			assert.Greater(t, wantSize, int64(100*downloadChunkSize))
+
+			info, err := os.Stat(filepath.Join(dir, "out", "results.jsonl"))
+			require.NoError(t, err)
+			assert.Equal(t, wantSize, info.Size())
+			_, err = os.Stat(filepath.Join(dir, "out", "results.jsonl.part"))
+			assert.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestParseResults(t *testing.T) {
+	cases := []struct {
+		name      string
+		lines     int
+		malformed bool
+		wantItems int
+	}{
+		{name: "large synthetic stream", lines: 20_000, wantItems: 20_000},
+		{name: "empty stream", lines: 0, wantItems: 0},
+		{name: "malformed lines are skipped", lines: 3, malformed: true, wantItems: 3},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var r io.Reader = &syntheticResults{lines: c.lines}
+			if c.malformed {
+				r = io.MultiReader(bytes.NewBufferString("not json\n"), r)
+			}
+			counter := &countingReader{r: r}
+
+			result, err := ParseResults("job_1", counter)
+			require.NoError(t, err)
+
+			assert.Equal(t, "job_1", result.JobID)
+			assert.Equal(t, c.wantItems, result.Successes)
+			assert.Len(t, result.Content, c.wantItems)
+			assert.Len(t, result.Usage, c.wantItems)
+			// the scanner reads in bounded increments rather than slurping the stream
+			assert.LessOrEqual(t, counter.maxRead, scanBufferSize)
+			if c.wantItems > 0 {
+				assert.Equal(t, "analysis 0", result.Content["req_0"])
+			}
+		})
+	}
+}
@@ -23,15 +23,14 @@ func NewMonitor(manager *Manager) *Monitor {
 
 // WatchJob monitors a job with progress updates
 func (m *Monitor) WatchJob(ctx context.Context, jobID string, progressCallback func(*models.BatchJob)) (*models.BatchJob, error) {
-	ticker := time.NewTicker(30 * time.Second) // Poll every 30 seconds
-	defer ticker.Stop()
-
 	for {
+		t := time.NewTimer(m.manager.nextPollDelay())
 		select {
 		case <-ctx.Done():
+			t.Stop()
 			return nil, ctx.Err()
-		case <-ticker.C:
-			job, err := m.manager.GetJobStatus(ctx, jobID)
+		case <-t.C:
+			job, err := m.manager.pollStatus(ctx, jobID)
 			if err != nil {
 				return nil, fmt.Errorf("failed to get job status: %w", err)
 			}
@@ -44,8 +43,10 @@ func (m *Monitor) WatchJob(ctx context.Context, jobID string, progressCallback f
 			// Check if job is finished
 			switch job.Status {
 			case models.BatchStatusCompleted:
+				m.manager.notify(ctx, job)
 				return job, nil
 			case models.BatchStatusFailed, models.BatchStatusExpired, models.BatchStatusCancelled:
+				m.manager.notify(ctx, job)
 				return job, fmt.Errorf("job finished with status: %s", job.Status)
 			default:
 				// Continue monitoring
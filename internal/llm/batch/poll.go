@@ -0,0 +1,86 @@
+// internal/llm/batch/poll.go
+package batch
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+)
+
+const (
+	// defaultPollJitter spreads each poll by ±20% so concurrent waits drift apart
+	defaultPollJitter = 0.2
+	// defaultStatusSpacing is the minimum gap between two status calls across all waiters
+	defaultStatusSpacing = 250 * time.Millisecond
+)
+
+// statusLimiter spaces status calls evenly so concurrent waits don't burst
+type statusLimiter struct {
+	mu      sync.Mutex
+	spacing time.Duration
+	next    time.Time
+}
+
+// sharedStatusLimiter is used by every Manager in the process
+var sharedStatusLimiter = newStatusLimiter(defaultStatusSpacing)
+
+func newStatusLimiter(spacing time.Duration) *statusLimiter {
+	return &statusLimiter{spacing: spacing}
+}
+
+// Wait reserves the next free slot and sleeps until it is reached
+func (l *statusLimiter) Wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.spacing)
+	l.mu.Unlock()
+
+	wait := time.Until(slot)
+	if wait <= 0 {
+		return nil
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
+
+// jitteredInterval returns base shifted by a random amount within ±factor*base.
+// r must return values in [0, 1).
+func jitteredInterval(base time.Duration, factor float64, r func() float64) time.Duration {
+	if factor <= 0 || base <= 0 {
+		return base
+	}
+	spread := float64(base) * factor
+	return base + time.Duration((r()*2-1)*spread)
+}
+
+// nextPollDelay returns the jittered delay before the next status check
+func (m *Manager) nextPollDelay() time.Duration {
+	return jitteredInterval(m.pollDelay, m.pollJitter, m.rand)
+}
+
+// pollStatus fetches a job status through the shared status limiter and the
+// global outbound semaphore
+func (m *Manager) pollStatus(ctx context.Context, jobID string) (*models.BatchJob, error) {
+	if err := m.statusLimiter.Wait(ctx); err != nil {
+		return nil, err
+	}
+	if err := pkgopenai.Outbound().Acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer pkgopenai.Outbound().Release()
+
+	return m.client.GetBatchStatus(ctx, jobID)
+}
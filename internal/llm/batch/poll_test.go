@@ -0,0 +1,125 @@
+package batch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/models/mocks"
+)
+
+func TestManager_NextPollDelay(t *testing.T) {
+	cases := []struct {
+		name    string
+		base    time.Duration
+		jitter  float64
+		samples int
+		varies  bool
+	}{
+		{name: "default jitter", base: 30 * time.Second, jitter: defaultPollJitter, samples: 500, varies: true},
+		{name: "wide jitter", base: time.Second, jitter: 1, samples: 500, varies: true},
+		{name: "jitter disabled", base: 10 * time.Second, jitter: 0, samples: 50, varies: false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			m := NewManager(nil)
+			m.SetPollDelay(c.base)
+			m.SetPollJitter(c.jitter)
+
+			lo := c.base - time.Duration(float64(c.base)*c.jitter)
+			hi := c.base + time.Duration(float64(c.base)*c.jitter)
+			seen := map[time.Duration]bool{}
+			for range c.samples {
+				d := m.nextPollDelay()
+				assert.GreaterOrEqual(t, d, lo)
+				assert.LessOrEqual(t, d, hi)
+				seen[d] = true
+			}
+			if c.varies {
+				assert.Greater(t, len(seen), c.samples/2, "intervals should vary")
+			} else {
+				assert.Len(t, seen, 1)
+			}
+		})
+	}
+}
+
+func TestJitteredInterval_Bounds(t *testing.T) {
+	cases := []struct {
+		name string
+		r    float64
+		want time.Duration
+	}{
+		{name: "lowest draw", r: 0, want: 8 * time.Second},
+		{name: "middle draw", r: 0.5, want: 10 * time.Second},
+		{name: "highest draw", r: 0.999999, want: 12 * time.Second},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := jitteredInterval(10*time.Second, 0.2, func() float64 { return c.r })
+			assert.InDelta(t, float64(c.want), float64(got), float64(time.Millisecond))
+		})
+	}
+}
+
+func TestStatusLimiter_SpacesConcurrentCalls(t *testing.T) {
+	const (
+		waiters = 5
+		spacing = 20 * time.Millisecond
+	)
+	l := newStatusLimiter(spacing)
+
+	var (
+		mu    sync.Mutex
+		times []time.Time
+		wg    sync.WaitGroup
+	)
+	for range waiters {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			require.NoError(t, l.Wait(context.Background()))
+			mu.Lock()
+			times = append(times, time.Now())
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	first, last := times[0], times[0]
+	for _, ts := range times {
+		if ts.Before(first) {
+			first = ts
+		}
+		if ts.After(last) {
+			last = ts
+		}
+	}
+	assert.GreaterOrEqual(t, last.Sub(first), time.Duration(waiters-1)*spacing-5*time.Millisecond)
+}
+
+func TestManager_WaitForCompletionUsesJitteredPolling(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockAiBatchClient(ctrl)
+
+	gomock.InOrder(
+		client.EXPECT().GetBatchStatus(gomock.Any(), "batch_1").Return(&models.BatchJob{ID: "batch_1", Status: models.BatchStatusInProgress}, nil),
+		client.EXPECT().GetBatchStatus(gomock.Any(), "batch_1").Return(&models.BatchJob{ID: "batch_1", Status: models.BatchStatusCompleted}, nil),
+	)
+
+	m := NewManager(client)
+	m.statusLimiter = newStatusLimiter(time.Millisecond)
+	m.SetPollDelay(5 * time.Millisecond)
+
+	job, err := m.WaitForCompletion(context.Background(), "batch_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.BatchStatusCompleted, job.Status)
+}
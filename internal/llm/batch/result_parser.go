@@ -61,14 +61,7 @@ func NewBatchResultParser(client ResultsReader) *ResultParser {
 
 // AggregateBatchResult processes batch results and errors into a unified view
 func (ra *ResultParser) AggregateBatchResult(ctx context.Context, jobID string) (*models.BatchResult, error) {
-	result := &models.BatchResult{
-		JobID:     jobID,
-		Items:     make(map[string]any),
-		Errors:    make(map[string]string),
-		ToolCalls: make(map[string][]models.ToolCall),
-		Content:   make(map[string]string),
-		Usage:     make(map[string]models.BatchUsage),
-	}
+	result := newBatchResult(jobID)
 
 	// Process success results
 	if err := ra.processResults(ctx, jobID, result); err != nil {
@@ -88,6 +81,32 @@ func (ra *ResultParser) AggregateBatchResult(ctx context.Context, jobID string)
 	return result, nil
 }
 
+// ParseResults parses a success results JSONL stream (e.g. a previously downloaded file)
+// line by line, without loading the whole content in memory
+func ParseResults(jobID string, r io.Reader) (*models.BatchResult, error) {
+	result := newBatchResult(jobID)
+	if err := parseResultLines(r, result); err != nil {
+		return nil, fmt.Errorf("failed to parse results: %w", err)
+	}
+
+	result.Successes = len(result.Items)
+	result.Failures = len(result.Errors)
+
+	return result, nil
+}
+
+// newBatchResult creates an empty result with all maps initialized
+func newBatchResult(jobID string) *models.BatchResult {
+	return &models.BatchResult{
+		JobID:     jobID,
+		Items:     make(map[string]any),
+		Errors:    make(map[string]string),
+		ToolCalls: make(map[string][]models.ToolCall),
+		Content:   make(map[string]string),
+		Usage:     make(map[string]models.BatchUsage),
+	}
+}
+
 // processResults reads and processes the success results JSONL file
 func (ra *ResultParser) processResults(ctx context.Context, jobID string, result *models.BatchResult) error {
 	reader, err := ra.client.GetBatchResults(ctx, jobID)
@@ -96,7 +115,12 @@ func (ra *ResultParser) processResults(ctx context.Context, jobID string, result
 	}
 	defer reader.Close()
 
-	return scanJSONLLines(reader, func(item map[string]any) {
+	return parseResultLines(reader, result)
+}
+
+// parseResultLines streams success JSONL lines from r into result
+func parseResultLines(r io.Reader, result *models.BatchResult) error {
+	return scanJSONLLines(r, func(item map[string]any) {
 		// Marshal -> Unmarshal into our typed struct
 		data, err := json.Marshal(item)
 		if err != nil {
@@ -189,9 +213,17 @@ func (ra *ResultParser) processErrors(ctx context.Context, jobID string, result
 	})
 }
 
+const (
+	// scanBufferSize is the initial read buffer used when scanning JSONL streams
+	scanBufferSize = 64 * 1024
+	// maxJSONLLineSize bounds the memory used for a single JSONL line while scanning
+	maxJSONLLineSize = 16 * 1024 * 1024
+)
+
 // scanJSONLLines scans JSONL format and calls fn for each parsed line
 func scanJSONLLines(r io.Reader, fn func(map[string]any)) error {
 	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, scanBufferSize), maxJSONLLineSize)
 	for scanner.Scan() {
 		var item map[string]any
 		if err := json.Unmarshal(scanner.Bytes(), &item); err == nil {
@@ -0,0 +1,180 @@
+// internal/llm/batch/webhook.go
+package batch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+// Webhook payload formats
+const (
+	WebhookFormatJSON  = "json"
+	WebhookFormatSlack = "slack"
+)
+
+const (
+	defaultWebhookTimeout     = 10 * time.Second
+	defaultWebhookMaxAttempts = 4
+	defaultWebhookBackoff     = time.Second
+)
+
+// WebhookOptions configures the batch completion webhook
+type WebhookOptions struct {
+	URL         string
+	Format      string        // json (default) or slack
+	Timeout     time.Duration // per attempt, default 10s
+	MaxAttempts int           // default 4
+}
+
+// WebhookPayload is the generic JSON body posted when a job reaches a terminal status
+type WebhookPayload struct {
+	Event         string             `json:"event"`
+	JobID         string             `json:"job_id"`
+	Status        models.BatchStatus `json:"status"`
+	Total         int                `json:"total"`
+	Completed     int                `json:"completed"`
+	Failed        int                `json:"failed"`
+	EstimatedCost *float64           `json:"estimated_cost,omitempty"`
+	CreatedAt     time.Time          `json:"created_at,omitzero"`
+	CompletedAt   time.Time          `json:"completed_at,omitzero"`
+}
+
+// Notifier posts batch job outcomes to a webhook, retrying with exponential backoff
+type Notifier struct {
+	opts    WebhookOptions
+	client  *http.Client
+	backoff time.Duration
+}
+
+// NewNotifier creates a webhook notifier
+func NewNotifier(opts WebhookOptions) *Notifier {
+	if opts.Format == "" {
+		opts.Format = WebhookFormatJSON
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = defaultWebhookTimeout
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = defaultWebhookMaxAttempts
+	}
+	return &Notifier{
+		opts:    opts,
+		client:  &http.Client{Timeout: opts.Timeout, Transport: sandbox.Guard(nil)},
+		backoff: defaultWebhookBackoff,
+	}
+}
+
+// Notify posts the job outcome. Server errors, rate limiting and network failures
+// are retried; other client errors and requests blocked by the sandbox are not.
+func (n *Notifier) Notify(ctx context.Context, job *models.BatchJob) error {
+	body, err := n.body(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	delay := n.backoff
+	var lastErr error
+	for attempt := 1; attempt <= n.opts.MaxAttempts; attempt++ {
+		retry, err := n.post(ctx, body)
+		if err == nil {
+			slog.Info("Batch webhook delivered", "job_id", job.ID, "status", job.Status, "attempt", attempt)
+			return nil
+		}
+		lastErr = err
+		if !retry || attempt == n.opts.MaxAttempts {
+			break
+		}
+
+		slog.Warn("Batch webhook failed, retrying", "job_id", job.ID, "attempt", attempt, "retry_in", delay, "error", err)
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+		delay *= 2
+	}
+	return fmt.Errorf("batch webhook for job %s failed: %w", job.ID, lastErr)
+}
+
+// post sends one attempt and reports whether a failure is worth retrying
+func (n *Notifier) post(ctx context.Context, body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.opts.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		// the sandbox blocks every attempt the same way
+		return ctx.Err() == nil && !errors.Is(err, sandbox.ErrNetworkDisabled), err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+}
+
+func (n *Notifier) body(job *models.BatchJob) ([]byte, error) {
+	payload := NewWebhookPayload(job)
+	if n.opts.Format == WebhookFormatSlack {
+		return json.Marshal(map[string]string{"text": slackText(payload)})
+	}
+	return json.Marshal(payload)
+}
+
+// NewWebhookPayload summarizes a job for the webhook
+func NewWebhookPayload(job *models.BatchJob) WebhookPayload {
+	p := WebhookPayload{
+		Event:     "batch." + string(job.Status),
+		JobID:     job.ID,
+		Status:    job.Status,
+		Total:     job.RequestCounts.Total,
+		Completed: job.RequestCounts.Completed,
+		Failed:    job.RequestCounts.Failed,
+	}
+	if job.CreatedAt > 0 {
+		p.CreatedAt = time.Unix(job.CreatedAt, 0).UTC()
+	}
+	if job.CompletedAt != nil {
+		p.CompletedAt = time.Unix(*job.CompletedAt, 0).UTC()
+	}
+
+	// cost estimated at submission, either on the job or in its metadata
+	if job.CostEstimate != nil {
+		p.EstimatedCost = &job.CostEstimate.EstimatedCost
+	} else if v, err := strconv.ParseFloat(job.Metadata["estimated_cost"], 64); err == nil {
+		p.EstimatedCost = &v
+	}
+	return p
+}
+
+func slackText(p WebhookPayload) string {
+	icon := ":white_check_mark:"
+	if p.Status != models.BatchStatusCompleted {
+		icon = ":x:"
+	}
+	text := fmt.Sprintf("%s Batch job `%s` %s: %d/%d requests completed, %d failed",
+		icon, p.JobID, p.Status, p.Completed, p.Total, p.Failed)
+	if p.EstimatedCost != nil {
+		text += fmt.Sprintf(", est. cost $%.4f", *p.EstimatedCost)
+	}
+	return text
+}
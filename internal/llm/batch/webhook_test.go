@@ -0,0 +1,161 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/models/mocks"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+// webhookStub records POSTed bodies and answers with the queued status codes (200 once exhausted)
+type webhookStub struct {
+	mu       sync.Mutex
+	bodies   [][]byte
+	statuses []int
+}
+
+func (s *webhookStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.bodies = append(s.bodies, body)
+	status := http.StatusOK
+	if len(s.statuses) > 0 {
+		status, s.statuses = s.statuses[0], s.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func (s *webhookStub) calls() [][]byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.bodies
+}
+
+func completedJob() *models.BatchJob {
+	completedAt := int64(1760000600)
+	job := &models.BatchJob{
+		ID:          "job_1",
+		Status:      models.BatchStatusCompleted,
+		CreatedAt:   1760000000,
+		CompletedAt: &completedAt,
+		Metadata:    map[string]string{"estimated_cost": "0.125"},
+	}
+	job.RequestCounts.Total = 10
+	job.RequestCounts.Completed = 9
+	job.RequestCounts.Failed = 1
+	return job
+}
+
+func TestManager_WaitForCompletion_PostsWebhook(t *testing.T) {
+	stub := &webhookStub{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	ctrl := gomock.NewController(t)
+	client := mocks.NewMockAiBatchClient(ctrl)
+	running := &models.BatchJob{ID: "job_1", Status: models.BatchStatusInProgress}
+	gomock.InOrder(
+		client.EXPECT().GetBatchStatus(gomock.Any(), "job_1").Return(running, nil),
+		client.EXPECT().GetBatchStatus(gomock.Any(), "job_1").Return(completedJob(), nil),
+	)
+
+	m := NewManager(client)
+	m.statusLimiter = newStatusLimiter(time.Millisecond)
+	m.SetPollDelay(5 * time.Millisecond)
+	m.SetNotifier(NewNotifier(WebhookOptions{URL: srv.URL}))
+
+	job, err := m.WaitForCompletion(context.Background(), "job_1")
+	require.NoError(t, err)
+	assert.Equal(t, models.BatchStatusCompleted, job.Status)
+
+	// only the terminal status is posted
+	calls := stub.calls()
+	require.Len(t, calls, 1)
+	var got WebhookPayload
+	require.NoError(t, json.Unmarshal(calls[0], &got))
+	assert.Equal(t, "batch.completed", got.Event)
+	assert.Equal(t, "job_1", got.JobID)
+	assert.Equal(t, models.BatchStatusCompleted, got.Status)
+	assert.Equal(t, 10, got.Total)
+	assert.Equal(t, 9, got.Completed)
+	assert.Equal(t, 1, got.Failed)
+	require.NotNil(t, got.EstimatedCost)
+	assert.InDelta(t, 0.125, *got.EstimatedCost, 1e-9)
+	assert.Equal(t, time.Unix(1760000600, 0).UTC(), got.CompletedAt)
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	cases := []struct {
+		name      string
+		statuses  []int
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "delivered first time", wantCalls: 1},
+		{name: "retried after server error", statuses: []int{500, 503}, wantCalls: 3},
+		{name: "retried after rate limiting", statuses: []int{429}, wantCalls: 2},
+		{name: "client error not retried", statuses: []int{400}, wantCalls: 1, wantErr: true},
+		{name: "gives up after max attempts", statuses: []int{500, 500, 500}, wantCalls: 3, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			stub := &webhookStub{statuses: c.statuses}
+			srv := httptest.NewServer(stub)
+			defer srv.Close()
+
+			n := NewNotifier(WebhookOptions{URL: srv.URL, MaxAttempts: 3})
+			n.backoff = time.Millisecond
+
+			err := n.Notify(context.Background(), completedJob())
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Len(t, stub.calls(), c.wantCalls)
+		})
+	}
+}
+
+func TestNotifier_SandboxNotRetried(t *testing.T) {
+	sandbox.Enable()
+	t.Cleanup(sandbox.Disable)
+
+	n := NewNotifier(WebhookOptions{URL: "https://hooks.example.com/batch", MaxAttempts: 3})
+	// a retry would wait far longer than the test allows
+	n.backoff = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	err := n.Notify(ctx, completedJob())
+	require.ErrorIs(t, err, sandbox.ErrNetworkDisabled)
+}
+
+func TestNotifier_SlackFormat(t *testing.T) {
+	stub := &webhookStub{}
+	srv := httptest.NewServer(stub)
+	defer srv.Close()
+
+	n := NewNotifier(WebhookOptions{URL: srv.URL, Format: WebhookFormatSlack})
+	require.NoError(t, n.Notify(context.Background(), completedJob()))
+
+	calls := stub.calls()
+	require.Len(t, calls, 1)
+	var got map[string]string
+	require.NoError(t, json.Unmarshal(calls[0], &got))
+	assert.Equal(t, ":white_check_mark: Batch job `job_1` completed: 9/10 requests completed, 1 failed, est. cost $0.1250", got["text"])
+}
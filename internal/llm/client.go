@@ -28,6 +28,10 @@ type Client struct {
 	runner   *llmopenai.Runner   // SDK-like loop (create → tools → submit → continue)
 	provider *llmopenai.Provider // holds cfg + shared bag
 
+	// strategy serves interactive calls; selected by llm.openai.session_api
+	strategy Strategy
+	chat     *llmopenai.ChatStrategy // set when session_api is chat_completions
+
 	toolRegistry map[bag.Key]models.Tool
 	consumer     models.ToolConsumer
 }
@@ -44,30 +48,41 @@ func NewLLMClient(cfg *config.Config, sharedBag bag.SharedBag) (*Client, error)
 		return nil, fmt.Errorf("shared bag not provided")
 	}
 
+	// --- Sync path (Responses) ---
+	doer := pkgopenai.NewHTTPClient() // *http.Client with sane defaults
+	po := pkgopenai.NewClient(doer, cfg.LLM.OpenAI)
+
 	// --- Batch path (existing) ---
 	filesystem := fs.OS{}
 	factory := NewBatchServiceFactory(cfg, filesystem, sharedBag)
+	// batch retries draw from the same run budget as the sync path
+	factory.SetRetryBudget(po.RetryBudget())
 	batchManager, err := factory.CreateManager()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create batch manager: %w", err)
 	}
 
-	// --- Sync path (Responses) ---
-	doer := pkgopenai.NewHTTPClient() // *http.Client with sane defaults
-	po := pkgopenai.NewClient(doer, cfg.LLM.OpenAI)
-
 	provider := llmopenai.NewProvider(po, cfg.LLM, sharedBag)
 	engine := llmopenai.NewEngine(po, cfg.LLM)      // pure transport
 	runner := llmopenai.NewRunner(engine, provider) // agent loop
 
-	return &Client{
+	c := &Client{
 		config:       &cfg.LLM,
 		batchManager: batchManager,
 		engine:       engine,
 		runner:       runner,
 		provider:     provider,
+		strategy:     runner,
 		toolRegistry: map[bag.Key]models.Tool{},
-	}, nil
+	}
+
+	if cfg.LLM.OpenAI.SessionAPI == config.SessionAPIChatCompletions {
+		c.chat = llmopenai.NewChatStrategy(po, cfg.LLM, sharedBag)
+		c.strategy = c.chat
+	}
+
+	slog.Debug("LLM session strategy selected", "strategy", c.strategy.Name())
+	return c, nil
 }
 
 func (c *Client) RegisterTool(t ...models.Tool) {
@@ -77,20 +92,28 @@ func (c *Client) RegisterTool(t ...models.Tool) {
 	// Make them visible to the runner/provider
 	c.runner.RegisterTool(t...)
 	c.provider.RegisterTool(t...)
+	if c.chat != nil {
+		for _, tool := range t {
+			c.chat.RegisterTool(tool)
+		}
+	}
 }
 
 func (c *Client) SetToolConsumer(tc models.ToolConsumer) {
 	c.consumer = tc
 	c.runner.SetToolConsumer(tc)
 	c.provider.SetToolConsumer(tc)
+	if c.chat != nil {
+		c.chat.SetToolConsumer(tc)
+	}
 }
 
-// Ask is the SDK-like sync run (mirrors Python Runner.run).
+// Ask is the SDK-like sync run (mirrors Python Runner.run), served by the configured strategy.
 func (c *Client) Ask(ctx context.Context, req models.PromptRequest) (*models.LLMResponse, error) {
-	if c.runner == nil {
-		return nil, errors.New("responses runner not initialized")
+	if c.strategy == nil {
+		return nil, errors.New("session strategy not initialized")
 	}
-	return c.runner.Run(ctx, req)
+	return c.strategy.Ask(ctx, req)
 }
 
 // AskStream streams tokens (if your Engine enables streaming later).
@@ -10,6 +10,7 @@ import (
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/fs"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
 )
 
 // BatchServiceFactory creates batch processing services
@@ -17,6 +18,7 @@ type BatchServiceFactory struct {
 	cfg        *config.Config
 	filesystem fs.FS
 	sharedBag  bag.SharedBag
+	budget     *pkgopenai.RetryBudget
 }
 
 // NewBatchServiceFactory creates a new factory
@@ -28,12 +30,30 @@ func NewBatchServiceFactory(cfg *config.Config, filesystem fs.FS, sharedBag bag.
 	}
 }
 
+// SetRetryBudget makes the batch client draw its retries from budget, the retry
+// budget of the run. Without it the client gets a budget of its own.
+func (f *BatchServiceFactory) SetRetryBudget(budget *pkgopenai.RetryBudget) {
+	f.budget = budget
+}
+
 func (f *BatchServiceFactory) CreateManager() (*batch.Manager, error) {
 	client, err := f.createBatchClient()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create batch client: %w", err)
 	}
-	return batch.NewManager(client), nil
+	manager := batch.NewManager(client)
+	if jitter := f.cfg.LLM.OpenAI.BatchPollJitter; jitter != nil {
+		manager.SetPollJitter(*jitter)
+	}
+	if wh := f.cfg.LLM.OpenAI.BatchWebhook; wh.URL != "" {
+		manager.SetNotifier(batch.NewNotifier(batch.WebhookOptions{
+			URL:         wh.URL,
+			Format:      wh.Format,
+			Timeout:     wh.Timeout,
+			MaxAttempts: wh.MaxAttempts,
+		}))
+	}
+	return manager, nil
 }
 
 // createBatchClient creates the appropriate batch client based on configuration
@@ -48,5 +68,9 @@ func (f *BatchServiceFactory) createBatchClient() (models.AiBatchClient, error)
 		return nil, fmt.Errorf("unsupported AI provider for batch processing: %s", f.cfg.LLM.Provider)
 	}
 
-	return openai.NewBatchClient(f.cfg.LLM, f.sharedBag)
+	budget := f.budget
+	if budget == nil {
+		budget = pkgopenai.NewRetryBudget(f.cfg.LLM.OpenAI.Retry.RunBudget)
+	}
+	return openai.NewBatchClient(f.cfg.LLM, f.sharedBag, budget)
 }
@@ -9,11 +9,14 @@ import (
 	"fmt"
 	"io"
 	"log/slog"
+	"net/http"
 	"strconv"
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
 	oa "github.com/openai/openai-go/v2"
 	"github.com/openai/openai-go/v2/option"
 	"github.com/openai/openai-go/v2/shared"
@@ -35,9 +38,16 @@ type batchClient struct {
 	config config.LLMConfig
 }
 
-// NewBatchClient creates a new OpenAI batch client
-func NewBatchClient(cfg config.LLMConfig, sharedBag bag.SharedBag) (models.AiBatchClient, error) {
-	opts := []option.RequestOption{}
+// NewBatchClient creates a new OpenAI batch client. Its retries follow llm.openai.retry
+// and draw from budget, the retry budget of the run (nil = unlimited).
+func NewBatchClient(cfg config.LLMConfig, sharedBag bag.SharedBag, budget *pkgopenai.RetryBudget) (models.AiBatchClient, error) {
+	opts := []option.RequestOption{
+		// guarded so sandbox mode blocks batch uploads and polling too
+		option.WithHTTPClient(&http.Client{Transport: sandbox.Guard(nil)}),
+		// retried by WithRetry instead of the SDK, so retries are budgeted
+		option.WithMaxRetries(0),
+		option.WithMiddleware(retryMiddleware(cfg.OpenAI.Retry, budget)),
+	}
 
 	if cfg.APIKey != "" {
 		opts = append(opts, option.WithAPIKey(cfg.APIKey))
@@ -53,6 +63,49 @@ func NewBatchClient(cfg config.LLMConfig, sharedBag bag.SharedBag) (models.AiBat
 	}, nil
 }
 
+// retryMiddleware sends SDK requests through pkg/openai.WithRetry, replaying the
+// request body on each attempt
+func retryMiddleware(cfg models.RetryConfig, budget *pkgopenai.RetryBudget) option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		return pkgopenai.WithRetry(req.Context(), cfg, budget, func(ctx context.Context) (*http.Response, error) {
+			attempt := req.Clone(ctx)
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				attempt.Body = body
+			}
+			return next(attempt)
+		})
+	}
+}
+
+const (
+	// defaultUploadContentType is the content-type used for batch input files unless configured
+	defaultUploadContentType = "application/jsonl"
+	// defaultUploadFilename is the filename used for batch input files unless configured
+	defaultUploadFilename = "batch_input.jsonl"
+)
+
+// uploadParams builds the file upload params for the batch input, honoring the
+// configured content-type and filename for gateways that reject application/jsonl
+func (bc *batchClient) uploadParams(jsonlData []byte) oa.FileNewParams {
+	contentType := bc.config.OpenAI.BatchUploadContentType
+	if contentType == "" {
+		contentType = defaultUploadContentType
+	}
+	filename := bc.config.OpenAI.BatchUploadFilename
+	if filename == "" {
+		filename = defaultUploadFilename
+	}
+
+	return oa.FileNewParams{
+		File:    oa.File(bytes.NewReader(jsonlData), filename, contentType),
+		Purpose: oa.FilePurposeBatch,
+	}
+}
+
 // SubmitBatch submits a batch of requests to OpenAI
 func (bc *batchClient) SubmitBatch(ctx context.Context, reqs []models.BatchRequest, opts models.BatchOptions) (*models.BatchJob, error) {
 	slog.Info("Submitting batch to OpenAI",
@@ -87,10 +140,7 @@ func (bc *batchClient) SubmitBatch(ctx context.Context, reqs []models.BatchReque
 	}
 
 	// Upload the JSONL file
-	uploadResp, err := bc.client.Files.New(ctx, oa.FileNewParams{
-		File:    oa.File(bytes.NewReader(jsonlData), "batch_input.jsonl", "application/jsonl"),
-		Purpose: oa.FilePurposeBatch,
-	})
+	uploadResp, err := bc.client.Files.New(ctx, bc.uploadParams(jsonlData))
 	if err != nil {
 		return nil, fmt.Errorf("failed to upload batch file: %w", err)
 	}
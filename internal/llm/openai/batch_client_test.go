@@ -0,0 +1,119 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+func TestBatchClient_Sandbox(t *testing.T) {
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"batch_1","object":"batch","status":"completed"}`))
+	}))
+	defer srv.Close()
+
+	sandbox.Enable()
+	t.Cleanup(sandbox.Disable)
+
+	client, err := NewBatchClient(config.LLMConfig{APIKey: "test", BaseURL: srv.URL}, bag.NewSharedBag(), nil)
+	require.NoError(t, err)
+
+	_, err = client.GetBatchStatus(context.Background(), "batch_1")
+	require.ErrorIs(t, err, sandbox.ErrNetworkDisabled)
+	_, err = client.SubmitBatch(context.Background(), []models.BatchRequest{
+		{CustomID: "req_1", Method: "POST", URL: "/v1/chat/completions", Body: map[string]any{"model": "gpt-4o-mini"}},
+	}, models.BatchOptions{CompletionWindow: "24h"})
+	require.ErrorIs(t, err, sandbox.ErrNetworkDisabled)
+	assert.Zero(t, calls, "no request may reach the server in sandbox mode")
+}
+
+func TestBatchClient_Retry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, `{"error":{"message":"overloaded"}}`, http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{APIKey: "test", BaseURL: srv.URL}
+	cfg.OpenAI.Retry = models.RetryConfig{MaxRetries: 5, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, ExponentialBase: 2}
+	budget := pkgopenai.NewRetryBudget(2)
+	client, err := NewBatchClient(cfg, bag.NewSharedBag(), budget)
+	require.NoError(t, err)
+
+	_, err = client.GetBatchStatus(context.Background(), "batch_1")
+	require.ErrorIs(t, err, pkgopenai.ErrServerError)
+	// the run budget stops the retries before max_retries, the SDK adds none
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Equal(t, 2, budget.Used())
+}
+
+func TestBatchClient_UploadContentType(t *testing.T) {
+	cases := []struct {
+		name            string
+		contentType     string
+		filename        string
+		wantContentType string
+		wantFilename    string
+	}{
+		{name: "defaults", wantContentType: "application/jsonl", wantFilename: "batch_input.jsonl"},
+		{name: "json gateway", contentType: "application/json", wantContentType: "application/json", wantFilename: "batch_input.jsonl"},
+		{name: "plain text gateway", contentType: "text/plain", filename: "input.txt", wantContentType: "text/plain", wantFilename: "input.txt"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var gotContentType, gotFilename string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/files" {
+					http.Error(w, `{"error":{"message":"unexpected"}}`, http.StatusBadRequest)
+					return
+				}
+				_, header, err := r.FormFile("file")
+				if err != nil {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				gotContentType = header.Header.Get("Content-Type")
+				gotFilename = header.Filename
+				// stop the flow right after the upload
+				http.Error(w, `{"error":{"message":"stop"}}`, http.StatusBadRequest)
+			}))
+			defer srv.Close()
+
+			cfg := config.LLMConfig{
+				APIKey:  "test",
+				BaseURL: srv.URL,
+				OpenAI: config.OpenAIConfig{
+					BatchUploadContentType: c.contentType,
+					BatchUploadFilename:    c.filename,
+				},
+			}
+			client, err := NewBatchClient(cfg, bag.NewSharedBag(), nil)
+			require.NoError(t, err)
+
+			_, err = client.SubmitBatch(context.Background(), []models.BatchRequest{
+				{CustomID: "req_1", Method: "POST", URL: "/v1/chat/completions", Body: map[string]any{"model": "gpt-4o-mini"}},
+			}, models.BatchOptions{CompletionWindow: "24h"})
+			require.Error(t, err)
+
+			assert.Equal(t, c.wantContentType, gotContentType)
+			assert.Equal(t, c.wantFilename, gotFilename)
+		})
+	}
+}
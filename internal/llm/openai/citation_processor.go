@@ -10,19 +10,12 @@ import (
 	"time"
 
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
 )
 
-// Citation represents a parsed web search citation
-type Citation struct {
-	URL        string    `json:"url"`
-	Title      string    `json:"title,omitempty"`
-	Snippet    string    `json:"snippet,omitempty"`
-	Source     string    `json:"source,omitempty"`
-	Timestamp  time.Time `json:"timestamp"`
-	Query      string    `json:"query"`
-	Relevance  float64   `json:"relevance,omitempty"`
-	CitationID string    `json:"citation_id"` // For referencing in text
-}
+// Citation represents a parsed web search citation; it is shared with the report
+// package through the bag
+type Citation = models.Citation
 
 // CitationResult represents the complete result from web search citation processing
 type CitationResult struct {
@@ -35,6 +28,16 @@ type CitationResult struct {
 	Error       string     `json:"error,omitempty"`
 }
 
+// URLAnnotation is a url_citation annotation attached to Responses API output_text.
+// StartIndex and EndIndex are character (not byte) offsets into the text the
+// annotation belongs to.
+type URLAnnotation struct {
+	URL        string `json:"url"`
+	Title      string `json:"title,omitempty"`
+	StartIndex int    `json:"start_index"`
+	EndIndex   int    `json:"end_index"`
+}
+
 // CitationProcessor handles web search citation extraction and storage
 type CitationProcessor struct {
 	sharedBag bag.SharedBag
@@ -90,6 +93,65 @@ func (p *CitationProcessor) ProcessCitations(query, response string) (*CitationR
 	return result, nil
 }
 
+// ProcessAnnotations stores citations from the structured url_citation annotations the
+// hosted web_search tool attaches to output text, skipping the free-text parsers.
+func (p *CitationProcessor) ProcessAnnotations(query, content string, annotations []URLAnnotation) (*CitationResult, error) {
+	processedAt := time.Now()
+	citations := p.parseAnnotations(content, annotations)
+	for i := range citations {
+		citations[i].Query = query
+		citations[i].Timestamp = processedAt
+	}
+
+	result := &CitationResult{
+		Query:       query,
+		Content:     content,
+		Citations:   citations,
+		TotalCites:  len(citations),
+		ProcessedAt: processedAt,
+		Success:     true,
+	}
+	p.storeCitationResult(result)
+
+	slog.Info("Annotation citations processed",
+		"query", query,
+		"annotations", len(annotations),
+		"citations_found", len(citations),
+	)
+
+	return result, nil
+}
+
+// parseAnnotations converts url_citation annotations into citations, deduplicated by URL
+func (p *CitationProcessor) parseAnnotations(content string, annotations []URLAnnotation) []Citation {
+	citations := []Citation{}
+	seen := make(map[string]bool)
+	chars := []rune(content)
+
+	for _, a := range annotations {
+		if !p.isValidURL(a.URL) || seen[a.URL] {
+			continue
+		}
+		seen[a.URL] = true
+
+		citation := Citation{
+			URL:        a.URL,
+			Title:      a.Title,
+			CitationID: fmt.Sprintf("[%d]", len(citations)+1),
+		}
+		if parsed, err := url.Parse(a.URL); err == nil {
+			citation.Source = parsed.Host
+		}
+		// the annotated span is the sentence the source backs
+		if a.StartIndex >= 0 && a.StartIndex < a.EndIndex && a.EndIndex <= len(chars) {
+			citation.Snippet = strings.TrimSpace(string(chars[a.StartIndex:a.EndIndex]))
+		}
+		citations = append(citations, citation)
+	}
+
+	return citations
+}
+
 // parseResponse attempts to parse citations from different response formats
 func (p *CitationProcessor) parseResponse(response string) ([]Citation, string, error) {
 	// Try JSON structured response first
@@ -313,7 +375,7 @@ func (p *CitationProcessor) storeCitationResult(result *CitationResult) {
 
 	// Store citations separately for easy access
 	if result.Success && len(result.Citations) > 0 {
-		citationsKey := bag.Key(fmt.Sprintf("%s.citations", bag.WebSearch))
+		citationsKey := bag.KWebSearchCitations
 		var allCitations []Citation
 		if existing, ok := p.sharedBag.Get(citationsKey); ok {
 			if existingCitations, ok := existing.([]Citation); ok {
@@ -352,8 +414,7 @@ func GetWebSearchCitations(sharedBag bag.SharedBag) ([]Citation, bool) {
 		return nil, false
 	}
 
-	citationsKey := bag.Key(fmt.Sprintf("%s.citations", bag.WebSearch))
-	if citations, ok := sharedBag.Get(citationsKey); ok {
+	if citations, ok := sharedBag.Get(bag.KWebSearchCitations); ok {
 		if webCitations, ok := citations.([]Citation); ok {
 			return webCitations, true
 		}
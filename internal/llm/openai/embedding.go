@@ -0,0 +1,217 @@
+// internal/llm/openai/embedding.go
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	defaultEmbeddingModel   = "text-embedding-3-small"
+	defaultEmbeddingTimeout = 30 * time.Second
+
+	// per-request limits of the embeddings endpoint
+	maxEmbeddingInputsPerRequest = 2048
+	maxEmbeddingTokensPerRequest = 300_000
+
+	// embeddingConcurrency bounds the chunk requests EmbedMany keeps in flight
+	embeddingConcurrency = 4
+)
+
+type embeddingReq struct {
+	Model string `json:"model"`
+	Input any    `json:"input"` // string or []string
+}
+
+type embeddingResp struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embedding returns the embedding vector of text. The call goes through the shared
+// pkg/openai client (timeout-configured transport, rate limiter, outbound semaphore),
+// is bounded by the configured embedding timeout and returns ctx.Err() as soon as
+// the caller's context is done.
+func (p *Provider) Embedding(ctx context.Context, text string) ([]float64, error) {
+	vectors, err := p.embed(ctx, text)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) == 0 {
+		return nil, fmt.Errorf("embedding response contained no vectors")
+	}
+	return vectors[0], nil
+}
+
+// EmbedMany embeds every input and returns the vectors in input order. Inputs are split
+// into chunks that respect the per-request array and token limits, chunks are sent
+// concurrently (bounded) and a failed chunk is retried by the client (llm.openai.retry)
+// before the whole call fails.
+func (p *Provider) EmbedMany(ctx context.Context, inputs []string) ([][]float64, error) {
+	if len(inputs) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		results  = make([][]float64, len(inputs))
+		slots    = make(chan struct{}, embeddingConcurrency)
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for _, chunk := range chunkEmbeddingInputs(inputs, maxEmbeddingInputsPerRequest, maxEmbeddingTokensPerRequest) {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(chunk embeddingChunk) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			vectors, err := p.embedChunk(ctx, chunk)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("embedding chunk at input %d: %w", chunk.start, err)
+					cancel()
+				})
+				return
+			}
+			// chunks cover disjoint ranges, so no locking is needed
+			copy(results[chunk.start:], vectors)
+		}(chunk)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// embedChunk embeds one chunk. Failed attempts are retried by the client with
+// the configured retry policy, drawing from the run's retry budget.
+func (p *Provider) embedChunk(ctx context.Context, chunk embeddingChunk) ([][]float64, error) {
+	vectors, err := p.embed(ctx, chunk.inputs)
+	if err != nil {
+		return nil, err
+	}
+	if len(vectors) != len(chunk.inputs) {
+		return nil, fmt.Errorf("embedding returned %d vectors for %d inputs", len(vectors), len(chunk.inputs))
+	}
+	return vectors, nil
+}
+
+// embeddingChunk is a contiguous slice of EmbedMany inputs sent in one request
+type embeddingChunk struct {
+	start  int
+	inputs []string
+}
+
+// chunkEmbeddingInputs splits inputs into contiguous chunks of at most maxInputs items
+// and roughly maxTokens estimated tokens. An input larger than maxTokens gets its own chunk.
+func chunkEmbeddingInputs(inputs []string, maxInputs, maxTokens int) []embeddingChunk {
+	var (
+		chunks []embeddingChunk
+		start  int
+		tokens int
+	)
+	for i, in := range inputs {
+		n := estimateEmbeddingTokens(in)
+		if i > start && (i-start >= maxInputs || tokens+n > maxTokens) {
+			chunks = append(chunks, embeddingChunk{start: start, inputs: inputs[start:i]})
+			start, tokens = i, 0
+		}
+		tokens += n
+	}
+	return append(chunks, embeddingChunk{start: start, inputs: inputs[start:]})
+}
+
+// estimateEmbeddingTokens approximates the token count of s (~4 characters per token)
+func estimateEmbeddingTokens(s string) int {
+	return utf8.RuneCountInString(s)/4 + 1
+}
+
+// embed posts input (string or []string) to /v1/embeddings and returns the vectors
+// ordered by their input index
+func (p *Provider) embed(ctx context.Context, input any) ([][]float64, error) {
+	if p.cli == nil {
+		return nil, fmt.Errorf("embedding client not configured")
+	}
+
+	timeout := p.cfg.OpenAI.EmbeddingTimeout
+	if timeout <= 0 {
+		timeout = defaultEmbeddingTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	model := p.cfg.OpenAI.EmbeddingModel
+	if model == "" {
+		model = defaultEmbeddingModel
+	}
+
+	base := p.cfg.BaseURL
+	if base == "" {
+		base = "https://api.openai.com"
+	}
+
+	headers := http.Header{}
+	if p.cfg.APIKey != "" {
+		headers.Set("Authorization", "Bearer "+p.cfg.APIKey)
+	}
+
+	resp, err := p.cli.DoJSON(ctx, http.MethodPost, normalizeBase(base)+"/v1/embeddings", headers, embeddingReq{Model: model, Input: input}, nil)
+	if err != nil {
+		// report cancellation/deadline as such rather than as a transport error
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("embedding request failed: %w", err)
+	}
+	// retries exhausted on server errors come back without a response
+	if resp == nil {
+		return nil, fmt.Errorf("embedding request failed: no response")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		b, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("embedding request -> %d: %s", resp.StatusCode, string(b))
+	}
+
+	var out embeddingResp
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		return nil, fmt.Errorf("decode embedding response: %w", err)
+	}
+
+	vectors := make([][]float64, len(out.Data))
+	for _, d := range out.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,182 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+)
+
+func TestProvider_Embedding(t *testing.T) {
+	cases := []struct {
+		name    string
+		timeout time.Duration
+		// cancelMidway cancels the caller context once the server has the request
+		cancelMidway bool
+		// hang keeps the server from answering until the request is abandoned
+		hang    bool
+		wantErr error
+		want    []float64
+	}{
+		{name: "returns the vector", want: []float64{0.1, 0.2, 0.3}},
+		{name: "cancel mid-embedding", hang: true, cancelMidway: true, wantErr: context.Canceled},
+		{name: "embedding timeout", hang: true, timeout: 50 * time.Millisecond, wantErr: context.DeadlineExceeded},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			received := make(chan struct{})
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body embeddingReq
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				assert.Equal(t, "/v1/embeddings", r.URL.Path)
+				assert.Equal(t, defaultEmbeddingModel, body.Model)
+				close(received)
+
+				if c.hang {
+					select {
+					case <-r.Context().Done():
+					case <-time.After(5 * time.Second):
+					}
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(map[string]any{
+					"data": []map[string]any{{"index": 0, "embedding": c.want}},
+				})
+			}))
+			defer srv.Close()
+
+			cfg := config.LLMConfig{
+				BaseURL: srv.URL,
+				OpenAI:  config.OpenAIConfig{EmbeddingTimeout: c.timeout},
+			}
+			cli := pkgopenai.NewClient(pkgopenai.NewHTTPClient(), cfg.OpenAI)
+			provider := NewProvider(cli, cfg, bag.NewSharedBag())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			if c.cancelMidway {
+				go func() {
+					<-received
+					cancel()
+				}()
+			}
+
+			start := time.Now()
+			got, err := provider.Embedding(ctx, "portfolio concentration risk")
+			if c.wantErr != nil {
+				require.ErrorIs(t, err, c.wantErr)
+				assert.Less(t, time.Since(start), 2*time.Second, "embedding should return promptly")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestChunkEmbeddingInputs(t *testing.T) {
+	cases := []struct {
+		name      string
+		inputs    []string
+		maxInputs int
+		maxTokens int
+		want      []int // chunk sizes
+	}{
+		{name: "fits in one request", inputs: []string{"a", "b", "c"}, maxInputs: 10, maxTokens: 100, want: []int{3}},
+		{name: "array size limit", inputs: []string{"a", "b", "c", "d", "e"}, maxInputs: 2, maxTokens: 100, want: []int{2, 2, 1}},
+		// "abcdefgh" estimates to 3 tokens
+		{name: "token limit", inputs: []string{"abcdefgh", "abcdefgh", "abcdefgh"}, maxInputs: 10, maxTokens: 6, want: []int{2, 1}},
+		{name: "oversized input gets its own chunk", inputs: []string{"a", strings.Repeat("x", 100), "b"}, maxInputs: 10, maxTokens: 5, want: []int{1, 1, 1}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			chunks := chunkEmbeddingInputs(c.inputs, c.maxInputs, c.maxTokens)
+			sizes := make([]int, 0, len(chunks))
+			next := 0
+			for _, ch := range chunks {
+				assert.Equal(t, next, ch.start, "chunks must be contiguous")
+				next += len(ch.inputs)
+				sizes = append(sizes, len(ch.inputs))
+			}
+			assert.Equal(t, c.want, sizes)
+			assert.Equal(t, len(c.inputs), next)
+		})
+	}
+}
+
+func TestProvider_EmbedMany(t *testing.T) {
+	const total = 2*maxEmbeddingInputsPerRequest + 500
+
+	var (
+		mu         sync.Mutex
+		chunkSizes []int
+		failedOnce bool
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input []string `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		mu.Lock()
+		// the second chunk fails once and must be retried
+		if body.Input[0] == fmt.Sprintf("item-%d", maxEmbeddingInputsPerRequest) && !failedOnce {
+			failedOnce = true
+			mu.Unlock()
+			http.Error(w, `{"error":{"message":"overloaded"}}`, http.StatusServiceUnavailable)
+			return
+		}
+		chunkSizes = append(chunkSizes, len(body.Input))
+		mu.Unlock()
+
+		// answer out of order; the index field carries the position
+		data := make([]map[string]any, 0, len(body.Input))
+		for i := len(body.Input) - 1; i >= 0; i-- {
+			n, err := strconv.Atoi(strings.TrimPrefix(body.Input[i], "item-"))
+			require.NoError(t, err)
+			data = append(data, map[string]any{"index": i, "embedding": []float64{float64(n)}})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{BaseURL: srv.URL}
+	cfg.OpenAI.Retry = models.RetryConfig{MaxRetries: 2, BaseDelay: time.Millisecond, MaxDelay: time.Millisecond, ExponentialBase: 2, RunBudget: 1}
+	cli := pkgopenai.NewClient(pkgopenai.NewHTTPClient(), cfg.OpenAI)
+	provider := NewProvider(cli, cfg, bag.NewSharedBag())
+
+	inputs := make([]string, total)
+	for i := range inputs {
+		inputs[i] = fmt.Sprintf("item-%d", i)
+	}
+
+	got, err := provider.EmbedMany(context.Background(), inputs)
+	require.NoError(t, err)
+	require.Len(t, got, total)
+	for i, v := range got {
+		require.Equal(t, []float64{float64(i)}, v, "vector %d out of order", i)
+	}
+
+	assert.True(t, failedOnce)
+	assert.ElementsMatch(t, []int{maxEmbeddingInputsPerRequest, maxEmbeddingInputsPerRequest, 500}, chunkSizes)
+	// the chunk retry is drawn from the run's budget
+	assert.Equal(t, 1, cli.RetryBudget().Used())
+}
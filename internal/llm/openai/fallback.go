@@ -0,0 +1,114 @@
+// internal/llm/openai/fallback.go
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	llmutils "github.com/amaurybrisou/mosychlos/internal/llm/llm_utils"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+	"github.com/openai/openai-go/v2/responses"
+)
+
+// APIError is a non-2xx answer of the OpenAI API
+type APIError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s -> %d: %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// isModelUnavailable reports whether another model may succeed where this one
+// failed: rate limits, server errors and unknown or inaccessible models. Bad
+// requests and cancellations fail the same way whatever the model.
+func isModelUnavailable(err error) bool {
+	if errors.Is(err, pkgopenai.ErrRateLimited) || errors.Is(err, pkgopenai.ErrServerError) {
+		return true
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusNotFound,
+		apiErr.StatusCode == http.StatusTooManyRequests,
+		apiErr.StatusCode >= http.StatusInternalServerError:
+		return true
+	}
+	return strings.Contains(apiErr.Body, "model_not_found")
+}
+
+// fallbackChain returns the models to try in order: the requested one, then the
+// configured fallbacks, without duplicates
+func (r *Runner) fallbackChain(requested string) []string {
+	chain := []string{requested}
+	for _, m := range r.provider.cfg.FallbackModels {
+		if name := m.String(); name != "" && !slices.Contains(chain, name) {
+			chain = append(chain, name)
+		}
+	}
+	return chain
+}
+
+// createWithFallback creates a response with create.Model, moving down the
+// fallback chain while the model is unavailable. create.Model is set to the
+// model that served the request, so the rest of the response chain sticks to it.
+func (r *Runner) createWithFallback(ctx context.Context, create *createReq) (*responses.Response, error) {
+	chain := r.fallbackChain(create.Model)
+
+	var errs []error
+	for i, model := range chain {
+		attempt := *create
+		attempt.Model = model
+		// reasoning models reject sampling parameters, the others reasoning ones
+		if llmutils.IsReasoningModel(model) {
+			attempt.Temperature, attempt.TopP = nil, nil
+			attempt.Reasoning = reasoning(r.provider.cfg.OpenAI)
+		} else {
+			attempt.Temperature, attempt.TopP = r.provider.cfg.OpenAI.Sampling()
+			attempt.Reasoning = nil
+		}
+
+		resp, err := r.engine.Create(ctx, attempt)
+		if err == nil {
+			if i > 0 {
+				slog.Warn("Response served by fallback model",
+					"requested_model", chain[0],
+					"model", model)
+			}
+			*create = attempt
+			return resp, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", model, err))
+		if !isModelUnavailable(err) || ctx.Err() != nil {
+			break
+		}
+		if i+1 < len(chain) {
+			// switching model is a retry too
+			if !r.provider.cli.RetryBudget().Take() {
+				slog.Warn("Retry budget exhausted, not falling back",
+					"model", model,
+					"fallback_model", chain[i+1])
+				break
+			}
+			slog.Warn("Model unavailable, falling back",
+				"model", model,
+				"fallback_model", chain[i+1],
+				"error", err)
+		}
+	}
+
+	if len(errs) == 1 {
+		return nil, errors.Unwrap(errs[0])
+	}
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,159 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+)
+
+func TestRunner_RunFallsBackToNextModel(t *testing.T) {
+	const unavailable = `{"error":{"message":"The model does not exist or you do not have access to it.","code":"model_not_found"}}`
+	temperature := 0.1
+
+	cases := []struct {
+		name       string
+		status     map[string]int // per model; models not listed answer 200
+		wantModels []string       // models requested, in order
+		wantServed string
+		wantErr    bool
+	}{
+		{
+			name:       "primary unavailable, fallback serves",
+			status:     map[string]int{"gpt-5-mini": http.StatusNotFound},
+			wantModels: []string{"gpt-5-mini", "gpt-4o-mini"},
+			wantServed: "gpt-4o-mini",
+		},
+		{
+			name:       "primary and first fallback overloaded",
+			status:     map[string]int{"gpt-5-mini": http.StatusServiceUnavailable, "gpt-4o-mini": http.StatusTooManyRequests},
+			wantModels: []string{"gpt-5-mini", "gpt-4o-mini", "gpt-4o"},
+			wantServed: "gpt-4o",
+		},
+		{
+			name:       "primary serves",
+			wantModels: []string{"gpt-5-mini"},
+			wantServed: "gpt-5-mini",
+		},
+		{
+			name:       "bad request does not fall back",
+			status:     map[string]int{"gpt-5-mini": http.StatusBadRequest},
+			wantModels: []string{"gpt-5-mini"},
+			wantErr:    true,
+		},
+		{
+			name:       "every model unavailable",
+			status:     map[string]int{"gpt-5-mini": http.StatusNotFound, "gpt-4o-mini": http.StatusNotFound, "gpt-4o": http.StatusNotFound},
+			wantModels: []string{"gpt-5-mini", "gpt-4o-mini", "gpt-4o"},
+			wantErr:    true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var reqs []map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				reqs = append(reqs, body)
+
+				w.Header().Set("Content-Type", "application/json")
+				if status, ok := c.status[body["model"].(string)]; ok {
+					w.WriteHeader(status)
+					if status == http.StatusNotFound {
+						_, _ = w.Write([]byte(unavailable))
+					} else {
+						_, _ = w.Write([]byte(`{"error":{"message":"request failed"}}`))
+					}
+					return
+				}
+				_, _ = w.Write([]byte(usageResponseBody))
+			}))
+			defer srv.Close()
+
+			cfg := config.LLMConfig{
+				Model:          config.LLMModelGPT5Mini,
+				FallbackModels: []config.LLMModel{config.LLMModelGPT4oMini, config.LLMModelGPT4o},
+				BaseURL:        srv.URL,
+				OpenAI:         config.OpenAIConfig{Temperature: &temperature},
+			}
+			sharedBag := bag.NewSharedBag()
+			cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+			runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, sharedBag))
+
+			resp, err := runner.Run(context.Background(), models.PromptRequest{
+				Messages: []map[string]any{{"role": "user", "content": "hi"}},
+			})
+
+			requested := make([]string, 0, len(reqs))
+			for _, r := range reqs {
+				requested = append(requested, r["model"].(string))
+			}
+			assert.Equal(t, c.wantModels, requested)
+			// sampling parameters are dropped for reasoning models only
+			assert.NotContains(t, reqs[0], "temperature")
+			if len(reqs) > 1 {
+				assert.Equal(t, temperature, reqs[1]["temperature"])
+			}
+
+			if c.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.wantServed, resp.Model)
+
+			raw, ok := sharedBag.Get(bag.KToolComputations)
+			require.True(t, ok)
+			computations := raw.([]models.ToolComputation)
+			require.Len(t, computations, 1)
+			args := computations[0].Arguments.(map[string]any)
+			assert.Equal(t, c.wantServed, args["model"])
+			if c.wantServed != "gpt-5-mini" {
+				assert.Equal(t, "gpt-5-mini", args["requested_model"])
+			} else {
+				assert.NotContains(t, args, "requested_model")
+			}
+		})
+	}
+}
+
+func TestRunner_RunSharesRetryBudget(t *testing.T) {
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		requested = append(requested, body["model"].(string))
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{
+		Model:          config.LLMModelGPT5Mini,
+		FallbackModels: []config.LLMModel{config.LLMModelGPT4oMini, config.LLMModelGPT4o},
+		BaseURL:        srv.URL,
+		OpenAI: config.OpenAIConfig{
+			Retry: models.RetryConfig{MaxRetries: 3, ExponentialBase: 2, RunBudget: 4},
+		},
+	}
+	cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+	runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, bag.NewSharedBag()))
+
+	_, err := runner.Run(context.Background(), models.PromptRequest{
+		Messages: []map[string]any{{"role": "user", "content": "hi"}},
+	})
+	require.ErrorIs(t, err, pkgopenai.ErrServerError)
+
+	// 1 attempt + 3 retries on the primary, 1 fallback, then nothing left to retry
+	assert.Equal(t, []string{"gpt-5-mini", "gpt-5-mini", "gpt-5-mini", "gpt-5-mini", "gpt-4o-mini"}, requested)
+	assert.Equal(t, 4, cli.RetryBudget().Used())
+}
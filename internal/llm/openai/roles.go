@@ -0,0 +1,37 @@
+package openai
+
+import (
+	"maps"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+)
+
+// remapRoles rewrites the roles of msgs per roleMap for OpenAI-compatible backends
+// that reject some of them. Text content of a remapped message gets the mapping
+// prefix, and a tool message remapped to another role loses its tool_call_id.
+// msgs is returned as is when roleMap is empty.
+func remapRoles(msgs []map[string]any, roleMap map[string]config.RoleMapping) []map[string]any {
+	if len(roleMap) == 0 {
+		return msgs
+	}
+	out := make([]map[string]any, len(msgs))
+	for i, m := range msgs {
+		role, _ := m["role"].(string)
+		mapping, ok := roleMap[role]
+		if !ok {
+			out[i] = m
+			continue
+		}
+
+		remapped := maps.Clone(m)
+		remapped["role"] = mapping.Role
+		if content, ok := m["content"].(string); ok {
+			remapped["content"] = mapping.Prefix + content
+		}
+		if mapping.Role != "tool" {
+			delete(remapped, "tool_call_id")
+		}
+		out[i] = remapped
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+)
+
+func TestRemapRoles(t *testing.T) {
+	msgs := []map[string]any{
+		{"role": "system", "content": "You are an analyst."},
+		{"role": "user", "content": "Price of VOO?"},
+		{"role": "tool", "tool_call_id": "call_1", "content": `{"price":500}`},
+		{"role": "user", "content": []any{map[string]any{"type": "input_text", "text": "and now?"}}},
+	}
+
+	cases := []struct {
+		name    string
+		roleMap map[string]config.RoleMapping
+		want    []map[string]any
+	}{
+		{
+			name: "no mapping",
+			want: msgs,
+		},
+		{
+			name: "system and tool sent as user",
+			roleMap: map[string]config.RoleMapping{
+				"system": {Role: "user", Prefix: "Instructions: "},
+				"tool":   {Role: "user", Prefix: "Tool result: "},
+			},
+			want: []map[string]any{
+				{"role": "user", "content": "Instructions: You are an analyst."},
+				{"role": "user", "content": "Price of VOO?"},
+				{"role": "user", "content": `Tool result: {"price":500}`},
+				{"role": "user", "content": []any{map[string]any{"type": "input_text", "text": "and now?"}}},
+			},
+		},
+		{
+			name:    "non-text content keeps its parts",
+			roleMap: map[string]config.RoleMapping{"user": {Role: "developer", Prefix: "Q: "}},
+			want: []map[string]any{
+				{"role": "system", "content": "You are an analyst."},
+				{"role": "developer", "content": "Q: Price of VOO?"},
+				{"role": "tool", "tool_call_id": "call_1", "content": `{"price":500}`},
+				{"role": "developer", "content": []any{map[string]any{"type": "input_text", "text": "and now?"}}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, remapRoles(msgs, c.roleMap))
+		})
+	}
+
+	// the caller's messages are not modified
+	assert.Equal(t, "system", msgs[0]["role"])
+	assert.Equal(t, "call_1", msgs[2]["tool_call_id"])
+}
+
+func TestStrategies_RemapRolesForCustomBackend(t *testing.T) {
+	roleMap := map[string]config.RoleMapping{"system": {Role: "user", Prefix: "Instructions: "}}
+	req := models.PromptRequest{Messages: []map[string]any{
+		{"role": "system", "content": "Be brief."},
+		{"role": "user", "content": "hi"},
+	}}
+	remapped := []any{
+		map[string]any{"role": "user", "content": "Instructions: Be brief."},
+		map[string]any{"role": "user", "content": "hi"},
+	}
+
+	cases := []struct {
+		name    string
+		baseURL bool
+		want    []any
+	}{
+		{name: "official endpoint ignores the map", want: []any{
+			map[string]any{"role": "system", "content": "Be brief."},
+			map[string]any{"role": "user", "content": "hi"},
+		}},
+		{name: "custom backend remaps", baseURL: true, want: remapped},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := config.LLMConfig{Model: config.LLMModelGPT4o, RoleMap: roleMap}
+			if c.baseURL {
+				cfg.BaseURL = "http://localhost:11434"
+			}
+			cli := pkgopenai.NewClient(http.DefaultClient, cfg.OpenAI)
+			runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, nil))
+
+			raw, err := json.Marshal(runner.buildCreateRequest(req))
+			require.NoError(t, err)
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(raw, &body))
+			assert.Equal(t, c.want, body["input"])
+		})
+	}
+
+	t.Run("chat completions", func(t *testing.T) {
+		var body map[string]any
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+		}))
+		defer srv.Close()
+
+		cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL, RoleMap: roleMap}
+		_, err := NewChatStrategy(pkgopenai.NewClient(srv.Client(), cfg.OpenAI), cfg, nil).Ask(context.Background(), req)
+		require.NoError(t, err)
+		assert.Equal(t, remapped, body["messages"])
+	})
+}
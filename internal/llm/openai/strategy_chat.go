@@ -2,30 +2,53 @@
 package openai
 
 import (
+	"cmp"
 	"context"
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	llmutils "github.com/amaurybrisou/mosychlos/internal/llm/llm_utils"
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
 	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
 )
 
+// ErrChatToolsUnsupported is returned when a request with function tools is sent
+// through the chat completions session, which does not run tool calls
+var ErrChatToolsUnsupported = errors.New("function tools are not supported by the chat_completions session API, use responses")
+
 // ChatStrategy calls the legacy Chat Completions REST endpoint via pkg/openai.Client.
 type ChatStrategy struct {
 	cli     *pkgopenai.Client
 	baseURL string
 	model   string
+	apiKey  string
+	roleMap map[string]config.RoleMapping
+	openai  config.OpenAIConfig
+
+	// sharedBag receives the transcript of the conversation
+	sharedBag bag.SharedBag
 
 	toolRegistry map[bag.Key]models.Tool
 	consumer     models.ToolConsumer
 }
 
-func NewChatStrategy(cli *pkgopenai.Client, baseURL, model string) *ChatStrategy {
+func NewChatStrategy(cli *pkgopenai.Client, cfg config.LLMConfig, sharedBag bag.SharedBag) *ChatStrategy {
+	base := cfg.BaseURL
+	if base == "" {
+		base = "https://api.openai.com"
+	}
 	return &ChatStrategy{
 		cli:          cli,
-		baseURL:      baseURL,
-		model:        model,
+		baseURL:      normalizeBase(base),
+		model:        cfg.Model.String(),
+		apiKey:       cfg.APIKey,
+		roleMap:      cfg.RoleMapping(),
+		openai:       cfg.OpenAI,
+		sharedBag:    sharedBag,
 		toolRegistry: make(map[bag.Key]models.Tool),
 	}
 }
@@ -37,7 +60,10 @@ type chatReq struct {
 	Messages    []map[string]any `json:"messages"`
 	MaxTokens   *int             `json:"max_tokens,omitempty"`
 	Temperature *float64         `json:"temperature,omitempty"`
-	// You can add: TopP, PresencePenalty, FrequencyPenalty, Stop, etc.
+	TopP        *float64         `json:"top_p,omitempty"`
+	// ResponseFormat is the structured output format, see chatResponseFormat
+	ResponseFormat map[string]any `json:"response_format,omitempty"`
+	// You can add: PresencePenalty, FrequencyPenalty, Stop, etc.
 }
 
 type chatResp struct {
@@ -48,6 +74,7 @@ type chatResp struct {
 		Message struct {
 			Role    string `json:"role"`
 			Content string `json:"content"`
+			Refusal string `json:"refusal,omitempty"`
 		} `json:"message"`
 		FinishReason string `json:"finish_reason"`
 	} `json:"choices"`
@@ -58,32 +85,67 @@ func (s *ChatStrategy) RegisterTool(t models.Tool)            { s.toolRegistry[t
 func (s *ChatStrategy) SetToolConsumer(c models.ToolConsumer) { s.consumer = c }
 
 func (s *ChatStrategy) Ask(ctx context.Context, req models.PromptRequest) (*models.LLMResponse, error) {
+	// dropping the tools would answer without the data they fetch
+	if len(req.Tools) > 0 {
+		return nil, ErrChatToolsUnsupported
+	}
 	// messages in PromptRequest are already []map[string]any with {role, content}
 	body := chatReq{
 		Model:    firstNonEmpty(req.Model, s.model),
-		Messages: filterChatSupported(req.Messages), // drop unsupported roles like "tool"
+		Messages: filterChatSupported(remapRoles(req.Messages, s.roleMap)), // drop unsupported roles like "tool"
 	}
 	if req.MaxTokens > 0 {
 		body.MaxTokens = &req.MaxTokens
 	}
+	// reasoning models reject sampling parameters
+	if !llmutils.IsReasoningModel(body.Model) {
+		body.Temperature, body.TopP = s.openai.Sampling()
+	}
+	// a per-request temperature replaces the configured sampling
 	if req.Temperature != nil {
-		body.Temperature = req.Temperature
+		body.Temperature, body.TopP = req.Temperature, nil
+	}
+	body.ResponseFormat = chatResponseFormat(req.ResponseFormat)
+
+	headers := http.Header{}
+	if s.apiKey != "" {
+		headers.Set("Authorization", "Bearer "+s.apiKey)
 	}
 
+	appendTranscript(s.sharedBag, requestTranscript(body.Messages, time.Now())...)
+
 	var out chatResp
-	// Headers: your pkg/openai middleware already injects Authorization, etc.
-	_, err := s.cli.DoJSON(ctx, http.MethodPost, s.baseURL+"/v1/chat/completions", nil, body, &out)
+	_, err := s.cli.DoJSON(ctx, http.MethodPost, s.baseURL+"/v1/chat/completions", headers, body, &out)
 	if err != nil {
 		return nil, err
 	}
-	content := ""
+	content, refusal, finishReason := "", "", ""
 	if len(out.Choices) > 0 {
 		content = out.Choices[0].Message.Content
+		refusal = out.Choices[0].Message.Refusal
+		finishReason = out.Choices[0].FinishReason
+	}
+	if refusal == "" && finishReason == models.FinishReasonContentFilter {
+		refusal = contentFilterRefusal
+	}
+	appendTranscript(s.sharedBag, models.TranscriptMessage{
+		Role:      models.RoleAssistant,
+		Content:   cmp.Or(content, refusal),
+		Model:     body.Model,
+		CreatedAt: time.Now(),
+	})
+	if refusal != "" {
+		return nil, &models.RefusalError{Model: body.Model, Message: refusal}
+	}
+	// chat completions has no response chain to continue from, so surface it
+	if finishReason == models.FinishReasonLength {
+		return nil, fmt.Errorf("%w: chat completion for model %s", models.ErrResponseTruncated, body.Model)
 	}
 	return &models.LLMResponse{
-		Model:   body.Model,
-		Content: content,
-		Usage:   out.Usage,
+		Model:        body.Model,
+		Content:      content,
+		Usage:        out.Usage,
+		FinishReason: finishReason,
 	}, nil
 }
 
@@ -92,6 +154,25 @@ func (s *ChatStrategy) AskStream(ctx context.Context, _ models.PromptRequest) (<
 	return nil, errors.New("streaming not supported for OpenAI chat strategy")
 }
 
+// chatResponseFormat maps a response format to the chat completions
+// response_format: json_schema formats carry their name and schema, other
+// types (json_object, text) only their type
+func chatResponseFormat(rf *models.ResponseFormat) map[string]any {
+	if rf == nil || rf.Format.Type == "" {
+		return nil
+	}
+	if rf.Format.Type != bag.ResponseFormatJSON {
+		return map[string]any{"type": string(rf.Format.Type)}
+	}
+	return map[string]any{
+		"type": string(bag.ResponseFormatJSON),
+		"json_schema": map[string]any{
+			"name":   rf.Format.Name,
+			"schema": rf.Format.Schema,
+		},
+	}
+}
+
 // filterChatSupported removes roles not supported by /v1/chat/completions
 func filterChatSupported(ms []map[string]any) []map[string]any {
 	out := make([]map[string]any, 0, len(ms))
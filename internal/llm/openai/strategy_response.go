@@ -53,28 +53,10 @@ func (s *ResponsesStrategy) Ask(ctx context.Context, req models.PromptRequest) (
 		funcToolDefs = append(funcToolDefs, t.Definition())
 	}
 
-	// optional built-in web_search_preview tool
+	// optional built-in web_search tool
 	var extraTools []any
 	if s.cfg != nil && s.cfg.OpenAI.WebSearch {
-		ws := map[string]any{"type": bag.WebSearch}
-		// Context size hint if present: "low" | "medium" | "high"
-		if sz := s.cfg.OpenAI.WebSearchContextSize; sz != "" {
-			ws[bag.WebSearch.String()] = map[string]any{"context_size": sz}
-		}
-
-		if s.cfg.OpenAI.WebSearchUserLocation.Country != nil {
-			ws["country"] = *s.cfg.OpenAI.WebSearchUserLocation.Country
-		}
-		if s.cfg.OpenAI.WebSearchUserLocation.City != nil {
-			ws["city"] = *s.cfg.OpenAI.WebSearchUserLocation.City
-		}
-		if s.cfg.OpenAI.WebSearchUserLocation.Region != nil {
-			ws["region"] = *s.cfg.OpenAI.WebSearchUserLocation.Region
-		}
-		if s.cfg.OpenAI.WebSearchUserLocation.Timezone != nil {
-			ws["timezone"] = *s.cfg.OpenAI.WebSearchUserLocation.Timezone
-		}
-		extraTools = append(extraTools, ws)
+		extraTools = append(extraTools, hostedWebSearchTool(s.cfg.OpenAI))
 	}
 
 	turn, err := toolsruntime.RunConversation(
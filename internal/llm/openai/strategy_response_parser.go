@@ -6,7 +6,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
@@ -20,14 +22,21 @@ func min(a, b int) int {
 	return b
 }
 
+// contentFilterRefusal is the refusal message of an answer blocked by the content filter
+const contentFilterRefusal = "response blocked by the content filter"
+
 // Scan a Responses API response and extract:
 // - main output text (first output_text item)
 // - tool calls (function_call/custom_tool_call)
+// - reasoning summaries (reasoning models only), kept apart from the content
+// - refusals, kept apart from the content
 // - usage (tokens)
 func processResponsesAPIResult(resp *responses.Response, start time.Time) (*models.AssistantTurn, error) {
 	content := map[string]any{}
 	var (
 		toolCalls []models.ToolCall
+		reasoning []string
+		refusals  []string
 	)
 
 	slog.Debug("Processing OpenAI response",
@@ -51,7 +60,10 @@ func processResponsesAPIResult(resp *responses.Response, start time.Time) (*mode
 					}
 
 				case "refusal":
-					content[key("refusal", i, j)] = c.Refusal
+					// kept out of the content, which callers may unmarshal as structured output
+					if c.Refusal != "" {
+						refusals = append(refusals, c.Refusal)
+					}
 
 				default:
 					raw := c.RawJSON()
@@ -89,9 +101,26 @@ func processResponsesAPIResult(resp *responses.Response, start time.Time) (*mode
 				},
 			})
 
+		case "web_search_call":
+			// hosted tool: OpenAI already ran the search, results come back as url_citation annotations
+			slog.Debug("Hosted web_search call completed", "response_id", resp.ID, "item_id", item.ID)
+
 		case "reasoning":
-			// informational only; do not gate logic on this presence
-			slog.Debug("Reasoning item present", "response_id", resp.ID)
+			// kept for the system report; do not gate logic on this presence
+			r := item.AsReasoning()
+			for _, s := range r.Summary {
+				if s.Text != "" {
+					reasoning = append(reasoning, s.Text)
+				}
+			}
+			for _, c := range r.Content {
+				if c.Text != "" {
+					reasoning = append(reasoning, c.Text)
+				}
+			}
+			slog.Debug("Reasoning item present",
+				"response_id", resp.ID,
+				"summary_parts", len(r.Summary))
 
 		default:
 			content[key(item.Type, i, -1)] = json.RawMessage(item.RawJSON())
@@ -114,13 +143,76 @@ func processResponsesAPIResult(resp *responses.Response, start time.Time) (*mode
 		return nil, fmt.Errorf("marshal content: %w", err)
 	}
 
+	reason := finishReason(resp, len(toolCalls) > 0)
+	refusal := strings.Join(refusals, "\n")
+	if refusal == "" && reason == models.FinishReasonContentFilter {
+		refusal = contentFilterRefusal
+	}
+
 	return &models.AssistantTurn{
-		Content:   string(buf),
-		ToolCalls: toolCalls,
-		Usage:     usage,
+		Content:      string(buf),
+		Reasoning:    strings.Join(reasoning, "\n\n"),
+		Refusal:      refusal,
+		ToolCalls:    toolCalls,
+		Usage:        usage,
+		FinishReason: reason,
 	}, nil
 }
 
+// finishReason maps the Responses status onto the Chat Completions finish_reason vocabulary
+func finishReason(resp *responses.Response, hasToolCalls bool) string {
+	if resp.Status == responses.ResponseStatusIncomplete {
+		switch resp.IncompleteDetails.Reason {
+		case "max_output_tokens":
+			return models.FinishReasonLength
+		case "content_filter":
+			return models.FinishReasonContentFilter
+		}
+	}
+	if hasToolCalls {
+		return models.FinishReasonToolCalls
+	}
+	return models.FinishReasonStop
+}
+
+// collectURLAnnotations joins every output_text of the response and gathers its
+// url_citation annotations, shifting their offsets onto the joined text.
+func collectURLAnnotations(resp *responses.Response) (string, []URLAnnotation) {
+	var (
+		text        strings.Builder
+		annotations []URLAnnotation
+	)
+	for _, item := range resp.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, c := range item.AsMessage().Content {
+			if c.Type != "output_text" {
+				continue
+			}
+			if text.Len() > 0 {
+				text.WriteString("\n")
+			}
+			// annotation indices count characters, not bytes
+			offset := utf8.RuneCountInString(text.String())
+			ot := c.AsOutputText()
+			text.WriteString(ot.Text)
+			for _, a := range ot.Annotations {
+				if a.Type != "url_citation" {
+					continue
+				}
+				annotations = append(annotations, URLAnnotation{
+					URL:        a.URL,
+					Title:      a.Title,
+					StartIndex: offset + int(a.StartIndex),
+					EndIndex:   offset + int(a.EndIndex),
+				})
+			}
+		}
+	}
+	return text.String(), annotations
+}
+
 func key(kind string, i, j int) string {
 	if j >= 0 {
 		return fmt.Sprintf("%s_%d_%d", kind, i, j)
@@ -4,10 +4,12 @@ package openai
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
@@ -43,8 +45,7 @@ func NewProvider(cli *pkgopenai.Client, cfg config.LLMConfig, sharedBag bag.Shar
 	}
 }
 
-func (p *Provider) Name() string                                                  { return p.name }
-func (p *Provider) Embedding(ctx context.Context, text string) ([]float64, error) { return nil, nil }
+func (p *Provider) Name() string { return p.name }
 func (p *Provider) RegisterTool(t ...models.Tool) {
 	for _, tool := range t {
 		p.toolByKey[tool.Key()] = tool
@@ -86,16 +87,40 @@ func (e *Engine) build(path string) string {
 // ----- request bodies (explicit JSON; independent from SDK helpers) -----
 
 type createReq struct {
-	Model             string   `json:"model"`
-	Input             any      `json:"input,omitempty"` // string or []messages
-	Tools             []any    `json:"tools,omitempty"`
-	MaxOutputTokens   int64    `json:"max_output_tokens,omitempty"`
-	Temperature       *float64 `json:"temperature,omitempty"`
-	ServiceTier       string   `json:"service_tier,omitempty"`
-	ParallelToolCalls *bool    `json:"parallel_tool_calls,omitempty"`
-	ResponseFormat    any      `json:"text,omitempty"`
-	Metadata          any      `json:"metadata,omitempty"`
-	Store             bool     `json:"store,omitempty"`
+	Model             string           `json:"model"`
+	Input             any              `json:"input,omitempty"` // string or []messages
+	Tools             []any            `json:"tools,omitempty"`
+	MaxOutputTokens   int64            `json:"max_output_tokens,omitempty"`
+	Temperature       *float64         `json:"temperature,omitempty"`
+	TopP              *float64         `json:"top_p,omitempty"`
+	Reasoning         *reasoningParams `json:"reasoning,omitempty"`
+	ServiceTier       string           `json:"service_tier,omitempty"`
+	ParallelToolCalls *bool            `json:"parallel_tool_calls,omitempty"`
+	ResponseFormat    any              `json:"text,omitempty"`
+	Metadata          any              `json:"metadata,omitempty"`
+	Store             bool             `json:"store,omitempty"`
+}
+
+// reasoningParams configures reasoning models; a summary must be asked for to
+// get one back
+type reasoningParams struct {
+	Effort  string `json:"effort,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+// reasoning returns the reasoning parameters of the configuration, nil when none is set
+func reasoning(cfg config.OpenAIConfig) *reasoningParams {
+	var p reasoningParams
+	if cfg.ReasoningEffort != nil {
+		p.Effort = *cfg.ReasoningEffort
+	}
+	if cfg.ReasoningSummary != nil {
+		p.Summary = *cfg.ReasoningSummary
+	}
+	if p == (reasoningParams{}) {
+		return nil
+	}
+	return &p
 }
 
 // function_call_output item for continuation
@@ -106,9 +131,10 @@ type funcCallOutputItem struct {
 }
 
 type continueReq struct {
-	Model              string               `json:"model"`
-	PreviousResponseID string               `json:"previous_response_id"`
-	Input              []funcCallOutputItem `json:"input"`
+	Model              string `json:"model"`
+	PreviousResponseID string `json:"previous_response_id"`
+	Input              any    `json:"input"` // []funcCallOutputItem or []messages
+	MaxOutputTokens    int64  `json:"max_output_tokens,omitempty"`
 }
 
 func (e *Engine) doJSON(ctx context.Context, method, url string, body any) (*responses.Response, error) {
@@ -141,7 +167,7 @@ func (e *Engine) doJSON(ctx context.Context, method, url string, body any) (*res
 
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		b, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("%s %s -> %d: %s", method, url, resp.StatusCode, string(b))
+		return nil, &APIError{Method: method, URL: url, StatusCode: resp.StatusCode, Body: string(b)}
 	}
 
 	var out responses.Response
@@ -169,6 +195,21 @@ func (e *Engine) Continue(ctx context.Context, model string, previousResponseID
 	return e.doJSON(ctx, http.MethodPost, u, body)
 }
 
+// ContinueText appends a user message to the response chain, e.g. to ask the model to
+// pick up a truncated answer where it stopped
+func (e *Engine) ContinueText(ctx context.Context, model, previousResponseID, text string, maxOutputTokens int64) (*responses.Response, error) {
+	if previousResponseID == "" {
+		return nil, fmt.Errorf("previousResponseID is required")
+	}
+	body := continueReq{
+		Model:              model,
+		PreviousResponseID: url.PathEscape(previousResponseID),
+		Input:              []map[string]any{{"role": "user", "content": text}},
+		MaxOutputTokens:    maxOutputTokens,
+	}
+	return e.doJSON(ctx, http.MethodPost, e.build("responses"), body)
+}
+
 // ------------------------------ Runner (SDK-like loop) ------------------------------
 //
 // Mirrors the Python Agents SDK run loop using the Responses API chaining:
@@ -177,55 +218,96 @@ func (e *Engine) Continue(ctx context.Context, model string, previousResponseID
 // 3) Repeat until no tool calls remain, return final text.
 
 type Runner struct {
-	engine   *Engine
-	provider *Provider
+	engine    *Engine
+	provider  *Provider
+	citations *CitationProcessor
 }
 
 func NewRunner(engine *Engine, provider *Provider) *Runner {
-	return &Runner{engine: engine, provider: provider}
+	return &Runner{
+		engine:    engine,
+		provider:  provider,
+		citations: NewCitationProcessor(provider.sharedBag),
+	}
 }
 
 func (r *Runner) RegisterTool(t ...models.Tool)          { r.provider.RegisterTool(t...) }
 func (r *Runner) SetToolConsumer(tc models.ToolConsumer) { r.provider.SetToolConsumer(tc) }
 
-func (r *Runner) Run(ctx context.Context, req models.PromptRequest) (*models.LLMResponse, error) {
-	// Build create request
-	isReasoning := llmutils.IsReasoningModel(r.provider.cfg.Model.String())
+func (r *Runner) Name() string { return r.provider.Name() }
+
+// Ask runs the Responses API loop; it makes Runner usable as an interactive strategy.
+func (r *Runner) Ask(ctx context.Context, req models.PromptRequest) (*models.LLMResponse, error) {
+	return r.Run(ctx, req)
+}
+
+// AskStream is not supported yet on the Responses runner.
+func (r *Runner) AskStream(ctx context.Context, _ models.PromptRequest) (<-chan models.StreamChunk, error) {
+	return nil, fmt.Errorf("streaming not supported by %s", r.Name())
+}
+
+// buildCreateRequest maps our prompt request (messages, tools, structured output) and
+// the configured knobs onto a Responses API create request.
+func (r *Runner) buildCreateRequest(req models.PromptRequest) createReq {
+	cfg := r.provider.cfg
 	create := createReq{
 		Model: req.Model,
-		Input: req.Messages, // your code already formats Responses "messages" style
+		Input: remapRoles(req.Messages, cfg.RoleMapping()), // your code already formats Responses "messages" style
 	}
 	if create.Model == "" {
-		create.Model = r.provider.cfg.Model.String()
+		create.Model = cfg.Model.String()
 	}
+	isReasoning := llmutils.IsReasoningModel(create.Model)
 	// knobs
-	if max := r.provider.cfg.OpenAI.MaxCompletionTokens; max > 0 {
+	if max := cfg.OpenAI.MaxCompletionTokens; max > 0 {
 		create.MaxOutputTokens = max
 	}
-	if r.provider.cfg.OpenAI.Temperature != nil && !isReasoning {
-		create.Temperature = r.provider.cfg.OpenAI.Temperature
+	if isReasoning {
+		create.Reasoning = reasoning(cfg.OpenAI)
+	} else {
+		create.Temperature, create.TopP = cfg.OpenAI.Sampling()
 	}
-	if r.provider.cfg.OpenAI.ServiceTier != nil && *r.provider.cfg.OpenAI.ServiceTier != "auto" {
-		create.ServiceTier = *r.provider.cfg.OpenAI.ServiceTier
+	if cfg.OpenAI.ServiceTier != nil && *cfg.OpenAI.ServiceTier != "auto" {
+		create.ServiceTier = *cfg.OpenAI.ServiceTier
 	}
-	if r.provider.cfg.OpenAI.ParallelToolCalls {
+	if cfg.OpenAI.ParallelToolCalls {
 		t := true
 		create.ParallelToolCalls = &t
 	}
 	if req.ResponseFormat != nil {
 		create.ResponseFormat = req.ResponseFormat
 	}
-	if req.Tools != nil && len(req.Tools) > 0 {
+	if len(req.Tools) > 0 {
 		create.Tools = toAnyTools(req.Tools)
 	}
+	// hosted tools are executed by OpenAI, not by our tool layer
+	if cfg.OpenAI.WebSearch {
+		create.Tools = append(create.Tools, hostedWebSearchTool(cfg.OpenAI))
+	}
+	return create
+}
+
+func (r *Runner) Run(ctx context.Context, req models.PromptRequest) (*models.LLMResponse, error) {
+	create := r.buildCreateRequest(req)
+	requested := create.Model
 
 	start := time.Now()
+	r.provider.recordTranscript(requestTranscript(req.Messages, start)...)
 
 	var (
-		last     *responses.Response
-		err      error
-		turns    int
-		maxTurns = 32
+		last      *responses.Response
+		err       error
+		turns     int
+		maxTurns  = 32
+		callStart = start
+
+		// truncation recovery
+		stitched      strings.Builder
+		continuations int
+		capRetries    int
+
+		// reasoning summaries of every turn
+		reasoning []string
 	)
 	for {
 		turns++
@@ -234,7 +316,7 @@ func (r *Runner) Run(ctx context.Context, req models.PromptRequest) (*models.LLM
 		}
 
 		if last == nil {
-			last, err = r.engine.Create(ctx, create)
+			last, err = r.createWithFallback(ctx, &create)
 			if err != nil {
 				return nil, err
 			}
@@ -245,14 +327,96 @@ func (r *Runner) Run(ctx context.Context, req models.PromptRequest) (*models.LLM
 		if err != nil {
 			return nil, err
 		}
+		r.provider.trackTokenUsage(callStart, requested, create.Model, turn)
+		r.provider.recordReasoning(create.Model, last.ID, turn)
+		r.provider.recordTranscript(models.TranscriptMessage{
+			Role:      models.RoleAssistant,
+			Content:   cmp.Or(outputText(last), turn.Refusal),
+			ToolCalls: turn.ToolCalls,
+			Model:     create.Model,
+			CreatedAt: time.Now(),
+		})
+		if turn.Reasoning != "" {
+			reasoning = append(reasoning, turn.Reasoning)
+		}
+
+		// a refusal is not an answer: callers must not parse it as one
+		if turn.Refusal != "" {
+			slog.Warn("Model refused to answer",
+				"model", create.Model,
+				"response_id", last.ID,
+				"refusal", preview(turn.Refusal, 200))
+			return nil, &models.RefusalError{Model: create.Model, Message: turn.Refusal}
+		}
+
+		// hosted web_search results arrive inline as url_citation annotations;
+		// store them and keep going, there is no function output to send back
+		if text, annotations := collectURLAnnotations(last); len(annotations) > 0 {
+			if _, err := r.citations.ProcessAnnotations(lastUserContent(req.Messages), text, annotations); err != nil {
+				slog.Warn("Failed to process web search citations", "response_id", last.ID, "error", err)
+			}
+		}
+
+		if turn.FinishReason == models.FinishReasonLength {
+			// structured output cannot be stitched: ask again with a larger budget
+			if create.ResponseFormat != nil {
+				raised, ok := raiseTokenCap(create.MaxOutputTokens)
+				if !ok {
+					// the same cap would truncate again, don't pay for another call
+					return nil, fmt.Errorf("%w: structured output incomplete at %s",
+						models.ErrResponseTruncated, tokenCapString(create.MaxOutputTokens))
+				}
+				if capRetries >= maxTokenCapRetries || !r.provider.cli.RetryBudget().Take() {
+					return nil, fmt.Errorf("%w: structured output still incomplete at %s",
+						models.ErrResponseTruncated, tokenCapString(create.MaxOutputTokens))
+				}
+				capRetries++
+				create.MaxOutputTokens = raised
+				slog.Warn("Structured response truncated, retrying with a higher token cap",
+					"response_id", last.ID,
+					"max_output_tokens", create.MaxOutputTokens,
+					"attempt", capRetries)
+				last, callStart = nil, time.Now()
+				continue
+			}
+
+			// free text: ask the model to carry on and stitch the parts together
+			if continuations >= maxContinuations {
+				return nil, fmt.Errorf("%w: still incomplete after %d continuations",
+					models.ErrResponseTruncated, continuations)
+			}
+			continuations++
+			stitched.WriteString(outputText(last))
+			slog.Warn("Response truncated, requesting continuation",
+				"response_id", last.ID,
+				"continuation", continuations)
+			callStart = time.Now()
+			r.provider.recordTranscript(models.TranscriptMessage{Role: models.RoleUser, Content: continuationPrompt, CreatedAt: callStart})
+			last, err = r.engine.ContinueText(ctx, create.Model, last.ID, continuationPrompt, create.MaxOutputTokens)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if stitched.Len() > 0 {
+			stitched.WriteString(outputText(last))
+			buf, err := json.Marshal(map[string]any{key("output_text", 0, 0): stitched.String()})
+			if err != nil {
+				return nil, fmt.Errorf("marshal stitched content: %w", err)
+			}
+			turn.Content = string(buf)
+		}
 
 		// If no tool calls → final
 		if len(turn.ToolCalls) == 0 {
 			return &models.LLMResponse{
-				CreatedAt: time.Now(),
-				Model:     create.Model,
-				Content:   turn.Content,
-				Usage:     &turn.Usage,
+				CreatedAt:    time.Now(),
+				Model:        create.Model,
+				Content:      turn.Content,
+				Reasoning:    strings.Join(reasoning, "\n\n"),
+				Usage:        &turn.Usage,
+				FinishReason: turn.FinishReason,
 			}, nil
 		}
 
@@ -287,9 +451,16 @@ func (r *Runner) Run(ctx context.Context, req models.PromptRequest) (*models.LLM
 				CallID: call.CallID, // IMPORTANT: use external CallID, not internal ID
 				Output: outStr,      // JSON string or plain text
 			})
+			r.provider.recordTranscript(models.TranscriptMessage{
+				Role:       models.RoleTool,
+				Content:    outStr,
+				ToolCallID: call.CallID,
+				CreatedAt:  time.Now(),
+			})
 		}
 
 		// Continue the same response chain
+		callStart = time.Now()
 		next, err := r.engine.Continue(ctx, create.Model, last.ID, items)
 		if err != nil {
 			return nil, err
@@ -299,6 +470,94 @@ func (r *Runner) Run(ctx context.Context, req models.PromptRequest) (*models.LLM
 	}
 }
 
+const (
+	// maxContinuations bounds how many times a truncated text answer is continued
+	maxContinuations = 3
+	// maxTokenCapRetries bounds how many times a truncated structured answer is retried
+	maxTokenCapRetries = 2
+	// maxOutputTokenCap is the ceiling for raised token budgets
+	maxOutputTokenCap = 32768
+
+	continuationPrompt = "Your previous answer was cut off. Continue exactly where you stopped, without repeating anything."
+)
+
+// raiseTokenCap doubles an explicit output token budget, bounded by
+// maxOutputTokenCap; ok is false when the budget cannot grow. Without an explicit
+// budget the model answered up to its own limit, which no cap would raise.
+func raiseTokenCap(current int64) (raised int64, ok bool) {
+	if current <= 0 || current >= maxOutputTokenCap {
+		return current, false
+	}
+	if current*2 > maxOutputTokenCap {
+		return maxOutputTokenCap, true
+	}
+	return current * 2, true
+}
+
+// tokenCapString describes an output token budget for errors
+func tokenCapString(tokens int64) string {
+	if tokens <= 0 {
+		return "the model's output limit"
+	}
+	return fmt.Sprintf("%d output tokens", tokens)
+}
+
+// outputText concatenates every output_text part of a response
+func outputText(resp *responses.Response) string {
+	var b strings.Builder
+	for _, item := range resp.Output {
+		if item.Type != "message" {
+			continue
+		}
+		for _, c := range item.AsMessage().Content {
+			if c.Type == "output_text" {
+				b.WriteString(c.AsOutputText().Text)
+			}
+		}
+	}
+	return b.String()
+}
+
+// lastUserContent returns the text of the most recent user message, used as the citation query.
+func lastUserContent(messages []map[string]any) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if role, _ := messages[i]["role"].(string); role != "user" {
+			continue
+		}
+		if content, ok := messages[i]["content"].(string); ok {
+			return content
+		}
+	}
+	return ""
+}
+
+// hostedWebSearchTool builds the Responses API hosted web_search tool from config.
+func hostedWebSearchTool(cfg config.OpenAIConfig) map[string]any {
+	ws := map[string]any{"type": "web_search"}
+	// context size hint if present: "low" | "medium" | "high"
+	if sz := cfg.WebSearchContextSize; sz != "" {
+		ws["search_context_size"] = sz
+	}
+
+	if loc := cfg.WebSearchUserLocation; loc != nil {
+		userLocation := map[string]any{"type": "approximate"}
+		for k, v := range map[string]*string{
+			"country":  loc.Country,
+			"city":     loc.City,
+			"region":   loc.Region,
+			"timezone": loc.Timezone,
+		} {
+			if v != nil && *v != "" {
+				userLocation[k] = *v
+			}
+		}
+		if len(userLocation) > 1 {
+			ws["user_location"] = userLocation
+		}
+	}
+	return ws
+}
+
 // toAnyTools converts your ToolDef types into the Responses API function tool schema.
 func toAnyTools(funcTools []models.ToolDef) []any {
 	out := make([]any, 0, len(funcTools))
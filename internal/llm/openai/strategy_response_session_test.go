@@ -0,0 +1,519 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+)
+
+// toolTypes returns the "type" of every tool in a create request body
+func toolTypes(tools []any) []string {
+	out := make([]string, 0, len(tools))
+	for _, t := range tools {
+		if m, ok := t.(map[string]any); ok {
+			out = append(out, m["type"].(string))
+		}
+	}
+	return out
+}
+
+func TestRunner_BuildCreateRequest(t *testing.T) {
+	country, city := "FR", "Paris"
+	temperature, topP := 0.2, 0.9
+
+	cases := []struct {
+		name      string
+		openai    config.OpenAIConfig
+		req       models.PromptRequest
+		wantTypes []string
+		validate  func(t *testing.T, body createReq)
+	}{
+		{
+			name:      "web search disabled",
+			req:       models.PromptRequest{Messages: []map[string]any{{"role": "user", "content": "hi"}}},
+			wantTypes: []string{},
+		},
+		{
+			name:      "web search enabled",
+			openai:    config.OpenAIConfig{WebSearch: true, WebSearchContextSize: "high"},
+			req:       models.PromptRequest{Messages: []map[string]any{{"role": "user", "content": "hi"}}},
+			wantTypes: []string{"web_search"},
+			validate: func(t *testing.T, body createReq) {
+				ws := body.Tools[0].(map[string]any)
+				assert.Equal(t, "high", ws["search_context_size"])
+				assert.NotContains(t, ws, "user_location")
+			},
+		},
+		{
+			name:      "only the chosen sampling param is sent",
+			openai:    config.OpenAIConfig{Temperature: &temperature, TopP: &topP, SamplingParam: config.SamplingParamTopP},
+			req:       models.PromptRequest{Messages: []map[string]any{{"role": "user", "content": "hi"}}},
+			wantTypes: []string{},
+			validate: func(t *testing.T, body createReq) {
+				assert.Nil(t, body.Temperature)
+				assert.Equal(t, &topP, body.TopP)
+			},
+		},
+		{
+			name: "web search with function tools and location",
+			openai: config.OpenAIConfig{
+				WebSearch:             true,
+				WebSearchUserLocation: &config.WebSearchUserLocationConfig{Country: &country, City: &city},
+			},
+			req: models.PromptRequest{
+				Messages: []map[string]any{{"role": "user", "content": "hi"}},
+				Tools: []models.ToolDef{&models.CustomToolDef{
+					Type:        models.CustomToolDefType,
+					FunctionDef: models.FunctionDef{Name: "fmp", Parameters: map[string]any{"type": "object"}},
+				}},
+				ResponseFormat: &models.ResponseFormat{Format: models.Format{Type: bag.ResponseFormatJSON, Name: "out"}},
+			},
+			wantTypes: []string{"function", "web_search"},
+			validate: func(t *testing.T, body createReq) {
+				ws := body.Tools[1].(map[string]any)
+				assert.Equal(t, map[string]any{"type": "approximate", "country": "FR", "city": "Paris"}, ws["user_location"])
+				assert.NotNil(t, body.ResponseFormat)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := config.LLMConfig{Model: config.LLMModelGPT4o, OpenAI: c.openai}
+			runner := NewRunner(nil, NewProvider(nil, cfg, bag.NewSharedBag()))
+
+			body := runner.buildCreateRequest(c.req)
+			assert.Equal(t, config.LLMModelGPT4o.String(), body.Model)
+			assert.Equal(t, c.wantTypes, toolTypes(body.Tools))
+			if c.validate != nil {
+				c.validate(t, body)
+			}
+		})
+	}
+}
+
+func TestRunner_RunSendsHostedWebSearch(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/responses", r.URL.Path)
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"id":"resp_1","object":"response","output":[{"type":"message","id":"msg_1","role":"assistant",` +
+			`"content":[{"type":"output_text","text":"done","annotations":[]}]}],"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2}}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{
+		Model:   config.LLMModelGPT4o,
+		APIKey:  "test",
+		BaseURL: srv.URL,
+		OpenAI:  config.OpenAIConfig{WebSearch: true},
+	}
+	cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+	runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, bag.NewSharedBag()))
+
+	resp, err := runner.Run(context.Background(), models.PromptRequest{
+		Messages: []map[string]any{{"role": "user", "content": "latest CPI print?"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "done")
+
+	tools, ok := got["tools"].([]any)
+	require.True(t, ok, "expected tools in request body: %v", got)
+	require.Len(t, tools, 1)
+	assert.Equal(t, "web_search", tools[0].(map[string]any)["type"])
+}
+
+func TestRunner_RunStoresWebSearchCitations(t *testing.T) {
+	const text = "CPI rose 0.3% in May. Core inflation eased."
+	body := `{"id":"resp_2","object":"response","output":[` +
+		`{"type":"web_search_call","id":"ws_1","status":"completed","action":{"type":"search","query":"latest CPI"}},` +
+		`{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"` + text + `","annotations":[` +
+		`{"type":"url_citation","url":"https://www.bls.gov/cpi/","title":"BLS CPI","start_index":0,"end_index":21},` +
+		`{"type":"url_citation","url":"https://example.com/core","title":"Core","start_index":22,"end_index":43},` +
+		`{"type":"url_citation","url":"https://www.bls.gov/cpi/","title":"BLS CPI","start_index":22,"end_index":43}]}]}],` +
+		`"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2}}`
+
+	calls := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL, OpenAI: config.OpenAIConfig{WebSearch: true}}
+	sharedBag := bag.NewSharedBag()
+	cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+	runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, sharedBag))
+
+	resp, err := runner.Run(context.Background(), models.PromptRequest{
+		Messages: []map[string]any{{"role": "user", "content": "latest CPI print?"}},
+	})
+	require.NoError(t, err)
+	assert.Contains(t, resp.Content, "CPI rose")
+	// the hosted call is not a function tool, so the turn ends without a continuation
+	assert.Equal(t, 1, calls)
+
+	citations, ok := GetWebSearchCitations(sharedBag)
+	require.True(t, ok)
+	require.Len(t, citations, 2)
+	assert.Equal(t, "https://www.bls.gov/cpi/", citations[0].URL)
+	assert.Equal(t, "CPI rose 0.3% in May.", citations[0].Snippet)
+	assert.Equal(t, "www.bls.gov", citations[0].Source)
+	assert.Equal(t, "latest CPI print?", citations[0].Query)
+	assert.Equal(t, "[2]", citations[1].CitationID)
+	assert.Equal(t, "Core inflation eased.", citations[1].Snippet)
+
+	results, ok := GetCitationResults(sharedBag)
+	require.True(t, ok)
+	require.Len(t, results, 1)
+	assert.Equal(t, text, results[0].Content)
+}
+
+func TestRunner_RunStoresCitationsAfterMultibyteText(t *testing.T) {
+	// annotation indices count characters: the euro sign and accents are several
+	// bytes each and must not shift the spans
+	const (
+		first  = "Les prix ont augmenté de 0,3 € en mai."
+		second = "L'inflation sous-jacente a ralenti."
+	)
+	body := `{"id":"resp_3","object":"response","output":[` +
+		`{"type":"message","id":"msg_1","role":"assistant","content":[` +
+		`{"type":"output_text","text":"` + first + `","annotations":[` +
+		`{"type":"url_citation","url":"https://www.insee.fr/prix","title":"Insee","start_index":25,"end_index":38}]},` +
+		`{"type":"output_text","text":"` + second + `","annotations":[` +
+		`{"type":"url_citation","url":"https://example.com/core","title":"Core","start_index":0,"end_index":35}]}]}],` +
+		`"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2}}`
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL, OpenAI: config.OpenAIConfig{WebSearch: true}}
+	sharedBag := bag.NewSharedBag()
+	cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+	runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, sharedBag))
+
+	_, err := runner.Run(context.Background(), models.PromptRequest{
+		Messages: []map[string]any{{"role": "user", "content": "inflation en France ?"}},
+	})
+	require.NoError(t, err)
+
+	citations, ok := GetWebSearchCitations(sharedBag)
+	require.True(t, ok)
+	require.Len(t, citations, 2)
+	assert.Equal(t, "0,3 € en mai.", citations[0].Snippet)
+	assert.Equal(t, second, citations[1].Snippet)
+}
+
+// responseBody builds a minimal Responses API payload with one output_text part
+func responseBody(id, status, text string) string {
+	incomplete := ""
+	if status == "incomplete" {
+		incomplete = `"incomplete_details":{"reason":"max_output_tokens"},`
+	}
+	return `{"id":"` + id + `","object":"response","status":"` + status + `",` + incomplete +
+		`"output":[{"type":"message","id":"msg_` + id + `","role":"assistant",` +
+		`"content":[{"type":"output_text","text":"` + text + `","annotations":[]}]}],` +
+		`"usage":{"input_tokens":1,"output_tokens":1,"total_tokens":2}}`
+}
+
+func TestRunner_RunHandlesTruncation(t *testing.T) {
+	cases := []struct {
+		name        string
+		structured  bool
+		maxTokens   int64
+		responses   []string
+		wantErr     error
+		wantContent string
+		validate    func(t *testing.T, reqs []map[string]any)
+	}{
+		{
+			name: "text is continued and stitched",
+			responses: []string{
+				responseBody("r1", "incomplete", "Equities look "),
+				responseBody("r2", "completed", "overvalued."),
+			},
+			wantContent: `{"output_text_0_0":"Equities look overvalued."}`,
+			validate: func(t *testing.T, reqs []map[string]any) {
+				require.Len(t, reqs, 2)
+				assert.Equal(t, "r1", reqs[1]["previous_response_id"])
+				input := reqs[1]["input"].([]any)[0].(map[string]any)
+				assert.Equal(t, "user", input["role"])
+				assert.Equal(t, continuationPrompt, input["content"])
+			},
+		},
+		{
+			name: "text stays truncated",
+			responses: []string{
+				responseBody("r1", "incomplete", "a"),
+				responseBody("r2", "incomplete", "b"),
+				responseBody("r3", "incomplete", "c"),
+				responseBody("r4", "incomplete", "d"),
+			},
+			wantErr: models.ErrResponseTruncated,
+			validate: func(t *testing.T, reqs []map[string]any) {
+				assert.Len(t, reqs, 1+maxContinuations)
+			},
+		},
+		{
+			name:       "structured output retried with a higher cap",
+			structured: true,
+			maxTokens:  1000,
+			responses: []string{
+				responseBody("r1", "incomplete", `{\"holdings\":[`),
+				responseBody("r2", "completed", `{\"holdings\":[]}`),
+			},
+			wantContent: `{"output_text_0_0":"{\"holdings\":[]}"}`,
+			validate: func(t *testing.T, reqs []map[string]any) {
+				require.Len(t, reqs, 2)
+				assert.NotContains(t, reqs[1], "previous_response_id")
+				assert.EqualValues(t, 2000, reqs[1]["max_output_tokens"])
+			},
+		},
+		{
+			name:       "structured output stays truncated",
+			structured: true,
+			maxTokens:  1000,
+			responses: []string{
+				responseBody("r1", "incomplete", `{`),
+				responseBody("r2", "incomplete", `{`),
+				responseBody("r3", "incomplete", `{`),
+			},
+			wantErr: models.ErrResponseTruncated,
+			validate: func(t *testing.T, reqs []map[string]any) {
+				require.Len(t, reqs, 1+maxTokenCapRetries)
+				assert.EqualValues(t, 2000, reqs[1]["max_output_tokens"])
+				assert.EqualValues(t, 4000, reqs[2]["max_output_tokens"])
+			},
+		},
+		{
+			name:       "structured output at the model's own limit is not retried",
+			structured: true,
+			responses:  []string{responseBody("r1", "incomplete", `{`)},
+			wantErr:    models.ErrResponseTruncated,
+			validate: func(t *testing.T, reqs []map[string]any) {
+				require.Len(t, reqs, 1)
+				assert.NotContains(t, reqs[0], "max_output_tokens")
+			},
+		},
+		{
+			name:       "structured output at the ceiling is not retried",
+			structured: true,
+			maxTokens:  maxOutputTokenCap,
+			responses:  []string{responseBody("r1", "incomplete", `{`)},
+			wantErr:    models.ErrResponseTruncated,
+			validate: func(t *testing.T, reqs []map[string]any) {
+				require.Len(t, reqs, 1)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var reqs []map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body map[string]any
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+				reqs = append(reqs, body)
+				require.LessOrEqual(t, len(reqs), len(c.responses), "unexpected extra request")
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(c.responses[len(reqs)-1]))
+			}))
+			defer srv.Close()
+
+			cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL}
+			cfg.OpenAI.MaxCompletionTokens = c.maxTokens
+			cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+			runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, bag.NewSharedBag()))
+
+			req := models.PromptRequest{Messages: []map[string]any{{"role": "user", "content": "analyze"}}}
+			if c.structured {
+				req.ResponseFormat = &models.ResponseFormat{Format: models.Format{Type: bag.ResponseFormatJSON, Name: "out"}}
+			}
+
+			resp, err := runner.Run(context.Background(), req)
+			if c.wantErr != nil {
+				require.ErrorIs(t, err, c.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, models.FinishReasonStop, resp.FinishReason)
+				assert.JSONEq(t, c.wantContent, resp.Content)
+			}
+			c.validate(t, reqs)
+		})
+	}
+}
+
+func TestRunner_RunRefusal(t *testing.T) {
+	cases := []struct {
+		name        string
+		body        string
+		wantMessage string
+	}{
+		{
+			name: "refusal content",
+			body: `{"id":"resp_1","object":"response","status":"completed","output":[` +
+				`{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"refusal","refusal":"I can't help with that."}]}]}`,
+			wantMessage: "I can't help with that.",
+		},
+		{
+			name:        "blocked by the content filter",
+			body:        `{"id":"resp_2","object":"response","status":"incomplete","incomplete_details":{"reason":"content_filter"},"output":[]}`,
+			wantMessage: contentFilterRefusal,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(c.body))
+			}))
+			defer srv.Close()
+
+			cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL}
+			cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+			runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, bag.NewSharedBag()))
+
+			resp, err := runner.Run(context.Background(), models.PromptRequest{
+				Messages:       []map[string]any{{"role": "user", "content": "hi"}},
+				ResponseFormat: &models.ResponseFormat{Format: models.Format{Type: bag.ResponseFormatJSON, Name: "out"}},
+			})
+			assert.Nil(t, resp)
+			require.ErrorIs(t, err, models.ErrContentRefused)
+
+			var refusal *models.RefusalError
+			require.ErrorAs(t, err, &refusal)
+			assert.Equal(t, c.wantMessage, refusal.Message)
+			assert.Equal(t, "gpt-4o", refusal.Model)
+		})
+	}
+}
+
+func TestChatStrategy_AskSampling(t *testing.T) {
+	temperature, topP, override := 0.2, 0.9, 0.7
+
+	cases := []struct {
+		name     string
+		model    config.LLMModel
+		openai   config.OpenAIConfig
+		req      models.PromptRequest
+		wantBody map[string]any
+	}{
+		{
+			name:     "only the chosen sampling param is sent",
+			model:    config.LLMModelGPT4o,
+			openai:   config.OpenAIConfig{Temperature: &temperature, TopP: &topP, SamplingParam: config.SamplingParamTopP},
+			wantBody: map[string]any{"top_p": topP},
+		},
+		{
+			name:     "request temperature overrides the config",
+			model:    config.LLMModelGPT4o,
+			openai:   config.OpenAIConfig{TopP: &topP},
+			req:      models.PromptRequest{Temperature: &override},
+			wantBody: map[string]any{"temperature": override},
+		},
+		{
+			name:     "reasoning models get no sampling",
+			model:    config.LLMModelGPT5Mini,
+			openai:   config.OpenAIConfig{Temperature: &temperature},
+			wantBody: map[string]any{},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var got map[string]any
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"ok"},"finish_reason":"stop"}]}`))
+			}))
+			defer srv.Close()
+
+			cfg := config.LLMConfig{Model: c.model, BaseURL: srv.URL, OpenAI: c.openai}
+			req := c.req
+			req.Messages = []map[string]any{{"role": "user", "content": "hi"}}
+			_, err := NewChatStrategy(pkgopenai.NewClient(srv.Client(), cfg.OpenAI), cfg, nil).Ask(context.Background(), req)
+			require.NoError(t, err)
+
+			sampling := map[string]any{}
+			for _, k := range []string{"temperature", "top_p"} {
+				if v, ok := got[k]; ok {
+					sampling[k] = v
+				}
+			}
+			assert.Equal(t, c.wantBody, sampling)
+		})
+	}
+}
+
+func TestChatStrategy_AskStructuredOutput(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"{\"score\":3}"},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL}
+	strategy := NewChatStrategy(pkgopenai.NewClient(srv.Client(), cfg.OpenAI), cfg, nil)
+	messages := []map[string]any{{"role": "user", "content": "score the risk"}}
+	schema := map[string]any{"type": "object", "properties": map[string]any{"score": map[string]any{"type": "integer"}}}
+
+	resp, err := strategy.Ask(context.Background(), models.PromptRequest{
+		Messages:       messages,
+		ResponseFormat: &models.ResponseFormat{Format: models.Format{Type: bag.ResponseFormatJSON, Name: "risk", Schema: schema}},
+	})
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"score":3}`, resp.Content)
+	assert.Equal(t, map[string]any{
+		"type":        "json_schema",
+		"json_schema": map[string]any{"name": "risk", "schema": schema},
+	}, got["response_format"])
+
+	// tools are refused rather than silently dropped
+	got = nil
+	_, err = strategy.Ask(context.Background(), models.PromptRequest{
+		Messages: messages,
+		Tools: []models.ToolDef{&models.CustomToolDef{
+			Type:        models.CustomToolDefType,
+			FunctionDef: models.FunctionDef{Name: "fmp", Parameters: map[string]any{"type": "object"}},
+		}},
+	})
+	require.ErrorIs(t, err, ErrChatToolsUnsupported)
+	assert.Nil(t, got, "nothing is sent")
+}
+
+func TestChatStrategy_AskRefusal(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":null,"refusal":"I can't help with that."},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL}
+	_, err := NewChatStrategy(pkgopenai.NewClient(srv.Client(), cfg.OpenAI), cfg, nil).Ask(context.Background(), models.PromptRequest{
+		Messages: []map[string]any{{"role": "user", "content": "hi"}},
+	})
+
+	var refusal *models.RefusalError
+	require.ErrorAs(t, err, &refusal)
+	assert.Equal(t, "I can't help with that.", refusal.Message)
+	assert.ErrorIs(t, err, models.ErrContentRefused)
+}
@@ -0,0 +1,45 @@
+// internal/llm/openai/transcript.go
+package openai
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// recordTranscript appends messages to the run transcript kept in the shared bag.
+func (p *Provider) recordTranscript(msgs ...models.TranscriptMessage) {
+	appendTranscript(p.sharedBag, msgs...)
+}
+
+// appendTranscript appends messages to the transcript of sharedBag. Messages are
+// recorded as sent; redaction happens on export.
+func appendTranscript(sharedBag bag.SharedBag, msgs ...models.TranscriptMessage) {
+	if sharedBag == nil || len(msgs) == 0 {
+		return
+	}
+	sharedBag.Update(bag.KTranscript, func(current any) any {
+		transcript, _ := current.([]models.TranscriptMessage)
+		return append(transcript, msgs...)
+	})
+}
+
+// requestTranscript converts the input messages of a request; non-text content
+// is kept as JSON
+func requestTranscript(messages []map[string]any, at time.Time) []models.TranscriptMessage {
+	out := make([]models.TranscriptMessage, 0, len(messages))
+	for _, m := range messages {
+		role, _ := m["role"].(string)
+		content, ok := m["content"].(string)
+		if !ok && m["content"] != nil {
+			raw, err := json.Marshal(m["content"])
+			if err == nil {
+				content = string(raw)
+			}
+		}
+		out = append(out, models.TranscriptMessage{Role: models.Role(role), Content: content, CreatedAt: at})
+	}
+	return out
+}
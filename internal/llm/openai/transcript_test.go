@@ -0,0 +1,110 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+)
+
+// keyTool is a registered tool known only by its key; the test never runs it
+type keyTool bag.Key
+
+func (k keyTool) Name() string                          { return string(k) }
+func (k keyTool) Key() bag.Key                          { return bag.Key(k) }
+func (k keyTool) Description() string                   { return "" }
+func (k keyTool) Definition() models.ToolDef            { return nil }
+func (k keyTool) Tags() []string                        { return nil }
+func (k keyTool) IsExternal() bool                      { return false }
+func (k keyTool) Run(context.Context, any) (any, error) { return nil, nil }
+
+func TestRunner_RunRecordsTranscript(t *testing.T) {
+	bodies := []string{
+		`{"id":"resp_1","object":"response","status":"completed","output":[` +
+			`{"type":"function_call","id":"fc_1","call_id":"call_1","name":"fmp","arguments":"{\"ticker\":\"VOO\"}","status":"completed"}]}`,
+		`{"id":"resp_2","object":"response","status":"completed","output":[` +
+			`{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"Hold VOO.","annotations":[]}]}]}`,
+	}
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(bodies[min(int(calls.Add(1))-1, len(bodies)-1)]))
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL}
+	sharedBag := bag.NewSharedBag()
+	cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+	runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, sharedBag))
+	runner.RegisterTool(keyTool("fmp"))
+
+	_, err := runner.Run(context.Background(), models.PromptRequest{
+		Messages: []map[string]any{
+			{"role": "system", "content": "You are an analyst."},
+			{"role": "user", "content": "Should I hold VOO?"},
+		},
+	})
+	require.NoError(t, err)
+
+	raw, ok := sharedBag.Get(bag.KTranscript)
+	require.True(t, ok)
+	msgs := raw.([]models.TranscriptMessage)
+	require.Len(t, msgs, 5)
+
+	roles := make([]models.Role, len(msgs))
+	for i, m := range msgs {
+		roles[i] = m.Role
+		assert.False(t, m.CreatedAt.IsZero())
+	}
+	assert.Equal(t, []models.Role{models.RoleSystem, models.RoleUser, models.RoleAssistant, models.RoleTool, models.RoleAssistant}, roles)
+
+	assert.Equal(t, "Should I hold VOO?", msgs[1].Content)
+	require.Len(t, msgs[2].ToolCalls, 1)
+	assert.Equal(t, "fmp", msgs[2].ToolCalls[0].Function.Name)
+	assert.Equal(t, `{"ticker":"VOO"}`, msgs[2].ToolCalls[0].Function.Arguments)
+	assert.Equal(t, "gpt-4o", msgs[2].Model)
+	assert.Equal(t, "call_1", msgs[3].ToolCallID)
+	assert.Equal(t, "Hold VOO.", msgs[4].Content)
+}
+
+func TestChatStrategy_AskRecordsTranscript(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"Hold VOO."},"finish_reason":"stop"}]}`))
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL}
+	sharedBag := bag.NewSharedBag()
+	_, err := NewChatStrategy(pkgopenai.NewClient(srv.Client(), cfg.OpenAI), cfg, sharedBag).Ask(context.Background(), models.PromptRequest{
+		Messages: []map[string]any{
+			{"role": "system", "content": "You are an analyst."},
+			{"role": "user", "content": "Should I hold VOO?"},
+		},
+	})
+	require.NoError(t, err)
+
+	raw, ok := sharedBag.Get(bag.KTranscript)
+	require.True(t, ok)
+	msgs := raw.([]models.TranscriptMessage)
+	require.Len(t, msgs, 3)
+
+	roles := make([]models.Role, len(msgs))
+	for i, m := range msgs {
+		roles[i] = m.Role
+		assert.False(t, m.CreatedAt.IsZero())
+	}
+	assert.Equal(t, []models.Role{models.RoleSystem, models.RoleUser, models.RoleAssistant}, roles)
+	assert.Equal(t, "Should I hold VOO?", msgs[1].Content)
+	assert.Equal(t, "Hold VOO.", msgs[2].Content)
+	assert.Equal(t, "gpt-4o", msgs[2].Model)
+}
@@ -0,0 +1,82 @@
+// internal/llm/openai/usage_tracking.go
+package openai
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/internal/llm/batch"
+	"github.com/amaurybrisou/mosychlos/internal/tools"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// openAIAPIToolName is the name under which LLM calls show up in the tool metrics
+const openAIAPIToolName = "openai_api"
+
+// usagePricing prices tracked calls with the same per-model table as batch estimates
+var usagePricing = batch.NewCostOptimizer()
+
+// trackTokenUsage records one model call with its real token counts and their
+// estimated cost so cost tracking sees LLM usage alongside tool usage. model is the model that served the call; the
+// requested model is recorded too when a fallback served it instead.
+func (p *Provider) trackTokenUsage(start time.Time, requested, model string, turn *models.AssistantTurn) {
+	if p.sharedBag == nil {
+		return
+	}
+
+	args := map[string]any{
+		"model":      model,
+		"tool_calls": len(turn.ToolCalls),
+	}
+	if requested != "" && requested != model {
+		args["requested_model"] = requested
+	}
+
+	tools.RecordComputation(p.sharedBag, models.ToolComputation{
+		ToolName:  openAIAPIToolName,
+		Arguments: args,
+		Result: map[string]any{
+			"finish_reason": turn.FinishReason,
+			"input_tokens":  turn.Usage.InputTokens,
+			"output_tokens": turn.Usage.OutputTokens,
+		},
+		StartTime:  start,
+		Duration:   time.Since(start),
+		Success:    true,
+		TokensUsed: turn.Usage.TotalTokens,
+		Cost:       usagePricing.UsageCost(model, turn.Usage.InputTokens, turn.Usage.OutputTokens),
+	})
+
+	slog.Debug("OpenAI token usage tracked",
+		"model", model,
+		"input_tokens", turn.Usage.InputTokens,
+		"output_tokens", turn.Usage.OutputTokens,
+		"total_tokens", turn.Usage.TotalTokens,
+		"finish_reason", turn.FinishReason,
+	)
+}
+
+// recordReasoning keeps the reasoning summary of a response for the system report
+func (p *Provider) recordReasoning(model, responseID string, turn *models.AssistantTurn) {
+	if p.sharedBag == nil || turn.Reasoning == "" {
+		return
+	}
+
+	trace := models.ReasoningTrace{
+		Model:      model,
+		ResponseID: responseID,
+		Summary:    turn.Reasoning,
+		CreatedAt:  time.Now(),
+	}
+	p.sharedBag.Update(bag.KReasoningTraces, func(current any) any {
+		traces, _ := current.([]models.ReasoningTrace)
+		return append(traces, trace)
+	})
+
+	slog.Debug("Reasoning summary recorded",
+		"model", model,
+		"response_id", responseID,
+		"len", len(turn.Reasoning),
+	)
+}
@@ -0,0 +1,192 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v2/responses"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+)
+
+const usageResponseBody = `{"id":"resp_1","object":"response","status":"completed","output":[` +
+	`{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"ok","annotations":[]}]}],` +
+	`"usage":{"input_tokens":120,"output_tokens":30,"total_tokens":150}}`
+
+func TestProcessResponsesAPIResult_FinishReasonAndUsage(t *testing.T) {
+	cases := []struct {
+		name       string
+		body       string
+		wantReason string
+		wantUsage  models.Usage
+	}{
+		{
+			name:       "completed text",
+			body:       usageResponseBody,
+			wantReason: models.FinishReasonStop,
+			wantUsage:  models.Usage{PromptTokens: 120, CompletionTokens: 30, InputTokens: 120, OutputTokens: 30, TotalTokens: 150},
+		},
+		{
+			name: "truncated by max_output_tokens",
+			body: `{"id":"resp_2","object":"response","status":"incomplete","incomplete_details":{"reason":"max_output_tokens"},` +
+				`"output":[],"usage":{"input_tokens":10,"output_tokens":5,"total_tokens":15}}`,
+			wantReason: models.FinishReasonLength,
+			wantUsage:  models.Usage{PromptTokens: 10, CompletionTokens: 5, InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		},
+		{
+			name:       "content filtered",
+			body:       `{"id":"resp_3","object":"response","status":"incomplete","incomplete_details":{"reason":"content_filter"},"output":[]}`,
+			wantReason: models.FinishReasonContentFilter,
+		},
+		{
+			name: "function call",
+			body: `{"id":"resp_4","object":"response","status":"completed","output":[` +
+				`{"type":"function_call","id":"fc_1","call_id":"call_1","name":"fmp","arguments":"{}","status":"completed"}],` +
+				`"usage":{"input_tokens":7,"output_tokens":3,"total_tokens":10}}`,
+			wantReason: models.FinishReasonToolCalls,
+			wantUsage:  models.Usage{PromptTokens: 7, CompletionTokens: 3, InputTokens: 7, OutputTokens: 3, TotalTokens: 10},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var resp responses.Response
+			require.NoError(t, json.Unmarshal([]byte(c.body), &resp))
+
+			turn, err := processResponsesAPIResult(&resp, time.Now())
+			require.NoError(t, err)
+			assert.Equal(t, c.wantReason, turn.FinishReason)
+			assert.Equal(t, c.wantUsage, turn.Usage)
+		})
+	}
+}
+
+func TestRunner_RunTracksTokenUsage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(usageResponseBody))
+	}))
+	defer srv.Close()
+
+	cfg := config.LLMConfig{Model: config.LLMModelGPT4o, BaseURL: srv.URL}
+	sharedBag := bag.NewSharedBag()
+	cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+	runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, sharedBag))
+
+	for range 2 {
+		resp, err := runner.Run(context.Background(), models.PromptRequest{
+			Messages: []map[string]any{{"role": "user", "content": "hi"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, models.FinishReasonStop, resp.FinishReason)
+		assert.Equal(t, 150, resp.Usage.TotalTokens)
+	}
+
+	raw, ok := sharedBag.Get(bag.KToolMetrics)
+	require.True(t, ok)
+	metrics := raw.(models.ToolMetrics)
+	assert.Equal(t, 300, metrics.TotalTokens)
+	assert.Equal(t, 2, metrics.ByTool[openAIAPIToolName].Calls)
+	assert.Equal(t, 300, metrics.ByTool[openAIAPIToolName].Tokens)
+	// gpt-4o: 120 input tokens at $0.005/1K and 30 output tokens at $0.015/1K, twice
+	assert.InDelta(t, 0.0021, metrics.ByTool[openAIAPIToolName].Cost, 1e-9)
+	assert.InDelta(t, 0.0021, metrics.TotalCost, 1e-9)
+
+	raw, ok = sharedBag.Get(bag.KToolComputations)
+	require.True(t, ok)
+	computations := raw.([]models.ToolComputation)
+	require.Len(t, computations, 2)
+	assert.Equal(t, 150, computations[0].TokensUsed)
+	assert.InDelta(t, 0.00105, computations[0].Cost, 1e-9)
+	assert.Equal(t, models.FinishReasonStop, computations[0].Result.(map[string]any)["finish_reason"])
+}
+
+const reasoningResponseBody = `{"id":"resp_r","object":"response","status":"completed","output":[` +
+	`{"type":"reasoning","id":"rs_1","summary":[{"type":"summary_text","text":"Weighed the equity tilt."},` +
+	`{"type":"summary_text","text":"Checked cash drag."}]},` +
+	`{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"Rebalance.","annotations":[]}]}],` +
+	`"usage":{"input_tokens":10,"output_tokens":20,"total_tokens":30}}`
+
+func TestProcessResponsesAPIResult_Reasoning(t *testing.T) {
+	cases := []struct {
+		name          string
+		body          string
+		wantReasoning string
+	}{
+		{
+			name:          "reasoning summary is kept apart from content",
+			body:          reasoningResponseBody,
+			wantReasoning: "Weighed the equity tilt.\n\nChecked cash drag.",
+		},
+		{
+			name: "reasoning without summary",
+			body: `{"id":"resp_e","object":"response","status":"completed","output":[{"type":"reasoning","id":"rs_1","summary":[]},` +
+				`{"type":"message","id":"msg_1","role":"assistant","content":[{"type":"output_text","text":"Rebalance.","annotations":[]}]}]}`,
+		},
+		{
+			name: "no reasoning item",
+			body: usageResponseBody,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			var resp responses.Response
+			require.NoError(t, json.Unmarshal([]byte(c.body), &resp))
+
+			turn, err := processResponsesAPIResult(&resp, time.Now())
+			require.NoError(t, err)
+			assert.Equal(t, c.wantReasoning, turn.Reasoning)
+
+			var content map[string]any
+			require.NoError(t, json.Unmarshal([]byte(turn.Content), &content))
+			assert.NotContains(t, turn.Content, "reasoning")
+			assert.NotContains(t, turn.Content, "Weighed")
+		})
+	}
+}
+
+func TestRunner_RunRecordsReasoning(t *testing.T) {
+	var body map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(reasoningResponseBody))
+	}))
+	defer srv.Close()
+
+	effort, summary := "low", "auto"
+	cfg := config.LLMConfig{
+		Model:   config.LLMModelGPT5Mini,
+		BaseURL: srv.URL,
+		OpenAI:  config.OpenAIConfig{ReasoningEffort: &effort, ReasoningSummary: &summary},
+	}
+	sharedBag := bag.NewSharedBag()
+	cli := pkgopenai.NewClient(srv.Client(), cfg.OpenAI)
+	runner := NewRunner(NewEngine(cli, cfg), NewProvider(cli, cfg, sharedBag))
+
+	resp, err := runner.Run(context.Background(), models.PromptRequest{
+		Messages: []map[string]any{{"role": "user", "content": "hi"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"effort": "low", "summary": "auto"}, body["reasoning"])
+	assert.Equal(t, "Weighed the equity tilt.\n\nChecked cash drag.", resp.Reasoning)
+	assert.NotContains(t, resp.Content, "Weighed")
+
+	raw, ok := sharedBag.Get(bag.KReasoningTraces)
+	require.True(t, ok)
+	traces := raw.([]models.ReasoningTrace)
+	require.Len(t, traces, 1)
+	assert.Equal(t, "gpt-5-mini", traces[0].Model)
+	assert.Equal(t, "resp_r", traces[0].ResponseID)
+	assert.Equal(t, resp.Reasoning, traces[0].Summary)
+}
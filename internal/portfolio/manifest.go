@@ -0,0 +1,270 @@
+package portfolio
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// Importer parses one exported portfolio file
+type Importer func(data []byte) (*models.Portfolio, error)
+
+// importers are the importer types a manifest source may reference
+var importers = map[string]Importer{
+	"yaml": importYAML,
+	"json": importJSON,
+	"csv":  importCSV,
+}
+
+// RegisterImporter adds (or replaces) a named importer
+func RegisterImporter(name string, imp Importer) {
+	importers[name] = imp
+}
+
+// Importers returns the registered importer types, sorted
+func Importers() []string {
+	return slices.Sorted(maps.Keys(importers))
+}
+
+// Manifest lists the portfolio files to consolidate, e.g.
+//
+//	base_currency: EUR
+//	sources:
+//	  - path: broker_a.yaml
+//	  - path: broker_b.csv
+//	    importer: csv
+//	    currency: USD
+//	    provider: broker_b
+type Manifest struct {
+	// BaseCurrency of the merged portfolio; defaults to the first source's
+	BaseCurrency string           `yaml:"base_currency,omitempty"`
+	Sources      []ManifestSource `yaml:"sources"`
+
+	dir string // paths are relative to the manifest's directory
+}
+
+// ManifestSource is one exported portfolio file
+type ManifestSource struct {
+	Path string `yaml:"path"`
+	// Importer type (yaml, json, csv); inferred from the file extension when empty
+	Importer string `yaml:"importer,omitempty"`
+	// Currency used for accounts and holdings of this source that have none
+	Currency string `yaml:"currency,omitempty"`
+	// Provider labels the accounts of this source that have none (e.g. the broker)
+	Provider string `yaml:"provider,omitempty"`
+}
+
+// LoadManifest reads and validates a manifest file
+func LoadManifest(fsys fs.FS, path string) (*Manifest, error) {
+	data, err := fsys.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", path, err)
+	}
+	m.dir = filepath.Dir(path)
+
+	if err := m.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Validate checks that every source has a path and a known importer
+func (m *Manifest) Validate() error {
+	if len(m.Sources) == 0 {
+		return errors.New("no sources")
+	}
+	for i, src := range m.Sources {
+		if src.Path == "" {
+			return fmt.Errorf("source %d: path is required", i)
+		}
+		if _, ok := importers[src.importer()]; !ok {
+			return fmt.Errorf("source %s: unknown importer %q (use one of %s)", src.Path, src.importer(), strings.Join(Importers(), ", "))
+		}
+	}
+	return nil
+}
+
+func (s ManifestSource) importer() string {
+	if s.Importer != "" {
+		return strings.ToLower(s.Importer)
+	}
+	switch ext := strings.ToLower(filepath.Ext(s.Path)); ext {
+	case ".yml":
+		return "yaml"
+	default:
+		return strings.TrimPrefix(ext, ".")
+	}
+}
+
+// Load imports every source and merges them into one portfolio
+func (m *Manifest) Load(fsys fs.FS) (*models.Portfolio, error) {
+	sources := make([]models.Portfolio, 0, len(m.Sources))
+	for _, src := range m.Sources {
+		path := src.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(m.dir, path)
+		}
+
+		data, err := fsys.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read source %s: %w", src.Path, err)
+		}
+		p, err := importers[src.importer()](data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import source %s: %w", src.Path, err)
+		}
+
+		if src.Currency != "" && p.BaseCurrency == "" {
+			p.BaseCurrency = src.Currency
+		}
+		for i := range p.Accounts {
+			if src.Currency != "" && p.Accounts[i].Currency == "" {
+				p.Accounts[i].Currency = src.Currency
+			}
+			if src.Provider != "" && p.Accounts[i].Provider == "" {
+				p.Accounts[i].Provider = src.Provider
+			}
+		}
+		sources = append(sources, *p)
+	}
+
+	merged := models.Portfolio{BaseCurrency: m.BaseCurrency}.Merge(sources...)
+	return &merged, nil
+}
+
+// ManifestFetcher fetches the consolidated portfolio of a manifest file
+type ManifestFetcher struct {
+	FS   fs.FS
+	Path string
+}
+
+// Fetch implements Fetcher
+func (f ManifestFetcher) Fetch(_ context.Context) (*models.Portfolio, error) {
+	m, err := LoadManifest(f.FS, f.Path)
+	if err != nil {
+		return nil, err
+	}
+	return m.Load(f.FS)
+}
+
+func importYAML(data []byte) (*models.Portfolio, error) {
+	var p models.Portfolio
+	if err := yaml.Unmarshal(data, &p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+// importJSON reads the same fields as the YAML format (as_of, accounts, ...)
+func importJSON(data []byte) (*models.Portfolio, error) {
+	if !json.Valid(data) {
+		return nil, errors.New("invalid JSON")
+	}
+	// JSON is valid YAML and models.Portfolio only carries yaml tags
+	return importYAML(data)
+}
+
+// importCSV reads one holding per row. A header row is required; recognized
+// columns are account, ticker, quantity, cost_basis, currency, type, name, isin,
+// sector, region and priced_at (ticker and quantity are mandatory). Rows are grouped into
+// accounts by the account column.
+func importCSV(data []byte) (*models.Portfolio, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.TrimLeadingSpace = true
+
+	header, err := r.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %w", err)
+	}
+	cols := make(map[string]int, len(header))
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for _, required := range []string{"ticker", "quantity"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("CSV is missing the %q column", required)
+		}
+	}
+
+	p := &models.Portfolio{}
+	accounts := map[string]int{}
+	for line := 2; ; line++ {
+		row, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		get := func(col string) string {
+			if i, ok := cols[col]; ok && i < len(row) {
+				return strings.TrimSpace(row[i])
+			}
+			return ""
+		}
+
+		h := models.Holding{
+			Ticker:   get("ticker"),
+			Currency: get("currency"),
+			Type:     models.AssetType(get("type")),
+			ISIN:     get("isin"),
+			Name:     get("name"),
+			Sector:   get("sector"),
+			Region:   get("region"),
+		}
+		if h.Quantity, err = strconv.ParseFloat(get("quantity"), 64); err != nil {
+			return nil, fmt.Errorf("line %d: invalid quantity %q", line, get("quantity"))
+		}
+		if v := get("cost_basis"); v != "" {
+			if h.CostBasis, err = strconv.ParseFloat(v, 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid cost_basis %q", line, v)
+			}
+		}
+		if v := get("priced_at"); v != "" {
+			if h.PricedAt, err = parseDate(v); err != nil {
+				return nil, fmt.Errorf("line %d: invalid priced_at %q", line, v)
+			}
+		}
+
+		name := get("account")
+		if name == "" {
+			name = "default"
+		}
+		i, ok := accounts[name]
+		if !ok {
+			i = len(p.Accounts)
+			accounts[name] = i
+			p.Accounts = append(p.Accounts, models.Account{Name: name, Type: models.AccountBrokerage})
+		}
+		p.Accounts[i].Holdings = append(p.Accounts[i].Holdings, h)
+	}
+	return p, nil
+}
+
+// parseDate accepts the dates of the portfolio format: YYYY-MM-DD or RFC3339
+func parseDate(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
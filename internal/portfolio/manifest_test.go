@@ -0,0 +1,137 @@
+package portfolio
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+	return dir
+}
+
+func TestManifest_Load(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"manifest.yaml": `
+base_currency: EUR
+sources:
+  - path: exports/broker_a.yaml
+  - path: exports/broker_b.csv
+    currency: USD
+    provider: broker_b
+`,
+		"exports/broker_a.yaml": `
+as_of: "2026-10-15"
+base_currency: EUR
+accounts:
+  - name: PEA
+    type: brokerage
+    holdings:
+      - {ticker: CW8, quantity: 10, cost_basis: 450, type: etf}
+`,
+		"exports/broker_b.csv": `account,ticker,quantity,cost_basis,currency,type
+Taxable,AAPL,5,180,,stock
+Taxable,VWRL,20,95.5,GBP,etf
+IRA,BND,12,72,,etf
+`,
+	})
+
+	m, err := LoadManifest(fs.OS{}, filepath.Join(dir, "manifest.yaml"))
+	require.NoError(t, err)
+	got, err := m.Load(fs.OS{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "EUR", got.BaseCurrency)
+	assert.Equal(t, "2026-10-15", got.AsOf)
+	require.Len(t, got.Accounts, 3)
+
+	pea, taxable, ira := got.Accounts[0], got.Accounts[1], got.Accounts[2]
+	assert.Equal(t, "PEA", pea.Name)
+	assert.Equal(t, "EUR", pea.Currency)
+	assert.Equal(t, []models.Holding{{Ticker: "CW8", Quantity: 10, CostBasis: 450, Currency: "EUR", Type: models.ETF, PricedAt: time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)}}, pea.Holdings)
+
+	assert.Equal(t, "Taxable", taxable.Name)
+	assert.Equal(t, "broker_b", taxable.Provider)
+	assert.Equal(t, "USD", taxable.Currency)
+	require.Len(t, taxable.Holdings, 2)
+	assert.Equal(t, "USD", taxable.Holdings[0].Currency)
+	assert.Equal(t, "GBP", taxable.Holdings[1].Currency)
+	assert.InDelta(t, 95.5, taxable.Holdings[1].CostBasis, 1e-9)
+
+	assert.Equal(t, "IRA", ira.Name)
+	assert.Equal(t, "USD", ira.Holdings[0].Currency)
+
+	assert.ElementsMatch(t, []string{"CW8", "AAPL", "VWRL", "BND"}, got.Tickers())
+}
+
+func TestManifestFetcher_Fetch(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"manifest.yaml": "sources:\n  - path: a.json\n  - path: b.yml\n",
+		"a.json":        `{"base_currency": "USD", "accounts": [{"name": "A", "holdings": [{"ticker": "MSFT", "quantity": 1, "cost_basis": 400}]}]}`,
+		"b.yml":         "accounts:\n  - name: B\n    currency: CHF\n    holdings:\n      - {ticker: NESN, quantity: 3, cost_basis: 90}\n",
+	})
+
+	got, err := ManifestFetcher{FS: fs.OS{}, Path: filepath.Join(dir, "manifest.yaml")}.Fetch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, "USD", got.BaseCurrency)
+	require.Len(t, got.Accounts, 2)
+	assert.Equal(t, "USD", got.Accounts[0].Holdings[0].Currency)
+	assert.Equal(t, "CHF", got.Accounts[1].Holdings[0].Currency)
+}
+
+func TestLoadManifest_Invalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		manifest string
+		wantErr  string
+	}{
+		{name: "no sources", manifest: "base_currency: EUR\n", wantErr: "no sources"},
+		{name: "missing path", manifest: "sources:\n  - importer: csv\n", wantErr: "path is required"},
+		{name: "unknown importer", manifest: "sources:\n  - path: export.xlsx\n", wantErr: `unknown importer "xlsx"`},
+		{name: "not yaml", manifest: "sources: [", wantErr: "failed to parse manifest"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"manifest.yaml": c.manifest})
+			_, err := LoadManifest(fs.OS{}, filepath.Join(dir, "manifest.yaml"))
+			assert.ErrorContains(t, err, c.wantErr)
+		})
+	}
+}
+
+func TestImportCSV_Errors(t *testing.T) {
+	cases := []struct {
+		name    string
+		csv     string
+		wantErr string
+	}{
+		{name: "missing quantity column", csv: "ticker,cost_basis\nAAPL,1\n", wantErr: `missing the "quantity" column`},
+		{name: "bad quantity", csv: "ticker,quantity\nAAPL,many\n", wantErr: "line 2: invalid quantity"},
+		{name: "empty file", csv: "", wantErr: "failed to read CSV header"},
+		{name: "bad priced_at", csv: "ticker,quantity,priced_at\nAAPL,1,yesterday\n", wantErr: "line 2: invalid priced_at"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := importCSV([]byte(c.csv))
+			assert.ErrorContains(t, err, c.wantErr)
+		})
+	}
+}
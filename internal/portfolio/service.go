@@ -66,7 +66,7 @@ func (s *service) GetPortfolio(ctx context.Context, fetcher Fetcher) (*models.Po
 		s.bag.Set(bag.KPortfolio, portfolio)
 
 		// store normalized portfolio for AI analysis
-		if normalizedPortfolio, err := portfolio.Normalize(); err != nil {
+		if normalizedPortfolio, err := portfolio.NormalizeWith(s.config.Portfolio.NormalizeOptions()); err != nil {
 			fmt.Printf("Warning: failed to normalize portfolio: %v\n", err)
 		} else {
 			s.bag.Set(bag.KPortfolioNormalizedForAI, normalizedPortfolio)
@@ -159,7 +159,7 @@ func (s *service) getCurrentPortfolio() *models.Portfolio {
 	s.bag.Set(bag.KPortfolio, portfolio)
 
 	// also store normalized version for AI analysis
-	if normalizedPortfolio, err := portfolio.Normalize(); err != nil {
+	if normalizedPortfolio, err := portfolio.NormalizeWith(s.config.Portfolio.NormalizeOptions()); err != nil {
 		fmt.Printf("Warning: failed to normalize cached portfolio: %v\n", err)
 	} else {
 		s.bag.Set(bag.KPortfolioNormalizedForAI, normalizedPortfolio)
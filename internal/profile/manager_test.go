@@ -1,7 +1,9 @@
 package profile
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"io/fs"
 	"path/filepath"
 	"testing"
@@ -101,6 +103,21 @@ func (tfs *testFS) Rename(oldPath, newPath string) error {
 	return nil
 }
 
+func (tfs *testFS) Create(path string) (io.WriteCloser, error) {
+	return &testFileWriter{fs: tfs, path: path}, nil
+}
+
+// testFileWriter buffers writes and commits them to the map on Close
+type testFileWriter struct {
+	bytes.Buffer
+	fs   *testFS
+	path string
+}
+
+func (w *testFileWriter) Close() error {
+	return w.fs.WriteFile(w.path, w.Bytes(), 0644)
+}
+
 type testFileInfo struct {
 	name  string
 	isDir bool
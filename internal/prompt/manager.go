@@ -12,6 +12,7 @@ import (
 
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/version"
 )
 
 //go:embed templates/portfolio/*.tmpl
@@ -63,6 +64,7 @@ func (m *manager) BuildPrompt(ctx context.Context, analysisType models.AnalysisT
 // gatherPromptData collects all necessary data from the shared bag and config
 func (m *manager) gatherPromptData(_ context.Context, analysisType models.AnalysisType) (*PromptData, error) {
 	data := &PromptData{
+		EngineVersion:     version.Engine,
 		Localization:      m.deps.Config.UserLocalization,
 		UserProfile:       m.deps.Config.UserProfile,
 		InvestmentProfile: m.deps.Config.UserProfile,
@@ -84,6 +86,18 @@ func (m *manager) gatherPromptData(_ context.Context, analysisType models.Analys
 		}
 	}
 
+	if gaps, exists := m.deps.Bag.Get(bag.KAllocationGaps); exists {
+		if comparison, ok := gaps.(*models.AllocationComparison); ok {
+			data.AllocationGaps = comparison
+		}
+	}
+
+	if plan, exists := m.deps.Bag.Get(bag.KRebalancePlan); exists {
+		if p, ok := plan.(*models.RebalancePlan); ok {
+			data.RebalancePlan = p
+		}
+	}
+
 	// Get market data if available
 	if marketData, exists := m.deps.Bag.Get(bag.KMarketDataNormalized); exists {
 		if market, ok := marketData.(*models.NormalizedMarketData); ok {
@@ -32,6 +32,10 @@ type PromptData struct {
 	Portfolio  *models.NormalizedPortfolio  `json:"portfolio,omitempty"`
 	MarketData *models.NormalizedMarketData `json:"market_data,omitempty"`
 	MacroData  *models.NormalizedMacroData  `json:"macro_data,omitempty"`
+	// AllocationGaps compare the portfolio to the allocation recommended for the profile
+	AllocationGaps *models.AllocationComparison `json:"allocation_gaps,omitempty"`
+	// RebalancePlan is the computed trade plan toward the recommended allocation
+	RebalancePlan *models.RebalancePlan `json:"rebalance_plan,omitempty"`
 
 	// Analysis-specific context
 	AnalysisType models.AnalysisType `json:"analysis_type"`
@@ -2,6 +2,8 @@ package report
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
@@ -92,6 +94,52 @@ func (l *bagLoader) LoadCustomerData(ctx context.Context, sharedBag bag.SharedBa
 		data.Fundamentals = fundamentals
 	}
 
+	if citations, ok := sharedBag.Get(bag.KWebSearchCitations); ok {
+		if c, ok := citations.([]models.Citation); ok {
+			data.Citations = c
+		}
+	}
+
+	if alerts, ok := sharedBag.Get(bag.KConcentrationAlerts); ok {
+		if a, ok := alerts.([]models.Alert); ok {
+			data.Alerts = a
+		} else if a, ok := decodeBagValue[[]models.Alert](alerts); ok {
+			// bags loaded from JSON hold generic values
+			data.Alerts = a
+		}
+	}
+
+	if v, ok := sharedBag.Get(bag.KHealthScore); ok {
+		if h, ok := v.(models.HealthScore); ok {
+			data.Health = &h
+		} else if h, ok := decodeBagValue[models.HealthScore](v); ok {
+			data.Health = &h
+		}
+	}
+
+	if v, ok := sharedBag.Get(bag.KAllocationGaps); ok {
+		if c, ok := v.(*models.AllocationComparison); ok {
+			data.Allocation = c
+		} else if c, ok := decodeBagValue[models.AllocationComparison](v); ok {
+			data.Allocation = &c
+		}
+	}
+
+	if v, ok := sharedBag.Get(bag.KRebalancePlan); ok {
+		if p, ok := v.(*models.RebalancePlan); ok {
+			data.Rebalance = p
+		} else if p, ok := decodeBagValue[models.RebalancePlan](v); ok {
+			data.Rebalance = &p
+		}
+	}
+
+	if n := normalizedPortfolio(sharedBag); n != nil {
+		data.StaleHoldings = n.StaleHoldings()
+		data.Holdings = n.Holdings
+		data.HoldingsCurrency = n.BaseCurrency
+		data.Sleeves = n.Sleeves
+	}
+
 	return data, nil
 }
 
@@ -142,12 +190,48 @@ func (l *bagLoader) LoadSystemData(ctx context.Context, sharedBag bag.SharedBag)
 	if toolComputations, ok := sharedBag.Get(bag.KToolComputations); ok {
 		if computations, ok := toolComputations.([]models.ToolComputation); ok {
 			data.ToolComputations = computations
+		} else if computations, ok := decodeBagValue[[]models.ToolComputation](toolComputations); ok {
+			// bags loaded from JSON hold generic values
+			data.ToolComputations = computations
+		}
+	}
+
+	// Load reasoning summaries of reasoning models
+	if reasoningTraces, ok := sharedBag.Get(bag.KReasoningTraces); ok {
+		if traces, ok := reasoningTraces.([]models.ReasoningTrace); ok {
+			data.ReasoningTraces = traces
+		} else if traces, ok := decodeBagValue[[]models.ReasoningTrace](reasoningTraces); ok {
+			data.ReasoningTraces = traces
+		}
+	}
+
+	// Record the FX rates behind the portfolio values, warning on stale ones
+	if n := normalizedPortfolio(sharedBag); n != nil {
+		data.FXRates = n.FXRates
+		for _, r := range n.StaleFXRates() {
+			data.Warnings = append(data.Warnings, fmt.Sprintf("FX rate %s/%s (%g) dates from %s, before the portfolio date %s: converted values may be off",
+				r.FromCurrency, r.ToCurrency, r.ExchangeRate, r.AsOf.Format("2006-01-02 15:04 MST"), n.AsOfDate.Format("2006-01-02")))
 		}
 	}
 
 	return data, nil
 }
 
+// normalizedPortfolio returns the normalized portfolio in the bag, or nil
+func normalizedPortfolio(sharedBag bag.SharedBag) *models.NormalizedPortfolio {
+	normalized, ok := sharedBag.Get(bag.KPortfolioNormalizedForAI)
+	if !ok {
+		return nil
+	}
+	if n, ok := normalized.(*models.NormalizedPortfolio); ok {
+		return n
+	}
+	if n, ok := decodeBagValue[models.NormalizedPortfolio](normalized); ok {
+		return &n
+	}
+	return nil
+}
+
 // LoadFullData extracts both customer and system data from the bag
 func (l *bagLoader) LoadFullData(ctx context.Context, sharedBag bag.SharedBag) (*models.FullReportData, error) {
 	customerData, err := l.LoadCustomerData(ctx, sharedBag)
@@ -171,3 +255,16 @@ func (l *bagLoader) IsBatchMode(sharedBag bag.SharedBag) bool {
 	value, _ := sharedBag.Get(bag.KBatchMode)
 	return value.(bool)
 }
+
+// decodeBagValue converts a generic (JSON-loaded) bag value to T
+func decodeBagValue[T any](v any) (T, bool) {
+	var out T
+	b, err := json.Marshal(v)
+	if err != nil {
+		return out, false
+	}
+	if err := json.Unmarshal(b, &out); err != nil {
+		return out, false
+	}
+	return out, true
+}
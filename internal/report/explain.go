@@ -0,0 +1,270 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// FigureSource attributes a report figure to the tool call that produced it
+type FigureSource struct {
+	Figure    string    `json:"figure"`
+	Tool      string    `json:"tool"`
+	Field     string    `json:"field"`
+	Arguments string    `json:"arguments,omitempty"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// toolValue is one numeric value found in a tool result
+type toolValue struct {
+	value float64
+	comp  *models.ToolComputation
+	field string
+}
+
+var (
+	// figurePattern matches numbers such as 18.2, -3.45 and 1,234.56
+	figurePattern = regexp.MustCompile(`-?\d{1,3}(?:,\d{3})+(?:\.\d+)?|-?\d+(?:\.\d+)?`)
+	// linkPattern matches markdown links, which are left untouched
+	linkPattern = regexp.MustCompile(`\[[^\]]*\]\([^)]*\)`)
+)
+
+// explainFigures annotates the first occurrence of each figure that matches a
+// value returned by a tool with the tool name and fetch time, and appends a
+// provenance table. Integers below 100 and years are too ambiguous to attribute
+// and are left alone, as are links, headings, tables and code blocks.
+func explainFigures(content string, computations []models.ToolComputation) (string, []FigureSource) {
+	values := toolValues(computations)
+	if len(values) == 0 {
+		return content, nil
+	}
+
+	var (
+		sources   []FigureSource
+		annotated = map[string]bool{}
+		inCode    bool
+	)
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			inCode = !inCode
+			continue
+		}
+		if inCode || strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "|") {
+			continue
+		}
+
+		skip := linkPattern.FindAllStringIndex(line, -1)
+		var b strings.Builder
+		last := 0
+		for _, m := range figurePattern.FindAllStringIndex(line, -1) {
+			start, end := m[0], m[1]
+			figure := line[start:end]
+			if annotated[figure] || insideAny(start, skip) || !isStandalone(line, start, end) {
+				continue
+			}
+			src, ok := attribute(figure, values)
+			if !ok {
+				continue
+			}
+			annotated[figure] = true
+			sources = append(sources, src)
+
+			b.WriteString(line[last:end])
+			fmt.Fprintf(&b, " *[%s, %s]*", src.Tool, src.FetchedAt.UTC().Format("2006-01-02 15:04 MST"))
+			last = end
+		}
+		if last > 0 {
+			b.WriteString(line[last:])
+			lines[i] = b.String()
+		}
+	}
+
+	if len(sources) == 0 {
+		return content, nil
+	}
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(strings.Join(lines, "\n"), "\n"))
+	b.WriteString("\n\n## Data Provenance\n\n")
+	b.WriteString("| Figure | Tool | Field | Arguments | Fetched |\n")
+	b.WriteString("|---|---|---|---|---|\n")
+	for _, s := range sources {
+		fmt.Fprintf(&b, "| %s | %s | `%s` | %s | %s |\n",
+			s.Figure, s.Tool, s.Field, strings.ReplaceAll(s.Arguments, "|", `\|`), s.FetchedAt.UTC().Format(time.RFC3339))
+	}
+	return b.String(), sources
+}
+
+// attribute finds the tool value a figure was taken from: an exact match first,
+// then a value rounded to the figure's precision; the latest fetch wins ties
+func attribute(figure string, values []toolValue) (FigureSource, bool) {
+	v, err := strconv.ParseFloat(strings.ReplaceAll(figure, ",", ""), 64)
+	if err != nil {
+		return FigureSource{}, false
+	}
+	decimals := 0
+	if _, frac, ok := strings.Cut(figure, "."); ok {
+		decimals = len(frac)
+	}
+	if decimals == 0 && (math.Abs(v) < 100 || (v >= 1900 && v <= 2100)) {
+		return FigureSource{}, false
+	}
+
+	var best *toolValue
+	bestExact := false
+	for i := range values {
+		tv := &values[i]
+		exact := tv.value == v
+		if !exact && (decimals == 0 || !roundsTo(tv.value, decimals, v)) {
+			continue
+		}
+		if best == nil || (exact && !bestExact) ||
+			(exact == bestExact && tv.comp.StartTime.After(best.comp.StartTime)) {
+			best, bestExact = tv, exact
+		}
+	}
+	if best == nil {
+		return FigureSource{}, false
+	}
+
+	src := FigureSource{
+		Figure:    figure,
+		Tool:      best.comp.ToolName,
+		Field:     best.field,
+		FetchedAt: best.comp.StartTime,
+	}
+	if best.comp.Arguments != nil {
+		if b, err := json.Marshal(best.comp.Arguments); err == nil && string(b) != "{}" && string(b) != "null" {
+			src.Arguments = truncateArgs(string(b), 80)
+		}
+	}
+	return src, true
+}
+
+func roundsTo(value float64, decimals int, want float64) bool {
+	p := math.Pow10(decimals)
+	return math.Abs(math.Round(value*p)/p-want) < 0.5/p/10
+}
+
+// toolValues collects the numeric values of successful data tool results. LLM
+// calls (computations that consumed tokens) are bookkeeping, not data sources.
+func toolValues(computations []models.ToolComputation) []toolValue {
+	var out []toolValue
+	for i := range computations {
+		comp := &computations[i]
+		if !comp.Success || comp.TokensUsed > 0 || comp.Result == nil {
+			continue
+		}
+		walkNumbers(genericValue(comp.Result), "", func(field string, v float64) {
+			out = append(out, toolValue{value: v, comp: comp, field: field})
+		})
+	}
+	return out
+}
+
+// genericValue converts a tool result to maps, slices and scalars
+func genericValue(v any) any {
+	switch x := v.(type) {
+	case map[string]any, []any, float64:
+		return x
+	case string:
+		var decoded any
+		if json.Unmarshal([]byte(x), &decoded) == nil {
+			return decoded
+		}
+		return x
+	case json.RawMessage:
+		return genericValue(string(x))
+	case []byte:
+		return genericValue(string(x))
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var decoded any
+	if json.Unmarshal(b, &decoded) != nil {
+		return nil
+	}
+	return decoded
+}
+
+func walkNumbers(v any, path string, fn func(field string, v float64)) {
+	switch x := v.(type) {
+	case map[string]any:
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			walkNumbers(x[k], p, fn)
+		}
+	case []any:
+		for i, item := range x {
+			walkNumbers(item, fmt.Sprintf("%s[%d]", path, i), fn)
+		}
+	case float64:
+		fn(path, x)
+	case string:
+		if len(x) <= 24 {
+			if f, err := strconv.ParseFloat(strings.TrimSpace(x), 64); err == nil && !math.IsNaN(f) && !math.IsInf(f, 0) {
+				fn(path, f)
+			}
+		}
+	}
+}
+
+// isStandalone rejects numbers that are part of a word, date, version or identifier
+func isStandalone(line string, start, end int) bool {
+	if start > 0 {
+		c := line[start-1]
+		if isWordByte(c) || c == '.' || c == '/' || c == '-' || c == ':' {
+			return false
+		}
+	}
+	if end < len(line) {
+		c := line[end]
+		if isWordByte(c) || c == '/' || c == '-' || c == ':' {
+			return false
+		}
+		// a trailing dot is fine at the end of a sentence, not before more digits
+		if c == '.' && end+1 < len(line) && line[end+1] >= '0' && line[end+1] <= '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func insideAny(pos int, spans [][]int) bool {
+	for _, s := range spans {
+		if pos >= s[0] && pos < s[1] {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateArgs(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
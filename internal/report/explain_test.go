@@ -0,0 +1,100 @@
+package report
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestExplainFigures(t *testing.T) {
+	fetched := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	computations := []models.ToolComputation{
+		{
+			ToolName:  "fmp",
+			Arguments: map[string]any{"symbol": "^VIX"},
+			Result:    map[string]any{"quote": map[string]any{"symbol": "^VIX", "price": 18.2}},
+			StartTime: fetched,
+			Success:   true,
+		},
+		// an older fetch of the same value loses to the latest one
+		{
+			ToolName:  "yfinance_market_data",
+			Result:    `{"vix": 18.2, "spx": "5,000"}`,
+			StartTime: fetched.Add(-time.Hour),
+			Success:   true,
+		},
+		{
+			ToolName:  "fred",
+			Result:    json.RawMessage(`{"observations":[{"value":"4.3312"}]}`),
+			StartTime: fetched.Add(-2 * time.Hour),
+			Success:   true,
+		},
+		// failed calls and LLM usage are not sources
+		{ToolName: "newsapi", Result: map[string]any{"total": 5123.0}, StartTime: fetched, Success: false},
+		{ToolName: "openai_api", Result: map[string]any{"tokens": 1500.0}, StartTime: fetched, Success: true, TokensUsed: 1500},
+	}
+
+	cases := []struct {
+		name    string
+		content string
+		want    []string
+		notWant []string
+		sources int
+	}{
+		{
+			name:    "annotates the first occurrence with the latest source",
+			content: "VIX 18.2 signals calm markets. VIX at 18.2 again.",
+			want: []string{
+				"VIX 18.2 *[fmp, 2026-10-16 08:00 UTC]* signals",
+				"VIX at 18.2 again.",
+				"## Data Provenance",
+				"| 18.2 | fmp | `quote.price` | {\"symbol\":\"^VIX\"} | 2026-10-16T08:00:00Z |",
+			},
+			sources: 1,
+		},
+		{
+			name:    "matches values rounded to the figure's precision",
+			content: "The 10Y yield is 4.33.",
+			want:    []string{"4.33 *[fred, 2026-10-16 06:00 UTC]*.", "`observations[0].value`"},
+			sources: 1,
+		},
+		{
+			name:    "ignores unknown figures, small integers, years and links",
+			content: "Hold 5123 shares in 2026, 3 funds, 42.1 and [18.2](https://x.io/18.2)\n## VIX 18.2\n| 18.2 |",
+			notWant: []string{"*[", "## Data Provenance"},
+		},
+		{
+			name:    "ignores failed calls and llm usage",
+			content: "Tokens: 1500",
+			notWant: []string{"*["},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, sources := explainFigures(c.content, computations)
+			for _, w := range c.want {
+				assert.Contains(t, got, w)
+			}
+			for _, w := range c.notWant {
+				assert.NotContains(t, got, w)
+			}
+			require.Len(t, sources, c.sources)
+		})
+	}
+}
+
+func TestExplainFigures_SourceAttribution(t *testing.T) {
+	fetched := time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC)
+	_, sources := explainFigures("VIX 18.2", []models.ToolComputation{
+		{ToolName: "fmp", Result: map[string]any{"vix": 18.2}, StartTime: fetched, Success: true},
+	})
+
+	require.Len(t, sources, 1)
+	assert.Equal(t, FigureSource{Figure: "18.2", Tool: "fmp", Field: "vix", FetchedAt: fetched}, sources[0])
+}
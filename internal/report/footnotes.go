@@ -0,0 +1,142 @@
+package report
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// citationRefPattern matches inline numeric references like [3]. The optional
+// trailing "(" or ":" identifies markdown links and link definitions, which are left alone.
+var citationRefPattern = regexp.MustCompile(`\[(\d+)\](\(|:)?`)
+
+// addCitationFootnotes numbers the deduplicated citations, rewrites inline [n]
+// references to point at them and appends the sources. Only indices within the
+// returned citations are rewritten; other brackets, such as array indices in
+// prose (weights[2]), are left alone. PDF output uses pandoc footnotes so they
+// land at the bottom of the page; other formats get a linked endnote section.
+func addCitationFootnotes(content string, citations []models.Citation, format models.ReportFormat) string {
+	sources, numbers := dedupeCitations(citations)
+	if len(sources) == 0 {
+		return content
+	}
+
+	referenced := map[int]bool{}
+	var out strings.Builder
+	last := 0
+	for _, loc := range citationRefPattern.FindAllStringSubmatchIndex(content, -1) {
+		n, ok := citationRef(content, loc, len(citations), numbers)
+		if !ok {
+			continue
+		}
+		referenced[n] = true
+		out.WriteString(content[last:loc[0]])
+		if format == models.FormatPDF {
+			fmt.Fprintf(&out, "[^%d]", n)
+		} else {
+			fmt.Fprintf(&out, "[[%d]](#source-%d)", n, n)
+		}
+		last = loc[1]
+	}
+	out.WriteString(content[last:])
+	content = out.String()
+
+	var b strings.Builder
+	b.WriteString(strings.TrimRight(content, "\n"))
+
+	if format == models.FormatPDF {
+		b.WriteString("\n\n")
+		for i, c := range sources {
+			if referenced[i+1] {
+				fmt.Fprintf(&b, "[^%d]: %s\n", i+1, citationLink(c))
+			}
+		}
+		// pandoc drops footnotes nobody references, so list those as plain sources
+		var unreferenced []string
+		for i, c := range sources {
+			if !referenced[i+1] {
+				unreferenced = append(unreferenced, fmt.Sprintf("%d. %s", i+1, citationLink(c)))
+			}
+		}
+		if len(unreferenced) > 0 {
+			b.WriteString("\n## Sources\n\n")
+			b.WriteString(strings.Join(unreferenced, "\n"))
+			b.WriteString("\n")
+		}
+		return b.String()
+	}
+
+	b.WriteString("\n\n## Sources\n\n")
+	for i, c := range sources {
+		fmt.Fprintf(&b, "%d. <a id=\"source-%d\"></a>%s\n", i+1, i+1, citationLink(c))
+	}
+	return b.String()
+}
+
+// citationRef returns the footnote number for the reference matched at loc, or
+// false when the match is a link, follows a word (an index such as x[1]) or its
+// index falls outside the returned citations
+func citationRef(content string, loc []int, count int, numbers map[string]int) (int, bool) {
+	if loc[4] >= 0 {
+		return 0, false
+	}
+	if loc[0] > 0 {
+		prev, _ := utf8.DecodeLastRuneInString(content[:loc[0]])
+		if prev == '_' || unicode.IsLetter(prev) || unicode.IsDigit(prev) {
+			return 0, false
+		}
+	}
+	id := content[loc[2]:loc[3]]
+	if i, err := strconv.Atoi(id); err != nil || i < 1 || i > count {
+		return 0, false
+	}
+	n, ok := numbers[id]
+	return n, ok
+}
+
+// dedupeCitations keeps the first citation per URL, in order, and maps the bare
+// number of each original citation ID ("[2]" -> "2") to its footnote number
+func dedupeCitations(citations []models.Citation) ([]models.Citation, map[string]int) {
+	sources := make([]models.Citation, 0, len(citations))
+	byURL := map[string]int{}
+	numbers := map[string]int{}
+
+	for _, c := range citations {
+		if c.URL == "" {
+			continue
+		}
+		n, ok := byURL[c.URL]
+		if !ok {
+			sources = append(sources, c)
+			n = len(sources)
+			byURL[c.URL] = n
+		}
+
+		id := strings.Trim(c.CitationID, "[]")
+		if _, err := strconv.Atoi(id); err != nil {
+			continue
+		}
+		if _, seen := numbers[id]; !seen {
+			numbers[id] = n
+		}
+	}
+	return sources, numbers
+}
+
+// citationLink renders a citation as a markdown link titled by the citation title
+func citationLink(c models.Citation) string {
+	title := strings.TrimSpace(c.Title)
+	if title == "" {
+		title = c.URL
+	}
+	title = strings.NewReplacer("[", "(", "]", ")").Replace(title)
+	if c.Source != "" && !strings.Contains(title, c.Source) {
+		title += " — " + c.Source
+	}
+	return fmt.Sprintf("[%s](%s)", title, c.URL)
+}
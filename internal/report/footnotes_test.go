@@ -0,0 +1,80 @@
+package report
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestAddCitationFootnotes(t *testing.T) {
+	citations := []models.Citation{
+		{CitationID: "[1]", URL: "https://www.reuters.com/markets/fed", Title: "Fed holds rates", Source: "reuters.com"},
+		{CitationID: "[2]", URL: "https://www.ft.com/content/chips", Title: "Chip [tariffs] widen", Source: "ft.com"},
+		// a second web search cited the Reuters article again
+		{CitationID: "[3]", URL: "https://www.reuters.com/markets/fed", Title: "Fed holds rates", Source: "reuters.com"},
+		{CitationID: "[4]", URL: "https://example.com/no-title"},
+		{CitationID: "[5]", Title: "missing url"},
+		// an ID past the returned sources never matches prose
+		{CitationID: "[12]", URL: "https://example.com/out-of-range"},
+	}
+	content := "Rates are on hold [1][3]. Semis face tariffs [2]. See [docs](https://docs.example.com) and [7].\n" +
+		"The model weights[2] and the [12] month window stay as written.\n"
+
+	cases := []struct {
+		name    string
+		format  models.ReportFormat
+		want    []string
+		notWant []string
+	}{
+		{
+			name:   "markdown endnotes",
+			format: models.FormatMarkdown,
+			want: []string{
+				"Rates are on hold [[1]](#source-1)[[1]](#source-1).",
+				"Semis face tariffs [[2]](#source-2).",
+				"[docs](https://docs.example.com) and [7].",
+				"The model weights[2] and the [12] month window stay as written.",
+				"## Sources\n\n" +
+					"1. <a id=\"source-1\"></a>[Fed holds rates — reuters.com](https://www.reuters.com/markets/fed)\n" +
+					"2. <a id=\"source-2\"></a>[Chip (tariffs) widen — ft.com](https://www.ft.com/content/chips)\n" +
+					"3. <a id=\"source-3\"></a>[https://example.com/no-title](https://example.com/no-title)\n" +
+					"4. <a id=\"source-4\"></a>[https://example.com/out-of-range](https://example.com/out-of-range)\n",
+			},
+			notWant: []string{"missing url", "5. ", "#source-12"},
+		},
+		{
+			name:   "pdf footnotes",
+			format: models.FormatPDF,
+			want: []string{
+				"Rates are on hold [^1][^1].",
+				"Semis face tariffs [^2].",
+				"The model weights[2] and the [12] month window stay as written.",
+				"[^1]: [Fed holds rates — reuters.com](https://www.reuters.com/markets/fed)\n",
+				"[^2]: [Chip (tariffs) widen — ft.com](https://www.ft.com/content/chips)\n",
+				"## Sources\n\n3. [https://example.com/no-title](https://example.com/no-title)\n",
+			},
+			notWant: []string{"[^3]", "#source-"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := addCitationFootnotes(content, citations, c.format)
+			for _, w := range c.want {
+				assert.Contains(t, got, w)
+			}
+			for _, w := range c.notWant {
+				assert.NotContains(t, got, w)
+			}
+			// each URL is listed exactly once
+			assert.Equal(t, 1, strings.Count(got, "(https://www.reuters.com/markets/fed)"))
+		})
+	}
+
+	t.Run("no citations leaves content untouched", func(t *testing.T) {
+		assert.Equal(t, content, addCitationFootnotes(content, nil, models.FormatMarkdown))
+	})
+}
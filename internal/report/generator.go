@@ -12,13 +12,15 @@ import (
 	"github.com/amaurybrisou/mosychlos/pkg/fs"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
 	"github.com/amaurybrisou/mosychlos/pkg/pdf"
+	"github.com/amaurybrisou/mosychlos/pkg/version"
 )
 
 // Generator implements the ReportGenerator interface
 type Generator struct {
-	deps      Dependencies
-	pdf       pdf.Converter
-	bagLoader BagLoader
+	deps         Dependencies
+	pdf          pdf.Converter
+	bagLoader    BagLoader
+	holdingsSort models.HoldingsSort
 }
 
 // NewGenerator creates a new report generator
@@ -34,9 +36,10 @@ func NewGenerator(deps Dependencies) *Generator {
 	bagLoader := NewBagLoader(deps.FileSystem)
 
 	return &Generator{
-		deps:      deps,
-		pdf:       pdf.New(pdfOptions...),
-		bagLoader: bagLoader,
+		deps:         deps,
+		pdf:          pdf.New(pdfOptions...),
+		bagLoader:    bagLoader,
+		holdingsSort: deps.Config.Report.HoldingsSort,
 	}
 }
 
@@ -49,10 +52,13 @@ func (g *Generator) GenerateCustomerReport(ctx context.Context, format models.Re
 		return nil, fmt.Errorf("failed to load customer data: %w", err)
 	}
 
+	filteredHeadlines := g.filterNews(customerData)
+
 	content, dataSources, err := g.renderCustomerReport(customerData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render customer report: %w", err)
 	}
+	content = addCitationFootnotes(content, customerData.Citations, format)
 
 	outputDir := filepath.Join(g.deps.Config.DataDir, g.deps.Config.Report.OutputDir)
 
@@ -63,11 +69,13 @@ func (g *Generator) GenerateCustomerReport(ctx context.Context, format models.Re
 		FilePath:    g.generateMarkdownFilePath(models.TypeCustomer, outputDir),
 		GeneratedAt: time.Now(),
 		Metadata: models.ReportMeta{
-			Title:            g.getReportTitle("Portfolio Analysis Report"),
-			Description:      "Comprehensive portfolio analysis and insights",
-			DataSources:      dataSources,
-			GenerationTimeMs: time.Since(startTime).Milliseconds(),
-			Version:          "1.0.0",
+			Title:             g.getReportTitle("Portfolio Analysis Report"),
+			Description:       "Comprehensive portfolio analysis and insights",
+			DataSources:       dataSources,
+			GenerationTimeMs:  time.Since(startTime).Milliseconds(),
+			Version:           "1.0.0",
+			EngineVersion:     version.Engine,
+			FilteredHeadlines: filteredHeadlines,
 		},
 	}
 
@@ -86,6 +94,9 @@ func (g *Generator) GenerateSystemReport(ctx context.Context, format models.Repo
 	if err != nil {
 		return nil, fmt.Errorf("failed to load system data: %w", err)
 	}
+	if !g.deps.Config.Report.IncludeReasoning {
+		systemData.ReasoningTraces = nil
+	}
 
 	content, dataSources, err := g.renderSystemReport(systemData)
 	if err != nil {
@@ -106,6 +117,7 @@ func (g *Generator) GenerateSystemReport(ctx context.Context, format models.Repo
 			DataSources:      dataSources,
 			GenerationTimeMs: time.Since(startTime).Milliseconds(),
 			Version:          "1.0.0",
+			EngineVersion:    version.Engine,
 		},
 	}
 
@@ -125,11 +137,17 @@ func (g *Generator) GenerateFullReport(ctx context.Context, format models.Report
 	if err != nil {
 		return nil, fmt.Errorf("failed to load full data: %w", err)
 	}
+	if !g.deps.Config.Report.IncludeReasoning && fullData.System != nil {
+		fullData.System.ReasoningTraces = nil
+	}
+
+	filteredHeadlines := g.filterNews(fullData.Customer)
 
 	content, dataSources, err := g.renderFullReport(fullData.Customer, fullData.System)
 	if err != nil {
 		return nil, fmt.Errorf("failed to render full report: %w", err)
 	}
+	content = addCitationFootnotes(content, fullData.Customer.Citations, format)
 
 	outputDir := filepath.Join(g.deps.Config.DataDir, g.deps.Config.Report.OutputDir)
 
@@ -140,11 +158,13 @@ func (g *Generator) GenerateFullReport(ctx context.Context, format models.Report
 		FilePath:    g.generateMarkdownFilePath(models.TypeFull, outputDir),
 		GeneratedAt: time.Now(),
 		Metadata: models.ReportMeta{
-			Title:            g.getReportTitle("Complete Portfolio & System Report"),
-			Description:      "Comprehensive analysis combining portfolio insights and system diagnostics",
-			DataSources:      dataSources,
-			GenerationTimeMs: time.Since(startTime).Milliseconds(),
-			Version:          "1.0.0",
+			Title:             g.getReportTitle("Complete Portfolio & System Report"),
+			Description:       "Comprehensive analysis combining portfolio insights and system diagnostics",
+			DataSources:       dataSources,
+			GenerationTimeMs:  time.Since(startTime).Milliseconds(),
+			Version:           "1.0.0",
+			EngineVersion:     version.Engine,
+			FilteredHeadlines: filteredHeadlines,
 		},
 	}
 
@@ -235,7 +255,24 @@ func (g *Generator) generateMarkdownFilePath(reportType models.ReportType, outpu
 	return filepath.Join(outputDir, filename)
 }
 
-func GenerateReport(fullData *models.FullReportData, outputDir, format string) error {
+// Options tunes GenerateReportWithOptions
+type Options struct {
+	// Explain annotates figures with the tool and fetch time they came from
+	Explain bool
+	// IncludeReasoning keeps the reasoning summaries of reasoning models in the
+	// system section; they are dropped otherwise
+	IncludeReasoning bool
+	// HoldingsSort is the display order of the holdings (weight_desc when empty)
+	HoldingsSort models.HoldingsSort
+}
+
+// GenerateReport writes a report of reportType (full when empty) for fullData to outputDir
+func GenerateReport(fullData *models.FullReportData, outputDir, format string, reportType models.ReportType) error {
+	return GenerateReportWithOptions(fullData, outputDir, format, reportType, Options{})
+}
+
+// GenerateReportWithOptions is GenerateReport with extra rendering options
+func GenerateReportWithOptions(fullData *models.FullReportData, outputDir, format string, reportType models.ReportType, opts Options) error {
 	// Minimal dependencies for file writing
 	fsys := fs.New(outputDir)
 	deps := Dependencies{
@@ -243,14 +280,49 @@ func GenerateReport(fullData *models.FullReportData, outputDir, format string) e
 		DataBag:    nil, // Not used here
 		FileSystem: fsys,
 	}
-	gen := &Generator{deps: deps}
+	gen := &Generator{deps: deps, holdingsSort: opts.HoldingsSort}
+
+	if reportType == "" {
+		reportType = models.TypeFull
+	}
+	if !opts.IncludeReasoning && fullData.System != nil && len(fullData.System.ReasoningTraces) > 0 {
+		system := *fullData.System
+		system.ReasoningTraces = nil
+		fullData = &models.FullReportData{Customer: fullData.Customer, System: &system}
+	}
 
 	// Render report content (markdown only for now)
-	content, _, err := gen.renderFullReport(fullData.Customer, fullData.System)
+	var (
+		content string
+		out     map[string]any
+		err     error
+	)
+	switch reportType {
+	case models.TypeCustomer:
+		content, _, err = gen.renderCustomerReport(fullData.Customer)
+		out = map[string]any{"customer": fullData.Customer}
+	case models.TypeSystem:
+		content, _, err = gen.renderSystemReport(fullData.System)
+		out = map[string]any{"system": fullData.System}
+	case models.TypeFull:
+		content, _, err = gen.renderFullReport(fullData.Customer, fullData.System)
+		out = map[string]any{
+			"customer": fullData.Customer,
+			"system":   fullData.System,
+		}
+	default:
+		return fmt.Errorf("unsupported report type: %s", reportType)
+	}
 	if err != nil {
 		return err
 	}
 
+	if opts.Explain && fullData.System != nil {
+		var sources []FigureSource
+		content, sources = explainFigures(content, fullData.System.ToolComputations)
+		out["provenance"] = sources
+	}
+
 	// Determine file extension
 	ext := ".md"
 	switch format {
@@ -260,15 +332,11 @@ func GenerateReport(fullData *models.FullReportData, outputDir, format string) e
 		ext = ".json"
 	}
 
-	filename := "full_report_" + time.Now().Format("20060102_150405") + ext
+	filename := string(reportType) + "_report_" + time.Now().Format("20060102_150405") + ext
 
 	// Write file
 	if format == "json" {
 		// Marshal as JSON
-		out := map[string]any{
-			"customer": fullData.Customer,
-			"system":   fullData.System,
-		}
 		data, err := json.MarshalIndent(out, "", "  ")
 		if err != nil {
 			return err
@@ -0,0 +1,313 @@
+package report
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestRenderCustomerReport_Alerts(t *testing.T) {
+	gen := &Generator{}
+	data := &models.CustomerReportData{
+		GeneratedAt: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		Alerts: []models.Alert{
+			{Type: models.AlertPosition, Severity: models.AlertCritical, Subject: "NVDA", Message: "Position NVDA is 24.0% of the portfolio (limit 20.0%)"},
+			{Type: models.AlertSector, Severity: models.AlertWarning, Subject: "Technology", Message: "Sector Technology is 35.0% of the portfolio (limit 30.0%)"},
+		},
+	}
+
+	content, _, err := gen.renderCustomerReport(data)
+	require.NoError(t, err)
+
+	alerts := strings.Index(content, "## ⚠️ Concentration Alerts")
+	require.GreaterOrEqual(t, alerts, 0)
+	assert.Less(t, alerts, strings.Index(content, "## Executive Summary"), "alerts come first")
+	assert.Contains(t, content, "- **🔴 Critical:** Position NVDA is 24.0% of the portfolio (limit 20.0%)")
+	assert.Contains(t, content, "- **🟠 Warning:** Sector Technology is 35.0% of the portfolio (limit 30.0%)")
+
+	data.Alerts = nil
+	content, _, err = gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "Concentration Alerts")
+}
+
+func TestRenderCustomerReport_StaleHoldings(t *testing.T) {
+	gen := &Generator{}
+	data := &models.CustomerReportData{
+		GeneratedAt: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		Portfolio:   &models.Portfolio{AsOf: "2026-10-16", BaseCurrency: "USD"},
+		StaleHoldings: []models.NormalizedHolding{
+			{Symbol: "BTC", WeightPercent: 12.5, PricedAt: time.Date(2026, 10, 6, 18, 0, 0, 0, time.UTC), IsStale: true},
+		},
+	}
+
+	content, _, err := gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "**Stale valuations:**")
+	assert.Contains(t, content, "> - **BTC** (12.5% of the portfolio), priced October 6, 2026 18:00 UTC")
+
+	data.StaleHoldings = nil
+	content, _, err = gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "Stale valuations")
+}
+
+func TestRenderCustomerReport_Sleeves(t *testing.T) {
+	gen := &Generator{}
+	data := &models.CustomerReportData{
+		GeneratedAt:      time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		HoldingsCurrency: "EUR",
+		Sleeves: []models.Sleeve{
+			{Name: models.SleeveEquity, Value: 6000, WeightPercent: 60, HoldingsCount: 3, AssetClasses: map[string]float64{"stock": 20, "etf": 40}},
+			{Name: models.SleeveFixedIncome, Value: 4000, WeightPercent: 40, HoldingsCount: 1, AssetClasses: map[string]float64{"bond": 40}},
+		},
+	}
+
+	content, _, err := gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "### Sleeves")
+	assert.Contains(t, content, "| Sleeve | Weight | Value (EUR) | Holdings | Breakdown |")
+	assert.Contains(t, content, "| **Equity** | 60.0% | 6000.00 | 3 | ETF 40.0%, Stock 20.0% |")
+	assert.Contains(t, content, "| **Fixed Income** | 40.0% | 4000.00 | 1 | Bond 40.0% |")
+
+	data.Sleeves = nil
+	content, _, err = gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "### Sleeves")
+}
+
+func TestRenderCustomerReport_Health(t *testing.T) {
+	gen := &Generator{}
+	data := &models.CustomerReportData{
+		GeneratedAt: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		Health: &models.HealthScore{
+			Score:      72,
+			Components: map[string]float64{models.HealthDiversification: 60, models.HealthConcentration: 80, models.HealthCurrency: 100},
+			Weights:    map[string]float64{models.HealthDiversification: 40, models.HealthConcentration: 40, models.HealthCurrency: 20},
+		},
+		Alerts: []models.Alert{{Severity: models.AlertWarning, Message: "NVDA is 12.0% of the portfolio"}},
+	}
+
+	content, _, err := gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "## 🩺 Portfolio Health: 72/100")
+	assert.Contains(t, content, "| Concentration | 80/100 | 40% |")
+	assert.Contains(t, content, "| Currency | 100/100 | 20% |")
+	assert.Less(t, strings.Index(content, "Portfolio Health"), strings.Index(content, "Concentration Alerts"))
+
+	data.Health = nil
+	content, _, err = gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "Portfolio Health")
+}
+
+func TestRenderCustomerReport_Allocation(t *testing.T) {
+	gen := &Generator{}
+	data := &models.CustomerReportData{
+		GeneratedAt:      time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		HoldingsCurrency: "USD",
+		Allocation: &models.AllocationComparison{
+			Profile: models.RiskConservative,
+			Gaps: []models.AllocationGap{
+				{Sleeve: models.SleeveAlternatives, ActualPct: 80, TargetPct: 5, GapPct: 75, Amount: -7500, Status: models.AllocationOverweight},
+				{Sleeve: models.SleeveFixedIncome, ActualPct: 0, TargetPct: 55, GapPct: -55, Amount: 5500, Status: models.AllocationUnderweight},
+				{Sleeve: models.SleeveCash, ActualPct: 5, TargetPct: 10, GapPct: -5, Amount: 500, Status: models.AllocationOnTarget},
+			},
+		},
+	}
+
+	content, _, err := gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "### Recommended Allocation (conservative profile)")
+	assert.Contains(t, content, "| Sleeve | Actual | Recommended | Gap | To Trade (USD) | Status |")
+	assert.Contains(t, content, "| **Alternatives** | 80.0% | 5.0% | +75.0 pts | -7500.00 | 🔺 Overweight |")
+	assert.Contains(t, content, "| **Fixed Income** | 0.0% | 55.0% | -55.0 pts | +5500.00 | 🔻 Underweight |")
+	assert.Contains(t, content, "| **Cash** | 5.0% | 10.0% | -5.0 pts | +500.00 | ✅ On target |")
+
+	data.Allocation = nil
+	content, _, err = gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.NotContains(t, content, "Recommended Allocation")
+}
+
+func TestRenderCustomerReport_Rebalance(t *testing.T) {
+	gen := &Generator{}
+	data := &models.CustomerReportData{
+		GeneratedAt:      time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		HoldingsCurrency: "USD",
+		Rebalance: &models.RebalancePlan{
+			TotalValue: 10000,
+			Trades: []models.Trade{
+				{Symbol: "BTC", Side: models.TradeSell, Quantity: 0.1, Price: 60000, Value: 6000, Cost: 30, Benefit: 120, Warnings: []models.TaxWarning{{
+					Symbol: "BTC", Period: "short_term", Acquired: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC), Quantity: 0.1, Gain: 500, DaysLeft: 200,
+					Message: "gains on lots held a year or less are taxed as ordinary income (200 days left)",
+				}}},
+				{Symbol: "VOO", Side: models.TradeBuy, Quantity: 4, Price: 500, Value: 2000, Cost: 2, Benefit: 40},
+			},
+			Cost:         32,
+			Benefit:      160,
+			RealizedGain: -150,
+			Tax:          -55.5,
+			TaxAware:     true,
+			Suppressed:   []models.Trade{{Symbol: "AAPL", Side: models.TradeBuy, Quantity: 0.3, Price: 200, Value: 60, SuppressedReason: models.SuppressedMinTrade}},
+			Targets:      map[string]float64{"BTC": 5, "VOO": 40},
+			Weights:      map[string]float64{"BTC": 5, "VOO": 40},
+			CashAfter:    4000,
+			Unreachable:  []string{models.SleeveFixedIncome},
+			Locked:       []models.LockedPosition{{Symbol: "AAPL", WeightPct: 30, TargetPct: 10}},
+		},
+	}
+
+	content, _, err := gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "### Rebalancing Plan")
+	assert.Contains(t, content, "| Sell | BTC | 0.1 | 60000.00 | 6000.00 | 30.00 |")
+	assert.Contains(t, content, "| Buy | VOO | 4 | 500.00 | 2000.00 | 2.00 |")
+	assert.Contains(t, content, "- **Turnover:** 8000.00, leaving 4000.00 in cash")
+	assert.Contains(t, content, "- **Estimated costs:** 32.00 in spreads and commissions, for an expected 160.00 of tracking error avoided")
+	assert.Contains(t, content, "- **Taxes:** the sells realize -150.00 of capital gains, about -55.50 in tax (lots picked to harvest losses and spare gains)")
+	assert.Contains(t, content, "- ⚠️ **Tax timing (BTC):** selling 0.1 unit(s) bought 2026-05-04 — gains on lots held a year or less are taxed as ordinary income (200 days left)")
+	assert.Contains(t, content, "- **Skipped:** 1 trade(s) too small or too costly to place (AAPL 60.00)")
+	assert.Contains(t, content, "- **No instrument to buy** for Fixed Income")
+	assert.Contains(t, content, "- 🔒 **Do not sell:** AAPL kept as is: 30.0% of the portfolio against a 10.0% target")
+
+	data.Rebalance.Trades = nil
+	content, _, err = gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "_No trade needed")
+}
+
+func TestRenderCustomerReport_AssetLocation(t *testing.T) {
+	gen := &Generator{}
+	data := &models.CustomerReportData{
+		GeneratedAt: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+		Rebalance: &models.RebalancePlan{
+			TotalValue: 20000,
+			Trades: []models.Trade{
+				{Account: "Brokerage", Symbol: "BND", Side: models.TradeSell, Quantity: 50, Price: 80, Value: 4000, Cost: 3},
+				{Account: "IRA", Symbol: "BND", Side: models.TradeBuy, Quantity: 100, Price: 80, Value: 8000, Cost: 5},
+			},
+			Accounts: []models.AccountAllocation{
+				{Account: "Brokerage", Treatment: models.TaxTaxable, Value: 10000, Targets: map[string]float64{"VOO": 100}},
+				{Account: "IRA", Wrapper: "ira", Treatment: models.TaxDeferred, Value: 10000, Targets: map[string]float64{"VOO": 20, "BND": 80}},
+			},
+		},
+	}
+
+	content, _, err := gen.renderCustomerReport(data)
+	require.NoError(t, err)
+	assert.Contains(t, content, "| Side | Account | Symbol | Quantity | Price | Value | Est. Cost |")
+	assert.Contains(t, content, "| Buy | IRA | BND | 100 | 80.00 | 8000.00 | 5.00 |")
+	assert.Contains(t, content, "- 🏦 **Brokerage** (taxable, 10000.00): VOO 100.0%")
+	assert.Contains(t, content, "- 🏦 **IRA** (tax-deferred, 10000.00): BND 80.0%, VOO 20.0%")
+}
+
+func TestLoadSystemData_StaleFXRates(t *testing.T) {
+	p := models.Portfolio{
+		AsOf:         "2026-10-16",
+		BaseCurrency: "USD",
+		Accounts: []models.Account{{Holdings: []models.Holding{
+			{Ticker: "CW8", Quantity: 1, CostBasis: 500, Currency: "EUR", Type: models.ETF},
+			{Ticker: "VWRL", Quantity: 1, CostBasis: 100, Currency: "GBP", Type: models.ETF},
+		}}},
+	}
+	rates := []models.NormalizedCurrencyRate{
+		{FromCurrency: "EUR", ToCurrency: "USD", ExchangeRate: 1.08, AsOf: time.Date(2026, 10, 9, 17, 0, 0, 0, time.UTC)},
+		{FromCurrency: "GBP", ToCurrency: "USD", ExchangeRate: 1.35, AsOf: time.Date(2026, 10, 15, 17, 0, 0, 0, time.UTC)},
+	}
+
+	cases := []struct {
+		name         string
+		maxFXAge     time.Duration
+		wantWarnings int
+	}{
+		{name: "fresh rates", maxFXAge: 30 * 24 * time.Hour},
+		{name: "stale rate warns", maxFXAge: 72 * time.Hour, wantWarnings: 1},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			n, err := p.NormalizeWith(models.NormalizeOptions{CurrencyRates: rates, MaxFXAge: c.maxFXAge})
+			require.NoError(t, err)
+			sb := bag.NewSharedBag()
+			sb.Set(bag.KPortfolioNormalizedForAI, n)
+
+			data, err := NewBagLoader(nil).LoadSystemData(context.Background(), sb)
+			require.NoError(t, err)
+			require.Len(t, data.Warnings, c.wantWarnings)
+			require.Len(t, data.FXRates, 2, "rate timestamps are recorded")
+			assert.Equal(t, rates[0].AsOf, data.FXRates[0].AsOf)
+
+			content, _, err := (&Generator{}).renderSystemReport(data)
+			require.NoError(t, err)
+			assert.Contains(t, content, "| EUR/USD | 1.08 | 2026-10-09 17:00 UTC")
+			if c.wantWarnings > 0 {
+				assert.Contains(t, data.Warnings[0], "FX rate EUR/USD (1.08) dates from 2026-10-09 17:00 UTC")
+				assert.Contains(t, content, "## ⚠️ Data Warnings")
+				assert.Contains(t, content, "2026-10-09 17:00 UTC ⚠️ stale")
+			} else {
+				assert.NotContains(t, content, "Data Warnings")
+			}
+		})
+	}
+
+	t.Run("error policy", func(t *testing.T) {
+		_, err := p.NormalizeWith(models.NormalizeOptions{CurrencyRates: rates, MaxFXAge: 72 * time.Hour, FXStalePolicy: models.FXStaleError})
+		assert.ErrorIs(t, err, models.ErrStaleFXRates)
+		assert.ErrorContains(t, err, "EUR/USD as of 2026-10-09T17:00:00Z")
+	})
+}
+
+func TestGenerateReportWithOptions_Reasoning(t *testing.T) {
+	cases := []struct {
+		name string
+		opts Options
+		want bool
+	}{
+		{name: "dropped by default", opts: Options{}},
+		{name: "included on request", opts: Options{IncludeReasoning: true}, want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir := t.TempDir()
+			system := &models.SystemReportData{
+				GeneratedAt: time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+				ReasoningTraces: []models.ReasoningTrace{{
+					Model:      "gpt-5-mini",
+					ResponseID: "resp_1",
+					Summary:    "Weighed the equity tilt.",
+					CreatedAt:  time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+				}},
+			}
+			fullData := &models.FullReportData{System: system}
+
+			require.NoError(t, GenerateReportWithOptions(fullData, dir, "markdown", models.TypeSystem, c.opts))
+			assert.Len(t, system.ReasoningTraces, 1, "input data is left untouched")
+
+			files, err := filepath.Glob(filepath.Join(dir, "system_report_*.md"))
+			require.NoError(t, err)
+			require.Len(t, files, 1)
+			content, err := os.ReadFile(files[0])
+			require.NoError(t, err)
+
+			if c.want {
+				assert.Contains(t, string(content), "### 🧠 Model Reasoning")
+				assert.Contains(t, string(content), "**gpt-5-mini** (08:00:00, `resp_1`):")
+				assert.Contains(t, string(content), "Weighed the equity tilt.")
+			} else {
+				assert.NotContains(t, string(content), "Model Reasoning")
+				assert.NotContains(t, string(content), "Weighed")
+			}
+		})
+	}
+}
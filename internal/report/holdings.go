@@ -0,0 +1,73 @@
+package report
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// sortHoldings returns a copy of holdings in the display order of mode; an unknown
+// or empty mode sorts by weight. Ties are broken by symbol so the order is stable.
+func sortHoldings(holdings []models.NormalizedHolding, mode models.HoldingsSort) []models.NormalizedHolding {
+	out := slices.Clone(holdings)
+	bySymbol := func(a, b models.NormalizedHolding) int {
+		return cmp.Or(cmp.Compare(strings.ToUpper(a.Symbol), strings.ToUpper(b.Symbol)), cmp.Compare(a.Name, b.Name))
+	}
+	byWeight := func(a, b models.NormalizedHolding) int {
+		return cmp.Or(cmp.Compare(b.WeightPercent, a.WeightPercent), bySymbol(a, b))
+	}
+
+	switch mode {
+	case models.HoldingsSortAlpha:
+		slices.SortStableFunc(out, bySymbol)
+	case models.HoldingsSortValueDesc:
+		slices.SortStableFunc(out, func(a, b models.NormalizedHolding) int {
+			return cmp.Or(cmp.Compare(b.Value, a.Value), bySymbol(a, b))
+		})
+	case models.HoldingsSortSector:
+		slices.SortStableFunc(out, func(a, b models.NormalizedHolding) int {
+			// holdings without a sector come last
+			if (a.Sector == "") != (b.Sector == "") {
+				if a.Sector == "" {
+					return 1
+				}
+				return -1
+			}
+			return cmp.Or(cmp.Compare(strings.ToLower(a.Sector), strings.ToLower(b.Sector)), byWeight(a, b))
+		})
+	default:
+		slices.SortStableFunc(out, byWeight)
+	}
+	return out
+}
+
+// acronyms are labels that title case would spell wrong
+var acronyms = map[string]string{"etf": "ETF"}
+
+// sleeveLabel turns a sleeve or asset class name such as fixed_income into Fixed Income
+func sleeveLabel(name string) string {
+	if label, ok := acronyms[name]; ok {
+		return label
+	}
+	return cases.Title(language.Und).String(strings.ReplaceAll(name, "_", " "))
+}
+
+// sleeveBreakdown lists the asset classes of a sleeve, largest first, e.g.
+// "Stock 40.0%, ETF 20.0%"
+func sleeveBreakdown(classes map[string]float64) string {
+	names := slices.SortedFunc(maps.Keys(classes), func(a, b string) int {
+		return cmp.Or(cmp.Compare(classes[b], classes[a]), cmp.Compare(a, b))
+	})
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, fmt.Sprintf("%s %.1f%%", sleeveLabel(name), classes[name]))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+func TestRenderCustomerReport_HoldingsSort(t *testing.T) {
+	holdings := []models.NormalizedHolding{
+		{Symbol: "VOO", Name: "Vanguard S&P 500 ETF", WeightPercent: 30, Value: 30000},
+		{Symbol: "BND", Name: "Vanguard Total Bond Market ETF", Sector: "Fixed Income", WeightPercent: 20, Value: 20000},
+		{Symbol: "AAPL", Name: "Apple Inc.", Sector: "Technology", WeightPercent: 12.5, Value: 12500},
+		{Symbol: "NVDA", Name: "NVIDIA Corp.", Sector: "Technology", WeightPercent: 25, Value: 25000},
+		{Symbol: "JNJ", Name: "Johnson & Johnson", Sector: "Healthcare", WeightPercent: 12.5, Value: 12510}, // same rounded weight as AAPL, larger value
+	}
+
+	for _, mode := range models.HoldingsSorts {
+		t.Run(string(mode), func(t *testing.T) {
+			gen := &Generator{holdingsSort: mode}
+			data := &models.CustomerReportData{
+				GeneratedAt:      time.Date(2026, 10, 16, 8, 0, 0, 0, time.UTC),
+				Holdings:         holdings,
+				HoldingsCurrency: "EUR",
+			}
+
+			content, _, err := gen.renderCustomerReport(data)
+			require.NoError(t, err)
+			assert.Equal(t, "VOO", data.Holdings[0].Symbol, "input data is left untouched")
+
+			got := holdingsSection(t, content)
+			golden := filepath.Join("testdata", "holdings_"+string(mode)+".golden.md")
+			if *update {
+				require.NoError(t, os.WriteFile(golden, []byte(got), 0o644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), got)
+		})
+	}
+
+	t.Run("default is weight descending", func(t *testing.T) {
+		assert.Equal(t, sortHoldings(holdings, models.HoldingsSortWeightDesc), sortHoldings(holdings, ""))
+	})
+}
+
+// holdingsSection extracts the holdings table of a rendered customer report
+func holdingsSection(t *testing.T, content string) string {
+	t.Helper()
+	start := strings.Index(content, "### Holdings")
+	require.GreaterOrEqual(t, start, 0, "no holdings section")
+	section := content[start:]
+	if end := strings.Index(section, "\n\n\n"); end >= 0 {
+		section = section[:end]
+	}
+	return strings.TrimSpace(section) + "\n"
+}
@@ -0,0 +1,67 @@
+package report
+
+import (
+	"sort"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// defaultNewsRelevanceThreshold is used when the report config does not set one
+const defaultNewsRelevanceThreshold = 0.3
+
+// filterHeadlines drops headlines whose relevance is below threshold, keeps the most
+// relevant first and caps the result to maxItems (0 = unlimited). It returns the kept
+// headlines and how many were dropped for low relevance.
+func filterHeadlines(items []models.NormalizedNewsItem, threshold float64, maxItems int) ([]models.NormalizedNewsItem, int) {
+	kept := make([]models.NormalizedNewsItem, 0, len(items))
+	for _, it := range items {
+		if it.Relevance < threshold {
+			continue
+		}
+		kept = append(kept, it)
+	}
+	filtered := len(items) - len(kept)
+
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Relevance > kept[j].Relevance })
+	if maxItems > 0 && len(kept) > maxItems {
+		kept = kept[:maxItems]
+	}
+	return kept, filtered
+}
+
+// filterNews applies the configured relevance threshold and headline cap to the
+// news context in the customer data and returns how many headlines were filtered
+func (g *Generator) filterNews(data *models.CustomerReportData) int {
+	if data == nil {
+		return 0
+	}
+
+	threshold := defaultNewsRelevanceThreshold
+	maxItems := 0
+	if g.deps.Config != nil {
+		if t := g.deps.Config.Report.NewsRelevanceThreshold; t != nil {
+			threshold = *t
+		}
+		maxItems = g.deps.Config.Report.MaxHeadlines
+	}
+
+	switch news := data.NewsAnalyzed.(type) {
+	case *models.NormalizedNewsContext:
+		if news == nil {
+			return 0
+		}
+		// copy so the shared bag value is left untouched
+		filteredCtx := *news
+		var filtered int
+		filteredCtx.RecentHeadlines, filtered = filterHeadlines(news.RecentHeadlines, threshold, maxItems)
+		data.NewsAnalyzed = &filteredCtx
+		return filtered
+	case models.NormalizedNewsContext:
+		var filtered int
+		news.RecentHeadlines, filtered = filterHeadlines(news.RecentHeadlines, threshold, maxItems)
+		data.NewsAnalyzed = news
+		return filtered
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,75 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/nativeutils"
+)
+
+func TestFilterNews(t *testing.T) {
+	mixed := []models.NormalizedNewsItem{
+		{Title: "Fed holds rates", Relevance: 0.9},
+		{Title: "Celebrity buys yacht", Relevance: 0.05},
+		{Title: "Apple earnings beat", Relevance: 0.7},
+		{Title: "Local bakery opens", Relevance: 0.29},
+		{Title: "Oil prices edge up", Relevance: 0.3},
+		{Title: "Semiconductor tariffs", Relevance: 0.5},
+	}
+
+	cases := []struct {
+		name         string
+		report       config.ReportConfig
+		want         []string
+		wantFiltered int
+	}{
+		{
+			name:         "default threshold",
+			want:         []string{"Fed holds rates", "Apple earnings beat", "Semiconductor tariffs", "Oil prices edge up"},
+			wantFiltered: 2,
+		},
+		{
+			name:         "custom threshold",
+			report:       config.ReportConfig{NewsRelevanceThreshold: nativeutils.Ptr(0.6)},
+			want:         []string{"Fed holds rates", "Apple earnings beat"},
+			wantFiltered: 4,
+		},
+		{
+			name:         "capped keeps most relevant",
+			report:       config.ReportConfig{MaxHeadlines: 2},
+			want:         []string{"Fed holds rates", "Apple earnings beat"},
+			wantFiltered: 2,
+		},
+		{
+			name:         "zero threshold keeps everything",
+			report:       config.ReportConfig{NewsRelevanceThreshold: nativeutils.Ptr(0.0)},
+			want:         []string{"Fed holds rates", "Apple earnings beat", "Semiconductor tariffs", "Oil prices edge up", "Local bakery opens", "Celebrity buys yacht"},
+			wantFiltered: 0,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			g := &Generator{deps: Dependencies{Config: &config.Config{Report: c.report}}}
+			news := &models.NormalizedNewsContext{OverallSentiment: "neutral", RecentHeadlines: mixed}
+			data := &models.CustomerReportData{NewsAnalyzed: news}
+
+			filtered := g.filterNews(data)
+
+			got, ok := data.NewsAnalyzed.(*models.NormalizedNewsContext)
+			assert.True(t, ok)
+			titles := make([]string, 0, len(got.RecentHeadlines))
+			for _, h := range got.RecentHeadlines {
+				titles = append(titles, h.Title)
+			}
+			assert.Equal(t, c.want, titles)
+			assert.Equal(t, c.wantFiltered, filtered)
+			assert.Equal(t, "neutral", got.OverallSentiment)
+			// the bag value is not modified
+			assert.Len(t, news.RecentHeadlines, len(mixed))
+		})
+	}
+}
@@ -24,6 +24,12 @@ func (g *Generator) renderCustomerReport(data *models.CustomerReportData) (strin
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to get customer template: %w", err)
 	}
+	if len(data.Holdings) > 1 {
+		// sort a copy, the caller's data is left untouched
+		sorted := *data
+		sorted.Holdings = sortHoldings(data.Holdings, g.holdingsSort)
+		data = &sorted
+	}
 
 	var buf bytes.Buffer
 	if err := tmpl.Execute(&buf, data); err != nil {
@@ -151,6 +157,8 @@ func (g *Generator) getCustomerTemplate() (*template.Template, error) {
 		"formatInvestmentResearch": func(v interface{}) string {
 			return g.formatInvestmentResearchData(v)
 		},
+		"sleeveLabel":     sleeveLabel,
+		"sleeveBreakdown": sleeveBreakdown,
 		"formatWebSearchData": func(toolName string, arguments string, result string) string {
 			return g.formatWebSearchData(toolName, arguments, result)
 		},
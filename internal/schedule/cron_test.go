@@ -0,0 +1,124 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron(t *testing.T) {
+	cases := []struct {
+		name    string
+		expr    string
+		match   []string // times (RFC3339) that must match
+		noMatch []string // times that must not match
+		wantErr bool
+	}{
+		{
+			name:    "weekly on monday at 8",
+			expr:    "0 8 * * 1",
+			match:   []string{"2026-10-12T08:00:00Z", "2026-10-19T08:00:00Z"},
+			noMatch: []string{"2026-10-12T08:01:00Z", "2026-10-13T08:00:00Z", "2026-10-12T09:00:00Z"},
+		},
+		{
+			name:    "steps and ranges",
+			expr:    "*/15 9-17 * * 1-5",
+			match:   []string{"2026-10-16T09:00:00Z", "2026-10-16T17:45:00Z"},
+			noMatch: []string{"2026-10-16T09:10:00Z", "2026-10-16T18:00:00Z", "2026-10-17T10:00:00Z"},
+		},
+		{
+			name:    "lists and offset step",
+			expr:    "5/20 0,12 1,15 * *",
+			match:   []string{"2026-10-01T00:05:00Z", "2026-10-15T12:45:00Z"},
+			noMatch: []string{"2026-10-01T00:00:00Z", "2026-10-02T00:05:00Z"},
+		},
+		{
+			name:  "sunday as 7",
+			expr:  "0 0 * * 7",
+			match: []string{"2026-10-18T00:00:00Z"},
+		},
+		{
+			name:    "day of month or day of week when both restricted",
+			expr:    "0 0 1 * 1",
+			match:   []string{"2026-10-01T00:00:00Z", "2026-10-05T00:00:00Z"},
+			noMatch: []string{"2026-10-06T00:00:00Z"},
+		},
+		{
+			name:    "macro",
+			expr:    "@weekly",
+			match:   []string{"2026-10-18T00:00:00Z"},
+			noMatch: []string{"2026-10-19T00:00:00Z"},
+		},
+		{name: "too few fields", expr: "0 8 * *", wantErr: true},
+		{name: "minute out of range", expr: "60 8 * * *", wantErr: true},
+		{name: "month zero", expr: "0 0 1 0 *", wantErr: true},
+		{name: "reversed range", expr: "0 17-9 * * *", wantErr: true},
+		{name: "zero step", expr: "*/0 * * * *", wantErr: true},
+		{name: "not a number", expr: "0 eight * * *", wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cron, err := ParseCron(c.expr)
+			if c.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, s := range c.match {
+				assert.True(t, cron.Matches(mustTime(t, s)), "expected %s to match", s)
+			}
+			for _, s := range c.noMatch {
+				assert.False(t, cron.Matches(mustTime(t, s)), "expected %s not to match", s)
+			}
+		})
+	}
+}
+
+func TestCron_Next(t *testing.T) {
+	cases := []struct {
+		name  string
+		expr  string
+		after string
+		want  string
+	}{
+		{name: "later the same day", expr: "0 8 * * 1", after: "2026-10-12T07:30:00Z", want: "2026-10-12T08:00:00Z"},
+		{name: "strictly after a match", expr: "0 8 * * 1", after: "2026-10-12T08:00:00Z", want: "2026-10-19T08:00:00Z"},
+		{name: "seconds are ignored", expr: "*/5 * * * *", after: "2026-10-16T10:04:59Z", want: "2026-10-16T10:05:00Z"},
+		{name: "next month", expr: "30 6 1 * *", after: "2026-10-16T00:00:00Z", want: "2026-11-01T06:30:00Z"},
+		{name: "next year", expr: "0 0 1 1 *", after: "2026-10-16T00:00:00Z", want: "2027-01-01T00:00:00Z"},
+		{name: "leap day", expr: "0 0 29 2 *", after: "2026-10-16T00:00:00Z", want: "2028-02-29T00:00:00Z"},
+		{name: "never matches", expr: "0 0 30 2 *", after: "2026-10-16T00:00:00Z", want: ""},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cron, err := ParseCron(c.expr)
+			require.NoError(t, err)
+			got := cron.Next(mustTime(t, c.after))
+			if c.want == "" {
+				assert.True(t, got.IsZero(), "got %s", got)
+				return
+			}
+			assert.Equal(t, mustTime(t, c.want), got)
+		})
+	}
+}
+
+func TestCron_NextKeepsLocation(t *testing.T) {
+	loc := time.FixedZone("IST", 5*3600+1800)
+	cron, err := ParseCron("0 8 * * *")
+	require.NoError(t, err)
+
+	got := cron.Next(time.Date(2026, 10, 16, 8, 30, 0, 0, loc))
+	assert.Equal(t, time.Date(2026, 10, 17, 8, 0, 0, 0, loc), got)
+}
+
+func mustTime(t *testing.T, s string) time.Time {
+	t.Helper()
+	v, err := time.Parse(time.RFC3339, s)
+	require.NoError(t, err)
+	return v
+}
@@ -0,0 +1,246 @@
+package schedule
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// AnalysisTypes are the analysis types that can be scheduled; only the risk
+// pipeline has an engine registry so far
+var AnalysisTypes = []models.AnalysisType{
+	models.AnalysisRisk,
+}
+
+// Schedule is a recurring analysis
+type Schedule struct {
+	ID        string              `json:"id"`
+	Cron      string              `json:"cron"`
+	Type      models.AnalysisType `json:"type"`
+	Portfolio string              `json:"portfolio"`
+	Formats   []string            `json:"formats,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
+	LastRun   time.Time           `json:"last_run,omitzero"`
+	LastError string              `json:"last_error,omitempty"`
+}
+
+// NextRun returns the first run time after the last run (or creation)
+func (s Schedule) NextRun() (time.Time, error) {
+	c, err := ParseCron(s.Cron)
+	if err != nil {
+		return time.Time{}, err
+	}
+	from := s.CreatedAt
+	if s.LastRun.After(from) {
+		from = s.LastRun
+	}
+	// evaluated in the local time zone, whatever offset was persisted
+	return c.Next(from.Local()), nil
+}
+
+// Due reports whether a run was scheduled since the last run. Runs missed while
+// nothing was checking collapse into a single due run.
+func (s Schedule) Due(now time.Time) bool {
+	next, err := s.NextRun()
+	if err != nil || next.IsZero() {
+		return false
+	}
+	return !next.After(now)
+}
+
+// Store persists schedules as a JSON file
+type Store struct {
+	fs   fs.FS
+	path string
+}
+
+// NewStore creates a store backed by the file at path
+func NewStore(fsys fs.FS, path string) *Store {
+	return &Store{fs: fsys, path: path}
+}
+
+// Load returns the persisted schedules; a missing file means no schedules
+func (st *Store) Load() ([]Schedule, error) {
+	data, err := st.fs.ReadFile(st.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schedules: %w", err)
+	}
+
+	var schedules []Schedule
+	if err := json.Unmarshal(data, &schedules); err != nil {
+		return nil, fmt.Errorf("failed to parse schedules %s: %w", st.path, err)
+	}
+	return schedules, nil
+}
+
+// Save replaces the persisted schedules, writing through a temp file
+func (st *Store) Save(schedules []Schedule) error {
+	data, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schedules: %w", err)
+	}
+	if err := fs.EnsureDir(st.fs, filepath.Dir(st.path)); err != nil {
+		return fmt.Errorf("failed to create schedules directory: %w", err)
+	}
+
+	tmp := st.path + ".tmp"
+	if err := st.fs.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write schedules: %w", err)
+	}
+	if err := st.fs.Rename(tmp, st.path); err != nil {
+		return fmt.Errorf("failed to write schedules: %w", err)
+	}
+	return nil
+}
+
+// RunFunc executes one scheduled analysis
+type RunFunc func(ctx context.Context, s Schedule) error
+
+// Clock returns the current time
+type Clock func() time.Time
+
+// Option configures a Scheduler
+type Option func(*Scheduler)
+
+// WithClock replaces the wall clock, mainly for tests
+func WithClock(c Clock) Option { return func(s *Scheduler) { s.now = c } }
+
+// Scheduler manages schedules and runs the due ones
+type Scheduler struct {
+	store *Store
+	run   RunFunc
+	now   Clock
+}
+
+// New creates a scheduler; run may be nil when only managing schedules
+func New(store *Store, run RunFunc, opts ...Option) *Scheduler {
+	s := &Scheduler{store: store, run: run, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Add validates and persists a new schedule, assigning its ID and creation time
+func (s *Scheduler) Add(sch Schedule) (Schedule, error) {
+	if _, err := ParseCron(sch.Cron); err != nil {
+		return Schedule{}, err
+	}
+	if !slices.Contains(AnalysisTypes, sch.Type) {
+		return Schedule{}, fmt.Errorf("unsupported analysis type: %s", sch.Type)
+	}
+	if sch.Portfolio == "" {
+		return Schedule{}, fmt.Errorf("portfolio file is required")
+	}
+
+	schedules, err := s.store.Load()
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	sch.ID = uuid.NewString()[:8]
+	sch.CreatedAt = s.now()
+	sch.LastRun = time.Time{}
+	sch.LastError = ""
+	if err := s.store.Save(append(schedules, sch)); err != nil {
+		return Schedule{}, err
+	}
+	return sch, nil
+}
+
+// Remove deletes the schedule with the given ID
+func (s *Scheduler) Remove(id string) error {
+	schedules, err := s.store.Load()
+	if err != nil {
+		return err
+	}
+	i := slices.IndexFunc(schedules, func(sch Schedule) bool { return sch.ID == id })
+	if i < 0 {
+		return fmt.Errorf("schedule not found: %s", id)
+	}
+	return s.store.Save(slices.Delete(schedules, i, i+1))
+}
+
+// List returns all schedules
+func (s *Scheduler) List() ([]Schedule, error) {
+	return s.store.Load()
+}
+
+// Due returns the schedules due at the current time
+func (s *Scheduler) Due() ([]Schedule, error) {
+	schedules, err := s.store.Load()
+	if err != nil {
+		return nil, err
+	}
+	now := s.now()
+	var due []Schedule
+	for _, sch := range schedules {
+		if sch.Due(now) {
+			due = append(due, sch)
+		}
+	}
+	return due, nil
+}
+
+// Result is the outcome of one scheduled run
+type Result struct {
+	Schedule Schedule
+	Err      error
+}
+
+// RunDue executes every due schedule and records its run time and error.
+// A failed run is not retried until its next scheduled time.
+func (s *Scheduler) RunDue(ctx context.Context) ([]Result, error) {
+	if s.run == nil {
+		return nil, fmt.Errorf("scheduler has no run function")
+	}
+	schedules, err := s.store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	var results []Result
+	for i, sch := range schedules {
+		if !sch.Due(now) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+
+		slog.Info("Running scheduled analysis", "schedule_id", sch.ID, "type", sch.Type, "portfolio", sch.Portfolio)
+		runErr := s.run(ctx, sch)
+		if runErr != nil {
+			slog.Error("Scheduled analysis failed", "schedule_id", sch.ID, "error", runErr)
+		}
+
+		sch.LastRun = now
+		sch.LastError = ""
+		if runErr != nil {
+			sch.LastError = runErr.Error()
+		}
+		schedules[i] = sch
+		results = append(results, Result{Schedule: sch, Err: runErr})
+
+		// persist after each run so a crash does not repeat completed analyses
+		if err := s.store.Save(schedules); err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,159 @@
+package schedule
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestSchedule_Due(t *testing.T) {
+	// created on Wednesday 2026-10-07, runs Mondays at 08:00
+	created := mustTime(t, "2026-10-07T12:00:00Z")
+
+	cases := []struct {
+		name    string
+		lastRun string
+		now     string
+		want    bool
+	}{
+		{name: "before the first run", now: "2026-10-12T07:59:00Z", want: false},
+		{name: "at the first run", now: "2026-10-12T08:00:00Z", want: true},
+		{name: "first run missed", now: "2026-10-14T10:00:00Z", want: true},
+		{name: "already ran this week", lastRun: "2026-10-12T08:03:00Z", now: "2026-10-16T08:00:00Z", want: false},
+		{name: "due again next week", lastRun: "2026-10-12T08:03:00Z", now: "2026-10-19T08:00:00Z", want: true},
+		{name: "several runs missed are due once", lastRun: "2026-10-12T08:03:00Z", now: "2026-11-20T00:00:00Z", want: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := Schedule{Cron: "0 8 * * 1", CreatedAt: created}
+			if c.lastRun != "" {
+				s.LastRun = mustTime(t, c.lastRun)
+			}
+			assert.Equal(t, c.want, s.Due(mustTime(t, c.now)))
+		})
+	}
+}
+
+func TestSchedule_DueInvalidCron(t *testing.T) {
+	s := Schedule{Cron: "bogus", CreatedAt: mustTime(t, "2026-10-07T12:00:00Z")}
+	assert.False(t, s.Due(mustTime(t, "2027-01-01T00:00:00Z")))
+}
+
+// fakeClock is a settable clock
+type fakeClock struct{ now time.Time }
+
+func (f *fakeClock) Now() time.Time { return f.now }
+
+func newTestScheduler(t *testing.T, clock *fakeClock, run RunFunc) *Scheduler {
+	t.Helper()
+	store := NewStore(fs.OS{}, filepath.Join(t.TempDir(), "data", "schedules.json"))
+	return New(store, run, WithClock(clock.Now))
+}
+
+func TestScheduler_RunDue(t *testing.T) {
+	clock := &fakeClock{now: mustTime(t, "2026-10-07T12:00:00Z")}
+	var ran []string
+	failing := map[string]bool{}
+	s := newTestScheduler(t, clock, func(_ context.Context, sch Schedule) error {
+		ran = append(ran, sch.ID)
+		if failing[sch.ID] {
+			return errors.New("boom")
+		}
+		return nil
+	})
+
+	weekly, err := s.Add(Schedule{Cron: "0 8 * * 1", Type: models.AnalysisRisk, Portfolio: "p.json"})
+	require.NoError(t, err)
+	daily, err := s.Add(Schedule{Cron: "@daily", Type: models.AnalysisRisk, Portfolio: "p.json"})
+	require.NoError(t, err)
+	assert.Equal(t, clock.now, weekly.CreatedAt)
+	failing[daily.ID] = true
+
+	// nothing is due right after creation
+	results, err := s.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Thursday: only the daily schedule is due, and it fails
+	clock.now = mustTime(t, "2026-10-08T00:01:00Z")
+	results, err = s.RunDue(context.Background())
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, daily.ID, results[0].Schedule.ID)
+	assert.EqualError(t, results[0].Err, "boom")
+
+	// a failed run is recorded and not retried before its next time
+	results, err = s.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, results)
+
+	// Monday: both are due
+	clock.now = mustTime(t, "2026-10-12T08:00:00Z")
+	failing[daily.ID] = false
+	results, err = s.RunDue(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, []string{daily.ID, weekly.ID, daily.ID}, ran)
+
+	// run state is persisted
+	schedules, err := s.List()
+	require.NoError(t, err)
+	require.Len(t, schedules, 2)
+	for _, sch := range schedules {
+		assert.Equal(t, clock.now, sch.LastRun.UTC())
+		assert.Empty(t, sch.LastError)
+	}
+}
+
+func TestScheduler_Add(t *testing.T) {
+	cases := []struct {
+		name    string
+		sch     Schedule
+		wantErr string
+	}{
+		{name: "valid", sch: Schedule{Cron: "0 8 * * 1", Type: models.AnalysisRisk, Portfolio: "p.json"}},
+		{name: "invalid cron", sch: Schedule{Cron: "0 8 * *", Type: models.AnalysisRisk, Portfolio: "p.json"}, wantErr: "invalid cron expression"},
+		{name: "unknown type", sch: Schedule{Cron: "0 8 * * 1", Type: "astrology", Portfolio: "p.json"}, wantErr: "unsupported analysis type"},
+		{name: "type without registry", sch: Schedule{Cron: "0 8 * * 1", Type: models.AnalysisAllocation, Portfolio: "p.json"}, wantErr: "unsupported analysis type"},
+		{name: "missing portfolio", sch: Schedule{Cron: "0 8 * * 1", Type: models.AnalysisRisk}, wantErr: "portfolio file is required"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			s := newTestScheduler(t, &fakeClock{now: time.Now()}, nil)
+			got, err := s.Add(c.sch)
+			if c.wantErr != "" {
+				assert.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.NotEmpty(t, got.ID)
+
+			schedules, err := s.List()
+			require.NoError(t, err)
+			assert.Len(t, schedules, 1)
+		})
+	}
+}
+
+func TestScheduler_Remove(t *testing.T) {
+	s := newTestScheduler(t, &fakeClock{now: time.Now()}, nil)
+	sch, err := s.Add(Schedule{Cron: "@daily", Type: models.AnalysisRisk, Portfolio: "p.json"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Remove(sch.ID))
+	assert.Error(t, s.Remove(sch.ID))
+
+	schedules, err := s.List()
+	require.NoError(t, err)
+	assert.Empty(t, schedules)
+}
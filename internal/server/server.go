@@ -0,0 +1,180 @@
+// Package server exposes health, metrics and the latest report over HTTP so a
+// long-running deployment can feed dashboards.
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/internal/health"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+)
+
+// shutdownTimeout bounds how long in-flight requests may take once shutdown starts
+const shutdownTimeout = 10 * time.Second
+
+// reportExtensions are the report files served by /report/latest
+var reportExtensions = []string{".md", ".json", ".pdf"}
+
+// BagSource returns the bag to expose, typically the latest saved run
+type BagSource func() (bag.SharedBag, error)
+
+// Server serves /healthz, /metrics and /report/latest
+type Server struct {
+	bags       BagSource
+	reportsDir string
+	http       *http.Server
+}
+
+// New creates a server listening on addr
+func New(addr string, bags BagSource, reportsDir string) *Server {
+	s := &Server{
+		bags:       bags,
+		reportsDir: reportsDir,
+	}
+	s.http = &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return s
+}
+
+// Handler returns the HTTP routes of the server
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /metrics", s.handleMetrics)
+	mux.HandleFunc("GET /report/latest", s.handleLatestReport)
+	return mux
+}
+
+// Run serves until ctx is cancelled, then shuts down gracefully
+func (s *Server) Run(ctx context.Context) error {
+	errCh := make(chan error, 1)
+	go func() {
+		slog.Info("HTTP server listening", "addr", s.http.Addr)
+		errCh <- s.http.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("http server failed: %w", err)
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down HTTP server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := s.http.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("http server shutdown failed: %w", err)
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("http server failed: %w", err)
+	}
+	return nil
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	sb, err := s.bags()
+	if err != nil {
+		writeJSON(w, http.StatusServiceUnavailable, map[string]string{"status": health.StatusDown, "error": err.Error()})
+		return
+	}
+
+	summary := health.Summarize(sb)
+	status := http.StatusOK
+	if summary.Status == health.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, summary)
+}
+
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	sb, err := s.bags()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	health.PrometheusHandler(sb).ServeHTTP(w, r)
+}
+
+// handleLatestReport serves the most recent report; ?format=md|json|pdf restricts
+// the choice to one format
+func (s *Server) handleLatestReport(w http.ResponseWriter, r *http.Request) {
+	exts := reportExtensions
+	if f := r.URL.Query().Get("format"); f != "" {
+		exts = []string{"." + strings.TrimPrefix(strings.ToLower(f), ".")}
+	}
+
+	path, err := latestReport(s.reportsDir, exts)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if ct := mime.TypeByExtension(filepath.Ext(path)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	} else if filepath.Ext(path) == ".md" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+	}
+	w.Header().Set("Content-Disposition", fmt.Sprintf("inline; filename=%q", filepath.Base(path)))
+	http.ServeFile(w, r, path)
+}
+
+// latestReport returns the most recently written report in dir with one of exts
+func latestReport(dir string, exts []string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("no report available: %w", err)
+	}
+
+	var (
+		latest  string
+		latestT time.Time
+	)
+	for _, e := range entries {
+		name := e.Name()
+		// skip the intermediate markdown written while rendering PDFs
+		if e.IsDir() || strings.HasSuffix(name, "_temp.md") || !hasExt(name, exts) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		if latest == "" || info.ModTime().After(latestT) || (info.ModTime().Equal(latestT) && name > latest) {
+			latest, latestT = name, info.ModTime()
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no report available in %s", dir)
+	}
+	return filepath.Join(dir, latest), nil
+}
+
+func hasExt(name string, exts []string) bool {
+	for _, ext := range exts {
+		if strings.EqualFold(filepath.Ext(name), ext) {
+			return true
+		}
+	}
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("Failed to write JSON response", "error", err)
+	}
+}
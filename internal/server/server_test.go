@@ -0,0 +1,163 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/health"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func populatedBag(providerStatus string) BagSource {
+	return func() (bag.SharedBag, error) {
+		sb := bag.NewSharedBag()
+		sb.Set(bag.KToolMetrics, models.ToolMetrics{
+			TotalCalls: 3,
+			ByTool:     map[string]models.ToolStats{"fmp": {Calls: 3, Successes: 3}},
+		})
+		sb.Set(bag.KExternalDataHealth, &models.ExternalDataHealth{
+			Providers: map[string]models.DataProviderHealth{
+				"fmp":  {Name: "fmp", Status: "healthy"},
+				"fred": {Name: "fred", Status: providerStatus},
+			},
+		})
+		return sb, nil
+	}
+}
+
+// reportsDir writes reports with increasing modification times, oldest first
+func reportsDir(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	base := time.Now().Add(-time.Hour)
+	for i, name := range names {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("content of "+name), 0o644))
+		mtime := base.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+	return dir
+}
+
+func get(t *testing.T, h http.Handler, target string) *httptest.ResponseRecorder {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestServer_Healthz(t *testing.T) {
+	cases := []struct {
+		name       string
+		bags       BagSource
+		wantCode   int
+		wantStatus string
+	}{
+		{name: "all healthy", bags: populatedBag("healthy"), wantCode: http.StatusOK, wantStatus: health.StatusHealthy},
+		{name: "degraded provider", bags: populatedBag("degraded"), wantCode: http.StatusOK, wantStatus: health.StatusDegraded},
+		{name: "provider down", bags: populatedBag("down"), wantCode: http.StatusServiceUnavailable, wantStatus: health.StatusDown},
+		{
+			name:       "no saved run",
+			bags:       func() (bag.SharedBag, error) { return nil, errors.New("no saved run") },
+			wantCode:   http.StatusServiceUnavailable,
+			wantStatus: health.StatusDown,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := get(t, New("", c.bags, t.TempDir()).Handler(), "/healthz")
+			assert.Equal(t, c.wantCode, rec.Code)
+
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, c.wantStatus, body["status"])
+		})
+	}
+}
+
+func TestServer_Metrics(t *testing.T) {
+	rec := get(t, New("", populatedBag("healthy"), t.TempDir()).Handler(), "/metrics")
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, health.PrometheusContentType, rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), `mosychlos_tool_calls_total{tool="fmp"} 3`)
+	assert.Contains(t, rec.Body.String(), `mosychlos_provider_healthy{provider="fred",status="healthy"} 1`)
+}
+
+func TestServer_LatestReport(t *testing.T) {
+	dir := reportsDir(t,
+		"full_report_20260101_090000.json",
+		"full_report_20260102_090000.md",
+		"full_report_20260103_090000_temp.md",
+		"notes.txt",
+	)
+	h := New("", populatedBag("healthy"), dir).Handler()
+
+	cases := []struct {
+		name     string
+		target   string
+		wantCode int
+		wantBody string
+	}{
+		{name: "most recent report", target: "/report/latest", wantCode: http.StatusOK, wantBody: "content of full_report_20260102_090000.md"},
+		{name: "restricted to a format", target: "/report/latest?format=json", wantCode: http.StatusOK, wantBody: "content of full_report_20260101_090000.json"},
+		{name: "format without report", target: "/report/latest?format=pdf", wantCode: http.StatusNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			rec := get(t, h, c.target)
+			assert.Equal(t, c.wantCode, rec.Code)
+			if c.wantBody != "" {
+				assert.Equal(t, c.wantBody, rec.Body.String())
+			}
+		})
+	}
+
+	rec := get(t, New("", populatedBag("healthy"), filepath.Join(dir, "missing")).Handler(), "/report/latest")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestServer_RunGracefulShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- New(addr, populatedBag("healthy"), t.TempDir()).Run(ctx) }()
+
+	// wait for the listener
+	require.Eventually(t, func() bool {
+		resp, err := http.Get("http://" + addr + "/healthz")
+		if err != nil {
+			return false
+		}
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}, 2*time.Second, 20*time.Millisecond)
+
+	cancel()
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server did not shut down")
+	}
+}
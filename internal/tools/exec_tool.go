@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+const (
+	// defaultExecTimeout bounds an exec tool run when its config sets no timeout
+	defaultExecTimeout = 30 * time.Second
+	// maxExecStderr caps how much of the command stderr ends up in error messages
+	maxExecStderr = 2048
+)
+
+// ExecTool is a tool backed by an external command: the arguments JSON is written
+// to its stdin and its stdout must hold the JSON result
+type ExecTool struct {
+	cfg config.ExecToolConfig
+}
+
+var _ models.Tool = &ExecTool{}
+
+// NewExecTool creates a tool running the configured command
+func NewExecTool(cfg config.ExecToolConfig) (*ExecTool, error) {
+	if strings.TrimSpace(cfg.Name) == "" {
+		return nil, fmt.Errorf("exec tool: missing name")
+	}
+	if strings.TrimSpace(cfg.Command) == "" {
+		return nil, fmt.Errorf("exec tool %s: missing command", cfg.Name)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultExecTimeout
+	}
+	return &ExecTool{cfg: cfg}, nil
+}
+
+func (t *ExecTool) Name() string {
+	return t.cfg.Name
+}
+
+func (t *ExecTool) Key() bag.Key {
+	return bag.Key(t.cfg.Name)
+}
+
+func (t *ExecTool) Description() string {
+	if t.cfg.Description != "" {
+		return t.cfg.Description
+	}
+	return fmt.Sprintf("External tool running %s", t.cfg.Command)
+}
+
+func (t *ExecTool) IsExternal() bool {
+	return true
+}
+
+func (t *ExecTool) Tags() []string {
+	return []string{"exec", "external"}
+}
+
+func (t *ExecTool) Definition() models.ToolDef {
+	params := t.cfg.Parameters
+	if len(params) == 0 {
+		params = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		}
+	}
+	return &models.CustomToolDef{
+		Type: models.CustomToolDefType,
+		FunctionDef: models.FunctionDef{
+			Name:        t.Name(),
+			Description: t.Description(),
+			Parameters:  params,
+		},
+	}
+}
+
+// Run starts the command, feeds it the arguments JSON and returns its stdout
+// as raw JSON once validated
+func (t *ExecTool) Run(ctx context.Context, args any) (any, error) {
+	// an external command could reach the network, so it does not run in the sandbox
+	if sandbox.Enabled() {
+		return nil, fmt.Errorf("exec tool %s: %w", t.cfg.Name, sandbox.ErrNetworkDisabled)
+	}
+
+	input, err := execInput(args)
+	if err != nil {
+		return nil, fmt.Errorf("exec tool %s: %w", t.cfg.Name, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, t.cfg.Timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, t.cfg.Command, t.cfg.Args...)
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// don't wait forever on pipes held open by children of a killed command
+	cmd.WaitDelay = time.Second
+
+	if err := cmd.Run(); err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("exec tool %s: timed out after %s: %w", t.cfg.Name, t.cfg.Timeout, ctx.Err())
+		}
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("exec tool %s: %w", t.cfg.Name, ctx.Err())
+		}
+		return nil, fmt.Errorf("exec tool %s: command failed: %w%s", t.cfg.Name, err, stderrSuffix(stderr.Bytes()))
+	}
+
+	out := bytes.TrimSpace(stdout.Bytes())
+	if !json.Valid(out) {
+		return nil, fmt.Errorf("exec tool %s: command did not print valid JSON%s", t.cfg.Name, stderrSuffix(stderr.Bytes()))
+	}
+	return json.RawMessage(out), nil
+}
+
+// execInput turns tool arguments into the JSON document written to stdin
+func execInput(args any) ([]byte, error) {
+	switch v := args.(type) {
+	case nil:
+		return []byte("{}"), nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return []byte("{}"), nil
+		}
+		if !json.Valid([]byte(v)) {
+			return nil, fmt.Errorf("arguments are not valid JSON")
+		}
+		return []byte(v), nil
+	case []byte:
+		if !json.Valid(v) {
+			return nil, fmt.Errorf("arguments are not valid JSON")
+		}
+		return v, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode arguments: %w", err)
+		}
+		return b, nil
+	}
+}
+
+func stderrSuffix(stderr []byte) string {
+	msg := truncate(string(stderr), maxExecStderr)
+	if msg == "" {
+		return ""
+	}
+	return ": " + msg
+}
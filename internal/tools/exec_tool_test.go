@@ -0,0 +1,124 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+// writeScript writes an executable shell script into a temp dir and returns its path
+func writeScript(t *testing.T, body string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("exec tool scripts need a POSIX shell")
+	}
+	path := filepath.Join(t.TempDir(), "tool.sh")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+body+"\n"), 0o755))
+	return path
+}
+
+func TestExecTool_Run(t *testing.T) {
+	cases := []struct {
+		name    string
+		script  string
+		timeout time.Duration
+		args    any
+		want    string
+		wantErr string
+	}{
+		{name: "echoes arguments", script: "cat", args: `{"tickers":["AAPL","MSFT"]}`, want: `{"tickers":["AAPL","MSFT"]}`},
+		{name: "encodes non-string arguments", script: "cat", args: map[string]any{"n": 1}, want: `{"n":1}`},
+		{name: "empty arguments", script: "cat", args: "", want: `{}`},
+		{name: "invalid JSON output", script: "echo not json", args: `{}`, wantErr: "did not print valid JSON"},
+		{name: "command failure keeps stderr", script: "echo boom >&2; exit 3", args: `{}`, wantErr: "boom"},
+		{name: "timeout", script: "sleep 5", timeout: 100 * time.Millisecond, args: `{}`, wantErr: "timed out"},
+		{name: "invalid JSON arguments", script: "cat", args: `{nope`, wantErr: "arguments are not valid JSON"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			tool, err := NewExecTool(config.ExecToolConfig{
+				Name:    "echo_tool",
+				Command: writeScript(t, c.script),
+				Timeout: c.timeout,
+			})
+			require.NoError(t, err)
+
+			start := time.Now()
+			got, err := tool.Run(context.Background(), c.args)
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				assert.Less(t, time.Since(start), 3*time.Second)
+				return
+			}
+			require.NoError(t, err)
+			assert.JSONEq(t, c.want, string(got.(json.RawMessage)))
+		})
+	}
+}
+
+func TestExecTool_RunSandbox(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "ran")
+	tool, err := NewExecTool(config.ExecToolConfig{
+		Name:    "curl_tool",
+		Command: writeScript(t, "touch "+marker+"; echo '{}'"),
+	})
+	require.NoError(t, err)
+
+	sandbox.Enable()
+	t.Cleanup(sandbox.Disable)
+
+	_, err = tool.Run(context.Background(), `{}`)
+	require.ErrorIs(t, err, sandbox.ErrNetworkDisabled)
+	assert.NoFileExists(t, marker, "the command must not start in sandbox mode")
+}
+
+func TestNewToolManager_ExecTool(t *testing.T) {
+	sharedBag := bag.NewSharedBag()
+	sharedBag.Set(bag.KEngineRunID, "")
+
+	cfg := &config.Config{
+		DataDir:  t.TempDir(),
+		CacheDir: t.TempDir(),
+		Tools: config.ToolsConfig{
+			Exec: []config.ExecToolConfig{{
+				Name:        "echo_tool",
+				Description: "Echoes its arguments",
+				Command:     writeScript(t, "cat"),
+			}},
+		},
+	}
+
+	m, err := NewToolManager(cfg, sharedBag, nil)
+	require.NoError(t, err)
+
+	tool := m.Get("echo_tool")
+	require.NotNil(t, tool)
+	assert.True(t, tool.IsExternal())
+	assert.Equal(t, "echo_tool", tool.Definition().(*models.CustomToolDef).Name)
+
+	_, err = tool.Run(context.Background(), `{"symbol":"AAPL"}`)
+	require.NoError(t, err)
+
+	// the run is recorded like any built-in tool computation
+	raw, ok := sharedBag.Get(bag.KToolComputations)
+	require.True(t, ok)
+	computations := raw.([]models.ToolComputation)
+	require.Len(t, computations, 1)
+	assert.Equal(t, "echo_tool", computations[0].ToolName)
+	assert.True(t, computations[0].Success)
+	assert.Equal(t, map[string]any{"symbol": "AAPL"}, computations[0].Arguments)
+	assert.Equal(t, map[string]any{"symbol": "AAPL"}, computations[0].Result)
+}
@@ -0,0 +1,349 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/jsonpath"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+const (
+	// defaultHTTPToolTimeout bounds an HTTP tool request when its config sets no timeout
+	defaultHTTPToolTimeout = 30 * time.Second
+	// defaultHTTPToolCacheTTL is used when caching is enabled without a TTL
+	defaultHTTPToolCacheTTL = time.Hour
+	// maxHTTPToolErrorBody caps how much of an error response ends up in error messages
+	maxHTTPToolErrorBody = 512
+)
+
+// HTTPTool is a tool backed by a REST endpoint declared in config
+type HTTPTool struct {
+	cfg     config.HTTPToolConfig
+	method  string
+	url     *template.Template
+	body    *template.Template
+	headers map[string]*template.Template
+	output  map[string]*jsonpath.Path
+	http    *http.Client
+}
+
+var _ models.Tool = &HTTPTool{}
+
+// NewHTTPTool compiles the configured templates and JSONPaths. A nil client uses
+// a sandbox-guarded client with the configured timeout.
+func NewHTTPTool(cfg config.HTTPToolConfig, client *http.Client) (*HTTPTool, error) {
+	if strings.TrimSpace(cfg.Name) == "" {
+		return nil, fmt.Errorf("http tool: missing name")
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("http tool %s: %w", cfg.Name, err)
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultHTTPToolTimeout
+	}
+	if client == nil {
+		client = &http.Client{Timeout: cfg.Timeout, Transport: sandbox.Guard(nil)}
+	}
+
+	t := &HTTPTool{
+		cfg:     cfg,
+		method:  strings.ToUpper(cfg.Method),
+		headers: make(map[string]*template.Template, len(cfg.Headers)),
+		output:  make(map[string]*jsonpath.Path, len(cfg.Output)),
+		http:    client,
+	}
+	if t.method == "" {
+		t.method = http.MethodGet
+	}
+
+	var err error
+	// URL values are query-escaped; use {{raw .x}} to insert a value as is
+	if t.url, err = parseHTTPToolTemplate("url", cfg.URL, url.QueryEscape); err != nil {
+		return nil, fmt.Errorf("http tool %s: %w", cfg.Name, err)
+	}
+	if cfg.Body != "" {
+		if t.body, err = parseHTTPToolTemplate("body", cfg.Body, nil); err != nil {
+			return nil, fmt.Errorf("http tool %s: %w", cfg.Name, err)
+		}
+	}
+	for name, value := range cfg.Headers {
+		if t.headers[name], err = parseHTTPToolTemplate("header "+name, value, nil); err != nil {
+			return nil, fmt.Errorf("http tool %s: %w", cfg.Name, err)
+		}
+	}
+	for field, expr := range cfg.Output {
+		if t.output[field], err = jsonpath.Compile(expr); err != nil {
+			return nil, fmt.Errorf("http tool %s: output %s: %w", cfg.Name, field, err)
+		}
+	}
+	return t, nil
+}
+
+// parseHTTPToolTemplate parses a template exposing a `json` function (JSON-encodes a
+// value) and a `raw` function (prints a value without escaping). When escape is set,
+// plain {{.x}} actions are passed through it.
+func parseHTTPToolTemplate(name, text string, escape func(string) string) (*template.Template, error) {
+	funcs := template.FuncMap{
+		"json": func(v any) (string, error) {
+			b, err := json.Marshal(v)
+			return string(b), err
+		},
+		"raw": func(v any) rawValue { return rawValue(fmt.Sprint(v)) },
+	}
+	if escape != nil {
+		funcs["escape"] = func(v any) string {
+			if r, ok := v.(rawValue); ok {
+				return string(r)
+			}
+			return escape(fmt.Sprint(v))
+		}
+		text = escapeActions(text)
+	}
+
+	tmpl, err := template.New(name).Option("missingkey=error").Funcs(funcs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// rawValue marks a template value that must not be escaped
+type rawValue string
+
+// escapeActions pipes every {{...}} action through the escape function. URL
+// templates therefore support value actions only (no if/range blocks).
+func escapeActions(text string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(text, "{{")
+		if start < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		end := strings.Index(text[start:], "}}")
+		if end < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		end += start
+		b.WriteString(text[:start])
+		b.WriteString("{{" + strings.TrimSpace(text[start+2:end]) + " | escape}}")
+		text = text[end+2:]
+	}
+}
+
+func (t *HTTPTool) Name() string {
+	return t.cfg.Name
+}
+
+func (t *HTTPTool) Key() bag.Key {
+	return bag.Key(t.cfg.Name)
+}
+
+func (t *HTTPTool) Description() string {
+	if t.cfg.Description != "" {
+		return t.cfg.Description
+	}
+	return fmt.Sprintf("External REST tool calling %s", t.cfg.URL)
+}
+
+func (t *HTTPTool) IsExternal() bool {
+	return true
+}
+
+func (t *HTTPTool) Tags() []string {
+	return []string{"http", "external"}
+}
+
+func (t *HTTPTool) Definition() models.ToolDef {
+	params := t.cfg.Parameters
+	if len(params) == 0 {
+		params = map[string]any{
+			"type":       "object",
+			"properties": map[string]any{},
+		}
+	}
+	return &models.CustomToolDef{
+		Type: models.CustomToolDefType,
+		FunctionDef: models.FunctionDef{
+			Name:        t.Name(),
+			Description: t.Description(),
+			Parameters:  params,
+		},
+	}
+}
+
+// Run renders the request from the arguments, calls the endpoint and maps the JSON
+// response through the configured JSONPaths
+func (t *HTTPTool) Run(ctx context.Context, args any) (any, error) {
+	input, err := httpToolArgs(args)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %s: %w", t.cfg.Name, err)
+	}
+
+	req, err := t.newRequest(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %s: %w", t.cfg.Name, err)
+	}
+
+	resp, err := t.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %s: request failed: %w", t.cfg.Name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("http tool %s: failed to read response: %w", t.cfg.Name, err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("http tool %s: unexpected status %d: %s", t.cfg.Name, resp.StatusCode, truncate(string(body), maxHTTPToolErrorBody))
+	}
+
+	var doc any
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("http tool %s: response is not valid JSON: %w", t.cfg.Name, err)
+	}
+	if len(t.output) == 0 {
+		return doc, nil
+	}
+
+	result := make(map[string]any, len(t.output))
+	for field, path := range t.output {
+		v, err := path.Get(doc)
+		if err != nil {
+			return nil, fmt.Errorf("http tool %s: output %s: %w", t.cfg.Name, field, err)
+		}
+		result[field] = v
+	}
+	return result, nil
+}
+
+func (t *HTTPTool) newRequest(ctx context.Context, input map[string]any) (*http.Request, error) {
+	rawURL, err := render(t.url, input)
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if t.body != nil {
+		b, err := render(t.body, input)
+		if err != nil {
+			return nil, err
+		}
+		body = strings.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.method, rawURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if t.body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for name, tmpl := range t.headers {
+		v, err := render(tmpl, input)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set(name, v)
+	}
+
+	if auth := t.cfg.Auth; auth != nil {
+		switch auth.Type {
+		case "bearer":
+			req.Header.Set("Authorization", "Bearer "+auth.Token)
+		case "basic":
+			req.SetBasicAuth(auth.Username, auth.Password)
+		case "header":
+			req.Header.Set(auth.Name, auth.Token)
+		case "query":
+			q := req.URL.Query()
+			q.Set(auth.Name, auth.Token)
+			req.URL.RawQuery = q.Encode()
+		}
+	}
+	return req, nil
+}
+
+func render(tmpl *template.Template, data map[string]any) (string, error) {
+	var b bytes.Buffer
+	if err := tmpl.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("failed to render %s: %w", tmpl.Name(), err)
+	}
+	return b.String(), nil
+}
+
+// truncate shortens s to at most n bytes, marking the cut
+func truncate(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "…"
+}
+
+// httpToolArgs decodes tool arguments into the template data
+func httpToolArgs(args any) (map[string]any, error) {
+	input := map[string]any{}
+	switch v := args.(type) {
+	case nil:
+		return input, nil
+	case map[string]any:
+		return v, nil
+	case string:
+		if strings.TrimSpace(v) == "" {
+			return input, nil
+		}
+		if err := json.Unmarshal([]byte(v), &input); err != nil {
+			return nil, fmt.Errorf("arguments must be a JSON object: %w", err)
+		}
+		return input, nil
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode arguments: %w", err)
+		}
+		if err := json.Unmarshal(b, &input); err != nil {
+			return nil, fmt.Errorf("arguments must be a JSON object: %w", err)
+		}
+		return input, nil
+	}
+}
+
+// httpToolConfig builds the wrapper configuration (cache, rate limit, persisting)
+// of an HTTP tool
+func httpToolConfig(tool *HTTPTool) models.ToolConfig {
+	cfg := tool.cfg
+	tc := models.ToolConfig{
+		Key:          tool.Key(),
+		Config:       cfg,
+		CacheEnabled: cfg.CacheEnable,
+		CacheTTL:     cfg.CacheTTL,
+		Persisting:   cfg.Persisting,
+	}
+	if tc.CacheEnabled && tc.CacheTTL == 0 {
+		tc.CacheTTL = defaultHTTPToolCacheTTL
+	}
+	if rl := cfg.RateLimit; rl != nil {
+		tc.RateLimit = &models.ToolsRateLimit{
+			RequestsPerSecond: rl.RequestsPerSecond,
+			RequestsPerDay:    rl.RequestsPerDay,
+			Burst:             rl.Burst,
+		}
+	}
+	return tc
+}
@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+)
+
+const quoteResponse = `{
+	"data": {
+		"quote": {"symbol": "AAPL", "last": 190.5, "currency": "USD"},
+		"history": [{"close": 188.1}, {"close": 189.7}, {"close": 190.5}]
+	}
+}`
+
+// quoteServer answers quoteResponse for /quotes/AAPL and records the last request
+func quoteServer(t *testing.T, hits *atomic.Int32, last **http.Request) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if hits != nil {
+			hits.Add(1)
+		}
+		if last != nil {
+			*last = r.Clone(context.Background())
+		}
+		if r.URL.Path != "/quotes/AAPL" {
+			http.Error(w, `{"error":"unknown symbol"}`, http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(quoteResponse))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHTTPTool_Run(t *testing.T) {
+	cases := []struct {
+		name    string
+		output  map[string]string
+		args    any
+		want    any
+		wantErr string
+	}{
+		{
+			name: "maps response through JSONPath",
+			output: map[string]string{
+				"symbol": "$.data.quote.symbol",
+				"price":  "$.data.quote.last",
+				"closes": "$.data.history[*].close",
+			},
+			args: `{"symbol":"AAPL"}`,
+			want: map[string]any{"symbol": "AAPL", "price": 190.5, "closes": []any{188.1, 189.7, 190.5}},
+		},
+		{
+			name:   "no mapping returns the whole response",
+			args:   map[string]any{"symbol": "AAPL"},
+			want:   map[string]any{"data": map[string]any{"quote": map[string]any{"symbol": "AAPL", "last": 190.5, "currency": "USD"}, "history": []any{map[string]any{"close": 188.1}, map[string]any{"close": 189.7}, map[string]any{"close": 190.5}}}},
+			output: nil,
+		},
+		{
+			name:    "path without match",
+			output:  map[string]string{"volume": "$.data.quote.volume"},
+			args:    `{"symbol":"AAPL"}`,
+			wantErr: "output volume",
+		},
+		{
+			name:    "error status",
+			args:    `{"symbol":"ZZZZ"}`,
+			wantErr: "unexpected status 404",
+		},
+		{
+			name:    "missing template argument",
+			args:    `{}`,
+			wantErr: "failed to render url",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := quoteServer(t, nil, nil)
+			tool, err := NewHTTPTool(config.HTTPToolConfig{
+				Name:   "quotes",
+				URL:    srv.URL + "/quotes/{{.symbol}}",
+				Output: c.output,
+			}, srv.Client())
+			require.NoError(t, err)
+
+			got, err := tool.Run(context.Background(), c.args)
+			if c.wantErr != "" {
+				require.ErrorContains(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestHTTPTool_Request(t *testing.T) {
+	var last *http.Request
+	srv := quoteServer(t, nil, &last)
+
+	tool, err := NewHTTPTool(config.HTTPToolConfig{
+		Name:    "quotes",
+		URL:     srv.URL + "/quotes/{{.symbol}}?range={{.range}}",
+		Headers: map[string]string{"X-Client": "mosychlos-{{.symbol}}"},
+		Auth:    &config.HTTPToolAuthConfig{Type: "query", Name: "apikey", Token: "secret"},
+		Output:  map[string]string{"price": "$.data.quote.last"},
+	}, srv.Client())
+	require.NoError(t, err)
+
+	_, err = tool.Run(context.Background(), `{"symbol":"AAPL","range":"1 year"}`)
+	require.NoError(t, err)
+
+	require.NotNil(t, last)
+	assert.Equal(t, http.MethodGet, last.Method)
+	assert.Equal(t, "1 year", last.URL.Query().Get("range"), "URL values are escaped")
+	assert.Equal(t, "secret", last.URL.Query().Get("apikey"))
+	assert.Equal(t, "mosychlos-AAPL", last.Header.Get("X-Client"))
+}
+
+func TestNewToolManager_HTTPTool(t *testing.T) {
+	var hits atomic.Int32
+	srv := quoteServer(t, &hits, nil)
+
+	sharedBag := bag.NewSharedBag()
+	sharedBag.Set(bag.KEngineRunID, "")
+
+	cfg := &config.Config{
+		DataDir:  t.TempDir(),
+		CacheDir: t.TempDir(),
+		Tools: config.ToolsConfig{
+			HTTP: []config.HTTPToolConfig{{
+				Name:        "quotes",
+				URL:         srv.URL + "/quotes/{{.symbol}}",
+				Output:      map[string]string{"price": "$.data.quote.last"},
+				CacheEnable: true,
+				RateLimit:   &config.HTTPToolRateLimitConfig{RequestsPerSecond: 1, RequestsPerDay: 1},
+			}},
+		},
+	}
+
+	m, err := NewToolManager(cfg, sharedBag, nil)
+	require.NoError(t, err)
+	tool := m.Get("quotes")
+	require.NotNil(t, tool)
+
+	got, err := tool.Run(context.Background(), `{"symbol":"AAPL"}`)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"price": 190.5}, got)
+
+	// the second identical call is served from cache and does not hit the endpoint
+	_, err = tool.Run(context.Background(), `{"symbol":"AAPL"}`)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), hits.Load())
+
+	// a different call waits on the exhausted daily rate limit
+	ctx, cancel := context.WithTimeout(context.Background(), 300*time.Millisecond)
+	defer cancel()
+	_, err = tool.Run(ctx, `{"symbol":"MSFT"}`)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, int32(1), hits.Load())
+}
@@ -0,0 +1,67 @@
+package tools
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// LoggingTool logs each call of the wrapped tool
+type LoggingTool struct {
+	tool models.Tool
+}
+
+var _ models.Tool = (*LoggingTool)(nil)
+
+// NewLoggingTool creates a wrapper logging calls, durations and errors
+func NewLoggingTool(tool models.Tool) *LoggingTool {
+	return &LoggingTool{tool: tool}
+}
+
+func (l *LoggingTool) Name() string {
+	return l.tool.Name()
+}
+
+func (l *LoggingTool) Key() bag.Key {
+	return l.tool.Key()
+}
+
+func (l *LoggingTool) Description() string {
+	return l.tool.Description()
+}
+
+func (l *LoggingTool) Definition() models.ToolDef {
+	return l.tool.Definition()
+}
+
+func (l *LoggingTool) Tags() []string {
+	return l.tool.Tags()
+}
+
+func (l *LoggingTool) IsExternal() bool {
+	return l.tool.IsExternal()
+}
+
+func (l *LoggingTool) Run(ctx context.Context, args any) (any, error) {
+	slog.Debug("Tool call started", "tool", l.tool.Name())
+	start := time.Now()
+
+	result, err := l.tool.Run(ctx, args)
+	if err != nil {
+		slog.Warn("Tool call failed",
+			"tool", l.tool.Name(),
+			"duration_ms", time.Since(start).Milliseconds(),
+			"error", err,
+		)
+		return result, err
+	}
+
+	slog.Debug("Tool call completed",
+		"tool", l.tool.Name(),
+		"duration_ms", time.Since(start).Milliseconds(),
+	)
+	return result, nil
+}
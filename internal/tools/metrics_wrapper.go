@@ -66,9 +66,9 @@ func (w *MetricsWrapper) Run(ctx context.Context, args any) (any, error) {
 		return nil, fmt.Errorf("metrics_wrapper: failed to parse tool args: %w", err)
 	}
 
-	resultBytes, err := json.Marshal(result)
-	if err != nil {
-		return nil, fmt.Errorf("metrics_wrapper: failed to marshal tool result: %w", err)
+	resultBytes, marshalErr := json.Marshal(result)
+	if marshalErr != nil {
+		return nil, fmt.Errorf("metrics_wrapper: failed to marshal tool result: %w", marshalErr)
 	}
 
 	resultMap := map[string]any{}
@@ -101,6 +101,15 @@ func (w *MetricsWrapper) Run(ctx context.Context, args any) (any, error) {
 	return result, err
 }
 
+// RecordComputation stores a computation that did not go through a wrapped tool (e.g. an
+// LLM API call) and folds it into the aggregated metrics and API health.
+func RecordComputation(sharedBag bag.SharedBag, comp models.ToolComputation) {
+	w := &MetricsWrapper{sharedBag: sharedBag}
+	w.recordComputation(comp)
+	w.updateMetrics(comp)
+	w.updateAPIHealth(comp)
+}
+
 // recordComputation adds the computation to the shared bag
 func (w *MetricsWrapper) recordComputation(comp models.ToolComputation) {
 	w.sharedBag.Update(bag.KToolComputations, func(current any) any {
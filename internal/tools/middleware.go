@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/normalize"
+)
+
+// ToolMiddleware wraps a tool with one cross-cutting concern (logging, metrics,
+// caching, rate limiting...)
+type ToolMiddleware interface {
+	Wrap(next models.Tool) models.Tool
+}
+
+// ToolMiddlewareFunc adapts a plain function to ToolMiddleware
+type ToolMiddlewareFunc func(next models.Tool) models.Tool
+
+// Wrap implements ToolMiddleware
+func (f ToolMiddlewareFunc) Wrap(next models.Tool) models.Tool {
+	return f(next)
+}
+
+// Chain wraps tool with the middlewares. The first middleware is the outermost:
+// a call goes through middlewares in order, then reaches the tool.
+func Chain(tool models.Tool, middlewares ...ToolMiddleware) models.Tool {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		tool = middlewares[i].Wrap(tool)
+	}
+	return tool
+}
+
+// LoggingMiddleware logs every tool call with its duration and outcome
+func LoggingMiddleware() ToolMiddleware {
+	return ToolMiddlewareFunc(func(next models.Tool) models.Tool {
+		return NewLoggingTool(next)
+	})
+}
+
+// MetricsMiddleware records a ToolComputation and aggregated metrics in the shared bag
+func MetricsMiddleware(sharedBag bag.SharedBag) ToolMiddleware {
+	return ToolMiddlewareFunc(func(next models.Tool) models.Tool {
+		return NewMetricsWrapper(next, sharedBag)
+	})
+}
+
+// WireMinMiddleware returns token-minimized JSON to the LLM
+func WireMinMiddleware(reg normalize.Registry, sharedBag bag.SharedBag) ToolMiddleware {
+	return ToolMiddlewareFunc(func(next models.Tool) models.Tool {
+		return NewWireMinWrapper(next, reg, sharedBag)
+	})
+}
+
+// NormalizeMiddleware stores a normalized envelope of every result in the shared bag
+func NormalizeMiddleware(reg normalize.Registry, sharedBag bag.SharedBag) ToolMiddleware {
+	return ToolMiddlewareFunc(func(next models.Tool) models.Tool {
+		return NewNormalizeWrapper(next, reg, sharedBag)
+	})
+}
+
+// PersistingMiddleware writes tool inputs and outputs under dir
+func PersistingMiddleware(dir string) ToolMiddleware {
+	return ToolMiddlewareFunc(func(next models.Tool) models.Tool {
+		return NewIOPersistingTool(next, dir)
+	})
+}
+
+// CacheMiddleware serves repeated calls from the file cache under cacheDir
+func CacheMiddleware(cacheDir string, ttl time.Duration, sharedBag bag.SharedBag) ToolMiddleware {
+	return ToolMiddlewareFunc(func(next models.Tool) models.Tool {
+		return NewCachedToolWithMonitoring(next, cacheDir, ttl, sharedBag)
+	})
+}
+
+// RateLimitMiddleware throttles calls to the configured rate
+func RateLimitMiddleware(rl models.ToolsRateLimit) ToolMiddleware {
+	return ToolMiddlewareFunc(func(next models.Tool) models.Tool {
+		return NewRateLimitedTool(next, rl.RequestsPerSecond, rl.RequestsPerDay, rl.Burst)
+	})
+}
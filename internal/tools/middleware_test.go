@@ -0,0 +1,162 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/normalize"
+)
+
+// stubTool returns a fixed JSON result and appends to calls when run
+type stubTool struct {
+	calls *[]string
+}
+
+func (s *stubTool) Name() string               { return "stub" }
+func (s *stubTool) Key() bag.Key               { return bag.Key("stub") }
+func (s *stubTool) Description() string        { return "stub tool" }
+func (s *stubTool) Definition() models.ToolDef { return nil }
+func (s *stubTool) Tags() []string             { return nil }
+func (s *stubTool) IsExternal() bool           { return false }
+func (s *stubTool) Run(_ context.Context, _ any) (any, error) {
+	*s.calls = append(*s.calls, "tool")
+	return `{"ok":true}`, nil
+}
+
+// recorder is a middleware appending "<name>:in" and "<name>:out" around each call
+func recorder(name string, calls *[]string) ToolMiddleware {
+	return ToolMiddlewareFunc(func(next models.Tool) models.Tool {
+		return &recordingTool{Tool: next, name: name, calls: calls}
+	})
+}
+
+type recordingTool struct {
+	models.Tool
+	name  string
+	calls *[]string
+}
+
+func (r *recordingTool) Run(ctx context.Context, args any) (any, error) {
+	*r.calls = append(*r.calls, r.name+":in")
+	out, err := r.Tool.Run(ctx, args)
+	*r.calls = append(*r.calls, r.name+":out")
+	return out, err
+}
+
+func TestChain_Order(t *testing.T) {
+	var calls []string
+	tool := Chain(&stubTool{calls: &calls},
+		recorder("logging", &calls),
+		recorder("metrics", &calls),
+		recorder("cache", &calls),
+		recorder("rate_limit", &calls),
+	)
+
+	_, err := tool.Run(context.Background(), `{}`)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"logging:in", "metrics:in", "cache:in", "rate_limit:in",
+		"tool",
+		"rate_limit:out", "cache:out", "metrics:out", "logging:out",
+	}, calls)
+	assert.Equal(t, "stub", tool.Name(), "metadata is delegated through the chain")
+}
+
+// layers lists the wrappers of a chained tool, outermost first
+func layers(tool models.Tool) []string {
+	var out []string
+	for {
+		switch w := tool.(type) {
+		case *LoggingTool:
+			out, tool = append(out, "logging"), w.tool
+		case *MetricsWrapper:
+			out, tool = append(out, "metrics"), w.tool
+		case *WireMinWrapper:
+			out, tool = append(out, "wiremin"), w.tool
+		case *NormalizeWrapper:
+			out, tool = append(out, "normalize"), w.tool
+		case *IOPersister:
+			out, tool = append(out, "persisting"), w.tool
+		case *CachedTool:
+			out, tool = append(out, "cache"), w.tool
+		case *RateLimitedTool:
+			out, tool = append(out, "rate_limit"), w.tool
+		default:
+			return append(out, fmt.Sprintf("%T", tool))
+		}
+	}
+}
+
+func TestWrapTool_Middlewares(t *testing.T) {
+	cases := []struct {
+		name   string
+		config models.ToolConfig
+		runID  string
+		reg    normalize.Registry
+		want   []string
+	}{
+		{
+			name:   "bare tool",
+			config: models.ToolConfig{},
+			want:   []string{"logging", "metrics", "*tools.stubTool"},
+		},
+		{
+			name: "every concern configured",
+			config: models.ToolConfig{
+				CacheEnabled: true,
+				CacheTTL:     time.Hour,
+				RateLimit:    &models.ToolsRateLimit{RequestsPerSecond: 10, RequestsPerDay: 100},
+				Persisting:   true,
+			},
+			runID: "run-1",
+			reg:   normalize.DefaultRegistry(),
+			want:  []string{"logging", "metrics", "wiremin", "normalize", "persisting", "cache", "rate_limit", "*tools.stubTool"},
+		},
+		{
+			name:   "persisting needs a run ID",
+			config: models.ToolConfig{Persisting: true},
+			want:   []string{"logging", "metrics", "*tools.stubTool"},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			sharedBag := bag.NewSharedBag()
+			sharedBag.Set(bag.KEngineRunID, c.runID)
+
+			var calls []string
+			tool := wrapTool(&stubTool{calls: &calls}, &c.config, t.TempDir(), t.TempDir(), sharedBag, c.reg)
+			assert.Equal(t, c.want, layers(tool))
+
+			// a call goes through the whole chain down to the tool exactly once
+			_, err := tool.Run(context.Background(), `{"symbol":"AAPL"}`)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"tool"}, calls)
+
+			raw, ok := sharedBag.Get(bag.KToolComputations)
+			require.True(t, ok)
+			assert.Len(t, raw.([]models.ToolComputation), 1)
+		})
+	}
+}
+
+func TestToolManager_GetToolsMap(t *testing.T) {
+	m := &ToolManager{tools: map[string]models.Tool{
+		"stub": wrapTool(&stubTool{calls: new([]string)}, &models.ToolConfig{}, t.TempDir(), t.TempDir(), nil, nil),
+	}}
+
+	got := m.GetToolsMap()
+	require.Contains(t, got, "stub")
+	assert.IsType(t, &LoggingTool{}, got["stub"], "tools come back wrapped")
+
+	// the map is a copy
+	delete(got, "stub")
+	assert.NotNil(t, m.Get("stub"))
+}
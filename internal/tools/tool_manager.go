@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log/slog"
 	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 
@@ -47,6 +48,36 @@ func NewToolManager(cfg *config.Config, sharedBag bag.SharedBag, reg normalize.R
 		m.tools[toolConfig.Key.String()] = tool
 	}
 
+	// user-declared tools backed by external commands
+	for _, ec := range cfg.Tools.Exec {
+		if _, exists := m.tools[ec.Name]; exists {
+			return nil, fmt.Errorf("exec tool %s conflicts with a built-in tool", ec.Name)
+		}
+
+		tool, err := NewExecTool(ec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build tool: %w", err)
+		}
+
+		toolConfig := models.ToolConfig{Key: tool.Key(), Config: ec}
+		m.tools[ec.Name] = wrapTool(tool, &toolConfig, cfg.DataDir, cfg.CacheDir, sharedBag, reg)
+	}
+
+	// user-declared tools backed by REST endpoints
+	for _, hc := range cfg.Tools.HTTP {
+		if _, exists := m.tools[hc.Name]; exists {
+			return nil, fmt.Errorf("http tool %s conflicts with another tool", hc.Name)
+		}
+
+		tool, err := NewHTTPTool(hc, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build tool: %w", err)
+		}
+
+		toolConfig := httpToolConfig(tool)
+		m.tools[hc.Name] = wrapTool(tool, &toolConfig, cfg.DataDir, cfg.CacheDir, sharedBag, reg)
+	}
+
 	return m, nil
 }
 
@@ -68,6 +99,15 @@ func (m *ToolManager) Defs() []models.ToolDef {
 	return toolsToToolDefs(m.List())
 }
 
+// GetToolsMap returns the enabled tools by name, already wrapped with their middleware chain
+func (m *ToolManager) GetToolsMap() map[string]models.Tool {
+	out := make(map[string]models.Tool, len(m.tools))
+	for name, t := range m.tools {
+		out[name] = t
+	}
+	return out
+}
+
 // Bag exposes the manager’s shared bag (if needed by engines)
 func (m *ToolManager) Bag() bag.SharedBag {
 	return m.sharedBag
@@ -89,7 +129,7 @@ func (m *ToolManager) Close() error {
 	return nil
 }
 
-// wrapTool applies all configured wrappers to a tool
+// wrapTool applies all configured middlewares to a tool
 func wrapTool(
 	tool models.Tool,
 	config *models.ToolConfig,
@@ -97,67 +137,75 @@ func wrapTool(
 	sharedBag bag.SharedBag,
 	reg normalize.Registry,
 ) models.Tool {
-	wrapped := tool
+	return Chain(tool, toolMiddlewares(tool.Name(), config, dataDir, cacheDir, sharedBag, reg)...)
+}
 
-	// Apply rate limiting if configured
-	if config.RateLimit != nil {
-		wrapped = NewRateLimitedTool(
-			wrapped,
-			config.RateLimit.RequestsPerSecond,
-			config.RateLimit.RequestsPerDay,
-			config.RateLimit.Burst,
-		)
-		slog.Debug("Applied rate limiting",
-			"tool", tool.Name(),
-			"requests_per_second", config.RateLimit.RequestsPerSecond,
-			"requests_per_day", config.RateLimit.RequestsPerDay,
-		)
-	}
+// toolMiddlewares builds the middleware chain of a tool from its config, outermost
+// first: logging, metrics, wire-minification, normalization, I/O persisting,
+// caching, then rate limiting right before the tool itself
+func toolMiddlewares(
+	name string,
+	config *models.ToolConfig,
+	dataDir, cacheDir string,
+	sharedBag bag.SharedBag,
+	reg normalize.Registry,
+) []ToolMiddleware {
+	middlewares := []ToolMiddleware{LoggingMiddleware()}
 
-	// Apply caching if enabled
-	if config.CacheEnabled {
-		wrapped = NewCachedToolWithMonitoring(wrapped, cacheDir, config.CacheTTL, sharedBag)
-		slog.Debug("Applied caching",
-			"tool", tool.Name(),
-			"cache_ttl", config.CacheTTL,
+	// Apply metrics tracking if shared bag is available
+	if sharedBag != nil {
+		middlewares = append(middlewares, MetricsMiddleware(sharedBag))
+		slog.Debug("Applied metrics wrapper",
+			"tool", name,
 		)
 	}
 
-	// Apply Input/Output Persiting in config.DataDir (especially useful for debugging & generating test fixtures)
-	if runID := sharedBag.MustGet(bag.KEngineRunID).(string); config.Persisting && runID != "" {
-		wrapped = NewIOPersistingTool(wrapped,
-			filepath.Join(dataDir, "tools_i_o", fmt.Sprintf("run_%s_%s", runID, time.Now().Format("20060102_150405"))))
-		slog.Debug("Applied I/O persisting",
-			"tool", tool.Name(),
-			"data_dir", dataDir,
+	if sharedBag != nil && reg != nil {
+		// Wire-minify for the LLM (return compact JSON)
+		middlewares = append(middlewares, WireMinMiddleware(reg, sharedBag))
+		slog.Debug("Applied wire-minification wrapper",
+			"tool", name,
 		)
-	}
 
-	// 4) Normalize to a stable envelope (side-channel into SharedBag)
-	if sharedBag != nil && reg != nil {
-		wrapped = NewNormalizeWrapper(wrapped, reg, sharedBag)
+		// Normalize to a stable envelope (side-channel into SharedBag)
+		middlewares = append(middlewares, NormalizeMiddleware(reg, sharedBag))
 		slog.Debug("Applied normalization wrapper",
-			"tool", tool.Name(),
+			"tool", name,
 		)
 	}
 
-	// 5) Wire-minify for the LLM (return compact JSON)
-	if sharedBag != nil && reg != nil {
-		wrapped = NewWireMinWrapper(wrapped, reg, sharedBag)
-		slog.Debug("Applied wire-minification wrapper",
-			"tool", tool.Name(),
+	// Apply Input/Output Persiting in config.DataDir (especially useful for debugging & generating test fixtures)
+	if config.Persisting && sharedBag != nil {
+		if runID, _ := sharedBag.MustGet(bag.KEngineRunID).(string); runID != "" {
+			middlewares = append(middlewares, PersistingMiddleware(
+				filepath.Join(dataDir, "tools_i_o", fmt.Sprintf("run_%s_%s", runID, time.Now().Format("20060102_150405")))))
+			slog.Debug("Applied I/O persisting",
+				"tool", name,
+				"data_dir", dataDir,
+			)
+		}
+	}
+
+	// Apply caching if enabled
+	if config.CacheEnabled {
+		middlewares = append(middlewares, CacheMiddleware(cacheDir, config.CacheTTL, sharedBag))
+		slog.Debug("Applied caching",
+			"tool", name,
+			"cache_ttl", config.CacheTTL,
 		)
 	}
 
-	// Apply metrics tracking if shared bag is available
-	if sharedBag != nil {
-		wrapped = NewMetricsWrapper(wrapped, sharedBag)
-		slog.Debug("Applied metrics wrapper",
-			"tool", tool.Name(),
+	// Apply rate limiting if configured
+	if config.RateLimit != nil {
+		middlewares = append(middlewares, RateLimitMiddleware(*config.RateLimit))
+		slog.Debug("Applied rate limiting",
+			"tool", name,
+			"requests_per_second", config.RateLimit.RequestsPerSecond,
+			"requests_per_day", config.RateLimit.RequestsPerDay,
 		)
 	}
 
-	return wrapped
+	return middlewares
 }
 
 var (
@@ -179,6 +227,19 @@ func Register(name bag.Key, f ToolFactory) {
 	)
 }
 
+// Registered returns the sorted names of all registered tools, whether enabled or not
+func Registered() []string {
+	mu.Lock()
+	defer mu.Unlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func toolsToToolDefs(tools []models.Tool) []models.ToolDef {
 	toolDefs := make([]models.ToolDef, 0, len(tools))
 	for _, tool := range tools {
@@ -0,0 +1,79 @@
+package newsapi
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/newsapi"
+)
+
+// recordAnalyzedNews scores the fetched headlines against the topics and merges
+// them into the analyzed news context the report filters by relevance
+func (p *NewsAPITool) recordAnalyzedNews(ctx context.Context, topics []string, resp *newsapi.NewsAPIResponse) {
+	if p.sharedBag == nil {
+		return
+	}
+
+	articles := resp.ToNewsData().Articles
+	relevance := p.headlineRelevance(ctx, topics, articles)
+	headlines := make([]models.NormalizedNewsItem, 0, len(articles))
+	for i, a := range articles {
+		headlines = append(headlines, models.NormalizedNewsItem{
+			Title:       a.Title,
+			Source:      a.Source,
+			PublishedAt: a.PublishedAt,
+			Relevance:   relevance[i],
+		})
+	}
+
+	now := time.Now().UTC()
+	p.sharedBag.Update(bag.KNewsAnalyzed, func(current any) any {
+		news := models.NormalizedNewsContext{AsOfDate: now}
+		if c, ok := current.(*models.NormalizedNewsContext); ok && c != nil {
+			news = *c
+			news.RecentHeadlines = slices.Clone(c.RecentHeadlines)
+		}
+		news.LastUpdated = now
+		for _, h := range headlines {
+			seen := slices.ContainsFunc(news.RecentHeadlines, func(n models.NormalizedNewsItem) bool {
+				return n.Title == h.Title
+			})
+			if !seen {
+				news.RecentHeadlines = append(news.RecentHeadlines, h)
+			}
+		}
+		return &news
+	})
+}
+
+// headlineRelevance scores each article against the topics on a 0-1 scale:
+// embedding similarity when related news is enabled, otherwise the share of
+// topics the title mentions
+func (p *NewsAPITool) headlineRelevance(ctx context.Context, topics []string, articles []models.NewsArticle) []float64 {
+	if p.related != nil {
+		scores, err := p.related.Relevance(ctx, strings.Join(topics, " "), articles)
+		if err == nil {
+			return scores
+		}
+		slog.Warn("Failed to score news relevance, falling back to topic matching", "tool", p.Name(), "error", err)
+	}
+
+	scores := make([]float64, len(articles))
+	for i, a := range articles {
+		title := strings.ToLower(a.Title)
+		for _, topic := range topics {
+			if strings.Contains(title, strings.ToLower(topic)) {
+				scores[i]++
+			}
+		}
+		if len(topics) > 0 {
+			scores[i] /= float64(len(topics))
+		}
+	}
+	return scores
+}
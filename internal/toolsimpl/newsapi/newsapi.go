@@ -17,6 +17,9 @@ type NewsAPITool struct {
 	client    *newsapi.Client
 	locale    string
 	sharedBag bag.SharedBag
+	// related, when set, attaches the relatedTopK most similar seen articles
+	related     *RelatedNews
+	relatedTopK int
 }
 
 var _ models.Tool = &NewsAPITool{}
@@ -112,6 +115,10 @@ func (p *NewsAPITool) Run(ctx context.Context, args any) (any, error) {
 		)
 		return "", fmt.Errorf("newsapi fetch failed: %w", err)
 	}
+	if p.related != nil {
+		newsData.Related = p.relatedArticles(ctx, params.Topics, newsData)
+	}
+	p.recordAnalyzedNews(ctx, params.Topics, newsData)
 
 	// Convert to map for Marshal/Unmarshal pattern
 	// dataMap, err := json.Marshal(newsData)
@@ -2,11 +2,20 @@ package newsapi
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/internal/report"
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/newsapi"
+	"github.com/amaurybrisou/mosychlos/pkg/vector"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestProvider_New(t *testing.T) {
@@ -185,3 +194,159 @@ func TestProvider_Categories(t *testing.T) {
 }
 
 // Retained relevant tests
+
+// keywordEmbedder maps texts onto a 3D space: markets, tech, sports
+type keywordEmbedder struct{}
+
+func (keywordEmbedder) Embedding(_ context.Context, text string) ([]float64, error) {
+	v := []float64{0, 0, 0}
+	for i, words := range [][]string{{"stocks", "rates", "fed"}, {"chip", "ai", "nvidia"}, {"match", "league"}} {
+		for _, w := range words {
+			if strings.Contains(strings.ToLower(text), w) {
+				v[i]++
+			}
+		}
+	}
+	return v, nil
+}
+
+func (e keywordEmbedder) EmbedMany(ctx context.Context, inputs []string) ([][]float64, error) {
+	out := make([][]float64, 0, len(inputs))
+	for _, in := range inputs {
+		v, _ := e.Embedding(ctx, in)
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+func TestRelatedNews_FindSimilar(t *testing.T) {
+	articles := []models.NewsArticle{
+		{Title: "Fed holds rates, stocks rally", URL: "https://news/1"},
+		{Title: "Nvidia unveils new AI chip", URL: "https://news/2"},
+		{Title: "League match ends in draw", URL: "https://news/3"},
+		{Title: "AI chip demand lifts stocks", URL: "https://news/4"},
+	}
+
+	cases := []struct {
+		name  string
+		query string
+		topK  int
+		want  []string
+	}{
+		{name: "tech holding", query: "NVIDIA AI", topK: 2, want: []string{"https://news/2", "https://news/4"}},
+		{name: "macro", query: "fed rates", topK: 1, want: []string{"https://news/1"}},
+	}
+
+	cacheDir := t.TempDir()
+	related := NewRelatedNews(vector.NewIndex(fs.OS{}, cacheDir, relatedNewsIndex), keywordEmbedder{})
+	require.NoError(t, related.IndexArticles(context.Background(), articles))
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			// reload from disk to make sure the persisted index is used
+			reloaded := vector.NewIndex(fs.OS{}, cacheDir, relatedNewsIndex)
+			require.NoError(t, reloaded.Load())
+
+			got, err := NewRelatedNews(reloaded, keywordEmbedder{}).FindSimilar(context.Background(), c.query, c.topK)
+			require.NoError(t, err)
+			urls := make([]string, 0, len(got))
+			for _, a := range got {
+				urls = append(urls, a.URL)
+				assert.GreaterOrEqual(t, a.Relevance, 0.0)
+				assert.LessOrEqual(t, a.Relevance, 1.0+1e-9)
+			}
+			assert.Equal(t, c.want, urls)
+		})
+	}
+}
+
+func TestProvider_Run_RelatedArticles(t *testing.T) {
+	pages := map[string]string{
+		"nvidia": `{"status":"ok","totalResults":1,"articles":[{"title":"Nvidia unveils new AI chip","url":"https://news/2","source":{"name":"Wire"}}]}`,
+		"fed":    `{"status":"ok","totalResults":2,"articles":[{"title":"Fed holds rates, stocks rally","url":"https://news/1","source":{"name":"Wire"}},{"title":"AI chip demand lifts stocks","url":"https://news/4","source":{"name":"Wire"}}]}`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(pages[r.URL.Query().Get("q")]))
+	}))
+	defer srv.Close()
+
+	tool, err := new("test-key", srv.URL, "en", bag.NewSharedBag())
+	require.NoError(t, err)
+	tool.related = NewRelatedNews(vector.NewIndex(fs.OS{}, t.TempDir(), relatedNewsIndex), keywordEmbedder{})
+	tool.relatedTopK = 2
+
+	_, err = tool.Run(context.Background(), `{"topics":["fed"]}`)
+	require.NoError(t, err)
+
+	// the articles seen by the first fetch are related to the second one, the
+	// second fetch's own article is left to the response
+	out, err := tool.Run(context.Background(), `{"topics":["nvidia"]}`)
+	require.NoError(t, err)
+	resp, ok := out.(*newsapi.NewsAPIResponse)
+	require.True(t, ok)
+	require.Len(t, resp.Related, 2)
+	assert.Equal(t, "https://news/4", resp.Related[0].URL)
+	assert.Equal(t, "https://news/1", resp.Related[1].URL)
+}
+
+// countingEmbedder records how many texts were embedded in batches
+type countingEmbedder struct {
+	keywordEmbedder
+	embedded []string
+}
+
+func (e *countingEmbedder) EmbedMany(ctx context.Context, inputs []string) ([][]float64, error) {
+	e.embedded = append(e.embedded, inputs...)
+	return e.keywordEmbedder.EmbedMany(ctx, inputs)
+}
+
+func TestRelatedNews_IndexArticlesSkipsIndexed(t *testing.T) {
+	embedder := &countingEmbedder{}
+	related := NewRelatedNews(vector.NewIndex(fs.OS{}, t.TempDir(), relatedNewsIndex), embedder)
+
+	first := []models.NewsArticle{
+		{Title: "Fed holds rates, stocks rally", URL: "https://news/1"},
+		{Title: "Nvidia unveils new AI chip", URL: "https://news/2"},
+	}
+	require.NoError(t, related.IndexArticles(context.Background(), first))
+	require.NoError(t, related.IndexArticles(context.Background(), append(first, models.NewsArticle{Title: "League match ends in draw", URL: "https://news/3"})))
+
+	assert.Equal(t, []string{"Fed holds rates, stocks rally", "Nvidia unveils new AI chip", "League match ends in draw"}, embedder.embedded)
+}
+
+func TestProvider_Run_AnalyzedNewsReachesReport(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"status":"ok","totalResults":3,"articles":[` +
+			`{"title":"Fed holds rates, stocks rally","url":"https://news/1","source":{"name":"Wire"}},` +
+			`{"title":"League match ends in draw","url":"https://news/3","source":{"name":"Sport"}},` +
+			`{"title":"Fed minutes signal stocks caution","url":"https://news/5","source":{"name":"Wire"}}]}`))
+	}))
+	defer srv.Close()
+
+	sharedBag := bag.NewSharedBag()
+	tool, err := new("test-key", srv.URL, "en", sharedBag)
+	require.NoError(t, err)
+
+	_, err = tool.Run(context.Background(), `{"topics":["fed","stocks"]}`)
+	require.NoError(t, err)
+
+	v, ok := sharedBag.Get(bag.KNewsAnalyzed)
+	require.True(t, ok)
+	news, ok := v.(*models.NormalizedNewsContext)
+	require.True(t, ok)
+	require.Len(t, news.RecentHeadlines, 3)
+	assert.InDelta(t, 1.0, news.RecentHeadlines[0].Relevance, 1e-9)
+	assert.InDelta(t, 0.0, news.RecentHeadlines[1].Relevance, 1e-9)
+
+	cfg := &config.Config{DataDir: t.TempDir()}
+	gen := report.NewGenerator(report.Dependencies{Config: cfg, DataBag: sharedBag, FileSystem: fs.OS{}})
+	out, err := gen.GenerateCustomerReport(context.Background(), models.FormatMarkdown)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, out.Metadata.FilteredHeadlines)
+	assert.Contains(t, out.Content, "- Fed holds rates, stocks rally [Wire] (1.00 relevance)")
+	assert.Contains(t, out.Content, "- Fed minutes signal stocks caution [Wire] (1.00 relevance)")
+	assert.NotContains(t, out.Content, "League match")
+}
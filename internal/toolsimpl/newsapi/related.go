@@ -0,0 +1,155 @@
+package newsapi
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/newsapi"
+	"github.com/amaurybrisou/mosychlos/pkg/vector"
+)
+
+// relatedNewsIndex is the name of the persisted news embedding index
+const relatedNewsIndex = "news"
+
+// Embedder turns texts into embedding vectors, in input order
+type Embedder interface {
+	Embedding(ctx context.Context, text string) ([]float64, error)
+	EmbedMany(ctx context.Context, inputs []string) ([][]float64, error)
+}
+
+// RelatedNews keeps embeddings of seen articles and finds the ones most related
+// to a query (typically a holding name or ticker)
+type RelatedNews struct {
+	index    *vector.Index
+	embedder Embedder
+}
+
+// NewRelatedNews creates a related-news finder backed by an index persisted in the cache directory
+func NewRelatedNews(index *vector.Index, embedder Embedder) *RelatedNews {
+	return &RelatedNews{index: index, embedder: embedder}
+}
+
+// IndexArticles embeds the articles not indexed yet (keyed by URL, falling back to
+// the title) and persists the index. Articles already in the index are not
+// embedded again, so repeated fetches only pay for new headlines.
+func (r *RelatedNews) IndexArticles(ctx context.Context, articles []models.NewsArticle) error {
+	texts := make([]string, 0, len(articles))
+	items := make([]vector.Item, 0, len(articles))
+	seen := map[string]bool{}
+	for _, a := range articles {
+		id := articleID(a)
+		if id == "" || seen[id] || r.index.Has(id) {
+			continue
+		}
+		seen[id] = true
+		texts = append(texts, a.Title)
+		items = append(items, vector.Item{
+			ID: id,
+			Metadata: map[string]string{
+				"title":        a.Title,
+				"source":       a.Source,
+				"url":          a.URL,
+				"published_at": a.PublishedAt.Format(time.RFC3339),
+			},
+		})
+	}
+	if len(texts) == 0 {
+		return nil
+	}
+
+	vectors, err := r.embedder.EmbedMany(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("failed to embed news articles: %w", err)
+	}
+	for i := range items {
+		items[i].Vector = vectors[i]
+		if err := r.index.Add(items[i]); err != nil {
+			return err
+		}
+	}
+	return r.index.Save()
+}
+
+// FindSimilar returns the topK indexed articles most similar to query, best first,
+// with Relevance set to the cosine similarity. Articles whose ID is in exclude
+// are skipped and do not count towards topK.
+func (r *RelatedNews) FindSimilar(ctx context.Context, query string, topK int, exclude ...string) ([]models.NewsArticle, error) {
+	q, err := r.embedder.Embedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	matches, err := r.index.Search(q, topK+len(exclude))
+	if err != nil {
+		return nil, err
+	}
+
+	articles := make([]models.NewsArticle, 0, topK)
+	for _, m := range matches {
+		if slices.Contains(exclude, m.ID) {
+			continue
+		}
+		if len(articles) == topK {
+			break
+		}
+		publishedAt, _ := time.Parse(time.RFC3339, m.Metadata["published_at"])
+		articles = append(articles, models.NewsArticle{
+			Title:       m.Metadata["title"],
+			Source:      m.Metadata["source"],
+			URL:         m.Metadata["url"],
+			PublishedAt: publishedAt,
+			Relevance:   max(m.Score, 0), // relevance is a 0-1 scale
+		})
+	}
+	return articles, nil
+}
+
+// Relevance scores indexed articles against query by cosine similarity, on a 0-1
+// scale and in article order. Articles missing from the index score 0.
+func (r *RelatedNews) Relevance(ctx context.Context, query string, articles []models.NewsArticle) ([]float64, error) {
+	q, err := r.embedder.Embedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed query: %w", err)
+	}
+
+	scores := make([]float64, len(articles))
+	for i, a := range articles {
+		if item, ok := r.index.Get(articleID(a)); ok {
+			scores[i] = max(vector.CosineSimilarity(q, item.Vector), 0)
+		}
+	}
+	return scores, nil
+}
+
+func articleID(a models.NewsArticle) string {
+	if a.URL != "" {
+		return a.URL
+	}
+	return a.Title
+}
+
+// relatedArticles indexes the fetched articles and returns the previously indexed
+// articles most similar to the topics; the fetched ones are already in the
+// response. Failures are logged and only drop the related articles.
+func (p *NewsAPITool) relatedArticles(ctx context.Context, topics []string, resp *newsapi.NewsAPIResponse) []models.NewsArticle {
+	articles := resp.ToNewsData().Articles
+	if err := p.related.IndexArticles(ctx, articles); err != nil {
+		slog.Warn("Failed to index news articles", "tool", p.Name(), "error", err)
+		return nil
+	}
+	current := make([]string, 0, len(articles))
+	for _, a := range articles {
+		current = append(current, articleID(a))
+	}
+	related, err := p.related.FindSimilar(ctx, strings.Join(topics, " "), p.relatedTopK, current...)
+	if err != nil {
+		slog.Warn("Failed to find related news", "tool", p.Name(), "topics", topics, "error", err)
+		return nil
+	}
+	return related
+}
@@ -4,9 +4,13 @@ import (
 	"time"
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
+	llmopenai "github.com/amaurybrisou/mosychlos/internal/llm/openai"
 	"github.com/amaurybrisou/mosychlos/internal/tools"
 	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+	"github.com/amaurybrisou/mosychlos/pkg/vector"
 )
 
 // GetToolConfigs returns all NewsAPI tool configurations
@@ -15,7 +19,19 @@ func GetToolConfigs(_cfg any, sharedBag bag.SharedBag) models.ToolConfig {
 	return models.ToolConfig{
 		Key: bag.NewsAPI,
 		Constructor: func() (models.Tool, error) {
-			return new(cfg.APIKey, cfg.BaseURL, cfg.Locale, sharedBag)
+			tool, err := new(cfg.APIKey, cfg.BaseURL, cfg.Locale, sharedBag)
+			if err != nil {
+				return nil, err
+			}
+			if cfg.RelatedTopK > 0 && cfg.LLM != nil {
+				index := vector.NewIndex(fs.OS{}, cfg.CacheDir, relatedNewsIndex)
+				if err := index.Load(); err != nil {
+					return nil, err
+				}
+				embedder := llmopenai.NewProvider(pkgopenai.NewClient(pkgopenai.NewHTTPClient(), cfg.LLM.OpenAI), *cfg.LLM, sharedBag)
+				tool.related, tool.relatedTopK = NewRelatedNews(index, embedder), cfg.RelatedTopK
+			}
+			return tool, nil
 		},
 		Config:       cfg,
 		CacheEnabled: cfg.CacheEnable,
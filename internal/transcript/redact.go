@@ -0,0 +1,76 @@
+package transcript
+
+import (
+	"cmp"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// minSecretLen ignores configured secrets too short to be redacted safely
+const minSecretLen = 6
+
+// patterns mask secrets and personal data whatever their origin; order matters,
+// credentials are masked before the generic patterns can split them
+var patterns = []struct {
+	re   *regexp.Regexp
+	repl string
+}{
+	{regexp.MustCompile(`sk-[A-Za-z0-9_\-]{16,}`), "[REDACTED_KEY]"},
+	{regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9._~+/\-]+=*`), "$1 [REDACTED]"},
+	{regexp.MustCompile(`(?i)\b(api[_-]?key|apikey|access[_-]?token|token|secret|password)(["']?\s*[:=]\s*["']?)[^\s"'&,}]+`), "$1$2[REDACTED]"},
+	{regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`), "[REDACTED_EMAIL]"},
+	{regexp.MustCompile(`\b[A-Z]{2}\d{2}(?: ?[A-Z0-9]{4}){2,7}(?: ?[A-Z0-9]{1,4})?\b`), "[REDACTED_IBAN]"},
+	{regexp.MustCompile(`\+\d{1,3}[ .\-]?\(?\d{1,4}\)?(?:[ .\-]?\d{2,4}){2,4}\b`), "[REDACTED_PHONE]"},
+}
+
+// Redactor masks secrets and personal data in transcript messages
+type Redactor struct {
+	secrets []string
+}
+
+// NewRedactor creates a redactor that also masks the given secret values, such
+// as the configured API keys
+func NewRedactor(secrets ...string) *Redactor {
+	var kept []string
+	for _, s := range secrets {
+		if s = strings.TrimSpace(s); len(s) >= minSecretLen && !slices.Contains(kept, s) {
+			kept = append(kept, s)
+		}
+	}
+	// longest first, so a secret containing another is masked whole
+	slices.SortFunc(kept, func(a, b string) int { return cmp.Compare(len(b), len(a)) })
+	return &Redactor{secrets: kept}
+}
+
+// Redact masks secrets and personal data in s
+func (r *Redactor) Redact(s string) string {
+	if r != nil {
+		for _, secret := range r.secrets {
+			s = strings.ReplaceAll(s, secret, "[REDACTED]")
+		}
+	}
+	for _, p := range patterns {
+		s = p.re.ReplaceAllString(s, p.repl)
+	}
+	return s
+}
+
+// Messages returns redacted copies of msgs, tool call arguments included
+func (r *Redactor) Messages(msgs []models.TranscriptMessage) []models.TranscriptMessage {
+	out := make([]models.TranscriptMessage, len(msgs))
+	for i, m := range msgs {
+		m.Content = r.Redact(m.Content)
+		if len(m.ToolCalls) > 0 {
+			calls := slices.Clone(m.ToolCalls)
+			for j := range calls {
+				calls[j].Function.Arguments = r.Redact(calls[j].Function.Arguments)
+			}
+			m.ToolCalls = calls
+		}
+		out[i] = m
+	}
+	return out
+}
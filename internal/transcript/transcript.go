@@ -0,0 +1,74 @@
+// Package transcript exports the LLM conversation of a run for review, with
+// secrets and personal data redacted.
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// FromBag returns the transcript recorded in the shared bag
+func FromBag(sharedBag bag.SharedBag) []models.TranscriptMessage {
+	v, ok := sharedBag.Get(bag.KTranscript)
+	if !ok {
+		return nil
+	}
+	msgs, _ := v.([]models.TranscriptMessage)
+	return msgs
+}
+
+// Export writes the redacted transcript to path: JSON when the extension is
+// .json, markdown otherwise
+func Export(fsys fs.FS, path string, msgs []models.TranscriptMessage, r *Redactor) error {
+	redacted := r.Messages(msgs)
+
+	var data []byte
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var err error
+		if data, err = json.MarshalIndent(redacted, "", "  "); err != nil {
+			return fmt.Errorf("failed to marshal transcript: %w", err)
+		}
+	} else {
+		data = []byte(Markdown(redacted))
+	}
+
+	if dir := filepath.Dir(path); dir != "." {
+		if err := fs.EnsureDir(fsys, dir); err != nil {
+			return fmt.Errorf("failed to create transcript directory: %w", err)
+		}
+	}
+	if err := fsys.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+// Markdown renders the transcript one section per message
+func Markdown(msgs []models.TranscriptMessage) string {
+	var b strings.Builder
+	b.WriteString("# LLM Conversation Transcript\n")
+	for i, m := range msgs {
+		fmt.Fprintf(&b, "\n## %d. %s", i+1, m.Role)
+		switch {
+		case m.Model != "":
+			fmt.Fprintf(&b, " (%s)", m.Model)
+		case m.ToolCallID != "":
+			fmt.Fprintf(&b, " (`%s`)", m.ToolCallID)
+		}
+		fmt.Fprintf(&b, "\n\n_%s_\n", m.CreatedAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+
+		if m.Content != "" {
+			fmt.Fprintf(&b, "\n%s\n", m.Content)
+		}
+		for _, call := range m.ToolCalls {
+			fmt.Fprintf(&b, "\n**Tool call** `%s` (`%s`):\n\n```json\n%s\n```\n", call.Function.Name, call.CallID, call.Function.Arguments)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package transcript
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	r := NewRedactor("fmp-secret-value", "abc", "")
+
+	cases := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{name: "configured secret", in: "url?apikey=fmp-secret-value", want: "url?apikey=[REDACTED]"},
+		{name: "short secret ignored", in: "abc is fine", want: "abc is fine"},
+		{name: "openai key", in: "key sk-proj-ABCDEFGHIJKLMNOP1234", want: "key [REDACTED_KEY]"},
+		{name: "bearer token", in: "Authorization: Bearer eyJhbGciOi.x.y", want: "Authorization: Bearer [REDACTED]"},
+		{name: "key value", in: `{"password": "hunter22"}`, want: `{"password": "[REDACTED]"}`},
+		{name: "email", in: "contact jane.doe@example.com today", want: "contact [REDACTED_EMAIL] today"},
+		{name: "iban", in: "pay to FR76 3000 6000 0112 3456 7890 189", want: "pay to [REDACTED_IBAN]"},
+		{name: "phone", in: "call +33 6 12 34 56 78", want: "call [REDACTED_PHONE]"},
+		{name: "figures untouched", in: "VOO is 18.5% of 120000 EUR", want: "VOO is 18.5% of 120000 EUR"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, r.Redact(c.in))
+		})
+	}
+}
+
+func TestExport(t *testing.T) {
+	at := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+	msgs := []models.TranscriptMessage{
+		{Role: models.RoleUser, Content: "I am jane@example.com", CreatedAt: at},
+		{
+			Role:      models.RoleAssistant,
+			Model:     "gpt-4o",
+			ToolCalls: []models.ToolCall{{CallID: "call_1", Function: models.ToolCallFunction{Name: "fmp", Arguments: `{"apikey":"fmp-secret-value"}`}}},
+			CreatedAt: at,
+		},
+		{Role: models.RoleTool, Content: `{"price":500}`, ToolCallID: "call_1", CreatedAt: at},
+	}
+	r := NewRedactor("fmp-secret-value")
+
+	cases := []struct {
+		name     string
+		file     string
+		validate func(t *testing.T, data []byte)
+	}{
+		{
+			name: "markdown",
+			file: "out/transcript.md",
+			validate: func(t *testing.T, data []byte) {
+				s := string(data)
+				assert.Contains(t, s, "# LLM Conversation Transcript")
+				assert.Contains(t, s, "## 1. user\n\n_2026-10-16 09:30:00 UTC_\n\nI am [REDACTED_EMAIL]")
+				assert.Contains(t, s, "## 2. assistant (gpt-4o)")
+				assert.Contains(t, s, "**Tool call** `fmp` (`call_1`)")
+				assert.Contains(t, s, "## 3. tool (`call_1`)")
+				assert.NotContains(t, s, "fmp-secret-value")
+			},
+		},
+		{
+			name: "json",
+			file: "transcript.json",
+			validate: func(t *testing.T, data []byte) {
+				var got []models.TranscriptMessage
+				require.NoError(t, json.Unmarshal(data, &got))
+				require.Len(t, got, 3)
+				assert.Equal(t, "I am [REDACTED_EMAIL]", got[0].Content)
+				assert.Equal(t, `{"apikey":"[REDACTED]"}`, got[1].ToolCalls[0].Function.Arguments)
+				assert.Equal(t, "call_1", got[2].ToolCallID)
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), c.file)
+			require.NoError(t, Export(fs.OS{}, path, msgs, r))
+
+			data, err := os.ReadFile(path)
+			require.NoError(t, err)
+			c.validate(t, data)
+		})
+	}
+
+	// the recorded messages are left untouched
+	assert.Equal(t, "I am jane@example.com", msgs[0].Content)
+}
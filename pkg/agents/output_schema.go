@@ -0,0 +1,186 @@
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/openai/openai-go/v2/responses"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// ErrSchemaMismatch is returned (wrapped) when a manual response format disagrees
+// with the schema generated for the agent's output type
+var ErrSchemaMismatch = errors.New("response_format does not match the output type schema")
+
+// CheckOutputSchema verifies that rf, a response format set by hand, asks for the
+// schema the SDK generates for outputType. It is a dry run: nothing is sent. Either
+// side being unset is compatible.
+func CheckOutputSchema(outputType agents.OutputTypeInterface, rf *models.ResponseFormat) error {
+	if outputType == nil || rf == nil {
+		return nil
+	}
+	if outputType.IsPlainText() {
+		return fmt.Errorf("%w: output type %s is plain text, response_format asks for %s",
+			ErrSchemaMismatch, outputType.Name(), rf.Format.Type)
+	}
+	if rf.Format.Type != bag.ResponseFormatJSON {
+		return fmt.Errorf("%w: output type %s needs %s, response_format asks for %q",
+			ErrSchemaMismatch, outputType.Name(), bag.ResponseFormatJSON, rf.Format.Type)
+	}
+
+	want, err := outputType.JSONSchema()
+	if err != nil {
+		return fmt.Errorf("failed to generate the schema of output type %s: %w", outputType.Name(), err)
+	}
+	wantNorm, err := normalizeSchema(want)
+	if err != nil {
+		return fmt.Errorf("output type %s: %w", outputType.Name(), err)
+	}
+	gotNorm, err := normalizeSchema(rf.Format.Schema)
+	if err != nil {
+		return fmt.Errorf("response_format %s: %w", rf.Format.Name, err)
+	}
+	if path, ok := schemaDiff(wantNorm, gotNorm, "$"); !ok {
+		return fmt.Errorf("%w: output type %s and response_format %s differ at %s",
+			ErrSchemaMismatch, outputType.Name(), rf.Format.Name, path)
+	}
+	return nil
+}
+
+// Run runs agent on input. rf, when set, is sent as the response format of an
+// agent without output type; an agent with one must agree with it, which is
+// checked before any call.
+func Run(ctx context.Context, runner agents.Runner, agent *agents.Agent, input string, rf *models.ResponseFormat) (*agents.RunResult, error) {
+	if err := CheckOutputSchema(agent.OutputType, rf); err != nil {
+		return nil, fmt.Errorf("agent %s: %w", agent.Name, err)
+	}
+	if rf != nil && agent.OutputType == nil {
+		withFormat := *agent
+		withFormat.ModelSettings.CustomizeResponsesRequest = sendResponseFormat(agent.ModelSettings.CustomizeResponsesRequest, rf)
+		agent = &withFormat
+	}
+	return runner.Run(ctx, agent, input)
+}
+
+type customizeResponsesRequest = func(context.Context, *responses.ResponseNewParams, []option.RequestOption) (*responses.ResponseNewParams, []option.RequestOption, error)
+
+// sendResponseFormat sets rf as the text format of every Responses request, after
+// the agent's own customization if any
+func sendResponseFormat(next customizeResponsesRequest, rf *models.ResponseFormat) customizeResponsesRequest {
+	return func(ctx context.Context, params *responses.ResponseNewParams, opts []option.RequestOption) (*responses.ResponseNewParams, []option.RequestOption, error) {
+		if next != nil {
+			var err error
+			if params, opts, err = next(ctx, params, opts); err != nil {
+				return nil, nil, err
+			}
+		}
+		params.Text = responses.ResponseTextConfigParam{
+			Format: responses.ResponseFormatTextConfigUnionParam{
+				OfJSONSchema: &responses.ResponseFormatTextJSONSchemaConfigParam{
+					Name:   rf.Format.Name,
+					Schema: rf.Format.Schema,
+				},
+			},
+		}
+		return params, opts, nil
+	}
+}
+
+// normalizeSchema round-trips a schema through JSON, so that typed values
+// ([]string, json.RawMessage...) compare equal to their decoded form
+func normalizeSchema(schema map[string]any) (any, error) {
+	raw, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	var out any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return out, nil
+}
+
+// annotations do not constrain the output and are not compared
+var annotations = []string{"$schema", "$id", "$comment", "title", "description", "examples"}
+
+// schemaDiff reports the first path where two normalized schemas differ
+func schemaDiff(want, got any, path string) (string, bool) {
+	w, wok := want.(map[string]any)
+	g, gok := got.(map[string]any)
+	if !wok || !gok {
+		// boolean schemas, e.g. additionalProperties: false
+		return path, reflect.DeepEqual(want, got)
+	}
+
+	keys := slices.DeleteFunc(unionKeys(w, g), func(k string) bool { return slices.Contains(annotations, k) })
+	for _, k := range keys {
+		p := path + "." + k
+		switch k {
+		case "properties", "patternProperties", "$defs", "definitions":
+			wn, _ := w[k].(map[string]any)
+			gn, _ := g[k].(map[string]any)
+			for _, name := range unionKeys(wn, gn) {
+				if dp, ok := schemaDiff(wn[name], gn[name], p+"."+name); !ok {
+					return dp, false
+				}
+			}
+		case "items", "additionalProperties", "not":
+			if dp, ok := schemaDiff(w[k], g[k], p); !ok {
+				return dp, false
+			}
+		case "anyOf", "oneOf", "allOf", "prefixItems":
+			wl, _ := w[k].([]any)
+			gl, _ := g[k].([]any)
+			if len(wl) != len(gl) {
+				return p, false
+			}
+			for i := range wl {
+				if dp, ok := schemaDiff(wl[i], gl[i], fmt.Sprintf("%s[%d]", p, i)); !ok {
+					return dp, false
+				}
+			}
+		case "required":
+			// the order of required fields does not matter
+			if !reflect.DeepEqual(sortedStrings(w[k]), sortedStrings(g[k])) {
+				return p, false
+			}
+		default:
+			if !reflect.DeepEqual(w[k], g[k]) {
+				return p, false
+			}
+		}
+	}
+	return "", true
+}
+
+// unionKeys returns the keys of both maps in a stable order
+func unionKeys(w, g map[string]any) []string {
+	keys := slices.Collect(maps.Keys(w))
+	for k := range g {
+		if _, ok := w[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func sortedStrings(v any) []string {
+	list, _ := v.([]any)
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		s, _ := item.(string)
+		out = append(out, s)
+	}
+	slices.Sort(out)
+	return out
+}
@@ -0,0 +1,108 @@
+package agents
+
+import (
+	"context"
+	"testing"
+
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/bag"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+type allocation struct {
+	Ticker      string  `json:"ticker"`
+	Weight      float64 `json:"weight"`
+	Description string  `json:"description"`
+}
+
+func TestCheckOutputSchema(t *testing.T) {
+	outputType := agents.OutputType[allocation]()
+	generated, err := outputType.JSONSchema()
+	require.NoError(t, err)
+
+	format := func(schema map[string]any) *models.ResponseFormat {
+		return &models.ResponseFormat{Format: models.Format{Type: bag.ResponseFormatJSON, Name: "allocation", Schema: schema}}
+	}
+	// a hand-written copy of the generated schema, with typed values
+	manual := map[string]any{
+		"type":                 "object",
+		"title":                "Allocation",
+		"additionalProperties": false,
+		"required":             []string{"weight", "ticker", "description"},
+		"properties": map[string]any{
+			"ticker":      map[string]any{"type": "string", "description": "listing symbol"},
+			"weight":      map[string]any{"type": "number"},
+			"description": map[string]any{"type": "string"},
+		},
+	}
+	mismatched := map[string]any{
+		"type":                 "object",
+		"additionalProperties": false,
+		"required":             []string{"ticker", "weight", "description"},
+		"properties": map[string]any{
+			"ticker":      map[string]any{"type": "string"},
+			"weight":      map[string]any{"type": "string"},
+			"description": map[string]any{"type": "string"},
+		},
+	}
+
+	cases := []struct {
+		name       string
+		outputType agents.OutputTypeInterface
+		rf         *models.ResponseFormat
+		wantPath   string
+		wantErr    bool
+	}{
+		{name: "no response format", outputType: outputType},
+		{name: "no output type", rf: format(mismatched)},
+		{name: "generated schema", outputType: outputType, rf: format(generated)},
+		{name: "equivalent manual schema, annotations and required order aside", outputType: outputType, rf: format(manual)},
+		{name: "mismatched property type", outputType: outputType, rf: format(mismatched), wantErr: true, wantPath: "$.properties.weight.type"},
+		{
+			name:       "missing property named like an annotation",
+			outputType: outputType,
+			rf: format(map[string]any{
+				"type": "object", "additionalProperties": false, "required": []string{"ticker", "weight"},
+				"properties": map[string]any{"ticker": map[string]any{"type": "string"}, "weight": map[string]any{"type": "number"}},
+			}),
+			wantErr:  true,
+			wantPath: "$.properties.description",
+		},
+		{
+			name:       "plain text response format",
+			outputType: outputType,
+			rf:         &models.ResponseFormat{Format: models.Format{Type: "text"}},
+			wantErr:    true,
+		},
+		{name: "plain text output type", outputType: agents.OutputType[string](), rf: format(generated), wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := CheckOutputSchema(c.outputType, c.rf)
+			if !c.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			require.ErrorIs(t, err, ErrSchemaMismatch)
+			assert.Contains(t, err.Error(), c.wantPath)
+		})
+	}
+}
+
+func TestRun_SchemaMismatch(t *testing.T) {
+	agent := agents.New("allocator").WithOutputType(agents.OutputType[allocation]())
+	rf := &models.ResponseFormat{Format: models.Format{
+		Type:   bag.ResponseFormatJSON,
+		Name:   "allocation",
+		Schema: map[string]any{"type": "object", "properties": map[string]any{"weights": map[string]any{"type": "array"}}},
+	}}
+
+	// the check fails before the model is called
+	_, err := Run(context.Background(), agents.Runner{}, agent, "allocate", rf)
+	require.ErrorIs(t, err, ErrSchemaMismatch)
+	assert.ErrorContains(t, err, "agent allocator")
+}
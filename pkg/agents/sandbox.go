@@ -0,0 +1,30 @@
+package agents
+
+import (
+	"net/http"
+
+	"github.com/nlpodyssey/openai-agents-go/agents"
+	"github.com/openai/openai-go/v2/option"
+	"github.com/openai/openai-go/v2/packages/param"
+
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+// UseSandboxClient installs the SDK's default OpenAI client with a transport
+// wrapped by sandbox.Guard, so agent runs are answered by the sandbox stubs or
+// fail with sandbox.ErrNetworkDisabled. Retries are off: a blocked request never
+// succeeds later. An empty baseURL keeps the SDK default.
+func UseSandboxClient(baseURL, apiKey string) {
+	var base, key param.Opt[string]
+	if baseURL != "" {
+		base = param.NewOpt(baseURL)
+	}
+	if apiKey != "" {
+		key = param.NewOpt(apiKey)
+	}
+	client := agents.NewOpenaiClient(base, key,
+		option.WithHTTPClient(&http.Client{Transport: sandbox.Guard(http.DefaultTransport)}),
+		option.WithMaxRetries(0),
+	)
+	agents.SetDefaultOpenaiClient(client, false)
+}
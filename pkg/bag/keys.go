@@ -58,12 +58,17 @@ const (
 	KPortfolioPerformanceData Key = "portfolio.performance_data" // Performance metrics
 	KPortfolioComplianceData  Key = "portfolio.compliance_data"  // Compliance analysis
 	KPortfolioNormalizedForAI Key = "portfolio.normalized_ai"    // AI-normalized portfolio data
+	KConcentrationAlerts      Key = "portfolio.alerts"           // Triggered concentration alerts
+	KHealthScore              Key = "portfolio.health_score"     // Portfolio health score and components
+	KAllocationGaps           Key = "portfolio.allocation_gaps"  // Gaps to the profile's recommended allocation
+	KRebalancePlan            Key = "portfolio.rebalance_plan"   // Trades toward the recommended allocation
 
 	// === MARKET & ECONOMIC DATA ===
 	KMacro                Key = "macro"                 // Macroeconomic data
 	KMacroDataNormalized  Key = "macro.normalized"      // Normalized macro data
 	KMarketDataNormalized Key = "market.normalized"     // Normalized market data
 	KMarketDataFreshness  Key = "market_data_freshness" // Age/quality of market data
+	KMarketPrices         Key = "market.prices"         // Market unit prices by symbol, base currency
 	KFundamentals         Key = "fundamentals"          // Fundamental analysis data
 	KStockAnalysis        Key = "stock_analysis"        // Individual stock analysis
 
@@ -84,8 +89,9 @@ const (
 	KInvestmentResearchResult Key = "investment_research_result" // Investment research output
 
 	// === EXECUTION & REPORTING ===
-	KExecutionReport Key = "execution_report" // Execution report
-	KPack            Key = "context_pack"     // Context package for AI
+	KExecutionReport   Key = "execution_report"    // Execution report
+	KBatchRunSummaries Key = "batch_run_summaries" // Batch engine run summaries by engine name
+	KPack              Key = "context_pack"        // Context package for AI
 
 	// === PERFORMANCE & MONITORING ===
 	KToolComputations   Key = "tool_computations"    // Tool computation tracking
@@ -97,6 +103,8 @@ const (
 	KApplicationHealth  Key = "application_health"   // Overall system health status
 	KPerformanceMetrics Key = "performance_metrics"  // Application performance metrics
 	KExternalDataHealth Key = "external_data_health" // External data provider health
+	KReasoningTraces    Key = "reasoning_traces"     // Reasoning summaries of reasoning models
+	KTranscript         Key = "llm_transcript"       // LLM conversation messages of the run
 
 	ResponseFormatJSON Key = "json_schema" // JSON schema response format
 )
@@ -104,7 +112,8 @@ const (
 // === EXTERNAL TOOLS & DATA SOURCES ===
 const (
 	// Web & Search
-	WebSearch Key = "web_search_preview" // Web search functionality
+	WebSearch           Key = "web_search_preview"           // Web search functionality
+	KWebSearchCitations Key = "web_search_preview.citations" // Citations collected from web search output
 
 	// News & Information
 	NewsAPI       Key = "news_api"       // NewsAPI service
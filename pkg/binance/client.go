@@ -16,6 +16,7 @@ import (
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
 )
 
 // client implements the Client interface for Binance REST API
@@ -34,7 +35,7 @@ func New(cfg *config.BinanceConfig) Client {
 
 	return &client{
 		config:     cfg,
-		httpClient: &http.Client{Timeout: 30 * time.Second},
+		httpClient: &http.Client{Timeout: 30 * time.Second, Transport: sandbox.Guard(nil)},
 		baseURL:    baseURL,
 	}
 }
@@ -0,0 +1,225 @@
+// Package demo generates deterministic, realistic fixtures for tests, benchmarks
+// and demos.
+package demo
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// Options tunes the generated portfolio; zero values use the defaults
+type Options struct {
+	// BaseCurrency of the portfolio (default USD)
+	BaseCurrency string
+	// AsOf date of the portfolio (default 2025-01-02, fixed so output is reproducible)
+	AsOf time.Time
+	// TotalValue is the approximate portfolio value in base currency (default 250,000)
+	TotalValue float64
+	// Accounts is the number of brokerage accounts holdings are spread across (default 1)
+	Accounts int
+}
+
+const (
+	defaultBaseCurrency = "USD"
+	defaultTotalValue   = 250_000.0
+)
+
+var defaultAsOf = time.Date(2025, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+// instrument is a security the generator can hold
+type instrument struct {
+	ticker   string
+	name     string
+	isin     string
+	kind     models.AssetType
+	sector   string
+	region   string
+	currency string
+	price    float64
+}
+
+// universe holds realistic instruments, picked in seeded order
+var universe = []instrument{
+	{"AAPL", "Apple Inc.", "US0378331005", models.Stock, "Technology", "US", "USD", 190},
+	{"MSFT", "Microsoft Corp.", "US5949181045", models.Stock, "Technology", "US", "USD", 410},
+	{"NVDA", "NVIDIA Corp.", "US67066G1040", models.Stock, "Technology", "US", "USD", 130},
+	{"GOOGL", "Alphabet Inc. Class A", "US02079K3059", models.Stock, "Communication Services", "US", "USD", 170},
+	{"AMZN", "Amazon.com Inc.", "US0231351067", models.Stock, "Consumer Discretionary", "US", "USD", 185},
+	{"JPM", "JPMorgan Chase & Co.", "US46625H1005", models.Stock, "Financials", "US", "USD", 200},
+	{"JNJ", "Johnson & Johnson", "US4781601046", models.Stock, "Healthcare", "US", "USD", 155},
+	{"XOM", "Exxon Mobil Corp.", "US30231G1022", models.Stock, "Energy", "US", "USD", 115},
+	{"PG", "Procter & Gamble Co.", "US7427181091", models.Stock, "Consumer Staples", "US", "USD", 165},
+	{"ASML.AS", "ASML Holding N.V.", "NL0010273215", models.Stock, "Technology", "Europe", "EUR", 680},
+	{"MC.PA", "LVMH Moët Hennessy", "FR0000121014", models.Stock, "Consumer Discretionary", "Europe", "EUR", 690},
+	{"SAP.DE", "SAP SE", "DE0007164600", models.Stock, "Technology", "Europe", "EUR", 210},
+	{"NESN.SW", "Nestlé S.A.", "CH0038863350", models.Stock, "Consumer Staples", "Europe", "CHF", 95},
+	{"NOVO-B.CO", "Novo Nordisk A/S", "DK0062498333", models.Stock, "Healthcare", "Europe", "DKK", 850},
+	{"SHEL.L", "Shell plc", "GB00BP6MXD84", models.Stock, "Energy", "Europe", "GBP", 26},
+	{"7203.T", "Toyota Motor Corp.", "JP3633400001", models.Stock, "Consumer Discretionary", "Asia", "JPY", 2800},
+	{"TSM", "Taiwan Semiconductor ADR", "US8740391003", models.Stock, "Technology", "Asia", "USD", 170},
+	{"VWCE.DE", "Vanguard FTSE All-World UCITS ETF", "IE00BK5BQT80", models.ETF, "Diversified", "Global", "EUR", 120},
+	{"VTI", "Vanguard Total Stock Market ETF", "US9229087690", models.ETF, "Diversified", "US", "USD", 270},
+	{"IWDA.AS", "iShares Core MSCI World UCITS ETF", "IE00B4L5Y983", models.ETF, "Diversified", "Global", "EUR", 95},
+	{"EIMI.L", "iShares Core MSCI EM IMI UCITS ETF", "IE00BKM4GZ66", models.ETF, "Diversified", "Emerging", "USD", 32},
+	{"VNQ", "Vanguard Real Estate ETF", "US9229085538", models.REIT, "Real Estate", "US", "USD", 88},
+	{"AGG", "iShares Core US Aggregate Bond ETF", "US4642872265", models.BondIG, "Fixed Income", "US", "USD", 98},
+	{"IEF", "iShares 7-10 Year Treasury Bond ETF", "US4642874402", models.BondGov, "Fixed Income", "US", "USD", 94},
+	{"TIP", "iShares TIPS Bond ETF", "US4642871762", models.BondIL, "Fixed Income", "US", "USD", 108},
+	{"HYG", "iShares iBoxx High Yield Corporate Bond ETF", "US4642885135", models.BondHY, "Fixed Income", "US", "USD", 78},
+	{"IEAC.L", "iShares Core EUR Corp Bond UCITS ETF", "IE00B3F81R35", models.BondCorp, "Fixed Income", "Europe", "EUR", 122},
+	{"EMB", "iShares J.P. Morgan USD EM Bond ETF", "US4642882819", models.BondEM, "Fixed Income", "Emerging", "USD", 90},
+	{"GLD", "SPDR Gold Shares", "US78463V1070", models.Metal, "Commodities", "Global", "USD", 240},
+	{"DBC", "Invesco DB Commodity Index Tracking Fund", "US46138B1035", models.CommodityBroad, "Commodities", "Global", "USD", 22},
+	{"BTC", "Bitcoin", "", models.CryptoCore, "Crypto", "Global", "USD", 95_000},
+	{"ETH", "Ethereum", "", models.Crypto, "Crypto", "Global", "USD", 3_400},
+}
+
+// synthetic fallbacks used once the universe is exhausted
+var (
+	syntheticSectors = []string{"Technology", "Healthcare", "Financials", "Industrials", "Consumer Staples", "Utilities"}
+	syntheticRegions = []struct{ region, currency string }{{"US", "USD"}, {"Europe", "EUR"}, {"Asia", "JPY"}, {"Emerging", "USD"}}
+)
+
+// GeneratePortfolio builds a valid portfolio of nHoldings positions. The same seed,
+// holding count and options always produce the same portfolio. Holdings beyond the
+// built-in universe get synthetic DEMO tickers.
+func GeneratePortfolio(seed int64, nHoldings int, opts Options) *models.Portfolio {
+	opts = opts.withDefaults()
+	r := rand.New(rand.NewPCG(uint64(seed), uint64(seed)^0x9e3779b97f4a7c15))
+
+	instruments := pickInstruments(r, nHoldings)
+	weights := randomWeights(r, len(instruments))
+
+	brokerage := make([]models.Account, opts.Accounts)
+	for i := range brokerage {
+		brokerage[i] = models.Account{
+			ID:       fmt.Sprintf("demo-brokerage-%d", i+1),
+			Name:     fmt.Sprintf("Demo Brokerage %d", i+1),
+			Type:     models.AccountBrokerage,
+			Currency: opts.BaseCurrency,
+			Provider: "demo",
+			Balance:  roundTo(r.Float64()*0.02*opts.TotalValue, 2),
+		}
+	}
+	exchange := models.Account{
+		ID:       "demo-exchange",
+		Name:     "Demo Crypto Exchange",
+		Type:     models.AccountExchange,
+		Currency: "USD",
+		Provider: "demo",
+	}
+
+	for i, in := range instruments {
+		h := holdingFor(r, in, weights[i]*opts.TotalValue)
+		if in.kind == models.Crypto || in.kind == models.CryptoCore {
+			exchange.Holdings = append(exchange.Holdings, h)
+			continue
+		}
+		acct := &brokerage[r.IntN(len(brokerage))]
+		acct.Holdings = append(acct.Holdings, h)
+	}
+
+	accounts := brokerage
+	if len(exchange.Holdings) > 0 {
+		accounts = append(accounts, exchange)
+	}
+
+	return &models.Portfolio{
+		AsOf:         opts.AsOf.Format("2006-01-02"),
+		BaseCurrency: opts.BaseCurrency,
+		Accounts:     accounts,
+	}
+}
+
+func (o Options) withDefaults() Options {
+	if o.BaseCurrency == "" {
+		o.BaseCurrency = defaultBaseCurrency
+	}
+	if o.AsOf.IsZero() {
+		o.AsOf = defaultAsOf
+	}
+	if o.TotalValue <= 0 {
+		o.TotalValue = defaultTotalValue
+	}
+	if o.Accounts <= 0 {
+		o.Accounts = 1
+	}
+	return o
+}
+
+// pickInstruments returns n instruments in seeded order, synthesizing extra ones
+// when n exceeds the universe
+func pickInstruments(r *rand.Rand, n int) []instrument {
+	if n <= 0 {
+		return nil
+	}
+	picked := make([]instrument, 0, n)
+	for _, i := range r.Perm(len(universe)) {
+		if len(picked) == n {
+			return picked
+		}
+		picked = append(picked, universe[i])
+	}
+	for i := len(picked); i < n; i++ {
+		loc := syntheticRegions[r.IntN(len(syntheticRegions))]
+		picked = append(picked, instrument{
+			ticker:   fmt.Sprintf("DEMO%03d", i+1),
+			name:     fmt.Sprintf("Demo Holding %d", i+1),
+			kind:     models.Stock,
+			sector:   syntheticSectors[r.IntN(len(syntheticSectors))],
+			region:   loc.region,
+			currency: loc.currency,
+			price:    roundTo(10+r.Float64()*290, 2),
+		})
+	}
+	return picked
+}
+
+// randomWeights draws n positive weights summing to 1 with a realistic spread
+// (a few large positions, a long tail)
+func randomWeights(r *rand.Rand, n int) []float64 {
+	weights := make([]float64, n)
+	var sum float64
+	for i := range weights {
+		weights[i] = r.ExpFloat64()
+		sum += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= sum
+	}
+	return weights
+}
+
+// holdingFor sizes a position of roughly value, priced near the instrument price
+func holdingFor(r *rand.Rand, in instrument, value float64) models.Holding {
+	price := in.price * (0.9 + 0.2*r.Float64())
+
+	var quantity float64
+	switch in.kind {
+	case models.Crypto, models.CryptoCore:
+		quantity = math.Max(0.0001, roundTo(value/price, 4))
+	default:
+		quantity = math.Max(1, math.Round(value/price))
+	}
+
+	return models.Holding{
+		Ticker:    in.ticker,
+		Name:      in.name,
+		ISIN:      in.isin,
+		Quantity:  quantity,
+		CostBasis: roundTo(price*(0.7+0.6*r.Float64()), 2),
+		Currency:  in.currency,
+		Type:      in.kind,
+		Sector:    in.sector,
+		Region:    in.region,
+	}
+}
+
+func roundTo(v float64, decimals int) float64 {
+	p := math.Pow(10, float64(decimals))
+	return math.Round(v*p) / p
+}
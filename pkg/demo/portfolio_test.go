@@ -0,0 +1,60 @@
+package demo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/internal/portfolio"
+)
+
+func TestGeneratePortfolio(t *testing.T) {
+	cases := []struct {
+		name      string
+		seed      int64
+		nHoldings int
+		opts      Options
+	}{
+		{name: "small default", seed: 1, nHoldings: 5},
+		{name: "whole universe", seed: 42, nHoldings: len(universe)},
+		{name: "synthetic overflow", seed: 7, nHoldings: 120, opts: Options{Accounts: 3}},
+		{name: "eur base", seed: 99, nHoldings: 12, opts: Options{BaseCurrency: "EUR", AsOf: time.Date(2024, 6, 30, 0, 0, 0, 0, time.UTC)}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			p := GeneratePortfolio(c.seed, c.nHoldings, c.opts)
+
+			// same seed, same portfolio
+			assert.Equal(t, p, GeneratePortfolio(c.seed, c.nHoldings, c.opts))
+			assert.NotEqual(t, p, GeneratePortfolio(c.seed+1, c.nHoldings, c.opts))
+
+			count := 0
+			for _, a := range p.Accounts {
+				count += len(a.Holdings)
+			}
+			assert.Equal(t, c.nHoldings, count)
+			assert.Len(t, p.Tickers(), c.nHoldings, "tickers must be unique")
+
+			require.NoError(t, portfolio.NewBasicValidator().Validate(context.Background(), p))
+
+			normalized, err := p.Normalize()
+			require.NoError(t, err)
+			assert.Equal(t, c.nHoldings, normalized.HoldingsCount)
+			assert.Positive(t, normalized.TotalValue)
+		})
+	}
+}
+
+func BenchmarkPortfolioNormalize(b *testing.B) {
+	p := GeneratePortfolio(1, 200, Options{Accounts: 4})
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := p.Normalize(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package demo
+
+import "github.com/amaurybrisou/mosychlos/pkg/models"
+
+// Profile returns the investment profile of the demo investor: a moderate, US
+// based, balanced investor matching the generated portfolios
+func Profile() *models.InvestmentProfile {
+	return &models.InvestmentProfile{
+		InvestmentStyle:  "balanced",
+		ResearchDepth:    "intermediate",
+		RiskTolerance:    models.RiskModerate,
+		PreferredAssets:  []string{"stocks", "etfs", "bonds"},
+		TimeHorizonYears: 15,
+		RegionalContext: models.RegionalInvestmentContext{
+			Country:  "US",
+			Language: "en",
+			Currency: defaultBaseCurrency,
+			Timezone: "America/New_York",
+		},
+		PreferredAnalysisTypes: []models.AnalysisType{models.AnalysisRisk},
+		ProfileVersion:         "1.0",
+		Source:                 "demo",
+	}
+}
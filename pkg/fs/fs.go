@@ -2,6 +2,7 @@
 package fs
 
 import (
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -25,6 +26,7 @@ type FS interface {
 	Stat(path string) (fs.FileInfo, error)
 	Remove(path string) error
 	Rename(oldPath, newPath string) error
+	Create(path string) (io.WriteCloser, error)
 }
 
 // OS implements FS using the local operating system.
@@ -71,6 +73,14 @@ func (o OS) Rename(oldPath, newPath string) error {
 	return os.Rename(oldPath, newPath)
 }
 
+// Create creates or truncates the named file and returns a writer for streaming content into it.
+func (o OS) Create(path string) (io.WriteCloser, error) {
+	if o.RootPath != "" {
+		path = filepath.Join(o.RootPath, path)
+	}
+	return os.Create(path)
+}
+
 // EnsureDir ensures a directory relative or absolute exists.
 func EnsureDir(fsys FS, dir string) error { return fsys.MkdirAll(dir, 0o755) }
 
@@ -86,3 +96,9 @@ func (TMP) MkdirAll(path string, perm fs.FileMode) error               { return
 func (TMP) Stat(path string) (fs.FileInfo, error)                      { return nil, os.ErrNotExist }
 func (TMP) Remove(path string) error                                   { return nil }
 func (TMP) Rename(o, n string) error                                   { return nil }
+func (TMP) Create(path string) (io.WriteCloser, error)                 { return nopWriteCloser{io.Discard}, nil }
+
+// nopWriteCloser wraps a writer with a no-op Close.
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
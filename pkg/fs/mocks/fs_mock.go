@@ -5,6 +5,7 @@
 package mocks
 
 import (
+	io "io"
 	fs "io/fs"
 	reflect "reflect"
 
@@ -34,6 +35,21 @@ func (m *MockFS) EXPECT() *MockFSMockRecorder {
 	return m.recorder
 }
 
+// Create mocks base method.
+func (m *MockFS) Create(path string) (io.WriteCloser, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Create", path)
+	ret0, _ := ret[0].(io.WriteCloser)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Create indicates an expected call of Create.
+func (mr *MockFSMockRecorder) Create(path interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockFS)(nil).Create), path)
+}
+
 // MkdirAll mocks base method.
 func (m *MockFS) MkdirAll(path string, perm fs.FileMode) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,176 @@
+// Package jsonpath evaluates a small subset of JSONPath against decoded JSON
+// (the output of json.Unmarshal into any).
+//
+// Supported syntax: the root `$`, child members `.name` and `['name']`, array
+// indexes `[n]` (negative indexes count from the end) and the `*` wildcard
+// (`.*` or `[*]`). A path with a wildcard always yields a list.
+package jsonpath
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ErrNotFound is returned when a path without wildcard matches nothing
+var ErrNotFound = errors.New("jsonpath: no match")
+
+type segmentKind int
+
+const (
+	segKey segmentKind = iota
+	segIndex
+	segWildcard
+)
+
+type segment struct {
+	kind  segmentKind
+	key   string
+	index int
+}
+
+// Path is a compiled JSONPath expression
+type Path struct {
+	raw      string
+	segments []segment
+	wildcard bool
+}
+
+// Compile parses a JSONPath expression
+func Compile(expr string) (*Path, error) {
+	s := strings.TrimSpace(expr)
+	if !strings.HasPrefix(s, "$") {
+		return nil, fmt.Errorf("jsonpath %q: must start with $", expr)
+	}
+	p := &Path{raw: expr}
+	s = s[1:]
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '.':
+			s = s[1:]
+			if strings.HasPrefix(s, ".") {
+				return nil, fmt.Errorf("jsonpath %q: recursive descent is not supported", expr)
+			}
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			name := s[:end]
+			if name == "" {
+				return nil, fmt.Errorf("jsonpath %q: empty member name", expr)
+			}
+			s = s[end:]
+			if name == "*" {
+				p.segments = append(p.segments, segment{kind: segWildcard})
+				p.wildcard = true
+				continue
+			}
+			p.segments = append(p.segments, segment{kind: segKey, key: name})
+		case '[':
+			end := strings.IndexByte(s, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("jsonpath %q: unterminated bracket", expr)
+			}
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+			seg, err := parseBracket(inner)
+			if err != nil {
+				return nil, fmt.Errorf("jsonpath %q: %w", expr, err)
+			}
+			if seg.kind == segWildcard {
+				p.wildcard = true
+			}
+			p.segments = append(p.segments, seg)
+		default:
+			return nil, fmt.Errorf("jsonpath %q: unexpected character %q", expr, s[0])
+		}
+	}
+	return p, nil
+}
+
+func parseBracket(inner string) (segment, error) {
+	if inner == "*" {
+		return segment{kind: segWildcard}, nil
+	}
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+		return segment{kind: segKey, key: inner[1 : len(inner)-1]}, nil
+	}
+	n, err := strconv.Atoi(inner)
+	if err != nil {
+		return segment{}, fmt.Errorf("invalid subscript [%s]", inner)
+	}
+	return segment{kind: segIndex, index: n}, nil
+}
+
+// String returns the expression the path was compiled from
+func (p *Path) String() string {
+	return p.raw
+}
+
+// Get evaluates the path against doc. Paths with a wildcard return a []any of
+// every match (possibly empty); other paths return the single match or ErrNotFound.
+func (p *Path) Get(doc any) (any, error) {
+	nodes := []any{doc}
+	for _, seg := range p.segments {
+		next := make([]any, 0, len(nodes))
+		for _, n := range nodes {
+			next = append(next, step(n, seg)...)
+		}
+		nodes = next
+	}
+
+	if p.wildcard {
+		return nodes, nil
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("%w for %s", ErrNotFound, p.raw)
+	}
+	return nodes[0], nil
+}
+
+func step(node any, seg segment) []any {
+	switch seg.kind {
+	case segKey:
+		if m, ok := node.(map[string]any); ok {
+			if v, ok := m[seg.key]; ok {
+				return []any{v}
+			}
+		}
+	case segIndex:
+		if a, ok := node.([]any); ok {
+			i := seg.index
+			if i < 0 {
+				i += len(a)
+			}
+			if i >= 0 && i < len(a) {
+				return []any{a[i]}
+			}
+		}
+	case segWildcard:
+		switch v := node.(type) {
+		case []any:
+			return v
+		case map[string]any:
+			// sorted keys keep the output deterministic
+			out := make([]any, 0, len(v))
+			for _, k := range slices.Sorted(maps.Keys(v)) {
+				out = append(out, v[k])
+			}
+			return out
+		}
+	}
+	return nil
+}
+
+// Get compiles expr and evaluates it against doc
+func Get(doc any, expr string) (any, error) {
+	p, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Get(doc)
+}
@@ -0,0 +1,61 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const quoteDoc = `{
+	"meta": {"source": "acme", "as-of": "2026-01-02"},
+	"quotes": [
+		{"symbol": "AAPL", "price": 190.5},
+		{"symbol": "MSFT", "price": 410.25}
+	]
+}`
+
+func TestGet(t *testing.T) {
+	var doc any
+	require.NoError(t, json.Unmarshal([]byte(quoteDoc), &doc))
+
+	cases := []struct {
+		name    string
+		path    string
+		want    any
+		wantErr error
+	}{
+		{name: "root", path: "$", want: doc},
+		{name: "member", path: "$.meta.source", want: "acme"},
+		{name: "bracket member", path: "$.meta['as-of']", want: "2026-01-02"},
+		{name: "index", path: "$.quotes[1].symbol", want: "MSFT"},
+		{name: "negative index", path: "$.quotes[-1].price", want: 410.25},
+		{name: "array wildcard", path: "$.quotes[*].price", want: []any{190.5, 410.25}},
+		{name: "object wildcard", path: "$.meta.*", want: []any{"2026-01-02", "acme"}},
+		{name: "wildcard without match", path: "$.missing[*]", want: []any{}},
+		{name: "missing member", path: "$.meta.currency", wantErr: ErrNotFound},
+		{name: "index out of range", path: "$.quotes[5]", wantErr: ErrNotFound},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := Get(doc, c.path)
+			if c.wantErr != nil {
+				require.ErrorIs(t, err, c.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.want, got)
+		})
+	}
+}
+
+func TestCompile_Invalid(t *testing.T) {
+	for _, expr := range []string{"quotes[0]", "$..price", "$.quotes[0", "$.quotes[x]", "$.", "$quotes"} {
+		t.Run(expr, func(t *testing.T) {
+			_, err := Compile(expr)
+			assert.Error(t, err)
+		})
+	}
+}
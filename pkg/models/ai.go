@@ -4,6 +4,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -111,10 +112,51 @@ type ToolChoice struct {
 	Function ToolChoiceFunction `json:"function"`
 }
 
+// ErrResponseTruncated is returned (wrapped) when the model keeps hitting its output
+// token limit and the response could not be completed
+var ErrResponseTruncated = errors.New("response truncated at token limit")
+
+// ErrContentRefused is matched by the error returned when the model refuses to
+// answer or its answer is blocked by the content filter
+var ErrContentRefused = errors.New("content refused by model")
+
+// RefusalError carries the refusal message of the model; errors.Is matches it
+// against ErrContentRefused
+type RefusalError struct {
+	Model   string
+	Message string
+}
+
+func (e *RefusalError) Error() string {
+	return fmt.Sprintf("%s (model %s): %s", ErrContentRefused, e.Model, e.Message)
+}
+
+func (e *RefusalError) Unwrap() error { return ErrContentRefused }
+
+// Finish reasons reported on an assistant turn, normalized to the Chat Completions vocabulary
+const (
+	FinishReasonStop          = "stop"
+	FinishReasonLength        = "length"
+	FinishReasonToolCalls     = "tool_calls"
+	FinishReasonContentFilter = "content_filter"
+)
+
 type AssistantTurn struct {
-	Content   string     `json:"content"`
-	ToolCalls []ToolCall `json:"function_call,omitempty"`
-	Usage     Usage      `json:"usage,omitempty"`
+	Content      string     `json:"content"`
+	Reasoning    string     `json:"reasoning,omitempty"` // reasoning summary of reasoning models, never part of Content
+	Refusal      string     `json:"refusal,omitempty"`   // refusal message of the model, never part of Content
+	ToolCalls    []ToolCall `json:"function_call,omitempty"`
+	Usage        Usage      `json:"usage,omitempty"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+}
+
+// ReasoningTrace is the reasoning summary a reasoning model returned for one
+// response. It is diagnostic data for the system report only.
+type ReasoningTrace struct {
+	Model      string    `json:"model"`
+	ResponseID string    `json:"response_id"`
+	Summary    string    `json:"summary"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 // StreamChunk represents a chunk of streaming response
@@ -154,6 +196,8 @@ type Provider interface {
 	Name() string
 	NewSession() Session
 	Embedding(ctx context.Context, text string) ([]float64, error)
+	// EmbedMany embeds inputs in chunks and returns the vectors in input order
+	EmbedMany(ctx context.Context, inputs []string) ([][]float64, error)
 }
 
 type AiClient interface {
@@ -5,6 +5,8 @@ import (
 	"context"
 	"io"
 	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
 )
 
 //go:generate mockgen -source=ai_batch.go -destination=mocks/ai_batch_mock.go -package=mocks
@@ -27,7 +29,9 @@ type LLMResponse struct {
 	ID             string            `json:"id"`
 	Model          string            `json:"model"`
 	Content        string            `json:"content"`
+	Reasoning      string            `json:"reasoning,omitempty"` // reasoning summaries of every turn
 	Usage          *Usage            `json:"usage,omitempty"`
+	FinishReason   string            `json:"finish_reason,omitempty"`
 	Metadata       map[string]string `json:"metadata,omitempty"`
 	ResponseFormat *ResponseFormat   `json:"response_format,omitempty"`
 }
@@ -106,13 +110,16 @@ type AiBatchClient interface {
 
 type BatchManager interface {
 	SetPollDelay(delay time.Duration)
+	SetPollJitter(factor float64)
 	EstimateCost(requests []BatchRequest) *CostEstimate
 	ProcessBatch(ctx context.Context, requests []BatchRequest, opts BatchOptions, waitForCompletion bool) (*BatchJob, error)
 	GetJobStatus(ctx context.Context, jobID string) (*BatchJob, error)
 	WaitForCompletion(ctx context.Context, jobID string) (*BatchJob, error)
 	GetResults(ctx context.Context, jobID string) (*BatchResult, error)
+	DownloadResults(ctx context.Context, jobID string, fsys fs.FS, dst string) (int64, error)
 	CancelJob(ctx context.Context, jobID string) error
 	ListBatches(ctx context.Context, filters map[string]string) ([]BatchJob, error)
+	ListAllBatches(ctx context.Context, filters map[string]string, pageSize int) ([]BatchJob, error)
 	GetError(ctx context.Context, jobID string) (map[string]string, error)
 }
 
@@ -0,0 +1,156 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// AlertType is the concentration metric an alert is about
+type AlertType string
+
+const (
+	AlertPosition AlertType = "position" // single position weight
+	AlertSector   AlertType = "sector"   // sector weight
+	AlertCurrency AlertType = "currency" // currency exposure
+)
+
+// AlertSeverity ranks triggered alerts
+type AlertSeverity string
+
+const (
+	AlertWarning  AlertSeverity = "warning"
+	AlertCritical AlertSeverity = "critical"
+)
+
+// AlertLimit holds the warning and critical thresholds of one metric, in percent
+// of the portfolio. A zero threshold is disabled.
+type AlertLimit struct {
+	WarningPct  float64 `json:"warning_pct,omitempty" yaml:"warning_pct,omitempty" mapstructure:"warning_pct"`
+	CriticalPct float64 `json:"critical_pct,omitempty" yaml:"critical_pct,omitempty" mapstructure:"critical_pct"`
+}
+
+// Validate checks the thresholds are percentages and ordered
+func (l AlertLimit) Validate() error {
+	for _, v := range []float64{l.WarningPct, l.CriticalPct} {
+		if v < 0 || v > 100 {
+			return fmt.Errorf("threshold must be between 0 and 100, got: %g", v)
+		}
+	}
+	if l.WarningPct > 0 && l.CriticalPct > 0 && l.CriticalPct < l.WarningPct {
+		return fmt.Errorf("critical threshold %g is below warning threshold %g", l.CriticalPct, l.WarningPct)
+	}
+	return nil
+}
+
+// severity returns the severity of a weight against the limit, if any
+func (l AlertLimit) severity(pct float64) (AlertSeverity, float64, bool) {
+	if l.CriticalPct > 0 && pct > l.CriticalPct {
+		return AlertCritical, l.CriticalPct, true
+	}
+	if l.WarningPct > 0 && pct > l.WarningPct {
+		return AlertWarning, l.WarningPct, true
+	}
+	return "", 0, false
+}
+
+// AlertThresholds are the concentration limits checked by EvaluateAlerts
+type AlertThresholds struct {
+	Position AlertLimit `json:"position" yaml:"position" mapstructure:"position"`
+	Sector   AlertLimit `json:"sector" yaml:"sector" mapstructure:"sector"`
+	Currency AlertLimit `json:"currency" yaml:"currency" mapstructure:"currency"`
+}
+
+// Validate validates every limit
+func (t AlertThresholds) Validate() error {
+	if err := t.Position.Validate(); err != nil {
+		return fmt.Errorf("position: %w", err)
+	}
+	if err := t.Sector.Validate(); err != nil {
+		return fmt.Errorf("sector: %w", err)
+	}
+	if err := t.Currency.Validate(); err != nil {
+		return fmt.Errorf("currency: %w", err)
+	}
+	return nil
+}
+
+// Alert is a breached concentration threshold
+type Alert struct {
+	Type         AlertType     `json:"type"`
+	Severity     AlertSeverity `json:"severity"`
+	Subject      string        `json:"subject"` // ticker, sector or currency
+	WeightPct    float64       `json:"weight_pct"`
+	ThresholdPct float64       `json:"threshold_pct"`
+	Message      string        `json:"message"`
+}
+
+// EvaluateAlerts checks the portfolio's positions, sectors and currencies against
+// the thresholds. Equivalent tickers (same ExposureGroup) count as one position,
+// cash is not a position and holdings without a currency count in the base
+// currency. Alerts are sorted critical first, then by weight.
+func EvaluateAlerts(p *NormalizedPortfolio, t AlertThresholds) []Alert {
+	if p == nil {
+		return nil
+	}
+
+	positions := make(map[string]float64)
+	currencies := make(map[string]float64)
+	for _, h := range p.Holdings {
+		// cash is not a concentration risk
+		if h.AssetClass != "cash" {
+			position := h.Symbol
+			if h.ExposureGroup != "" {
+				position = h.ExposureGroup
+			}
+			positions[position] += h.WeightPercent
+		}
+
+		currency := h.Currency
+		if currency == "" {
+			currency = p.BaseCurrency
+		}
+		if currency != "" {
+			currencies[currency] += h.WeightPercent
+		}
+	}
+
+	var alerts []Alert
+	check := func(typ AlertType, limit AlertLimit, weights map[string]float64, format string) {
+		for _, subject := range slices.Sorted(maps.Keys(weights)) {
+			pct := weights[subject]
+			severity, threshold, ok := limit.severity(pct)
+			if !ok {
+				continue
+			}
+			alerts = append(alerts, Alert{
+				Type:         typ,
+				Severity:     severity,
+				Subject:      subject,
+				WeightPct:    pct,
+				ThresholdPct: threshold,
+				Message:      fmt.Sprintf(format, subject, pct, threshold),
+			})
+		}
+	}
+	check(AlertPosition, t.Position, positions, "Position %s is %.1f%% of the portfolio (limit %.1f%%)")
+	check(AlertSector, t.Sector, p.SectorAllocations, "Sector %s is %.1f%% of the portfolio (limit %.1f%%)")
+	check(AlertCurrency, t.Currency, currencies, "%s exposure is %.1f%% of the portfolio (limit %.1f%%)")
+
+	slices.SortStableFunc(alerts, func(a, b Alert) int {
+		if a.Severity != b.Severity {
+			if a.Severity == AlertCritical {
+				return -1
+			}
+			return 1
+		}
+		return cmp.Compare(b.WeightPct, a.WeightPct)
+	})
+	return alerts
+}
+
+// HasCriticalAlert reports whether any alert is critical
+func HasCriticalAlert(alerts []Alert) bool {
+	return slices.ContainsFunc(alerts, func(a Alert) bool { return a.Severity == AlertCritical })
+}
@@ -0,0 +1,145 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateAlerts(t *testing.T) {
+	// VOO and IVV are one S&P 500 position of 18%
+	portfolio := &NormalizedPortfolio{
+		BaseCurrency: "EUR",
+		Holdings: []NormalizedHolding{
+			{Symbol: "VOO", WeightPercent: 10, Sector: "Index", Currency: "USD", ExposureGroup: "VOO"},
+			{Symbol: "IVV", WeightPercent: 8, Sector: "Index", Currency: "USD", ExposureGroup: "VOO"},
+			{Symbol: "ASML", WeightPercent: 12, Sector: "Technology", Currency: "EUR"},
+			{Symbol: "SAP", WeightPercent: 9, Sector: "Technology"},
+			{Symbol: "CASH", WeightPercent: 61, AssetClass: "cash"},
+		},
+		SectorAllocations: map[string]float64{"Index": 18, "Technology": 21},
+	}
+
+	cases := []struct {
+		name       string
+		thresholds AlertThresholds
+		want       []Alert
+	}{
+		{
+			name:       "no thresholds",
+			thresholds: AlertThresholds{},
+		},
+		{
+			name:       "position below thresholds",
+			thresholds: AlertThresholds{Position: AlertLimit{WarningPct: 20, CriticalPct: 30}},
+		},
+		{
+			name:       "position warning counts equivalent tickers together",
+			thresholds: AlertThresholds{Position: AlertLimit{WarningPct: 15, CriticalPct: 30}},
+			want: []Alert{
+				{Type: AlertPosition, Severity: AlertWarning, Subject: "VOO", WeightPct: 18, ThresholdPct: 15,
+					Message: "Position VOO is 18.0% of the portfolio (limit 15.0%)"},
+			},
+		},
+		{
+			name:       "position critical",
+			thresholds: AlertThresholds{Position: AlertLimit{WarningPct: 11, CriticalPct: 16}},
+			want: []Alert{
+				{Type: AlertPosition, Severity: AlertCritical, Subject: "VOO", WeightPct: 18, ThresholdPct: 16,
+					Message: "Position VOO is 18.0% of the portfolio (limit 16.0%)"},
+				{Type: AlertPosition, Severity: AlertWarning, Subject: "ASML", WeightPct: 12, ThresholdPct: 11,
+					Message: "Position ASML is 12.0% of the portfolio (limit 11.0%)"},
+			},
+		},
+		{
+			name:       "sector below threshold",
+			thresholds: AlertThresholds{Sector: AlertLimit{WarningPct: 25}},
+		},
+		{
+			name:       "sector warning",
+			thresholds: AlertThresholds{Sector: AlertLimit{WarningPct: 20}},
+			want: []Alert{
+				{Type: AlertSector, Severity: AlertWarning, Subject: "Technology", WeightPct: 21, ThresholdPct: 20,
+					Message: "Sector Technology is 21.0% of the portfolio (limit 20.0%)"},
+			},
+		},
+		{
+			name:       "currency below threshold",
+			thresholds: AlertThresholds{Currency: AlertLimit{CriticalPct: 85}},
+		},
+		{
+			name:       "currency critical counts holdings without currency in the base currency",
+			thresholds: AlertThresholds{Currency: AlertLimit{WarningPct: 15, CriticalPct: 80}},
+			want: []Alert{
+				{Type: AlertCurrency, Severity: AlertCritical, Subject: "EUR", WeightPct: 82, ThresholdPct: 80,
+					Message: "EUR exposure is 82.0% of the portfolio (limit 80.0%)"},
+				{Type: AlertCurrency, Severity: AlertWarning, Subject: "USD", WeightPct: 18, ThresholdPct: 15,
+					Message: "USD exposure is 18.0% of the portfolio (limit 15.0%)"},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := EvaluateAlerts(portfolio, c.thresholds)
+			require.Len(t, got, len(c.want))
+			for i := range c.want {
+				assert.Equal(t, c.want[i].Type, got[i].Type)
+				assert.Equal(t, c.want[i].Severity, got[i].Severity)
+				assert.Equal(t, c.want[i].Subject, got[i].Subject)
+				assert.InDelta(t, c.want[i].WeightPct, got[i].WeightPct, 1e-9)
+				assert.Equal(t, c.want[i].ThresholdPct, got[i].ThresholdPct)
+				assert.Equal(t, c.want[i].Message, got[i].Message)
+			}
+		})
+	}
+}
+
+func TestAlertThresholds_Validate(t *testing.T) {
+	cases := []struct {
+		name       string
+		thresholds AlertThresholds
+		wantErr    bool
+	}{
+		{name: "empty", thresholds: AlertThresholds{}},
+		{name: "valid", thresholds: AlertThresholds{Position: AlertLimit{WarningPct: 10, CriticalPct: 20}}},
+		{name: "above 100", thresholds: AlertThresholds{Sector: AlertLimit{WarningPct: 120}}, wantErr: true},
+		{name: "negative", thresholds: AlertThresholds{Currency: AlertLimit{CriticalPct: -1}}, wantErr: true},
+		{name: "critical below warning", thresholds: AlertThresholds{Position: AlertLimit{WarningPct: 20, CriticalPct: 10}}, wantErr: true},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.thresholds.Validate()
+			if c.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestRunSummary_ExitCode(t *testing.T) {
+	cases := []struct {
+		name    string
+		summary RunSummary
+		want    int
+	}{
+		{name: "clean run", summary: RunSummary{}, want: 0},
+		{name: "warnings only", summary: RunSummary{Alerts: []Alert{{Severity: AlertWarning}}}, want: 0},
+		{name: "critical alert", summary: RunSummary{Alerts: []Alert{{Severity: AlertWarning}, {Severity: AlertCritical}}}, want: ExitCodeCriticalAlert},
+		{
+			name:    "failed items win over alerts",
+			summary: RunSummary{ItemErrors: []BatchItemError{{CustomID: "x"}}, Alerts: []Alert{{Severity: AlertCritical}}},
+			want:    ExitCodeFailed,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, c.summary.ExitCode())
+		})
+	}
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Citation represents a source cited by web search output
+type Citation struct {
+	URL        string    `json:"url"`
+	Title      string    `json:"title,omitempty"`
+	Snippet    string    `json:"snippet,omitempty"`
+	Source     string    `json:"source,omitempty"`
+	Timestamp  time.Time `json:"timestamp"`
+	Query      string    `json:"query"`
+	Relevance  float64   `json:"relevance,omitempty"`
+	CitationID string    `json:"citation_id"` // For referencing in text
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -37,6 +38,77 @@ type BatchEngineHooks interface {
 	ResultKey() bag.Key
 }
 
+// ErrBatchItemsFailed is returned (wrapped) by batch engines when the run completed
+// but one or more batch items failed along the way
+var ErrBatchItemsFailed = errors.New("batch items failed")
+
+// BatchItemError records a single failed batch item with its iteration context
+type BatchItemError struct {
+	Iteration int    `json:"iteration"`
+	CustomID  string `json:"custom_id"`
+	Message   string `json:"message"`
+}
+
+// Error implements the error interface
+func (e BatchItemError) Error() string {
+	return fmt.Sprintf("iteration %d: item %s: %s", e.Iteration, e.CustomID, e.Message)
+}
+
+// RunSummary summarizes a batch engine run, including every item error seen across iterations
+type RunSummary struct {
+	Engine     string           `json:"engine"`
+	Iterations int              `json:"iterations"`
+	Completed  int              `json:"completed"` // items that produced a final result
+	ItemErrors []BatchItemError `json:"item_errors,omitempty"`
+	// Alerts are the concentration alerts triggered by the analyzed portfolio
+	Alerts []Alert `json:"alerts,omitempty"`
+}
+
+const (
+	// ExitCodeFailed is the exit code of a run with failed items
+	ExitCodeFailed = 1
+	// ExitCodeCriticalAlert is the exit code of a successful run that triggered a critical alert
+	ExitCodeCriticalAlert = 2
+)
+
+// ExitCode maps the run outcome to a process exit code: ExitCodeFailed when
+// items failed, ExitCodeCriticalAlert when a critical concentration alert was
+// triggered, 0 otherwise. Warnings do not change the exit code.
+func (s *RunSummary) ExitCode() int {
+	switch {
+	case len(s.ItemErrors) > 0:
+		return ExitCodeFailed
+	case HasCriticalAlert(s.Alerts):
+		return ExitCodeCriticalAlert
+	}
+	return 0
+}
+
+// AddItemError records a failed item for the given iteration
+func (s *RunSummary) AddItemError(iteration int, customID, message string) {
+	s.ItemErrors = append(s.ItemErrors, BatchItemError{
+		Iteration: iteration,
+		CustomID:  customID,
+		Message:   message,
+	})
+}
+
+// Err returns an aggregated error wrapping ErrBatchItemsFailed and every item error,
+// or nil when all items succeeded
+func (s *RunSummary) Err() error {
+	if len(s.ItemErrors) == 0 {
+		return nil
+	}
+
+	errs := make([]error, 0, len(s.ItemErrors)+1)
+	errs = append(errs, fmt.Errorf("%w: engine %s: %d item(s) failed across %d iteration(s)",
+		ErrBatchItemsFailed, s.Engine, len(s.ItemErrors), s.Iterations))
+	for _, e := range s.ItemErrors {
+		errs = append(errs, e)
+	}
+	return errors.Join(errs...)
+}
+
 // ToolConstraints is an interface for BaseToolConstraints
 type ToolConstraints interface {
 	// Core constraint methods - actually used
@@ -0,0 +1,103 @@
+package models
+
+import (
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultBaseCurrency is the reporting currency when neither the caller nor the
+// portfolio sets one
+const DefaultBaseCurrency = "USD"
+
+// ErrStaleFXRates is returned (wrapped) when conversion relies on exchange rates
+// older than the allowed age and the policy is FXStaleError
+var ErrStaleFXRates = errors.New("stale FX rates")
+
+// FXStalePolicy tells what normalization does with stale exchange rates
+type FXStalePolicy string
+
+const (
+	// FXStaleWarn converts anyway and flags the rates in the output
+	FXStaleWarn FXStalePolicy = "warn"
+	// FXStaleError fails the normalization
+	FXStaleError FXStalePolicy = "error"
+)
+
+// FXTable converts amounts between currencies from a set of exchange rates. A
+// missing pair is derived from its inverse or crossed through a currency quoted
+// against both sides.
+type FXTable struct {
+	rates map[string]map[string]fxQuote
+}
+
+// fxQuote is a rate and the quoted rate it comes from
+type fxQuote struct {
+	rate   float64
+	source NormalizedCurrencyRate
+}
+
+// NewFXTable builds a table from rates; non-positive rates are ignored
+func NewFXTable(rates []NormalizedCurrencyRate) FXTable {
+	t := FXTable{rates: make(map[string]map[string]fxQuote)}
+	set := func(from, to string, q fxQuote) {
+		if t.rates[from] == nil {
+			t.rates[from] = make(map[string]fxQuote)
+		}
+		if _, ok := t.rates[from][to]; !ok {
+			t.rates[from][to] = q
+		}
+	}
+	// quoted rates first, so an inverse never overrides a quote
+	for _, r := range rates {
+		if r.ExchangeRate > 0 {
+			set(strings.ToUpper(r.FromCurrency), strings.ToUpper(r.ToCurrency), fxQuote{rate: r.ExchangeRate, source: r})
+		}
+	}
+	for _, r := range rates {
+		if r.ExchangeRate > 0 {
+			set(strings.ToUpper(r.ToCurrency), strings.ToUpper(r.FromCurrency), fxQuote{rate: 1 / r.ExchangeRate, source: r})
+		}
+	}
+	return t
+}
+
+// Rate returns the amount of to bought by one unit of from
+func (t FXTable) Rate(from, to string) (float64, bool) {
+	rate, _, ok := t.quote(from, to)
+	return rate, ok
+}
+
+// Convert converts amount from one currency to another
+func (t FXTable) Convert(amount float64, from, to string) (float64, bool) {
+	r, ok := t.Rate(from, to)
+	return amount * r, ok
+}
+
+// quote returns the rate from one currency to another and the quoted rates it
+// was derived from
+func (t FXTable) quote(from, to string) (float64, []NormalizedCurrencyRate, bool) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return 1, nil, true
+	}
+	if q, ok := t.rates[from][to]; ok {
+		return q.rate, []NormalizedCurrencyRate{q.source}, true
+	}
+	// cross through a common currency, in a stable order
+	for _, via := range slices.Sorted(maps.Keys(t.rates[from])) {
+		if q, ok := t.rates[via][to]; ok {
+			first := t.rates[from][via]
+			return first.rate * q.rate, []NormalizedCurrencyRate{first.source, q.source}, true
+		}
+	}
+	return 0, nil, false
+}
+
+// isStaleRate reports whether a dated rate is older than maxAge at asOf; undated
+// rates cannot be judged and are not stale
+func isStaleRate(r NormalizedCurrencyRate, asOf time.Time, maxAge time.Duration) bool {
+	return maxAge > 0 && !r.AsOf.IsZero() && asOf.Sub(r.AsOf) > maxAge
+}
@@ -0,0 +1,179 @@
+package models
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Health score components
+const (
+	HealthDiversification = "diversification"
+	HealthConcentration   = "concentration"
+	HealthCurrency        = "currency"
+	HealthCompliance      = "compliance"
+)
+
+const (
+	// healthTargetHoldings is the number of effective holdings scoring full
+	// diversification
+	healthTargetHoldings = 20.0
+	// healthPositionLimit and healthSectorLimit are the weights (percent) up to
+	// which concentration is not penalized, unless the profile sets its own
+	healthPositionLimit = 10.0
+	healthSectorLimit   = 30.0
+	// healthForeignTolerance is the foreign currency exposure (percent) up to which
+	// currency risk is not penalized
+	healthForeignTolerance = 30.0
+)
+
+// HealthWeights weigh the components of the health score. Only their ratios
+// matter; a component that cannot be scored is left out and the others reweighed.
+type HealthWeights struct {
+	Diversification float64 `json:"diversification" yaml:"diversification" mapstructure:"diversification"`
+	Concentration   float64 `json:"concentration" yaml:"concentration" mapstructure:"concentration"`
+	Currency        float64 `json:"currency" yaml:"currency" mapstructure:"currency"`
+	Compliance      float64 `json:"compliance" yaml:"compliance" mapstructure:"compliance"`
+}
+
+// DefaultHealthWeights returns the default weighting: diversification and
+// concentration 30% each, compliance 25% and currency 15%
+func DefaultHealthWeights() HealthWeights {
+	return HealthWeights{Diversification: 30, Concentration: 30, Currency: 15, Compliance: 25}
+}
+
+// IsZero reports whether no weight is set
+func (w HealthWeights) IsZero() bool {
+	return w == HealthWeights{}
+}
+
+// Validate checks the weights are non-negative
+func (w HealthWeights) Validate() error {
+	for name, v := range w.byComponent() {
+		if v < 0 || math.IsNaN(v) {
+			return fmt.Errorf("%s weight must be non-negative, got: %g", name, v)
+		}
+	}
+	return nil
+}
+
+func (w HealthWeights) byComponent() map[string]float64 {
+	return map[string]float64{
+		HealthDiversification: w.Diversification,
+		HealthConcentration:   w.Concentration,
+		HealthCurrency:        w.Currency,
+		HealthCompliance:      w.Compliance,
+	}
+}
+
+// HealthScore is the portfolio health composite with its breakdown
+type HealthScore struct {
+	// Score is the weighted average of the components, 0-100
+	Score int `json:"score"`
+	// Components are the component scores, 0-100
+	Components map[string]float64 `json:"components"`
+	// Weights are the weights applied, in percent of the score
+	Weights map[string]float64 `json:"weights"`
+}
+
+// ComputeHealthScore scores the portfolio health from 0 to 100 with the default
+// weights; see ComputeHealthScoreWith
+func ComputeHealthScore(normalized *NormalizedPortfolio, compliance *ComplianceRules, profile *InvestmentProfile) (int, map[string]float64) {
+	h := ComputeHealthScoreWith(DefaultHealthWeights(), normalized, compliance, profile)
+	return h.Score, h.Components
+}
+
+// ComputeHealthScoreWith scores the portfolio health from 0 to 100 as the
+// weighted average of four components, each 0-100:
+//
+//   - diversification: effective holdings (1/HHI), full marks at 20
+//   - concentration: the largest position and sector, full marks up to the
+//     profile's warning limits (10% and 30% by default), zero at three times them
+//   - currency: foreign currency exposure, full marks up to 30%, zero at 100%
+//   - compliance: the share of the portfolio neither disallowed by the
+//     jurisdiction rules (asset types, ticker blocklist) nor in a sector the
+//     profile avoids; left out without rules or profile
+//
+// Weights are normalized over the components scored. A nil portfolio scores 0.
+func ComputeHealthScoreWith(weights HealthWeights, normalized *NormalizedPortfolio, compliance *ComplianceRules, profile *InvestmentProfile) HealthScore {
+	if normalized == nil {
+		return HealthScore{}
+	}
+	if weights.IsZero() {
+		weights = DefaultHealthWeights()
+	}
+
+	positionLimit, sectorLimit := healthPositionLimit, healthSectorLimit
+	if profile != nil && profile.ConcentrationLimits != nil {
+		if l := profile.ConcentrationLimits.Position.WarningPct; l > 0 {
+			positionLimit = l
+		}
+		if l := profile.ConcentrationLimits.Sector.WarningPct; l > 0 {
+			sectorLimit = l
+		}
+	}
+
+	risk := normalized.RiskMetrics
+	components := map[string]float64{
+		HealthDiversification: 100 * clamp01((risk.EffectiveHoldings-1)/(healthTargetHoldings-1)),
+		HealthConcentration: min(
+			100*clamp01((3*positionLimit-risk.LargestPositionPct)/(2*positionLimit)),
+			100*clamp01((3*sectorLimit-risk.SectorConcentration)/(2*sectorLimit)),
+		),
+		HealthCurrency: 100 * clamp01((100-risk.ForeignCurrencyPct)/(100-healthForeignTolerance)),
+	}
+	if compliance != nil || (profile != nil && len(profile.AvoidedSectors) > 0) {
+		components[HealthCompliance] = 100 - nonCompliantPct(normalized.Holdings, compliance, profile)
+	}
+
+	total := 0.0
+	for name, w := range weights.byComponent() {
+		if _, ok := components[name]; ok {
+			total += w
+		}
+	}
+	h := HealthScore{Components: components, Weights: make(map[string]float64, len(components))}
+	if total == 0 {
+		return h
+	}
+	score := 0.0
+	for name, w := range weights.byComponent() {
+		if c, ok := components[name]; ok {
+			h.Weights[name] = w / total * 100
+			score += c * w / total
+		}
+	}
+	h.Score = int(math.Round(score))
+	return h
+}
+
+// nonCompliantPct is the portfolio weight (percent) of holdings the rules or the
+// profile rule out
+func nonCompliantPct(holdings []NormalizedHolding, rules *ComplianceRules, profile *InvestmentProfile) float64 {
+	has := func(list []string, v string) bool {
+		return v != "" && slices.ContainsFunc(list, func(s string) bool { return strings.EqualFold(strings.TrimSpace(s), v) })
+	}
+
+	pct := 0.0
+	for _, h := range holdings {
+		ruledOut := false
+		if rules != nil {
+			assetType := string(h.AssetType)
+			ruledOut = has(rules.DisallowedAssetTypes, assetType) || has(rules.DisallowedAssetTypes, h.AssetClass) ||
+				has(rules.TickerBlocklist, h.Symbol) ||
+				(len(rules.AllowedAssetTypes) > 0 && assetType != "" && !has(rules.AllowedAssetTypes, assetType))
+		}
+		if profile != nil && has(profile.AvoidedSectors, h.Sector) {
+			ruledOut = true
+		}
+		if ruledOut {
+			pct += h.WeightPercent
+		}
+	}
+	return min(pct, 100)
+}
+
+func clamp01(v float64) float64 {
+	return max(0, min(1, v))
+}
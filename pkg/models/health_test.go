@@ -0,0 +1,120 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeHealthScore(t *testing.T) {
+	sectors := []string{"Technology", "Healthcare", "Financials", "Energy", "Industrials"}
+	diversified := Portfolio{AsOf: "2026-10-16", BaseCurrency: "USD"}
+	account := Account{Currency: "USD"}
+	for i := range 20 {
+		account.Holdings = append(account.Holdings, Holding{
+			Ticker: fmt.Sprintf("T%02d", i), Quantity: 1, CostBasis: 500, Type: Stock, Sector: sectors[i%len(sectors)],
+		})
+	}
+	diversified.Accounts = []Account{account}
+
+	concentrated := Portfolio{AsOf: "2026-10-16", BaseCurrency: "USD", Accounts: []Account{{
+		Currency: "USD",
+		Holdings: []Holding{
+			{Ticker: "NVDA", Quantity: 1, CostBasis: 8000, Type: Stock, Sector: "Technology"},
+			{Ticker: "AAPL", Quantity: 1, CostBasis: 1500, Type: Stock, Sector: "Technology"},
+			{Ticker: "BTC", Quantity: 1, CostBasis: 500, Type: Crypto},
+		},
+	}}}
+
+	normalize := func(p Portfolio) *NormalizedPortfolio {
+		n, err := p.Normalize()
+		require.NoError(t, err)
+		return n
+	}
+	div, conc := normalize(diversified), normalize(concentrated)
+
+	cases := []struct {
+		name       string
+		compliance *ComplianceRules
+		profile    *InvestmentProfile
+	}{
+		{name: "without rules"},
+		{name: "with rules", compliance: &ComplianceRules{DisallowedAssetTypes: []string{"crypto"}}},
+		{name: "with profile", profile: &InvestmentProfile{AvoidedSectors: []string{"Energy"}}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			dScore, dComponents := ComputeHealthScore(div, c.compliance, c.profile)
+			cScore, cComponents := ComputeHealthScore(conc, c.compliance, c.profile)
+
+			assert.Greater(t, dScore, cScore)
+			assert.Greater(t, dComponents[HealthDiversification], cComponents[HealthDiversification])
+			assert.Greater(t, dComponents[HealthConcentration], cComponents[HealthConcentration])
+			assert.InDelta(t, 100, dComponents[HealthCurrency], 1e-9)
+			for _, score := range []int{dScore, cScore} {
+				assert.GreaterOrEqual(t, score, 0)
+				assert.LessOrEqual(t, score, 100)
+			}
+			_, scored := dComponents[HealthCompliance]
+			assert.Equal(t, c.compliance != nil || c.profile != nil, scored)
+		})
+	}
+
+	t.Run("compliance", func(t *testing.T) {
+		t.Parallel()
+		_, components := ComputeHealthScore(conc, &ComplianceRules{DisallowedAssetTypes: []string{"crypto"}}, nil)
+		assert.InDelta(t, 95, components[HealthCompliance], 1e-9)
+
+		_, components = ComputeHealthScore(div, nil, &InvestmentProfile{AvoidedSectors: []string{"energy"}})
+		assert.InDelta(t, 80, components[HealthCompliance], 1e-9)
+	})
+
+	t.Run("weights", func(t *testing.T) {
+		t.Parallel()
+		onlyCurrency := ComputeHealthScoreWith(HealthWeights{Currency: 1}, conc, nil, nil)
+		assert.Equal(t, 100, onlyCurrency.Score)
+		assert.Equal(t, map[string]float64{HealthDiversification: 0, HealthConcentration: 0, HealthCurrency: 100}, onlyCurrency.Weights)
+
+		// compliance is left out without rules, the other weights make up 100%
+		h := ComputeHealthScoreWith(DefaultHealthWeights(), conc, nil, nil)
+		assert.InDelta(t, 40, h.Weights[HealthDiversification], 1e-9)
+		assert.NotContains(t, h.Weights, HealthCompliance)
+	})
+
+	t.Run("nil portfolio", func(t *testing.T) {
+		t.Parallel()
+		score, components := ComputeHealthScore(nil, nil, nil)
+		assert.Zero(t, score)
+		assert.Empty(t, components)
+	})
+}
+
+func TestComplianceRules_ScreensHoldings(t *testing.T) {
+	cases := []struct {
+		name  string
+		rules *ComplianceRules
+		want  bool
+	}{
+		{name: "nil"},
+		{name: "empty", rules: &ComplianceRules{}},
+		{name: "only substitutes and leverage", rules: &ComplianceRules{TickerSubstitutes: map[string]string{"VOO": "CSPX"}, MaxLeverage: 1}},
+		{name: "allowed types", rules: &ComplianceRules{AllowedAssetTypes: []string{"etf"}}, want: true},
+		{name: "disallowed types", rules: &ComplianceRules{DisallowedAssetTypes: []string{"crypto"}}, want: true},
+		{name: "blocklist", rules: &ComplianceRules{TickerBlocklist: []string{"GME"}}, want: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.Equal(t, c.want, c.rules.ScreensHoldings())
+		})
+	}
+}
+
+func TestHealthWeights_Validate(t *testing.T) {
+	t.Parallel()
+	assert.NoError(t, DefaultHealthWeights().Validate())
+	assert.NoError(t, HealthWeights{}.Validate())
+	assert.Error(t, HealthWeights{Currency: -1}.Validate())
+}
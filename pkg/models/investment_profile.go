@@ -1,5 +1,7 @@
 package models
 
+import "fmt"
+
 // InvestmentProfile contains advanced investment preferences and context
 type InvestmentProfile struct {
 	// Investment Style and Approach
@@ -23,6 +25,9 @@ type InvestmentProfile struct {
 	PreferredAnalysisTypes []AnalysisType `json:"preferred_analysis_types,omitempty" yaml:"preferred_analysis_types,omitempty"` // Preferred analysis types
 	CustomRequirements     []string       `json:"custom_requirements,omitempty" yaml:"custom_requirements,omitempty"`           // User-specific requirements
 
+	// ConcentrationLimits override the configured concentration alert thresholds
+	ConcentrationLimits *AlertThresholds `json:"concentration_limits,omitempty" yaml:"concentration_limits,omitempty"`
+
 	// Metadata
 	ProfileVersion string `json:"profile_version,omitempty" yaml:"profile_version,omitempty"` // Profile format version
 	Source         string `json:"source,omitempty" yaml:"source,omitempty"`                   // Source of profile (default_regional, default_global, custom)
@@ -83,7 +88,14 @@ func (ip *InvestmentProfile) Validate() error {
 			Region:   ip.RegionalContext.Region,
 			City:     ip.RegionalContext.City,
 		}
-		return lc.Validate()
+		if err := lc.Validate(); err != nil {
+			return err
+		}
+	}
+	if ip.ConcentrationLimits != nil {
+		if err := ip.ConcentrationLimits.Validate(); err != nil {
+			return fmt.Errorf("concentration limits: %w", err)
+		}
 	}
 	return nil
 }
@@ -43,6 +43,12 @@ type ComplianceRules struct {
 	Notes                string            `yaml:"notes" mapstructure:"notes"`
 }
 
+// ScreensHoldings reports whether the rules can rule out a holding: allowed or
+// disallowed asset types, or a ticker blocklist
+func (cr *ComplianceRules) ScreensHoldings() bool {
+	return cr != nil && (len(cr.AllowedAssetTypes) > 0 || len(cr.DisallowedAssetTypes) > 0 || len(cr.TickerBlocklist) > 0)
+}
+
 // Validate validates the compliance rules
 func (cr *ComplianceRules) Validate() error {
 	// validate max leverage
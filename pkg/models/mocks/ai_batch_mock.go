@@ -10,6 +10,7 @@ import (
 	reflect "reflect"
 	time "time"
 
+	fs "github.com/amaurybrisou/mosychlos/pkg/fs"
 	models "github.com/amaurybrisou/mosychlos/pkg/models"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -163,6 +164,21 @@ func (mr *MockBatchManagerMockRecorder) CancelJob(ctx, jobID interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelJob", reflect.TypeOf((*MockBatchManager)(nil).CancelJob), ctx, jobID)
 }
 
+// DownloadResults mocks base method.
+func (m *MockBatchManager) DownloadResults(ctx context.Context, jobID string, fsys fs.FS, dst string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DownloadResults", ctx, jobID, fsys, dst)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DownloadResults indicates an expected call of DownloadResults.
+func (mr *MockBatchManagerMockRecorder) DownloadResults(ctx, jobID, fsys, dst interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownloadResults", reflect.TypeOf((*MockBatchManager)(nil).DownloadResults), ctx, jobID, fsys, dst)
+}
+
 // EstimateCost mocks base method.
 func (m *MockBatchManager) EstimateCost(requests []models.BatchRequest) *models.CostEstimate {
 	m.ctrl.T.Helper()
@@ -222,6 +238,21 @@ func (mr *MockBatchManagerMockRecorder) GetResults(ctx, jobID interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResults", reflect.TypeOf((*MockBatchManager)(nil).GetResults), ctx, jobID)
 }
 
+// ListAllBatches mocks base method.
+func (m *MockBatchManager) ListAllBatches(ctx context.Context, filters map[string]string, pageSize int) ([]models.BatchJob, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAllBatches", ctx, filters, pageSize)
+	ret0, _ := ret[0].([]models.BatchJob)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListAllBatches indicates an expected call of ListAllBatches.
+func (mr *MockBatchManagerMockRecorder) ListAllBatches(ctx, filters, pageSize interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAllBatches", reflect.TypeOf((*MockBatchManager)(nil).ListAllBatches), ctx, filters, pageSize)
+}
+
 // ListBatches mocks base method.
 func (m *MockBatchManager) ListBatches(ctx context.Context, filters map[string]string) ([]models.BatchJob, error) {
 	m.ctrl.T.Helper()
@@ -264,6 +295,18 @@ func (mr *MockBatchManagerMockRecorder) SetPollDelay(delay interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPollDelay", reflect.TypeOf((*MockBatchManager)(nil).SetPollDelay), delay)
 }
 
+// SetPollJitter mocks base method.
+func (m *MockBatchManager) SetPollJitter(factor float64) {
+	m.ctrl.T.Helper()
+	m.ctrl.Call(m, "SetPollJitter", factor)
+}
+
+// SetPollJitter indicates an expected call of SetPollJitter.
+func (mr *MockBatchManagerMockRecorder) SetPollJitter(factor interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetPollJitter", reflect.TypeOf((*MockBatchManager)(nil).SetPollJitter), factor)
+}
+
 // WaitForCompletion mocks base method.
 func (m *MockBatchManager) WaitForCompletion(ctx context.Context, jobID string) (*models.BatchJob, error) {
 	m.ctrl.T.Helper()
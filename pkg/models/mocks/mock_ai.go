@@ -347,6 +347,21 @@ func (m *MockProvider) EXPECT() *MockProviderMockRecorder {
 	return m.recorder
 }
 
+// EmbedMany mocks base method.
+func (m *MockProvider) EmbedMany(ctx context.Context, inputs []string) ([][]float64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EmbedMany", ctx, inputs)
+	ret0, _ := ret[0].([][]float64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EmbedMany indicates an expected call of EmbedMany.
+func (mr *MockProviderMockRecorder) EmbedMany(ctx, inputs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EmbedMany", reflect.TypeOf((*MockProvider)(nil).EmbedMany), ctx, inputs)
+}
+
 // Embedding mocks base method.
 func (m *MockProvider) Embedding(ctx context.Context, text string) ([]float64, error) {
 	m.ctrl.T.Helper()
@@ -199,3 +199,17 @@ func (a AnalyzedNewsData) String() string {
 	}
 	return baseStr
 }
+
+// String renders the headlines as a markdown list, most relevant first as kept
+// by the report filter
+func (n NormalizedNewsContext) String() string {
+	if len(n.RecentHeadlines) == 0 {
+		return "No relevant headlines"
+	}
+
+	var b strings.Builder
+	for _, h := range n.RecentHeadlines {
+		fmt.Fprintf(&b, "- %s [%s] (%.2f relevance)\n", h.Title, h.Source, h.Relevance)
+	}
+	return strings.TrimSuffix(b.String(), "\n")
+}
@@ -8,8 +8,8 @@ import "time"
 // No market data, no complex performance calculations - just the portfolio itself.
 type NormalizedPortfolio struct {
 	// Core Portfolio Identity
-	TotalValueUSD float64   `json:"total_value_usd" jsonschema_description:"Total portfolio value in USD"`
-	BaseCurrency  string    `json:"base_currency" jsonschema_description:"Primary currency used for portfolio valuation"`
+	TotalValue    float64   `json:"total_value" jsonschema_description:"Total portfolio value in the base currency"`
+	BaseCurrency  string    `json:"base_currency" jsonschema_description:"Reporting currency of all values"`
 	AsOfDate      time.Time `json:"as_of_date" jsonschema_description:"Date when portfolio data was captured"`
 	HoldingsCount int       `json:"holdings_count" jsonschema_description:"Total number of positions in the portfolio"`
 
@@ -18,6 +18,15 @@ type NormalizedPortfolio struct {
 	RegionAllocations map[string]float64 `json:"region_allocations" jsonschema_description:"Geographic exposure as percentage by region (US, Europe, Asia, etc.)"`
 	SectorAllocations map[string]float64 `json:"sector_allocations,omitempty" jsonschema_description:"Industry sector exposure as percentage (tech, healthcare, financials, etc.)"` // Only if we have sector data
 
+	// Asset classes grouped into presentation sleeves (equity, fixed income...)
+	Sleeves []Sleeve `json:"sleeves,omitempty" jsonschema_description:"Portfolio grouped into sleeves (equity, fixed_income, alternatives, cash) with value, weight and holding count"`
+
+	// Currencies without an exchange rate to the base currency; their holdings are
+	// counted at face value
+	UnconvertedCurrencies []string `json:"unconverted_currencies,omitempty" jsonschema_description:"Currencies counted at face value for lack of an exchange rate to the base currency"`
+	// Exchange rates the values were converted with
+	FXRates []NormalizedCurrencyRate `json:"fx_rates,omitempty" jsonschema_description:"Exchange rates used to convert holdings into the base currency, with their dates"`
+
 	// Individual Holdings
 	Holdings []NormalizedHolding `json:"holdings" jsonschema_description:"List of individual positions in the portfolio"`
 
@@ -27,19 +36,61 @@ type NormalizedPortfolio struct {
 
 // NormalizedHolding represents a single position with clean, consistent fields
 type NormalizedHolding struct {
-	Symbol        string  `json:"symbol" jsonschema_description:"Stock ticker symbol or instrument identifier"`
-	Name          string  `json:"name,omitempty" jsonschema_description:"Company or fund name"`
-	WeightPercent float64 `json:"weight_percent" jsonschema_description:"Position size as percentage of total portfolio value"` // 0-100
-	ValueUSD      float64 `json:"value_usd" jsonschema_description:"Current market value of holding in USD"`
-	Quantity      float64 `json:"quantity" jsonschema_description:"Number of shares or units owned"`
-	AssetClass    string  `json:"asset_class" jsonschema_description:"Investment type classification (stock, ETF, bond, cash, crypto)"` // "stock", "etf", "bond", "cash", "crypto"
-	Region        string  `json:"region" jsonschema_description:"Geographic market exposure (US, Europe, Asia, Emerging, Global)"`      // "US", "Europe", "Asia", "Emerging", "Global"
-	Sector        string  `json:"sector,omitempty" jsonschema_description:"Industry sector classification (technology, healthcare, financials, etc.)"`
-	Currency      string  `json:"currency" jsonschema_description:"Currency denomination of the investment"`
+	Symbol        string    `json:"symbol" jsonschema_description:"Stock ticker symbol or instrument identifier"`
+	Name          string    `json:"name,omitempty" jsonschema_description:"Company or fund name"`
+	WeightPercent float64   `json:"weight_percent" jsonschema_description:"Position size as percentage of total portfolio value"` // 0-100
+	Value         float64   `json:"value" jsonschema_description:"Current market value of holding in the base currency"`
+	Quantity      float64   `json:"quantity" jsonschema_description:"Number of shares or units owned"`
+	AssetClass    string    `json:"asset_class" jsonschema_description:"Investment type classification (stock, ETF, bond, cash, crypto)"` // "stock", "etf", "bond", "cash", "crypto"
+	AssetType     AssetType `json:"asset_type,omitempty" jsonschema_description:"Detailed instrument type (bond_ig, crypto_core, money_market...)"`
+	Region        string    `json:"region" jsonschema_description:"Geographic market exposure (US, Europe, Asia, Emerging, Global)"` // "US", "Europe", "Asia", "Emerging", "Global"
+	Sector        string    `json:"sector,omitempty" jsonschema_description:"Industry sector classification (technology, healthcare, financials, etc.)"`
+	Currency      string    `json:"currency" jsonschema_description:"Currency denomination of the investment"` // holding's, else account's, else portfolio's
+	ExposureGroup string    `json:"exposure_group,omitempty" jsonschema_description:"Canonical ticker of economically identical holdings counted as one position in risk metrics"`
 
 	// Simple flags for AI analysis
 	IsLargePosition bool `json:"is_large_position" jsonschema_description:"Indicates if position exceeds 5% of total portfolio"`        // >5% of portfolio
 	IsForeign       bool `json:"is_foreign" jsonschema_description:"Indicates if investment is in a currency other than base currency"` // Outside base currency
+
+	// Valuation freshness
+	PricedAt time.Time `json:"priced_at" jsonschema_description:"When the holding was last priced"`
+	IsStale  bool      `json:"is_stale,omitempty" jsonschema_description:"Indicates if the price is older than the staleness threshold, so the valuation is approximate"`
+
+	// Account and Wrapper are the account holding the position and its tax wrapper
+	Account string `json:"account,omitempty" jsonschema_description:"Name of the account holding the position"`
+	Wrapper string `json:"wrapper,omitempty" jsonschema_description:"Tax wrapper of the account (ira, pea...), if any"`
+
+	// Lots are the purchases of the holding, unit costs in the base currency
+	Lots []TaxLot `json:"lots,omitempty" jsonschema_description:"Purchase lots with their unit cost and date, for realized gains"`
+}
+
+// StaleHoldings returns the holdings whose price is older than the staleness threshold
+func (n *NormalizedPortfolio) StaleHoldings() []NormalizedHolding {
+	if n == nil {
+		return nil
+	}
+	var out []NormalizedHolding
+	for _, h := range n.Holdings {
+		if h.IsStale {
+			out = append(out, h)
+		}
+	}
+	return out
+}
+
+// StaleFXRates returns the exchange rates used for conversion that are older than
+// the staleness threshold
+func (n *NormalizedPortfolio) StaleFXRates() []NormalizedCurrencyRate {
+	if n == nil {
+		return nil
+	}
+	var out []NormalizedCurrencyRate
+	for _, r := range n.FXRates {
+		if r.IsStale {
+			out = append(out, r)
+		}
+	}
+	return out
 }
 
 // NormalizedRisk provides ONLY concentration and diversification metrics
@@ -81,10 +132,14 @@ type NormalizedMarketData struct {
 	CurrencyRates []NormalizedCurrencyRate `json:"currency_rates,omitempty" jsonschema_description:"Exchange rates for major currency pairs"` // vs USD
 }
 
+// NormalizedCurrencyRate is an exchange rate: one unit of FromCurrency buys
+// ExchangeRate units of ToCurrency
 type NormalizedCurrencyRate struct {
-	FromCurrency string  `json:"from_currency" jsonschema_description:"Base currency code (e.g., EUR, GBP, JPY)"`
-	ToCurrency   string  `json:"to_currency" jsonschema_description:"Quote currency code (typically USD)"`
-	ExchangeRate float64 `json:"exchange_rate" jsonschema_description:"Current exchange rate from base to quote currency"`
+	FromCurrency string    `json:"from_currency" jsonschema_description:"Base currency code (e.g., EUR, GBP, JPY)"`
+	ToCurrency   string    `json:"to_currency" jsonschema_description:"Quote currency code (typically USD)"`
+	ExchangeRate float64   `json:"exchange_rate" jsonschema_description:"Current exchange rate from base to quote currency"`
+	AsOf         time.Time `json:"as_of,omitempty" jsonschema_description:"When the rate was quoted"`
+	IsStale      bool      `json:"is_stale,omitempty" jsonschema_description:"Indicates if the rate is older than the staleness threshold, so converted values are approximate"`
 }
 
 // NormalizedIndex represents market index data from FMP tool
@@ -68,6 +68,11 @@ type Holding struct {
 	Name      string    `yaml:"name,omitempty"`
 	Sector    string    `yaml:"sector,omitempty"`
 	Region    string    `yaml:"region,omitempty"`
+	// PricedAt is when the holding was last priced (crypto trades 24/7, equities
+	// close); unset means the portfolio's as_of
+	PricedAt time.Time `yaml:"priced_at,omitempty"`
+	// Lots are the purchases making up the holding, when known
+	Lots []TaxLot `yaml:"lots,omitempty"`
 }
 
 type Account struct {
@@ -76,6 +81,10 @@ type Account struct {
 	Currency string      `yaml:"currency"`
 	Balance  float64     `yaml:"balance,omitempty"`
 	Holdings []Holding   `yaml:"holdings,omitempty"`
+	// Wrapper is the tax wrapper holding the account (pea, ira...), if any, and
+	// Opened the date it was opened, which some holding periods count from
+	Wrapper string    `yaml:"wrapper,omitempty"`
+	Opened  time.Time `yaml:"opened,omitempty"`
 	// optional metadata
 	ID       string   `yaml:"id,omitempty"`
 	Provider string   `yaml:"provider,omitempty"`
@@ -163,6 +172,51 @@ func (p Portfolio) UserID() string {
 	return fmt.Sprintf("portfolio_%x", h.Sum(nil))[:24]
 }
 
+// Merge combines p with others into a single portfolio. Accounts are copied in
+// order and missing currencies are resolved: an account without currency takes
+// its source's base currency, a holding without currency takes its account's.
+// The base currency is p's (or the first one set), AsOf is the oldest date, since
+// the merged view is only as current as its stalest source, holdings without
+// PricedAt take their source's date, and the result is not validated.
+func (p Portfolio) Merge(others ...Portfolio) Portfolio {
+	out := Portfolio{BaseCurrency: p.BaseCurrency}
+	var oldest time.Time
+
+	for _, src := range append([]Portfolio{p}, others...) {
+		if out.BaseCurrency == "" {
+			out.BaseCurrency = src.BaseCurrency
+		}
+		srcAsOf, err := src.AsOfTime()
+		if err == nil && !srcAsOf.IsZero() && (oldest.IsZero() || srcAsOf.Before(oldest)) {
+			oldest, out.AsOf = srcAsOf, src.AsOf
+		}
+
+		for _, a := range src.Accounts {
+			if a.Currency == "" {
+				a.Currency = src.BaseCurrency
+			}
+			holdings := make([]Holding, len(a.Holdings))
+			for i, h := range a.Holdings {
+				if h.Currency == "" {
+					h.Currency = a.Currency
+				}
+				// the merged as_of is the oldest one: keep the holding's own date
+				if h.PricedAt.IsZero() && err == nil {
+					h.PricedAt = srcAsOf
+				}
+				holdings[i] = h
+			}
+			if a.Holdings == nil {
+				holdings = nil
+			}
+			a.Holdings = holdings
+			a.Tags = append([]string(nil), a.Tags...)
+			out.Accounts = append(out.Accounts, a)
+		}
+	}
+	return out
+}
+
 // HoldingsByType filters holdings in an account by asset type.
 func (a Account) HoldingsByType(t AssetType) []Holding {
 	var out []Holding
@@ -1,8 +1,10 @@
 package models
 
 import (
+	"cmp"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"strings"
 	"time"
 
@@ -10,9 +12,73 @@ import (
 	"golang.org/x/text/language"
 )
 
+// TickerEquivalence maps tickers of economically identical instruments (e.g. VOO
+// and IVV, both tracking the S&P 500) to one canonical ticker
+type TickerEquivalence map[string]string
+
+// NewTickerEquivalence builds an equivalence from groups of identical tickers;
+// the first ticker of each group is the canonical one. Tickers are case-insensitive.
+func NewTickerEquivalence(groups [][]string) TickerEquivalence {
+	eq := make(TickerEquivalence)
+	for _, group := range groups {
+		if len(group) < 2 {
+			continue
+		}
+		canonical := strings.ToUpper(strings.TrimSpace(group[0]))
+		for _, ticker := range group {
+			eq[strings.ToUpper(strings.TrimSpace(ticker))] = canonical
+		}
+	}
+	return eq
+}
+
+// Canonical returns the canonical ticker of ticker's group, or ticker itself
+func (e TickerEquivalence) Canonical(ticker string) string {
+	if c, ok := e[strings.ToUpper(ticker)]; ok {
+		return c
+	}
+	return ticker
+}
+
+// NormalizeOptions tune the normalization of a portfolio
+type NormalizeOptions struct {
+	// Equivalence consolidates equivalent tickers into a single position for the
+	// concentration metrics
+	Equivalence TickerEquivalence
+	// MaxPriceAge flags the holdings priced longer than this before the portfolio
+	// date as stale (0 = never)
+	MaxPriceAge time.Duration
+	// BaseCurrency is the reporting currency of the values; defaults to the
+	// portfolio's, then DefaultBaseCurrency
+	BaseCurrency string
+	// CurrencyRates convert holding values into BaseCurrency
+	CurrencyRates []NormalizedCurrencyRate
+	// MaxFXAge flags the rates used for conversion dated longer than this before
+	// the portfolio date as stale (0 = never)
+	MaxFXAge time.Duration
+	// FXStalePolicy tells whether stale rates are flagged (default) or fail
+	FXStalePolicy FXStalePolicy
+	// Sleeves groups asset classes into presentation sleeves (DefaultSleeves
+	// when empty)
+	Sleeves SleeveMapping
+}
+
 // Normalize converts a Portfolio to a NormalizedPortfolio for AI analysis.
 // This method reuses the existing MarshalJSON logic but returns a structured type.
 func (p Portfolio) Normalize() (*NormalizedPortfolio, error) {
+	return p.NormalizeWith(NormalizeOptions{})
+}
+
+// NormalizeWith normalizes the portfolio with opts. Holdings are listed
+// individually, even when consolidated in the concentration metrics. Values are
+// converted into the reporting currency; a holding whose currency has no rate
+// keeps its amount and its currency is listed in UnconvertedCurrencies. The rates
+// used are listed in FXRates, stale ones flagged or rejected per opts.
+func (p Portfolio) NormalizeWith(opts NormalizeOptions) (*NormalizedPortfolio, error) {
+	eq := opts.Equivalence
+	base := strings.ToUpper(cmp.Or(opts.BaseCurrency, p.BaseCurrency, DefaultBaseCurrency))
+	fx := NewFXTable(opts.CurrencyRates)
+
 	// Parse the AsOf date
 	asOfTime, err := p.AsOfTime()
 	if err != nil {
@@ -23,41 +89,101 @@ func (p Portfolio) Normalize() (*NormalizedPortfolio, error) {
 	}
 
 	// Calculate total value and collect all holdings
-	var allHoldings []Holding
-	totalValueUSD := 0.0
+	type valuedHolding struct {
+		Holding
+		currency string
+		value    float64
+		// rate converts the holding's currency into the base currency
+		rate float64
+		// account, wrapper and opened describe the account holding it
+		account string
+		wrapper string
+		opened  time.Time
+	}
+	var allHoldings []valuedHolding
+	var unconverted []string
+	var fxRates []NormalizedCurrencyRate
+	totalValue := 0.0
 	holdingsCount := 0
 
 	for _, account := range p.Accounts {
 		for _, holding := range account.Holdings {
-			allHoldings = append(allHoldings, holding)
-			// For now, assume all values are in USD (we can enhance this later with currency conversion)
-			totalValueUSD += holding.Value(0)
+			currency := strings.ToUpper(cmp.Or(holding.Currency, account.Currency, p.BaseCurrency, base))
+			rate, used, ok := fx.quote(currency, base)
+			if !ok {
+				// no rate: keep the amount as is rather than drop the holding
+				rate = 1
+				if !slices.Contains(unconverted, currency) {
+					unconverted = append(unconverted, currency)
+				}
+			}
+			for _, r := range used {
+				if !slices.ContainsFunc(fxRates, func(u NormalizedCurrencyRate) bool {
+					return u.FromCurrency == r.FromCurrency && u.ToCurrency == r.ToCurrency
+				}) {
+					fxRates = append(fxRates, r)
+				}
+			}
+			value := holding.Value(0) * rate
+			allHoldings = append(allHoldings, valuedHolding{
+				Holding: holding, currency: currency, value: value, rate: rate,
+				account: cmp.Or(account.Name, account.ID), wrapper: strings.ToLower(account.Wrapper), opened: account.Opened,
+			})
+			totalValue += value
 			holdingsCount++
 		}
 	}
 
-	if totalValueUSD == 0 {
+	if totalValue == 0 {
 		return nil, fmt.Errorf("portfolio has zero total value")
 	}
+	slices.Sort(unconverted)
+
+	var stale []string
+	for i := range fxRates {
+		if fxRates[i].IsStale = isStaleRate(fxRates[i], asOfTime, opts.MaxFXAge); fxRates[i].IsStale {
+			stale = append(stale, fmt.Sprintf("%s/%s as of %s", fxRates[i].FromCurrency, fxRates[i].ToCurrency, fxRates[i].AsOf.Format(time.RFC3339)))
+		}
+	}
+	if len(stale) > 0 && opts.FXStalePolicy == FXStaleError {
+		return nil, fmt.Errorf("%w: %s older than %s", ErrStaleFXRates, strings.Join(stale, ", "), opts.MaxFXAge)
+	}
 
 	// Convert holdings to normalized format
 	normalizedHoldings := make([]NormalizedHolding, 0, len(allHoldings))
 	for _, holding := range allHoldings {
-		value := holding.Value(0)
-		weight := (value / totalValueUSD) * 100 // Convert to percentage
+		value := holding.value
+		weight := (value / totalValue) * 100 // Convert to percentage
+		pricedAt := holding.PricedAt
+		if pricedAt.IsZero() {
+			pricedAt = asOfTime
+		}
 
 		normalizedHolding := NormalizedHolding{
 			Symbol:          holding.Ticker,
 			Name:            holding.Name,
 			WeightPercent:   weight,
-			ValueUSD:        value,
+			Value:           value,
 			Quantity:        holding.Quantity,
 			AssetClass:      normalizeAssetClass(holding.Type),
+			AssetType:       holding.Type,
 			Region:          normalizeRegion(holding.Region),
 			Sector:          holding.Sector,
-			Currency:        holding.Currency,
+			Currency:        holding.currency,
 			IsLargePosition: weight > 5.0,
-			IsForeign:       holding.Currency != "" && holding.Currency != p.BaseCurrency,
+			IsForeign:       holding.currency != base,
+			PricedAt:        pricedAt,
+			Account:         holding.account,
+			Wrapper:         holding.wrapper,
+			IsStale:         opts.MaxPriceAge > 0 && asOfTime.Sub(pricedAt) > opts.MaxPriceAge,
+		}
+		if c := eq.Canonical(holding.Ticker); c != holding.Ticker {
+			normalizedHolding.ExposureGroup = c
+		}
+		for _, lot := range holding.Lots {
+			lot.UnitCost *= holding.rate
+			lot.Wrapper, lot.WrapperOpened = holding.wrapper, holding.opened
+			normalizedHolding.Lots = append(normalizedHolding.Lots, lot)
 		}
 		normalizedHoldings = append(normalizedHoldings, normalizedHolding)
 	}
@@ -66,20 +192,24 @@ func (p Portfolio) Normalize() (*NormalizedPortfolio, error) {
 	assetAllocations := calculateAssetAllocations(normalizedHoldings)
 	regionAllocations := calculateRegionAllocations(normalizedHoldings)
 	sectorAllocations := calculateSectorAllocations(normalizedHoldings)
+	sleeves := AggregateSleeves(normalizedHoldings, opts.Sleeves)
 
 	// Calculate risk metrics
-	riskMetrics := calculateRiskMetrics(normalizedHoldings, p.BaseCurrency)
+	riskMetrics := calculateRiskMetrics(normalizedHoldings, eq)
 
 	return &NormalizedPortfolio{
-		TotalValueUSD:     totalValueUSD,
-		BaseCurrency:      p.BaseCurrency,
-		AsOfDate:          asOfTime,
-		HoldingsCount:     holdingsCount,
-		AssetAllocations:  assetAllocations,
-		RegionAllocations: regionAllocations,
-		SectorAllocations: sectorAllocations,
-		Holdings:          normalizedHoldings,
-		RiskMetrics:       riskMetrics,
+		TotalValue:            totalValue,
+		BaseCurrency:          base,
+		UnconvertedCurrencies: unconverted,
+		FXRates:               fxRates,
+		AsOfDate:              asOfTime,
+		HoldingsCount:         holdingsCount,
+		AssetAllocations:      assetAllocations,
+		RegionAllocations:     regionAllocations,
+		SectorAllocations:     sectorAllocations,
+		Sleeves:               sleeves,
+		Holdings:              normalizedHoldings,
+		RiskMetrics:           riskMetrics,
 	}, nil
 }
 
@@ -156,32 +286,27 @@ func calculateSectorAllocations(holdings []NormalizedHolding) map[string]float64
 	return allocations
 }
 
-func calculateRiskMetrics(holdings []NormalizedHolding, baseCurrency string) NormalizedRisk {
+func calculateRiskMetrics(holdings []NormalizedHolding, eq TickerEquivalence) NormalizedRisk {
 	if len(holdings) == 0 {
 		return NormalizedRisk{}
 	}
 
-	// Calculate Herfindahl Index and other concentration metrics
-	herfindahlIndex := 0.0
-	largestPosition := 0.0
 	foreignCurrencyWeight := 0.0
-
-	// Track top 5 positions
-	weights := make([]float64, len(holdings))
 	regionConcentration := make(map[string]float64)
 	sectorConcentration := make(map[string]float64)
 
+	// positions are the economic exposures: the same or equivalent tickers held
+	// in several accounts count as one
+	positions := make(map[string]float64)
+
 	for i, holding := range holdings {
 		weight := holding.WeightPercent / 100.0 // Convert to decimal
-		weights[i] = weight
-
-		// Herfindahl Index
-		herfindahlIndex += weight * weight
 
-		// Largest position
-		if weight > largestPosition {
-			largestPosition = weight
+		key := fmt.Sprintf("#%d", i) // holdings without ticker stay separate
+		if holding.Symbol != "" {
+			key = strings.ToUpper(eq.Canonical(holding.Symbol))
 		}
+		positions[key] += weight
 
 		// Foreign currency exposure
 		if holding.IsForeign {
@@ -197,8 +322,17 @@ func calculateRiskMetrics(holdings []NormalizedHolding, baseCurrency string) Nor
 		}
 	}
 
-	// Sort weights to find top 5
-	// Simple approach: sum the largest weights
+	// Herfindahl Index and largest position over consolidated positions
+	herfindahlIndex := 0.0
+	weights := make([]float64, 0, len(positions))
+	for _, weight := range positions {
+		herfindahlIndex += weight * weight
+		weights = append(weights, weight)
+	}
+	slices.Sort(weights)
+	slices.Reverse(weights)
+	largestPosition := weights[0]
+
 	maxRegionConcentration := 0.0
 	for _, weight := range regionConcentration {
 		if weight > maxRegionConcentration {
@@ -213,17 +347,10 @@ func calculateRiskMetrics(holdings []NormalizedHolding, baseCurrency string) Nor
 		}
 	}
 
-	// Calculate top 5 positions (simplified)
+	// Top 5 positions
 	top5Weight := 0.0
-	count := 0
-	for _, weight := range weights {
-		if count < 5 {
-			top5Weight += weight
-			count++
-		}
-		if count == 5 {
-			break
-		}
+	for _, weight := range weights[:min(5, len(weights))] {
+		top5Weight += weight
 	}
 
 	// Effective holdings = 1 / Herfindahl Index
@@ -382,6 +509,9 @@ func (h Holding) MarshalJSON() ([]byte, error) {
 	if h.Region != "" {
 		compact["region"] = h.Region
 	}
+	if !h.PricedAt.IsZero() {
+		compact["priced_at"] = h.PricedAt
+	}
 
 	return json.Marshal(compact)
 }
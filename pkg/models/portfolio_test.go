@@ -3,6 +3,7 @@ package models
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -185,3 +186,339 @@ func TestPortfolio_Tickers(t *testing.T) {
 	assert.Contains(t, tickers, "MSFT")
 	assert.Contains(t, tickers, "GOOGL")
 }
+
+func TestPortfolio_Merge(t *testing.T) {
+	t.Parallel()
+
+	broker := Portfolio{
+		AsOf:         "2026-10-15",
+		BaseCurrency: "EUR",
+		Accounts: []Account{{
+			Name: "PEA",
+			Holdings: []Holding{
+				{Ticker: "CW8", Quantity: 10, CostBasis: 450, Type: ETF},
+				{Ticker: "AAPL", Quantity: 5, CostBasis: 180, Currency: "USD", Type: Stock},
+			},
+		}},
+	}
+	exchange := Portfolio{
+		AsOf:         "2026-10-12",
+		BaseCurrency: "USD",
+		Accounts: []Account{
+			{Name: "Spot", Currency: "USDT", Holdings: []Holding{{Ticker: "BTC", Quantity: 0.1, Type: Crypto}}},
+			{Name: "Empty"},
+		},
+	}
+
+	merged := broker.Merge(exchange)
+
+	assert.Equal(t, "EUR", merged.BaseCurrency)
+	assert.Equal(t, "2026-10-12", merged.AsOf)
+	assert.False(t, merged.Validated)
+	require.Len(t, merged.Accounts, 3)
+	assert.Equal(t, "EUR", merged.Accounts[0].Currency)
+	assert.Equal(t, "EUR", merged.Accounts[0].Holdings[0].Currency)
+	assert.Equal(t, "USD", merged.Accounts[0].Holdings[1].Currency)
+	assert.Equal(t, "USDT", merged.Accounts[1].Holdings[0].Currency)
+	assert.Equal(t, "USD", merged.Accounts[2].Currency)
+	assert.ElementsMatch(t, []string{"CW8", "AAPL", "BTC"}, merged.Tickers())
+	// holdings keep the date of their source, not the older merged as_of
+	assert.Equal(t, time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC), merged.Accounts[0].Holdings[0].PricedAt)
+	assert.Equal(t, time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC), merged.Accounts[1].Holdings[0].PricedAt)
+
+	// sources are left untouched
+	assert.Empty(t, broker.Accounts[0].Currency)
+	assert.Empty(t, broker.Accounts[0].Holdings[0].Currency)
+
+	t.Run("base currency from first source that sets one", func(t *testing.T) {
+		got := Portfolio{}.Merge(exchange, broker)
+		assert.Equal(t, "USD", got.BaseCurrency)
+		assert.Len(t, got.Accounts, 3)
+	})
+}
+
+func TestPortfolio_NormalizeWith_EquivalentTickers(t *testing.T) {
+	t.Parallel()
+
+	// VOO and IVV both track the S&P 500: 40% + 30% is one 70% position
+	p := Portfolio{
+		AsOf:         "2026-10-16",
+		BaseCurrency: "USD",
+		Accounts: []Account{
+			{Name: "Taxable", Holdings: []Holding{
+				{Ticker: "VOO", Quantity: 4, CostBasis: 100, Currency: "USD", Type: ETF},
+				{Ticker: "BND", Quantity: 3, CostBasis: 100, Currency: "USD", Type: ETF},
+			}},
+			{Name: "IRA", Holdings: []Holding{
+				{Ticker: "ivv", Quantity: 3, CostBasis: 100, Currency: "USD", Type: ETF},
+			}},
+		},
+	}
+	eq := NewTickerEquivalence([][]string{{"VOO", "IVV", "SPY"}})
+
+	cases := []struct {
+		name        string
+		eq          TickerEquivalence
+		wantHHI     float64
+		wantLargest float64
+	}{
+		{name: "without equivalence", eq: nil, wantHHI: 0.4*0.4 + 0.3*0.3 + 0.3*0.3, wantLargest: 40},
+		{name: "with equivalence", eq: eq, wantHHI: 0.7*0.7 + 0.3*0.3, wantLargest: 70},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			np, err := p.NormalizeWith(NormalizeOptions{Equivalence: c.eq})
+			require.NoError(t, err)
+
+			assert.InDelta(t, c.wantHHI, np.RiskMetrics.HerfindahlIndex, 1e-9)
+			assert.InDelta(t, 1/c.wantHHI, np.RiskMetrics.EffectiveHoldings, 1e-9)
+			assert.InDelta(t, c.wantLargest, np.RiskMetrics.LargestPositionPct, 1e-9)
+			assert.InDelta(t, 100, np.RiskMetrics.Top5PositionsPct, 1e-9)
+
+			// holdings are still listed individually
+			require.Len(t, np.Holdings, 3)
+			assert.Equal(t, "VOO", np.Holdings[0].Symbol)
+			assert.Equal(t, "ivv", np.Holdings[2].Symbol)
+			assert.InDelta(t, 30, np.Holdings[2].WeightPercent, 1e-9)
+		})
+	}
+
+	np, err := p.NormalizeWith(NormalizeOptions{Equivalence: eq})
+	require.NoError(t, err)
+	assert.Empty(t, np.Holdings[0].ExposureGroup, "canonical ticker is its own group")
+	assert.Empty(t, np.Holdings[1].ExposureGroup)
+	assert.Equal(t, "VOO", np.Holdings[2].ExposureGroup)
+}
+
+func TestPortfolio_Normalize_SameTickerAcrossAccounts(t *testing.T) {
+	t.Parallel()
+
+	p := Portfolio{Accounts: []Account{
+		{Name: "Brokerage", Holdings: []Holding{{Ticker: "AAPL", Quantity: 1, CostBasis: 50}, {Ticker: "MSFT", Quantity: 1, CostBasis: 50}}},
+		{Name: "Retirement", Wrapper: "IRA", Holdings: []Holding{{Ticker: "AAPL", Quantity: 1, CostBasis: 100}}},
+	}}
+
+	np, err := p.Normalize()
+	require.NoError(t, err)
+	assert.InDelta(t, 0.75*0.75+0.25*0.25, np.RiskMetrics.HerfindahlIndex, 1e-9)
+	assert.InDelta(t, 75, np.RiskMetrics.LargestPositionPct, 1e-9)
+	// holdings keep their account and its wrapper
+	assert.Equal(t, "Brokerage", np.Holdings[0].Account)
+	assert.Empty(t, np.Holdings[0].Wrapper)
+	assert.Equal(t, "Retirement", np.Holdings[2].Account)
+	assert.Equal(t, "ira", np.Holdings[2].Wrapper)
+}
+
+func TestPortfolio_NormalizeWith_StalePrices(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	p := Portfolio{
+		AsOf:         "2026-10-16",
+		BaseCurrency: "USD",
+		Accounts: []Account{{Holdings: []Holding{
+			{Ticker: "VOO", Quantity: 1, CostBasis: 100, Type: ETF, PricedAt: asOf.Add(-60 * time.Hour)}, // Friday close
+			{Ticker: "BTC", Quantity: 1, CostBasis: 100, Type: Crypto, PricedAt: asOf.Add(-10 * 24 * time.Hour)},
+			{Ticker: "BND", Quantity: 1, CostBasis: 100, Type: ETF}, // priced as of the portfolio date
+		}}},
+	}
+
+	cases := []struct {
+		name        string
+		maxPriceAge time.Duration
+		wantStale   []string
+	}{
+		{name: "staleness disabled", maxPriceAge: 0},
+		{name: "old price flagged", maxPriceAge: 72 * time.Hour, wantStale: []string{"BTC"}},
+		{name: "tight threshold", maxPriceAge: time.Hour, wantStale: []string{"VOO", "BTC"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			np, err := p.NormalizeWith(NormalizeOptions{MaxPriceAge: c.maxPriceAge})
+			require.NoError(t, err)
+
+			var stale []string
+			for _, h := range np.StaleHoldings() {
+				stale = append(stale, h.Symbol)
+			}
+			assert.Equal(t, c.wantStale, stale)
+
+			// unset priced_at defaults to the portfolio date
+			assert.Equal(t, asOf, np.Holdings[2].PricedAt)
+			assert.Equal(t, asOf.Add(-60*time.Hour), np.Holdings[0].PricedAt)
+		})
+	}
+}
+
+func TestPortfolio_NormalizeWith_BaseCurrency(t *testing.T) {
+	t.Parallel()
+
+	// a EUR portfolio with a USD position, rates quoted against USD only
+	p := Portfolio{
+		AsOf:         "2026-10-16",
+		BaseCurrency: "EUR",
+		Accounts: []Account{
+			{Currency: "EUR", Holdings: []Holding{
+				{Ticker: "CW8", Quantity: 2, CostBasis: 500, Type: ETF},                    // 1000 EUR
+				{Ticker: "VWRL", Quantity: 10, CostBasis: 100, Currency: "GBP", Type: ETF}, // 1000 GBP
+			}},
+			{Currency: "USD", Holdings: []Holding{
+				{Ticker: "VOO", Quantity: 2, CostBasis: 540, Type: ETF, Lots: []TaxLot{{Quantity: 2, UnitCost: 432}}}, // 1080 USD
+			}},
+		},
+	}
+	rates := []NormalizedCurrencyRate{
+		{FromCurrency: "EUR", ToCurrency: "USD", ExchangeRate: 1.08},
+		{FromCurrency: "GBP", ToCurrency: "USD", ExchangeRate: 1.35},
+	}
+
+	cases := []struct {
+		name         string
+		baseCurrency string
+		wantBase     string
+		wantTotal    float64
+	}{
+		{name: "portfolio currency by default", wantBase: "EUR", wantTotal: 1000 + 1250 + 1000},
+		{name: "into EUR", baseCurrency: "EUR", wantBase: "EUR", wantTotal: 1000 + 1250 + 1000},
+		{name: "into USD", baseCurrency: "usd", wantBase: "USD", wantTotal: 1080 + 1350 + 1080},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			np, err := p.NormalizeWith(NormalizeOptions{BaseCurrency: c.baseCurrency, CurrencyRates: rates})
+			require.NoError(t, err)
+
+			assert.Equal(t, c.wantBase, np.BaseCurrency)
+			assert.InDelta(t, c.wantTotal, np.TotalValue, 1e-6)
+			assert.Empty(t, np.UnconvertedCurrencies)
+
+			// the same position weighs the same whatever the reporting currency
+			assert.InDelta(t, 1000/3250.0*100, np.Holdings[0].WeightPercent, 1e-6)
+			assert.InDelta(t, 1250/3250.0*100, np.Holdings[1].WeightPercent, 1e-6)
+			assert.Equal(t, "GBP", np.Holdings[1].Currency)
+			assert.Equal(t, "USD", np.Holdings[2].Currency, "account currency applies")
+			assert.Equal(t, c.wantBase != "EUR", np.Holdings[0].IsForeign)
+		})
+	}
+
+	t.Run("cross rates are consistent", func(t *testing.T) {
+		inEUR, err := p.NormalizeWith(NormalizeOptions{BaseCurrency: "EUR", CurrencyRates: rates})
+		require.NoError(t, err)
+		inUSD, err := p.NormalizeWith(NormalizeOptions{BaseCurrency: "USD", CurrencyRates: rates})
+		require.NoError(t, err)
+
+		assert.InDelta(t, inEUR.TotalValue*1.08, inUSD.TotalValue, 1e-6)
+		for i := range inEUR.Holdings {
+			assert.InDelta(t, inEUR.Holdings[i].Value*1.08, inUSD.Holdings[i].Value, 1e-6)
+		}
+		// lot costs are converted like values
+		assert.InDelta(t, 400, inEUR.Holdings[2].Lots[0].UnitCost, 1e-6)
+		assert.InDelta(t, 432, inUSD.Holdings[2].Lots[0].UnitCost, 1e-6)
+	})
+
+	t.Run("missing rate counted at face value", func(t *testing.T) {
+		np, err := p.NormalizeWith(NormalizeOptions{BaseCurrency: "USD", CurrencyRates: rates[:1]})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"GBP"}, np.UnconvertedCurrencies)
+		assert.InDelta(t, 1000, np.Holdings[1].Value, 1e-6)
+	})
+}
+
+func TestFXTable_Rate(t *testing.T) {
+	t.Parallel()
+
+	fx := NewFXTable([]NormalizedCurrencyRate{
+		{FromCurrency: "EUR", ToCurrency: "USD", ExchangeRate: 1.25},
+		{FromCurrency: "USD", ToCurrency: "JPY", ExchangeRate: 150},
+		{FromCurrency: "CHF", ToCurrency: "USD", ExchangeRate: 0}, // ignored
+	})
+
+	cases := []struct {
+		name     string
+		from, to string
+		want     float64
+		wantOK   bool
+	}{
+		{name: "same currency", from: "CHF", to: "chf", want: 1, wantOK: true},
+		{name: "quoted", from: "EUR", to: "USD", want: 1.25, wantOK: true},
+		{name: "inverse", from: "USD", to: "EUR", want: 0.8, wantOK: true},
+		{name: "crossed", from: "EUR", to: "JPY", want: 187.5, wantOK: true},
+		{name: "crossed inverse", from: "JPY", to: "EUR", want: 1 / 187.5, wantOK: true},
+		{name: "unknown", from: "CHF", to: "USD"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, ok := fx.Rate(c.from, c.to)
+			assert.Equal(t, c.wantOK, ok)
+			assert.InDelta(t, c.want, got, 1e-9)
+		})
+	}
+}
+
+func TestPortfolio_NormalizeWith_Sleeves(t *testing.T) {
+	t.Parallel()
+
+	// a sample balanced portfolio holding every asset class
+	p := Portfolio{
+		AsOf:         "2026-10-16",
+		BaseCurrency: "USD",
+		Accounts: []Account{
+			{Name: "Brokerage", Holdings: []Holding{
+				{Ticker: "AAPL", Quantity: 10, CostBasis: 200, Type: Stock},      // 2000
+				{Ticker: "VOO", Quantity: 5, CostBasis: 500, Type: ETF},          // 2500
+				{Ticker: "VFIAX", Quantity: 2, CostBasis: 250, Type: MutualFund}, // 500
+				{Ticker: "BND", Quantity: 30, CostBasis: 75, Type: BondIG},       // 2250
+				{Ticker: "TIP", Quantity: 5, CostBasis: 110, Type: BondIL},       // 550
+				{Ticker: "SGOV", Quantity: 10, CostBasis: 100, Type: CashEQ},     // 1000
+			}},
+			{Name: "Exchange", Holdings: []Holding{
+				{Ticker: "BTC", Quantity: 0.02, CostBasis: 60000, Type: Crypto}, // 1200
+			}},
+		},
+	}
+
+	cases := []struct {
+		name    string
+		mapping SleeveMapping
+		want    []Sleeve
+	}{
+		{
+			name: "default sleeves",
+			want: []Sleeve{
+				{Name: SleeveEquity, Value: 5000, WeightPercent: 50, HoldingsCount: 3, AssetClasses: map[string]float64{"stock": 20, "etf": 25, "mutual_fund": 5}},
+				{Name: SleeveFixedIncome, Value: 2800, WeightPercent: 28, HoldingsCount: 2, AssetClasses: map[string]float64{"bond": 28}},
+				{Name: SleeveAlternatives, Value: 1200, WeightPercent: 12, HoldingsCount: 1, AssetClasses: map[string]float64{"crypto": 12}},
+				{Name: SleeveCash, Value: 1000, WeightPercent: 10, HoldingsCount: 1, AssetClasses: map[string]float64{"cash": 10}},
+			},
+		},
+		{
+			name:    "custom sleeves, unmapped classes in alternatives",
+			mapping: SleeveMapping{"stock": "growth", "etf": "growth", "bond": "defensive", "cash": "defensive"},
+			want: []Sleeve{
+				{Name: "growth", Value: 4500, WeightPercent: 45, HoldingsCount: 2, AssetClasses: map[string]float64{"stock": 20, "etf": 25}},
+				{Name: "defensive", Value: 3800, WeightPercent: 38, HoldingsCount: 3, AssetClasses: map[string]float64{"bond": 28, "cash": 10}},
+				{Name: SleeveAlternatives, Value: 1700, WeightPercent: 17, HoldingsCount: 2, AssetClasses: map[string]float64{"mutual_fund": 5, "crypto": 12}},
+			},
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			np, err := p.NormalizeWith(NormalizeOptions{Sleeves: c.mapping})
+			require.NoError(t, err)
+			require.Len(t, np.Sleeves, len(c.want))
+
+			for i, want := range c.want {
+				got := np.Sleeves[i]
+				assert.Equal(t, want.Name, got.Name)
+				assert.InDelta(t, want.Value, got.Value, 1e-6)
+				assert.InDelta(t, want.WeightPercent, got.WeightPercent, 1e-6)
+				assert.Equal(t, want.HoldingsCount, got.HoldingsCount)
+				assert.InDeltaMapValues(t, want.AssetClasses, got.AssetClasses, 1e-6)
+			}
+		})
+	}
+}
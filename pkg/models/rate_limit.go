@@ -6,11 +6,11 @@ import (
 )
 
 type RateLimitConfig struct {
-	Enabled      bool          `yaml:"enabled"`
-	BaseDelay    time.Duration `yaml:"base_delay"` // min sleep
-	MaxDelay     time.Duration `yaml:"max_delay"`  // cap
-	JitterFactor float64       `yaml:"jitter_factor"`
-	LogMetrics   bool          `yaml:"log_metrics"`
+	Enabled      bool          `mapstructure:"enabled" yaml:"enabled"`
+	BaseDelay    time.Duration `mapstructure:"base_delay" yaml:"base_delay"` // min sleep
+	MaxDelay     time.Duration `mapstructure:"max_delay" yaml:"max_delay"`   // cap
+	JitterFactor float64       `mapstructure:"jitter_factor" yaml:"jitter_factor"`
+	LogMetrics   bool          `mapstructure:"log_metrics" yaml:"log_metrics"`
 }
 
 // Validate validates the RateLimitConfig
@@ -39,11 +39,13 @@ func (rlc *RateLimitConfig) Validate() error {
 }
 
 type RetryConfig struct {
-	MaxRetries      int           `yaml:"max_retries"`
-	BaseDelay       time.Duration `yaml:"base_delay"`
-	MaxDelay        time.Duration `yaml:"max_delay"`
-	ExponentialBase float64       `yaml:"exponential_base"`
-	JitterFactor    float64       `yaml:"jitter_factor"`
+	MaxRetries      int           `mapstructure:"max_retries" yaml:"max_retries"`
+	BaseDelay       time.Duration `mapstructure:"base_delay" yaml:"base_delay"`
+	MaxDelay        time.Duration `mapstructure:"max_delay" yaml:"max_delay"`
+	ExponentialBase float64       `mapstructure:"exponential_base" yaml:"exponential_base"`
+	JitterFactor    float64       `mapstructure:"jitter_factor" yaml:"jitter_factor"`
+	// RunBudget caps the retries of all operations of a run together (0 = unlimited)
+	RunBudget int `mapstructure:"run_budget" yaml:"run_budget"`
 }
 
 // Validate validates the RetryConfig
@@ -78,5 +80,10 @@ func (rc *RetryConfig) Validate() error {
 		return fmt.Errorf("JitterFactor must be between 0 and 1, got: %f", rc.JitterFactor)
 	}
 
+	// RunBudget must be non-negative
+	if rc.RunBudget < 0 {
+		return fmt.Errorf("RunBudget must be non-negative, got: %d", rc.RunBudget)
+	}
+
 	return nil
 }
@@ -112,6 +112,18 @@ func TestRetryConfig_Validate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "negative run budget",
+			config: RetryConfig{
+				MaxRetries:      3,
+				BaseDelay:       1 * time.Second,
+				MaxDelay:        10 * time.Second,
+				ExponentialBase: 2.0,
+				JitterFactor:    0.1,
+				RunBudget:       -1,
+			},
+			wantErr: true,
+		},
 		{
 			name: "invalid exponential base",
 			config: RetryConfig{
@@ -0,0 +1,191 @@
+package models
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TradeSide is the direction of a trade
+type TradeSide string
+
+const (
+	TradeBuy  TradeSide = "buy"
+	TradeSell TradeSide = "sell"
+)
+
+// Reasons a trade of the plan is suppressed
+const (
+	// SuppressedMinTrade marks trades smaller than the minimum trade size
+	SuppressedMinTrade = "below_min_trade"
+	// SuppressedRoundLot marks trades of whole-share instruments under one share
+	SuppressedRoundLot = "below_round_lot"
+	// SuppressedNetBenefit marks trades costing more than the drift they close is worth
+	SuppressedNetBenefit = "below_net_benefit"
+)
+
+// Trade is a buy or sell of the rebalancing plan
+type Trade struct {
+	// Account is the account the trade is placed in, when planned by account
+	Account  string    `json:"account,omitempty" jsonschema_description:"Account to place the trade in"`
+	Symbol   string    `json:"symbol" jsonschema_description:"Ticker to trade"`
+	Side     TradeSide `json:"side" jsonschema_description:"buy or sell"`
+	Quantity float64   `json:"quantity" jsonschema_description:"Number of shares or units to trade"`
+	Price    float64   `json:"price" jsonschema_description:"Unit price in the base currency"`
+	// Value is the amount traded in the base currency, always positive
+	Value float64 `json:"value" jsonschema_description:"Amount traded in the base currency"`
+	// Cost is the expected spread and commission of the trade
+	Cost float64 `json:"cost" jsonschema_description:"Expected spread and commission in the base currency"`
+	// Benefit is what closing the drift is expected to be worth, when cost aware
+	Benefit float64 `json:"benefit,omitempty" jsonschema_description:"Expected worth of the drift closed in the base currency"`
+	// Lots are the tax lots a sell is taken from, in order
+	Lots []LotSale `json:"lots,omitempty" jsonschema_description:"Tax lots sold, in order"`
+	// RealizedGain is the capital gain (negative for a loss) a sell realizes
+	RealizedGain float64 `json:"realized_gain,omitempty" jsonschema_description:"Capital gain realized by a sell in the base currency, negative for a loss"`
+	// Tax is the expected tax on RealizedGain, negative when a loss offsets gains
+	Tax float64 `json:"tax,omitempty" jsonschema_description:"Expected tax on the realized gain in the base currency"`
+	// Warnings flag the lots sold inside a holding period or a wash-sale window
+	Warnings []TaxWarning `json:"warnings,omitempty" jsonschema_description:"Lots sold before a holding period ends or inside a wash-sale window"`
+	// SuppressedReason tells why the trade was left out of the plan
+	SuppressedReason string `json:"suppressed_reason,omitempty" jsonschema_description:"Why the trade was dropped from the plan"`
+}
+
+// LotSale is the part of a sell taken from one tax lot
+type LotSale struct {
+	Acquired time.Time `json:"acquired"`
+	Quantity float64   `json:"quantity"`
+	UnitCost float64   `json:"unit_cost"`
+	Gain     float64   `json:"gain"`
+	LongTerm bool      `json:"long_term"`
+	// Tax is the expected tax on Gain; a loss disallowed as a wash sale saves none
+	Tax float64 `json:"tax"`
+}
+
+// RebalancePlan is the set of trades moving the portfolio to its target weights
+type RebalancePlan struct {
+	// TotalValue is the portfolio value, cash included
+	TotalValue float64 `json:"total_value"`
+	// Trades are the trades to place, sells first
+	Trades []Trade `json:"trades"`
+	// Suppressed are the trades dropped as impractical
+	Suppressed []Trade `json:"suppressed,omitempty"`
+	// Targets are the target weights in percent; the rest is cash
+	Targets map[string]float64 `json:"targets"`
+	// Weights are the weights in percent once the trades are placed
+	Weights map[string]float64 `json:"weights"`
+	// CashAfter is the cash left once the trades are placed
+	CashAfter float64 `json:"cash_after"`
+	// Cost is the expected cost of the trades
+	Cost float64 `json:"cost"`
+	// Benefit is the expected worth of the trades, when cost aware
+	Benefit float64 `json:"benefit,omitempty"`
+	// RealizedGain and Tax total the sells; TaxAware tells whether lots were
+	// picked to minimize them
+	RealizedGain float64 `json:"realized_gain,omitempty"`
+	Tax          float64 `json:"tax,omitempty"`
+	TaxAware     bool    `json:"tax_aware,omitempty"`
+	// Unreachable lists the sleeves with a target but no instrument to buy
+	Unreachable []string `json:"unreachable,omitempty"`
+	// Locked are the do-not-sell positions, left untouched; Targets hold them at
+	// their weight and scale the others to fit
+	Locked []LockedPosition `json:"locked,omitempty"`
+	// Accounts tell where each target is held, when planned by account
+	Accounts []AccountAllocation `json:"accounts,omitempty"`
+}
+
+// AccountAllocation is the share of the targets located in an account
+type AccountAllocation struct {
+	Account   string       `json:"account"`
+	Wrapper   string       `json:"wrapper,omitempty"`
+	Treatment TaxTreatment `json:"treatment"`
+	Value     float64      `json:"value"`
+	// Targets are the weights in percent of the account value
+	Targets map[string]float64 `json:"targets"`
+}
+
+// LockedPosition is a position the plan may not trade
+type LockedPosition struct {
+	Symbol string `json:"symbol"`
+	// WeightPct is the weight (percent) the position keeps
+	WeightPct float64 `json:"weight_pct"`
+	// TargetPct is the weight it would have been rebalanced to
+	TargetPct float64 `json:"target_pct"`
+}
+
+// Summary lists the targets of the account, largest first: "BND 80.0%, VOO 20.0%"
+func (a AccountAllocation) Summary() string {
+	symbols := slices.Sorted(maps.Keys(a.Targets))
+	slices.SortStableFunc(symbols, func(x, y string) int { return cmp.Compare(a.Targets[y], a.Targets[x]) })
+	parts := make([]string, 0, len(symbols))
+	for _, s := range symbols {
+		parts = append(parts, fmt.Sprintf("%s %.1f%%", s, a.Targets[s]))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// LockedWeight returns the weight (percent) of the locked positions
+func (p *RebalancePlan) LockedWeight() float64 {
+	if p == nil {
+		return 0
+	}
+	weight := 0.0
+	for _, l := range p.Locked {
+		weight += l.WeightPct
+	}
+	return weight
+}
+
+// LockedTarget returns the weight (percent) the locked positions were targeted at
+func (p *RebalancePlan) LockedTarget() float64 {
+	if p == nil {
+		return 0
+	}
+	target := 0.0
+	for _, l := range p.Locked {
+		target += l.TargetPct
+	}
+	return target
+}
+
+// Turnover returns the value traded by the plan
+func (p *RebalancePlan) Turnover() float64 {
+	if p == nil {
+		return 0
+	}
+	total := 0.0
+	for _, t := range p.Trades {
+		total += t.Value
+	}
+	return total
+}
+
+// TaxWarnings returns the warnings of the trades, in trade order
+func (p *RebalancePlan) TaxWarnings() []TaxWarning {
+	if p == nil {
+		return nil
+	}
+	var out []TaxWarning
+	for _, t := range p.Trades {
+		out = append(out, t.Warnings...)
+	}
+	return out
+}
+
+// MaxDrift returns the largest distance (percentage points) between the weight
+// of a position after the trades and its target
+func (p *RebalancePlan) MaxDrift() float64 {
+	if p == nil {
+		return 0
+	}
+	drift := 0.0
+	symbols := slices.Collect(maps.Keys(p.Weights))
+	symbols = append(symbols, slices.Collect(maps.Keys(p.Targets))...)
+	for _, s := range symbols {
+		drift = max(drift, math.Abs(p.Weights[s]-p.Targets[s]))
+	}
+	return drift
+}
@@ -0,0 +1,155 @@
+package models
+
+import (
+	"cmp"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Risk tolerances of InvestmentProfile.RiskTolerance
+const (
+	RiskConservative = "conservative"
+	RiskModerate     = "moderate"
+	RiskAggressive   = "aggressive"
+)
+
+// Allocation gap statuses
+const (
+	AllocationOnTarget    = "on_target"
+	AllocationOverweight  = "overweight"
+	AllocationUnderweight = "underweight"
+)
+
+// AllocationBandPct is the drift (percentage points) from the recommended weight
+// a sleeve stays on target within
+const AllocationBandPct = 5.0
+
+// SleeveAllocation is a recommended allocation: the weight (percent) of each
+// sleeve, summing to 100
+type SleeveAllocation map[string]float64
+
+// AllocationTable holds the recommended allocations, keyed by risk tolerance or
+// by risk tolerance and investment style ("moderate/income")
+type AllocationTable map[string]SleeveAllocation
+
+// DefaultAllocationTable returns the recommended allocations of the three risk
+// tolerances over the default sleeves
+func DefaultAllocationTable() AllocationTable {
+	return AllocationTable{
+		RiskConservative: {SleeveEquity: 30, SleeveFixedIncome: 55, SleeveAlternatives: 5, SleeveCash: 10},
+		RiskModerate:     {SleeveEquity: 55, SleeveFixedIncome: 35, SleeveAlternatives: 5, SleeveCash: 5},
+		RiskAggressive:   {SleeveEquity: 80, SleeveFixedIncome: 10, SleeveAlternatives: 8, SleeveCash: 2},
+	}
+}
+
+// Recommended returns the allocation recommended for a profile and its key: the
+// risk tolerance and style entry first, then the risk tolerance alone. A profile
+// without risk tolerance is moderate.
+func (t AllocationTable) Recommended(profile *InvestmentProfile) (SleeveAllocation, string, bool) {
+	risk, style := RiskModerate, ""
+	if profile != nil {
+		risk = cmp.Or(strings.ToLower(strings.TrimSpace(profile.RiskTolerance)), RiskModerate)
+		style = strings.ToLower(strings.TrimSpace(profile.InvestmentStyle))
+	}
+	if style != "" {
+		if a, ok := t[risk+"/"+style]; ok {
+			return a, risk + "/" + style, true
+		}
+	}
+	a, ok := t[risk]
+	return a, risk, ok
+}
+
+// AllocationGap compares the actual weight of a sleeve to the recommended one
+type AllocationGap struct {
+	Sleeve    string  `json:"sleeve" jsonschema_description:"Sleeve name"`
+	ActualPct float64 `json:"actual_pct" jsonschema_description:"Current weight of the sleeve in percent"`
+	TargetPct float64 `json:"target_pct" jsonschema_description:"Recommended weight of the sleeve in percent"`
+	// GapPct is ActualPct - TargetPct: positive when overweight
+	GapPct float64 `json:"gap_pct" jsonschema_description:"Actual minus recommended weight, in percentage points"`
+	// Amount is the value to buy (positive) or sell (negative) to reach the target
+	Amount float64 `json:"amount" jsonschema_description:"Value to buy (positive) or sell (negative) to reach the target, in the base currency"`
+	Status string  `json:"status" jsonschema_description:"on_target, overweight or underweight"`
+}
+
+// AllocationComparison is the gap analysis of a portfolio against the allocation
+// recommended for its profile
+type AllocationComparison struct {
+	// Profile is the allocation table key used, e.g. conservative
+	Profile string          `json:"profile"`
+	Gaps    []AllocationGap `json:"gaps"`
+}
+
+// Target returns the recommended allocation the gaps were measured against
+func (c *AllocationComparison) Target() SleeveAllocation {
+	if c == nil {
+		return nil
+	}
+	target := make(SleeveAllocation, len(c.Gaps))
+	for _, g := range c.Gaps {
+		if g.TargetPct > 0 {
+			target[g.Sleeve] = g.TargetPct
+		}
+	}
+	return target
+}
+
+// OffTarget returns the gaps outside the allocation band
+func (c *AllocationComparison) OffTarget() []AllocationGap {
+	if c == nil {
+		return nil
+	}
+	var out []AllocationGap
+	for _, g := range c.Gaps {
+		if g.Status != AllocationOnTarget {
+			out = append(out, g)
+		}
+	}
+	return out
+}
+
+// CompareAllocation compares the sleeves of a portfolio to a recommended
+// allocation. Sleeves on either side are listed, largest gap first.
+func CompareAllocation(n *NormalizedPortfolio, target SleeveAllocation) []AllocationGap {
+	if n == nil {
+		return nil
+	}
+	sleeves := n.Sleeves
+	if sleeves == nil {
+		sleeves = AggregateSleeves(n.Holdings, nil)
+	}
+	actual := make(map[string]float64, len(sleeves))
+	for _, s := range sleeves {
+		actual[s.Name] = s.WeightPercent
+	}
+
+	names := slices.Sorted(maps.Keys(actual))
+	for name := range target {
+		if _, ok := actual[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	gaps := make([]AllocationGap, 0, len(names))
+	for _, name := range names {
+		g := AllocationGap{Sleeve: name, ActualPct: actual[name], TargetPct: target[name]}
+		g.GapPct = g.ActualPct - g.TargetPct
+		g.Amount = -g.GapPct * n.TotalValue / 100
+		switch {
+		case g.GapPct > AllocationBandPct:
+			g.Status = AllocationOverweight
+		case g.GapPct < -AllocationBandPct:
+			g.Status = AllocationUnderweight
+		default:
+			g.Status = AllocationOnTarget
+		}
+		gaps = append(gaps, g)
+	}
+	slices.SortStableFunc(gaps, func(a, b AllocationGap) int {
+		return cmp.Compare(math.Abs(b.GapPct), math.Abs(a.GapPct))
+	})
+	return gaps
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAllocationTable_Recommended(t *testing.T) {
+	t.Parallel()
+
+	table := DefaultAllocationTable()
+	table["moderate/income"] = SleeveAllocation{SleeveEquity: 40, SleeveFixedIncome: 50, SleeveCash: 10}
+
+	cases := []struct {
+		name    string
+		profile *InvestmentProfile
+		wantKey string
+		wantOK  bool
+	}{
+		{name: "no profile is moderate", wantKey: RiskModerate, wantOK: true},
+		{name: "risk tolerance", profile: &InvestmentProfile{RiskTolerance: "Conservative"}, wantKey: RiskConservative, wantOK: true},
+		{name: "risk tolerance and style", profile: &InvestmentProfile{RiskTolerance: "moderate", InvestmentStyle: "income"}, wantKey: "moderate/income", wantOK: true},
+		{name: "style without entry", profile: &InvestmentProfile{RiskTolerance: "aggressive", InvestmentStyle: "growth"}, wantKey: RiskAggressive, wantOK: true},
+		{name: "unknown risk tolerance", profile: &InvestmentProfile{RiskTolerance: "reckless"}, wantKey: "reckless"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			got, key, ok := table.Recommended(c.profile)
+			assert.Equal(t, c.wantKey, key)
+			assert.Equal(t, c.wantOK, ok)
+			if ok {
+				assert.Equal(t, table[key], got)
+			}
+		})
+	}
+}
+
+func TestCompareAllocation_CryptoHeavyConservative(t *testing.T) {
+	t.Parallel()
+
+	p := Portfolio{AsOf: "2026-10-16", BaseCurrency: "USD", Accounts: []Account{{
+		Currency: "USD",
+		Holdings: []Holding{
+			{Ticker: "BTC", Quantity: 1, CostBasis: 6000, Type: Crypto},
+			{Ticker: "ETH", Quantity: 1, CostBasis: 2000, Type: Crypto},
+			{Ticker: "VOO", Quantity: 1, CostBasis: 1500, Type: ETF},
+			{Ticker: "USD", Quantity: 1, CostBasis: 500, Type: Cash},
+		},
+	}}}
+	n, err := p.Normalize()
+	require.NoError(t, err)
+
+	target, key, ok := DefaultAllocationTable().Recommended(&InvestmentProfile{RiskTolerance: RiskConservative})
+	require.True(t, ok)
+	require.Equal(t, RiskConservative, key)
+
+	gaps := CompareAllocation(n, target)
+	byName := make(map[string]AllocationGap)
+	for _, g := range gaps {
+		byName[g.Sleeve] = g
+	}
+	require.Len(t, byName, 4)
+
+	// 80% crypto against 5% alternatives: the largest gap, to sell
+	assert.Equal(t, SleeveAlternatives, gaps[0].Sleeve)
+	assert.Equal(t, AllocationOverweight, gaps[0].Status)
+	assert.InDelta(t, 75, gaps[0].GapPct, 1e-9)
+	assert.InDelta(t, -7500, gaps[0].Amount, 1e-6)
+
+	// no bonds at all
+	assert.Equal(t, AllocationUnderweight, byName[SleeveFixedIncome].Status)
+	assert.InDelta(t, 0, byName[SleeveFixedIncome].ActualPct, 1e-9)
+	assert.InDelta(t, 5500, byName[SleeveFixedIncome].Amount, 1e-6)
+	assert.Equal(t, AllocationUnderweight, byName[SleeveEquity].Status)
+	assert.Equal(t, AllocationOnTarget, byName[SleeveCash].Status)
+
+	comparison := &AllocationComparison{Profile: key, Gaps: gaps}
+	assert.Len(t, comparison.OffTarget(), 3)
+
+	// the same portfolio suits an aggressive profile better
+	aggressive, _, _ := DefaultAllocationTable().Recommended(&InvestmentProfile{RiskTolerance: RiskAggressive})
+	assert.Less(t, CompareAllocation(n, aggressive)[0].GapPct, gaps[0].GapPct)
+}
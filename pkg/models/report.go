@@ -26,20 +26,49 @@ const (
 	TypeFull     ReportType = "full"
 )
 
+// HoldingsSort is the display order of the holdings in reports
+type HoldingsSort string
+
+const (
+	HoldingsSortWeightDesc HoldingsSort = "weight_desc" // largest weight first (default)
+	HoldingsSortAlpha      HoldingsSort = "alpha"       // by symbol
+	HoldingsSortValueDesc  HoldingsSort = "value_desc"  // largest value first
+	HoldingsSortSector     HoldingsSort = "sector"      // by sector, largest weight first within a sector
+)
+
+// HoldingsSorts lists the supported holdings sort orders
+var HoldingsSorts = []HoldingsSort{HoldingsSortWeightDesc, HoldingsSortAlpha, HoldingsSortValueDesc, HoldingsSortSector}
+
 // CustomerReportData contains data for customer-facing reports
 type CustomerReportData struct {
-	Portfolio       any       `json:"portfolio,omitempty"`
-	RiskMetrics     any       `json:"risk_metrics,omitempty"`
-	AllocationData  any       `json:"allocation_data,omitempty"`
-	PerformanceData any       `json:"performance_data,omitempty"`
-	ComplianceData  any       `json:"compliance_data,omitempty"`
-	StockAnalysis   any       `json:"stock_analysis,omitempty"`
-	Insights        any       `json:"insights,omitempty"`
-	NewsAnalyzed    any       `json:"news_analyzed,omitempty"`
-	Fundamentals    any       `json:"fundamentals,omitempty"`
-	Recommendations any       `json:"recommendations,omitempty"`
-	GeneratedAt     time.Time `json:"generated_at"`
-	CustomerName    string    `json:"customer_name,omitempty"`
+	Portfolio       any        `json:"portfolio,omitempty"`
+	RiskMetrics     any        `json:"risk_metrics,omitempty"`
+	AllocationData  any        `json:"allocation_data,omitempty"`
+	PerformanceData any        `json:"performance_data,omitempty"`
+	ComplianceData  any        `json:"compliance_data,omitempty"`
+	StockAnalysis   any        `json:"stock_analysis,omitempty"`
+	Insights        any        `json:"insights,omitempty"`
+	NewsAnalyzed    any        `json:"news_analyzed,omitempty"`
+	Fundamentals    any        `json:"fundamentals,omitempty"`
+	Recommendations any        `json:"recommendations,omitempty"`
+	Citations       []Citation `json:"citations,omitempty"`
+	Alerts          []Alert    `json:"alerts,omitempty"`
+	// StaleHoldings are priced too long before the portfolio date for their
+	// valuation to be current
+	StaleHoldings []NormalizedHolding `json:"stale_holdings,omitempty"`
+	// Sleeves aggregate the holdings by asset-class sleeve
+	Sleeves []Sleeve `json:"sleeves,omitempty"`
+	// Health is the portfolio health score, shown at the top of the report
+	Health *HealthScore `json:"health,omitempty"`
+	// Allocation compares the sleeves to the allocation recommended for the profile
+	Allocation *AllocationComparison `json:"allocation,omitempty"`
+	// Rebalance is the trade plan toward the recommended allocation
+	Rebalance *RebalancePlan `json:"rebalance,omitempty"`
+	// Holdings are the normalized positions, valued in HoldingsCurrency
+	Holdings         []NormalizedHolding `json:"holdings,omitempty"`
+	HoldingsCurrency string              `json:"holdings_currency,omitempty"`
+	GeneratedAt      time.Time           `json:"generated_at"`
+	CustomerName     string              `json:"customer_name,omitempty"`
 }
 
 // SystemReportData contains data for system diagnostic reports
@@ -50,8 +79,13 @@ type SystemReportData struct {
 	ExternalDataHealth  *ExternalDataHealth  `json:"external_data_health,omitempty"`
 	MarketDataFreshness *MarketDataFreshness `json:"market_data_freshness,omitempty"`
 	ToolComputations    []ToolComputation    `json:"tool_computations,omitempty"`
-	GeneratedAt         time.Time            `json:"generated_at"`
-	BatchMode           bool                 `json:"batch_mode,omitempty"`
+	ReasoningTraces     []ReasoningTrace     `json:"reasoning_traces,omitempty"`
+	// FXRates are the exchange rates the portfolio values were converted with
+	FXRates []NormalizedCurrencyRate `json:"fx_rates,omitempty"`
+	// Warnings are data quality issues worth a look, e.g. stale FX rates
+	Warnings    []string  `json:"warnings,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+	BatchMode   bool      `json:"batch_mode,omitempty"`
 }
 
 // ReportGenerator interface for generating different types of reports
@@ -84,8 +118,11 @@ type ReportMeta struct {
 	DataSources      []string          `json:"data_sources"`
 	GenerationTimeMs int64             `json:"generation_time_ms"`
 	Version          string            `json:"version"`
+	EngineVersion    string            `json:"engine_version,omitempty"`
 	CustomFields     map[string]string `json:"custom_fields,omitempty"`
 	CustomerName     string            `json:"customer_name,omitempty"`
+	// FilteredHeadlines counts news headlines dropped for falling below the relevance threshold
+	FilteredHeadlines int `json:"filtered_headlines,omitempty"`
 }
 
 // ReportRequest represents a request to generate a report
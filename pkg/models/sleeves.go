@@ -0,0 +1,78 @@
+package models
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+)
+
+// Default sleeve names
+const (
+	SleeveEquity       = "equity"
+	SleeveFixedIncome  = "fixed_income"
+	SleeveAlternatives = "alternatives"
+	SleeveCash         = "cash"
+)
+
+// AssetClasses lists the normalized asset classes of NormalizedHolding.AssetClass
+var AssetClasses = []string{"stock", "etf", "mutual_fund", "bond", "cash", "crypto", "other"}
+
+// SleeveMapping maps normalized asset classes to the sleeve they are presented in
+type SleeveMapping map[string]string
+
+// DefaultSleeves returns the usual advisor sleeves: equity, fixed income,
+// alternatives and cash
+func DefaultSleeves() SleeveMapping {
+	return SleeveMapping{
+		"stock":       SleeveEquity,
+		"etf":         SleeveEquity,
+		"mutual_fund": SleeveEquity,
+		"bond":        SleeveFixedIncome,
+		"cash":        SleeveCash,
+		"crypto":      SleeveAlternatives,
+		"other":       SleeveAlternatives,
+	}
+}
+
+// Sleeve aggregates the holdings of the asset classes mapped to it
+type Sleeve struct {
+	Name          string  `json:"name" jsonschema_description:"Sleeve name (equity, fixed_income, alternatives, cash or custom)"`
+	Value         float64 `json:"value" jsonschema_description:"Total value of the sleeve in the base currency"`
+	WeightPercent float64 `json:"weight_percent" jsonschema_description:"Sleeve size as percentage of total portfolio value"` // 0-100
+	HoldingsCount int     `json:"holdings_count" jsonschema_description:"Number of positions in the sleeve"`
+	// AssetClasses breaks the sleeve down by asset class, in percent of the portfolio
+	AssetClasses map[string]float64 `json:"asset_classes" jsonschema_description:"Percentage of the portfolio held in each asset class of the sleeve"`
+}
+
+// AggregateSleeves groups holdings into sleeves per mapping (DefaultSleeves when
+// empty); asset classes missing from the mapping fall into alternatives. Sleeves
+// are sorted by weight, largest first.
+func AggregateSleeves(holdings []NormalizedHolding, mapping SleeveMapping) []Sleeve {
+	if len(holdings) == 0 {
+		return nil
+	}
+	if len(mapping) == 0 {
+		mapping = DefaultSleeves()
+	}
+
+	sleeves := make(map[string]*Sleeve)
+	for _, h := range holdings {
+		name := cmp.Or(mapping[h.AssetClass], SleeveAlternatives)
+		s, ok := sleeves[name]
+		if !ok {
+			s = &Sleeve{Name: name, AssetClasses: make(map[string]float64)}
+			sleeves[name] = s
+		}
+		s.Value += h.Value
+		s.WeightPercent += h.WeightPercent
+		s.HoldingsCount++
+		s.AssetClasses[h.AssetClass] += h.WeightPercent
+	}
+
+	out := make([]Sleeve, 0, len(sleeves))
+	for _, name := range slices.Sorted(maps.Keys(sleeves)) {
+		out = append(out, *sleeves[name])
+	}
+	slices.SortStableFunc(out, func(a, b Sleeve) int { return cmp.Compare(b.WeightPercent, a.WeightPercent) })
+	return out
+}
@@ -0,0 +1,154 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// TaxLot is one purchase of a holding, used to compute the gains a sale realizes
+type TaxLot struct {
+	Quantity float64 `yaml:"quantity" json:"quantity"`
+	// UnitCost is the purchase price per unit, in the holding's currency (the
+	// base currency once normalized)
+	UnitCost float64   `yaml:"unit_cost" json:"unit_cost"`
+	Acquired time.Time `yaml:"acquired" json:"acquired"`
+	// Wrapper and WrapperOpened are set on normalization from the account holding
+	// the lot
+	Wrapper       string    `yaml:"-" json:"wrapper,omitempty"`
+	WrapperOpened time.Time `yaml:"-" json:"wrapper_opened,omitzero"`
+}
+
+// TaxRules are the capital gains rules of a jurisdiction
+type TaxRules struct {
+	Country CountryCode `json:"country"`
+	// ShortTermRate and LongTermRate tax realized gains (fractions, 0.30 = 30%)
+	ShortTermRate float64 `json:"short_term_rate"`
+	LongTermRate  float64 `json:"long_term_rate"`
+	// LongTermAfterDays is the holding period past which gains are long term; 0
+	// when the jurisdiction taxes all gains alike
+	LongTermAfterDays int `json:"long_term_after_days"`
+	// HoldingPeriods are the thresholds a gain should not be realized before
+	HoldingPeriods []HoldingPeriod `json:"holding_periods,omitempty"`
+	// WashSaleDays disallows a loss realized within this many days of buying the
+	// same security; 0 when the jurisdiction has no wash-sale rule
+	WashSaleDays int `json:"wash_sale_days,omitempty"`
+	// Wrappers are the tax-advantaged account types of the jurisdiction
+	Wrappers []TaxWrapper `json:"wrappers,omitempty"`
+}
+
+// TaxTreatment is how the income and gains of an account are taxed
+type TaxTreatment string
+
+const (
+	TaxTaxable  TaxTreatment = "taxable"
+	TaxDeferred TaxTreatment = "tax_deferred"
+	TaxExempt   TaxTreatment = "tax_exempt"
+)
+
+// Advantaged reports whether the income and gains of the account escape tax
+// while held
+func (t TaxTreatment) Advantaged() bool { return t == TaxDeferred || t == TaxExempt }
+
+// TaxWrapper is an account type with its own tax treatment (IRA, PEA...)
+type TaxWrapper struct {
+	Name      string       `json:"name"`
+	Treatment TaxTreatment `json:"treatment"`
+	// Eligible lists the asset classes the wrapper may hold; empty allows all
+	Eligible []string `json:"eligible,omitempty"`
+}
+
+// Holds reports whether the wrapper may hold an asset class
+func (w TaxWrapper) Holds(assetClass string) bool {
+	return len(w.Eligible) == 0 || slices.Contains(w.Eligible, assetClass)
+}
+
+// Wrapper returns the tax wrapper of an account; accounts without a wrapper, or
+// with one the jurisdiction does not know, are taxable
+func (r TaxRules) Wrapper(name string) TaxWrapper {
+	for _, w := range r.Wrappers {
+		if strings.EqualFold(w.Name, name) {
+			return w
+		}
+	}
+	return TaxWrapper{Name: name, Treatment: TaxTaxable}
+}
+
+// HoldingPeriod is a holding period past which gains are taxed more favorably
+type HoldingPeriod struct {
+	Name string `json:"name"`
+	Days int    `json:"days"`
+	// Wrapper limits the period to the lots of a tax wrapper (pea), counted from
+	// the wrapper opening date rather than the purchase
+	Wrapper string `json:"wrapper,omitempty"`
+	// Warning tells what selling before the end of the period costs
+	Warning string `json:"warning"`
+}
+
+// WashSalePeriod names the warnings of sales at a loss under the wash-sale rule
+const WashSalePeriod = "wash_sale"
+
+// TaxWarning flags the part of a sell realizing a gain or a loss under
+// unfavorable terms
+type TaxWarning struct {
+	Symbol   string    `json:"symbol"`
+	Period   string    `json:"period"`
+	Acquired time.Time `json:"acquired,omitzero"`
+	Quantity float64   `json:"quantity"`
+	Gain     float64   `json:"gain"`
+	// DaysLeft is how long to wait for the sale to clear the period
+	DaysLeft int    `json:"days_left"`
+	Message  string `json:"message"`
+}
+
+// IsLongTerm reports whether a lot acquired at acquired and sold at sold is held
+// long term; lots without a purchase date are
+func (r TaxRules) IsLongTerm(acquired, sold time.Time) bool {
+	return r.LongTermAfterDays == 0 || acquired.IsZero() || sold.Sub(acquired) > time.Duration(r.LongTermAfterDays)*24*time.Hour
+}
+
+// Rate returns the rate taxing the gain of a lot acquired at acquired and sold
+// at sold
+func (r TaxRules) Rate(acquired, sold time.Time) float64 {
+	if r.IsLongTerm(acquired, sold) {
+		return r.LongTermRate
+	}
+	return r.ShortTermRate
+}
+
+// HoldingWarnings flags the holding periods a sale of quantity units of lot at
+// sold, realizing gain, falls within. Only gains are flagged.
+func (r TaxRules) HoldingWarnings(symbol string, lot TaxLot, quantity, gain float64, sold time.Time) []TaxWarning {
+	if gain <= 0 {
+		return nil
+	}
+	var out []TaxWarning
+	for _, p := range r.HoldingPeriods {
+		start := lot.Acquired
+		if p.Wrapper != "" {
+			if !strings.EqualFold(p.Wrapper, lot.Wrapper) {
+				continue
+			}
+			start = lot.WrapperOpened
+		}
+		if p.Days <= 0 || start.IsZero() {
+			continue
+		}
+		held := sold.Sub(start)
+		if held > time.Duration(p.Days)*24*time.Hour {
+			continue
+		}
+		left := max(1, p.Days-int(held.Hours()/24))
+		out = append(out, TaxWarning{
+			Symbol:   symbol,
+			Period:   p.Name,
+			Acquired: lot.Acquired,
+			Quantity: quantity,
+			Gain:     gain,
+			DaysLeft: left,
+			Message:  fmt.Sprintf("%s (%d days left)", p.Warning, left),
+		})
+	}
+	return out
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaxRules_HoldingWarnings(t *testing.T) {
+	t.Parallel()
+
+	sold := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	rules := TaxRules{HoldingPeriods: []HoldingPeriod{
+		{Name: "short_term", Days: 365, Warning: "short-term gain"},
+		{Name: "pea_5_years", Days: 1826, Wrapper: "pea", Warning: "PEA closed"},
+	}}
+	cases := []struct {
+		name     string
+		lot      TaxLot
+		gain     float64
+		expected []string
+	}{
+		{
+			name:     "short-term gain",
+			lot:      TaxLot{Acquired: sold.AddDate(0, -2, 0)},
+			gain:     100,
+			expected: []string{"short_term"},
+		},
+		{
+			name: "short-term loss",
+			lot:  TaxLot{Acquired: sold.AddDate(0, -2, 0)},
+			gain: -100,
+		},
+		{
+			name: "long-term gain",
+			lot:  TaxLot{Acquired: sold.AddDate(-2, 0, 0)},
+			gain: 100,
+		},
+		{
+			name: "undated lot",
+			gain: 100,
+		},
+		{
+			name:     "young PEA",
+			lot:      TaxLot{Acquired: sold.AddDate(-2, 0, 0), Wrapper: "pea", WrapperOpened: sold.AddDate(-3, 0, 0)},
+			gain:     100,
+			expected: []string{"pea_5_years"},
+		},
+		{
+			name: "PEA over five years",
+			lot:  TaxLot{Acquired: sold.AddDate(-2, 0, 0), Wrapper: "pea", WrapperOpened: sold.AddDate(-6, 0, 0)},
+			gain: 100,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			var periods []string
+			for _, w := range rules.HoldingWarnings("VOO", c.lot, 1, c.gain, sold) {
+				periods = append(periods, w.Period)
+				assert.Positive(t, w.DaysLeft)
+			}
+			assert.Equal(t, c.expected, periods)
+		})
+	}
+}
@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// TranscriptMessage is one message of an LLM conversation, as recorded for review
+type TranscriptMessage struct {
+	Role       Role       `json:"role"`
+	Content    string     `json:"content,omitempty"`
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // assistant messages calling tools
+	ToolCallID string     `json:"tool_call_id,omitempty"` // tool messages: the call answered
+	Model      string     `json:"model,omitempty"`        // assistant messages: the model that answered
+	CreatedAt  time.Time  `json:"created_at"`
+}
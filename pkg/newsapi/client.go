@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
 )
 
 // Client provides access to NewsAPI endpoints
@@ -26,7 +27,8 @@ func NewClient(apiKey string) *Client {
 		apiKey:  apiKey,
 		baseURL: "https://newsapi.org/v2",
 		http: &http.Client{
-			Timeout: 30 * time.Second,
+			Timeout:   30 * time.Second,
+			Transport: sandbox.Guard(nil),
 		},
 	}
 }
@@ -173,6 +175,8 @@ type NewsAPIResponse struct {
 	Status       string        `json:"status"`
 	TotalResults int           `json:"totalResults"`
 	Articles     []NewsArticle `json:"articles"`
+	// Related are previously seen articles most similar to the request, best first
+	Related []models.NewsArticle `json:"related,omitempty"`
 }
 
 // NewsArticle represents a news article from NewsAPI
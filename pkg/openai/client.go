@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/amaurybrisou/mosychlos/internal/config"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
 )
 
 //go:generate mockgen -source=client.go -destination=mocks/client_mock.go -package=mocks
@@ -32,11 +33,25 @@ func NewClient(doer HTTPDoer, cfg config.OpenAIConfig) *Client {
 
 func (c *Client) Do(ctx context.Context, req *http.Request) (*http.Response, error) {
 	return c.middleware.Do(ctx, func(ctx context.Context) (*http.Response, error) {
+		// hold a slot per attempt only, so retry backoff does not starve other callers
+		if err := Outbound().Acquire(ctx); err != nil {
+			return nil, err
+		}
+		defer Outbound().Release()
 		req = req.WithContext(ctx)
 		return c.doer.Do(req)
 	})
 }
 
+// RetryBudget returns the retry budget shared by every call of the client, so
+// retries decided above the HTTP layer draw from it too
+func (c *Client) RetryBudget() *RetryBudget {
+	if c == nil {
+		return nil
+	}
+	return c.middleware.budget
+}
+
 // NewHTTPClient creates a new HTTP client with the specified middleware.
 func NewHTTPClient(mw ...RTMiddleware) *http.Client {
 	transport := http.DefaultTransport.(*http.Transport).Clone()
@@ -51,7 +66,8 @@ func NewHTTPClient(mw ...RTMiddleware) *http.Client {
 	transport.MaxConnsPerHost = 0
 	transport.MaxIdleConnsPerHost = 100
 
-	rt := http.RoundTripper(transport)
+	// the sandbox guard sits closest to the network so middleware cannot bypass it
+	rt := sandbox.Guard(transport)
 	if len(mw) > 0 {
 		rt = ChainRoundTripper(rt, mw...)
 	}
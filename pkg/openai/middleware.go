@@ -11,12 +11,14 @@ import (
 type MiddlewareChain struct {
 	cfg     config.OpenAIConfig
 	limiter Limiter
+	budget  *RetryBudget
 }
 
 func NewDefaultMiddlewareChain(cfg config.OpenAIConfig) *MiddlewareChain {
 	return &MiddlewareChain{
 		cfg:     cfg,
 		limiter: NewRPMLimiter(cfg.RateLimit),
+		budget:  NewRetryBudget(cfg.Retry.RunBudget),
 	}
 }
 
@@ -26,7 +28,7 @@ func (m *MiddlewareChain) Do(ctx context.Context, do DoFunc) (*http.Response, er
 			return nil, err
 		}
 	}
-	resp, err := WithRetry(ctx, m.cfg.Retry, do)
+	resp, err := WithRetry(ctx, m.cfg.Retry, m.budget, do)
 	if resp != nil {
 		m.limiter.Update(parseRateHeaders(resp.Header))
 	}
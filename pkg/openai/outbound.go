@@ -0,0 +1,47 @@
+// pkg/openai/outbound.go
+package openai
+
+import "context"
+
+// DefaultMaxOutbound caps the number of OpenAI requests in flight across the process
+const DefaultMaxOutbound = 8
+
+// Semaphore bounds concurrent outbound work. A nil *Semaphore never blocks.
+type Semaphore struct {
+	slots chan struct{}
+}
+
+// NewSemaphore creates a semaphore with n slots (at least one)
+func NewSemaphore(n int) *Semaphore {
+	if n < 1 {
+		n = 1
+	}
+	return &Semaphore{slots: make(chan struct{}, n)}
+}
+
+// Acquire blocks until a slot is free or ctx is done
+func (s *Semaphore) Acquire(ctx context.Context) error {
+	if s == nil {
+		return nil
+	}
+	select {
+	case s.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Release frees a slot taken by Acquire
+func (s *Semaphore) Release() {
+	if s == nil {
+		return
+	}
+	<-s.slots
+}
+
+var outbound = NewSemaphore(DefaultMaxOutbound)
+
+// Outbound returns the process-wide semaphore shared by every outbound OpenAI call,
+// whether it goes through Client or through the SDK (batch status polling).
+func Outbound() *Semaphore { return outbound }
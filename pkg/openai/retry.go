@@ -4,15 +4,28 @@ package openai
 import (
 	"context"
 	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
 	"time"
 
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
 )
 
 type DoFunc func(ctx context.Context) (*http.Response, error)
 
-func WithRetry(ctx context.Context, cfg models.RetryConfig, do DoFunc) (*http.Response, error) {
+var (
+	// ErrRateLimited is returned when requests are still rate limited after all retries
+	ErrRateLimited = errors.New("rate limited")
+	// ErrServerError is returned when the API still fails with a 5xx after all retries
+	ErrServerError = errors.New("server error")
+)
+
+// WithRetry calls do until it succeeds, retrying 5xx, 429 and network errors up to
+// cfg.MaxRetries times. Every retry is drawn from budget; once it is exhausted the
+// last error is returned.
+func WithRetry(ctx context.Context, cfg models.RetryConfig, budget *RetryBudget, do DoFunc) (*http.Response, error) {
 	var lastErr error
 	delay := cfg.BaseDelay
 	for attempt := 0; attempt <= cfg.MaxRetries; attempt++ {
@@ -20,10 +33,30 @@ func WithRetry(ctx context.Context, cfg models.RetryConfig, do DoFunc) (*http.Re
 		if err == nil && resp != nil && resp.StatusCode < 500 && resp.StatusCode != 429 {
 			return resp, nil
 		}
+		// a blocked request stays blocked, retrying only delays the error
+		if errors.Is(err, sandbox.ErrNetworkDisabled) {
+			return nil, err
+		}
 		if resp != nil && resp.StatusCode == 429 {
-			lastErr = errors.New("rate limited")
+			lastErr = ErrRateLimited
 		} else if err != nil {
 			lastErr = err
+		} else if resp != nil {
+			lastErr = fmt.Errorf("%w: %s", ErrServerError, resp.Status)
+		}
+		// the response is discarded, release its connection
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+		if attempt == cfg.MaxRetries {
+			break
+		}
+		if !budget.Take() {
+			slog.Warn("Retry budget exhausted, giving up",
+				"attempt", attempt+1,
+				"retries_used", budget.Used(),
+				"error", lastErr)
+			return nil, lastErr
 		}
 		// backoff
 		sleep := jitter(delay, cfg.JitterFactor)
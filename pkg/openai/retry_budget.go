@@ -0,0 +1,44 @@
+// pkg/openai/retry_budget.go
+package openai
+
+import "sync/atomic"
+
+// RetryBudget caps the total number of retries of a run, whatever retries them:
+// HTTP retries, truncated structured output, model fallback. A nil *RetryBudget
+// never runs out.
+type RetryBudget struct {
+	max  int64
+	used atomic.Int64
+}
+
+// NewRetryBudget creates a budget of max retries, nil (unlimited) when max <= 0
+func NewRetryBudget(max int) *RetryBudget {
+	if max <= 0 {
+		return nil
+	}
+	return &RetryBudget{max: int64(max)}
+}
+
+// Take spends one retry, reporting false once the budget is exhausted
+func (b *RetryBudget) Take() bool {
+	if b == nil {
+		return true
+	}
+	for {
+		used := b.used.Load()
+		if used >= b.max {
+			return false
+		}
+		if b.used.CompareAndSwap(used, used+1) {
+			return true
+		}
+	}
+}
+
+// Used returns the number of retries spent
+func (b *RetryBudget) Used() int {
+	if b == nil {
+		return 0
+	}
+	return int(b.used.Load())
+}
@@ -0,0 +1,61 @@
+package openai
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestWithRetry_SharedBudget(t *testing.T) {
+	cases := []struct {
+		name         string
+		budget       int
+		operations   int
+		wantAttempts int
+	}{
+		// every operation gets its first attempt, retries stop once the budget is spent
+		{name: "budget caps retries across operations", budget: 5, operations: 10, wantAttempts: 10 + 5},
+		{name: "budget larger than needed", budget: 100, operations: 3, wantAttempts: 3 * 4},
+		{name: "no budget", budget: 0, operations: 10, wantAttempts: 10 * 4},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			cfg := models.RetryConfig{MaxRetries: 3, ExponentialBase: 2}
+			budget := NewRetryBudget(c.budget)
+
+			var (
+				mu       sync.Mutex
+				attempts int
+			)
+			failing := func(context.Context) (*http.Response, error) {
+				mu.Lock()
+				attempts++
+				mu.Unlock()
+				return &http.Response{StatusCode: http.StatusServiceUnavailable, Status: "503 Service Unavailable"}, nil
+			}
+
+			var wg sync.WaitGroup
+			for range c.operations {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					_, err := WithRetry(context.Background(), cfg, budget, failing)
+					require.ErrorIs(t, err, ErrServerError)
+				}()
+			}
+			wg.Wait()
+
+			assert.Equal(t, c.wantAttempts, attempts)
+			if c.budget > 0 {
+				assert.LessOrEqual(t, budget.Used(), c.budget)
+			}
+		})
+	}
+}
@@ -0,0 +1,284 @@
+package rebalance
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/amaurybrisou/mosychlos/pkg/errors"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// locationOrder ranks the sleeves from the least tax efficient, located first in
+// the tax-advantaged accounts: bond interest is taxed as income every year,
+// equity gains only when sold and at a lower rate
+var locationOrder = []string{models.SleeveFixedIncome, models.SleeveAlternatives, models.SleeveEquity}
+
+// Account is an account of the portfolio: its trades stay in it and its cash
+// only funds its own buys
+type Account struct {
+	Name string
+	// Wrapper is the tax treatment of the account (taxable when unset)
+	Wrapper   models.TaxWrapper
+	Positions []Position
+	Cash      float64
+}
+
+// Value returns the value of the account, cash included
+func (a Account) Value() float64 {
+	total := a.Cash
+	for _, p := range a.Positions {
+		total += p.Value()
+	}
+	return total
+}
+
+// CalculateAccounts plans the trades moving a portfolio held in several accounts
+// to the target weights (percent of the whole portfolio), account by account.
+// Targets are located first: the least tax-efficient sleeves (fixed income, then
+// alternatives) fill the tax-advantaged accounts, tax-deferred before tax-exempt
+// ones, within the asset classes their wrapper may hold; the rest goes to the
+// taxable accounts, those already holding a position first. A target no account
+// may hold stays in cash. Each account is then planned as Calculate does, its
+// sells free of capital gains tax when the account is tax advantaged.
+func CalculateAccounts(accounts []Account, targets map[string]float64, opts Options) (*models.RebalancePlan, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if len(accounts) == 0 {
+		return nil, errors.EmptyUniverseError()
+	}
+
+	total := 0.0
+	merged := make(map[string]Position)
+	values := make(map[string]float64)
+	names := make(map[string]bool, len(accounts))
+	for _, a := range accounts {
+		if names[a.Name] {
+			return nil, errors.InvalidAccountError(a.Name, "duplicate account")
+		}
+		names[a.Name] = true
+		if a.Cash < 0 {
+			return nil, errors.NegativeValueError(a.Name+" cash", a.Cash)
+		}
+		for _, p := range a.Positions {
+			if p.Quantity < 0 {
+				return nil, errors.NegativeValueError(p.Symbol+" quantity", p.Quantity)
+			}
+			if p.Price <= 0 {
+				return nil, errors.InvalidInputsError(fmt.Sprintf("%s has no price", p.Symbol))
+			}
+			m, ok := merged[p.Symbol]
+			if !ok {
+				m = Position{Symbol: p.Symbol, Price: p.Price, WholeShares: p.WholeShares, Class: p.Class}
+			}
+			m.Quantity += p.Quantity
+			merged[p.Symbol] = m
+			values[p.Symbol] += p.Value()
+		}
+		total += a.Value()
+	}
+	for symbol, m := range merged {
+		if m.Quantity > 0 {
+			m.Price = values[symbol] / m.Quantity
+			merged[symbol] = m
+		}
+	}
+	if err := checkTargets(targets, merged); err != nil {
+		return nil, err
+	}
+	if total <= 0 {
+		return nil, errors.InvalidInputsError("portfolio has no value")
+	}
+
+	targets, locked := lockTargets(merged, total, targets, opts.DoNotSell)
+	located := locate(accounts, merged, total, targets, locked, opts.Sleeves)
+
+	plan := &models.RebalancePlan{TotalValue: total, Targets: targets, Locked: locked, TaxAware: opts.TaxAware}
+	after := make(map[string]float64, len(merged))
+	for i, a := range accounts {
+		value := a.Value()
+		alloc := models.AccountAllocation{
+			Account:   a.Name,
+			Wrapper:   a.Wrapper.Name,
+			Treatment: cmp.Or(a.Wrapper.Treatment, models.TaxTaxable),
+			Value:     value,
+			Targets:   make(map[string]float64, len(located[i])),
+		}
+		positions := slices.Clone(a.Positions)
+		for _, symbol := range slices.Sorted(maps.Keys(located[i])) {
+			if !slices.ContainsFunc(positions, func(p Position) bool { return p.Symbol == symbol }) {
+				m := merged[symbol]
+				positions = append(positions, Position{Symbol: symbol, Price: m.Price, WholeShares: m.WholeShares, Class: m.Class})
+			}
+			alloc.Targets[symbol] = located[i][symbol] / value * 100
+		}
+		plan.Accounts = append(plan.Accounts, alloc)
+		if len(positions) == 0 || value <= 0 {
+			plan.CashAfter += a.Cash
+			continue
+		}
+
+		accountOpts := opts
+		// the minimum trade size is relative to the whole portfolio
+		accountOpts.MinTradeValue = max(opts.MinTradeValue, opts.MinTradePct*total/100)
+		accountOpts.MinTradePct = 0
+		if alloc.Treatment.Advantaged() {
+			// no capital gains tax while held, and trades inside the wrapper
+			// withdraw nothing from it: its holding periods stay clear
+			accountOpts.Tax = models.TaxRules{Country: opts.Tax.Country}
+		}
+		p, err := Calculate(positions, a.Cash, alloc.Targets, accountOpts)
+		if err != nil {
+			return nil, fmt.Errorf("account %s: %w", a.Name, err)
+		}
+		for _, t := range p.Trades {
+			t.Account = a.Name
+			plan.Trades = append(plan.Trades, t)
+		}
+		for _, t := range p.Suppressed {
+			t.Account = a.Name
+			plan.Suppressed = append(plan.Suppressed, t)
+		}
+		for symbol, w := range p.Weights {
+			after[symbol] += w * value / 100
+		}
+		plan.CashAfter += p.CashAfter
+		plan.Cost += p.Cost
+		plan.Benefit += p.Benefit
+		plan.RealizedGain += p.RealizedGain
+		plan.Tax += p.Tax
+	}
+	if opts.Tax.WashSaleDays > 0 {
+		washSalesAcross(plan, opts.Tax.WashSaleDays)
+	}
+	plan.Weights = make(map[string]float64, len(after))
+	for symbol, v := range after {
+		plan.Weights[symbol] = v / total * 100
+	}
+	return plan, nil
+}
+
+// washSalesAcross disallows the losses a taxable account realizes on a symbol
+// another account buys back in the same plan, the window running from the buy
+func washSalesAcross(plan *models.RebalancePlan, days int) {
+	bought := make(map[string]bool)
+	for _, t := range plan.Trades {
+		if t.Side == models.TradeBuy {
+			bought[t.Symbol] = true
+		}
+	}
+	for i := range plan.Trades {
+		t := &plan.Trades[i]
+		if t.Side != models.TradeSell || !bought[t.Symbol] {
+			continue
+		}
+		for j := range t.Lots {
+			l := &t.Lots[j]
+			// sheltered or already washed losses save no tax
+			if l.Gain >= 0 || l.Tax == 0 {
+				continue
+			}
+			t.Tax -= l.Tax
+			plan.Tax -= l.Tax
+			l.Tax = 0
+			t.Warnings = append(t.Warnings, models.TaxWarning{
+				Symbol:   t.Symbol,
+				Period:   models.WashSalePeriod,
+				Acquired: l.Acquired,
+				Quantity: l.Quantity,
+				Gain:     l.Gain,
+				DaysLeft: days,
+				Message:  fmt.Sprintf("loss disallowed: %s is bought back in another account", t.Symbol),
+			})
+		}
+	}
+}
+
+// locate spreads the target values over the accounts, the locked positions
+// staying where they are; it returns the value of each symbol targeted in each
+// account
+func locate(accounts []Account, merged map[string]Position, total float64, targets map[string]float64, locked []models.LockedPosition, sleeves models.SleeveMapping) []map[string]float64 {
+	isLocked := make(map[string]bool, len(locked))
+	for _, l := range locked {
+		isLocked[l.Symbol] = true
+	}
+	located := make([]map[string]float64, len(accounts))
+	room := make([]float64, len(accounts))
+	for i, a := range accounts {
+		located[i] = make(map[string]float64)
+		room[i] = a.Value()
+		for _, p := range a.Positions {
+			if isLocked[p.Symbol] {
+				located[i][p.Symbol] += p.Value()
+				room[i] -= p.Value()
+			}
+		}
+	}
+
+	if sleeves == nil {
+		sleeves = models.DefaultSleeves()
+	}
+	rank := func(symbol string) int {
+		if r := slices.Index(locationOrder, sleeves[merged[symbol].Class]); r >= 0 {
+			return r
+		}
+		return len(locationOrder)
+	}
+	want := make(map[string]float64, len(targets))
+	for symbol, pct := range targets {
+		if !isLocked[symbol] && pct > 0 {
+			want[symbol] = pct * total / 100
+		}
+	}
+	symbols := slices.Sorted(maps.Keys(want))
+	slices.SortStableFunc(symbols, func(a, b string) int { return cmp.Compare(rank(a), rank(b)) })
+
+	treatmentOrder := []models.TaxTreatment{models.TaxDeferred, models.TaxExempt}
+	accountRank := func(i int, symbol string) (int, int) {
+		t := slices.Index(treatmentOrder, accounts[i].Wrapper.Treatment)
+		if t < 0 {
+			t = len(treatmentOrder)
+		}
+		// keep positions where they already are
+		held := 1
+		if slices.ContainsFunc(accounts[i].Positions, func(p Position) bool { return p.Symbol == symbol && p.Quantity > 0 }) {
+			held = 0
+		}
+		return t, held
+	}
+
+	// the tax-advantaged accounts are filled first, least tax-efficient sleeves
+	// first, then the rest goes wherever there is room
+	for _, advantagedOnly := range []bool{true, false} {
+		for _, symbol := range symbols {
+			order := make([]int, len(accounts))
+			for i := range order {
+				order[i] = i
+			}
+			slices.SortStableFunc(order, func(a, b int) int {
+				ta, ha := accountRank(a, symbol)
+				tb, hb := accountRank(b, symbol)
+				return cmp.Or(cmp.Compare(ta, tb), cmp.Compare(ha, hb))
+			})
+			for _, i := range order {
+				w := accounts[i].Wrapper
+				if want[symbol] <= 1e-9 {
+					break
+				}
+				if (advantagedOnly && !w.Treatment.Advantaged()) || !w.Holds(merged[symbol].Class) {
+					continue
+				}
+				put := min(want[symbol], room[i])
+				if put <= 1e-9 {
+					continue
+				}
+				located[i][symbol] += put
+				room[i] -= put
+				want[symbol] -= put
+			}
+		}
+	}
+	return located
+}
@@ -0,0 +1,208 @@
+package rebalance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/errors"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestCalculateAccounts_AssetLocation(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	us := models.TaxRules{Country: "US", ShortTermRate: 0.37, LongTermRate: 0.2, LongTermAfterDays: 365}
+	bought := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	// the bonds sit in the taxable account, the IRA only holds VOO
+	accounts := []Account{
+		{Name: "Brokerage", Positions: []Position{
+			{Symbol: "BND", Quantity: 50, Price: 80, Class: "bond", Lots: []models.TaxLot{{Quantity: 50, UnitCost: 70, Acquired: bought}}},
+			{Symbol: "VOO", Quantity: 15, Price: 400, Class: "etf"},
+		}},
+		{Name: "IRA", Wrapper: models.TaxWrapper{Name: "ira", Treatment: models.TaxDeferred}, Positions: []Position{
+			{Symbol: "VOO", Quantity: 25, Price: 400, Class: "etf", Lots: []models.TaxLot{{Quantity: 25, UnitCost: 300, Acquired: bought}}},
+		}},
+	}
+
+	plan, err := CalculateAccounts(accounts, map[string]float64{"BND": 40, "VOO": 60}, Options{Tax: us, AsOf: asOf})
+	require.NoError(t, err)
+
+	// the bonds move to the IRA, the taxable account holds the equity
+	require.Len(t, plan.Accounts, 2)
+	assert.Equal(t, "Brokerage", plan.Accounts[0].Account)
+	assert.Equal(t, models.TaxTaxable, plan.Accounts[0].Treatment)
+	assert.InDeltaMapValues(t, map[string]float64{"VOO": 100}, plan.Accounts[0].Targets, 1e-9)
+	assert.Equal(t, models.TaxDeferred, plan.Accounts[1].Treatment)
+	assert.InDeltaMapValues(t, map[string]float64{"BND": 80, "VOO": 20}, plan.Accounts[1].Targets, 1e-9)
+
+	type placed struct {
+		account, symbol string
+		side            models.TradeSide
+		value           float64
+	}
+	var trades []placed
+	for _, tr := range plan.Trades {
+		trades = append(trades, placed{tr.Account, tr.Symbol, tr.Side, tr.Value})
+	}
+	assert.Equal(t, []placed{
+		{"Brokerage", "BND", models.TradeSell, 4000},
+		{"Brokerage", "VOO", models.TradeBuy, 4000},
+		{"IRA", "VOO", models.TradeSell, 8000},
+		{"IRA", "BND", models.TradeBuy, 8000},
+	}, trades)
+
+	// the portfolio reaches its targets, cash stays in each account
+	assert.InDelta(t, 40, plan.Weights["BND"], 1e-9)
+	assert.InDelta(t, 60, plan.Weights["VOO"], 1e-9)
+	assert.InDelta(t, 0, plan.CashAfter, 1e-9)
+	// only the taxable sale is taxed
+	assert.InDelta(t, 500+2000, plan.RealizedGain, 1e-9)
+	assert.InDelta(t, 500*0.2, plan.Tax, 1e-9)
+}
+
+func TestCalculateAccounts_Eligibility(t *testing.T) {
+	t.Parallel()
+
+	// a PEA holds European equities only: the bonds stay in the taxable account
+	pea := models.TaxWrapper{Name: "pea", Treatment: models.TaxExempt, Eligible: []string{"stock", "etf"}}
+	plan, err := CalculateAccounts([]Account{
+		{Name: "CTO", Positions: []Position{
+			{Symbol: "CW8", Quantity: 10, Price: 500, Class: "etf"},
+			{Symbol: "OAT", Quantity: 0, Price: 100, Class: "bond"},
+		}},
+		{Name: "PEA", Wrapper: pea, Cash: 5000},
+	}, map[string]float64{"CW8": 60, "OAT": 40}, Options{})
+	require.NoError(t, err)
+
+	require.Len(t, plan.Accounts, 2)
+	assert.InDeltaMapValues(t, map[string]float64{"OAT": 80, "CW8": 20}, plan.Accounts[0].Targets, 1e-9)
+	assert.InDeltaMapValues(t, map[string]float64{"CW8": 100}, plan.Accounts[1].Targets, 1e-9)
+	for _, tr := range plan.Trades {
+		if tr.Symbol == "OAT" {
+			assert.Equal(t, "CTO", tr.Account)
+		}
+	}
+	assert.InDelta(t, 40, plan.Weights["OAT"], 1e-9)
+}
+
+func TestCalculateAccounts_HoldingPeriodsInsideWrapper(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	fr := models.TaxRules{
+		Country: "FR", ShortTermRate: 0.3, LongTermRate: 0.3,
+		HoldingPeriods: []models.HoldingPeriod{{Name: "pea_5_years", Days: 1826, Wrapper: "pea", Warning: "PEA closed"}},
+	}
+	pea := models.TaxWrapper{Name: "pea", Treatment: models.TaxExempt, Eligible: []string{"stock", "etf"}}
+	// the PEA opened two years ago sells CW8 at a gain to buy EWLD: the cash stays in it
+	plan, err := CalculateAccounts([]Account{
+		{Name: "CTO", Cash: 2000, Positions: []Position{
+			{Symbol: "EWLD", Quantity: 0, Price: 100, Class: "etf"},
+			{Symbol: "OAT", Quantity: 0, Price: 100, Class: "bond"},
+		}},
+		{Name: "PEA", Wrapper: pea, Positions: []Position{
+			{Symbol: "CW8", Quantity: 20, Price: 100, Class: "etf", Lots: []models.TaxLot{
+				{Quantity: 20, UnitCost: 50, Acquired: asOf.AddDate(-1, 0, 0), Wrapper: "pea", WrapperOpened: asOf.AddDate(-2, 0, 0)},
+			}},
+		}},
+	}, map[string]float64{"CW8": 25, "EWLD": 50, "OAT": 25}, Options{Tax: fr, AsOf: asOf})
+	require.NoError(t, err)
+
+	sold := false
+	for _, tr := range plan.Trades {
+		if tr.Side == models.TradeSell {
+			sold = true
+			assert.Equal(t, "PEA", tr.Account)
+		}
+	}
+	assert.True(t, sold)
+	assert.InDelta(t, 500, plan.RealizedGain, 1e-9)
+	assert.InDelta(t, 0, plan.Tax, 1e-9)
+	assert.Empty(t, plan.TaxWarnings())
+}
+
+func TestCalculateAccounts_WashSaleAcrossAccounts(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	rules := models.TaxRules{Country: "FR", ShortTermRate: 0.3, LongTermRate: 0.3, WashSaleDays: 30}
+	pea := models.TaxWrapper{Name: "pea", Treatment: models.TaxExempt, Eligible: []string{"stock", "etf"}}
+	// the CTO sells CW8 at a loss to make room for the bonds while the PEA buys it
+	plan, err := CalculateAccounts([]Account{
+		{Name: "CTO", Positions: []Position{
+			{Symbol: "CW8", Quantity: 20, Price: 100, Class: "etf", Lots: []models.TaxLot{
+				{Quantity: 20, UnitCost: 150, Acquired: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+			}},
+			{Symbol: "OAT", Quantity: 0, Price: 100, Class: "bond"},
+		}},
+		{Name: "PEA", Wrapper: pea, Cash: 1000},
+	}, map[string]float64{"CW8": 40, "OAT": 60}, Options{Tax: rules, AsOf: asOf})
+	require.NoError(t, err)
+
+	require.NotEmpty(t, plan.Trades)
+	sell := plan.Trades[0]
+	assert.Equal(t, "CTO", sell.Account)
+	assert.Equal(t, models.TradeSell, sell.Side)
+	assert.InDelta(t, 18, sell.Quantity, 1e-9)
+	assert.InDelta(t, -900, plan.RealizedGain, 1e-9)
+	assert.InDelta(t, 0, sell.Tax, 1e-9)
+	assert.InDelta(t, 0, plan.Tax, 1e-9)
+
+	warnings := plan.TaxWarnings()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, models.WashSalePeriod, warnings[0].Period)
+	assert.Equal(t, 30, warnings[0].DaysLeft)
+	assert.Contains(t, warnings[0].Message, "bought back in another account")
+}
+
+func TestCalculateAccounts_Errors(t *testing.T) {
+	t.Parallel()
+
+	voo := Position{Symbol: "VOO", Quantity: 1, Price: 500}
+	cases := []struct {
+		name     string
+		accounts []Account
+		targets  map[string]float64
+		want     error
+	}{
+		{name: "no accounts", want: errors.ErrEmptyUniverse},
+		{name: "duplicate account", accounts: []Account{{Name: "IRA", Positions: []Position{voo}}, {Name: "IRA"}}, want: errors.ErrInvalidAccount},
+		{name: "negative cash", accounts: []Account{{Name: "IRA", Cash: -1}}, want: errors.ErrNegativeValue},
+		{name: "target without position", accounts: []Account{{Name: "IRA", Positions: []Position{voo}}}, targets: map[string]float64{"BND": 10}, want: errors.ErrInvalidInputs},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := CalculateAccounts(c.accounts, c.targets, Options{})
+			assert.ErrorIs(t, err, c.want)
+		})
+	}
+}
+
+func TestAccounts(t *testing.T) {
+	t.Parallel()
+
+	rules := models.TaxRules{Wrappers: []models.TaxWrapper{{Name: "ira", Treatment: models.TaxDeferred}}}
+	n := &models.NormalizedPortfolio{Holdings: []models.NormalizedHolding{
+		{Symbol: "VOO", AssetClass: "etf", Quantity: 10, Value: 4000, Account: "Brokerage"},
+		{Symbol: "BND", AssetClass: "bond", Quantity: 50, Value: 4000, Account: "IRA", Wrapper: "ira"},
+		{Symbol: "USD", AssetClass: "cash", Quantity: 500, Value: 500, Account: "IRA", Wrapper: "ira"},
+		{Symbol: "AAPL", AssetClass: "stock", Quantity: 5, Value: 1000, Account: "Brokerage"},
+	}}
+
+	accounts := Accounts(n, rules, map[string]float64{"VOO": 450})
+	require.Len(t, accounts, 2)
+	assert.Equal(t, "Brokerage", accounts[0].Name)
+	assert.Equal(t, models.TaxTaxable, accounts[0].Wrapper.Treatment)
+	assert.Equal(t, []string{"AAPL", "VOO"}, []string{accounts[0].Positions[0].Symbol, accounts[0].Positions[1].Symbol})
+	// quoted positions take the market price, the others their value per unit
+	assert.InDelta(t, 200, accounts[0].Positions[0].Price, 1e-9)
+	assert.InDelta(t, 450, accounts[0].Positions[1].Price, 1e-9)
+	assert.Equal(t, models.TaxDeferred, accounts[1].Wrapper.Treatment)
+	assert.InDelta(t, 500, accounts[1].Cash, 1e-9)
+	assert.InDelta(t, 4500, accounts[1].Value(), 1e-9)
+}
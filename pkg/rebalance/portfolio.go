@@ -0,0 +1,135 @@
+package rebalance
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// Positions returns the tradable positions of a normalized portfolio, merged by
+// symbol across accounts, and its cash. Stocks and ETFs trade in whole shares.
+//
+// prices are the market unit prices by symbol in the base currency, as quoted by
+// a market data provider. They value the positions and the gains of their lots.
+// A symbol without a price is valued per unit at its normalized value, which is
+// its average cost when the portfolio only records cost bases: its lots then
+// sell close to no gain.
+func Positions(n *models.NormalizedPortfolio, prices map[string]float64) ([]Position, float64) {
+	if n == nil {
+		return nil, 0
+	}
+	return positions(n.Holdings, prices)
+}
+
+// Accounts returns the accounts of a normalized portfolio with their positions
+// and cash, in the order they first appear. Their tax wrapper comes from the
+// jurisdiction rules; prices are the market prices as for Positions.
+func Accounts(n *models.NormalizedPortfolio, rules models.TaxRules, prices map[string]float64) []Account {
+	if n == nil {
+		return nil
+	}
+	var names []string
+	byAccount := make(map[string][]models.NormalizedHolding)
+	wrappers := make(map[string]string)
+	for _, h := range n.Holdings {
+		if _, ok := byAccount[h.Account]; !ok {
+			names = append(names, h.Account)
+			wrappers[h.Account] = h.Wrapper
+		}
+		byAccount[h.Account] = append(byAccount[h.Account], h)
+	}
+
+	out := make([]Account, 0, len(names))
+	for _, name := range names {
+		a := Account{Name: name, Wrapper: models.TaxWrapper{Treatment: models.TaxTaxable}}
+		if w := wrappers[name]; w != "" {
+			a.Wrapper = rules.Wrapper(w)
+		}
+		a.Positions, a.Cash = positions(byAccount[name], prices)
+		out = append(out, a)
+	}
+	return out
+}
+
+// positions merges holdings by symbol into positions, priced at their market
+// price or else their value per unit, and sums the cash
+func positions(holdings []models.NormalizedHolding, prices map[string]float64) ([]Position, float64) {
+	cash := 0.0
+	bySymbol := make(map[string]*Position)
+	values := make(map[string]float64)
+	for _, h := range holdings {
+		if h.AssetClass == "cash" {
+			cash += h.Value
+			continue
+		}
+		if h.Quantity <= 0 {
+			continue
+		}
+		p, ok := bySymbol[h.Symbol]
+		if !ok {
+			p = &Position{Symbol: h.Symbol, WholeShares: h.AssetClass == "stock" || h.AssetClass == "etf", Class: h.AssetClass}
+			bySymbol[h.Symbol] = p
+		}
+		p.Quantity += h.Quantity
+		for _, l := range h.Lots {
+			// merged across accounts, each lot keeps the wrapper holding it
+			l.Wrapper = cmp.Or(l.Wrapper, h.Wrapper)
+			p.Lots = append(p.Lots, l)
+		}
+		values[h.Symbol] += h.Value
+	}
+
+	out := make([]Position, 0, len(bySymbol))
+	for _, symbol := range slices.Sorted(maps.Keys(bySymbol)) {
+		p := bySymbol[symbol]
+		p.Price = values[symbol] / p.Quantity
+		if price := prices[symbol]; price > 0 {
+			p.Price = price
+		}
+		out = append(out, *p)
+	}
+	return out, cash
+}
+
+// SleeveTargets spreads a sleeve allocation over the holdings of each sleeve in
+// proportion to their current weight, giving target weights by symbol. Cash
+// holdings are left to the cash remainder. Sleeves with a target but no holding
+// to buy are returned as unreachable; their weight stays in cash.
+func SleeveTargets(n *models.NormalizedPortfolio, target models.SleeveAllocation, mapping models.SleeveMapping) (map[string]float64, []string) {
+	if n == nil {
+		return nil, nil
+	}
+	if len(mapping) == 0 {
+		mapping = models.DefaultSleeves()
+	}
+	sleeveOf := func(h models.NormalizedHolding) string {
+		return cmp.Or(mapping[h.AssetClass], models.SleeveAlternatives)
+	}
+
+	sleeveWeight := make(map[string]float64)
+	for _, h := range n.Holdings {
+		if h.AssetClass != "cash" && h.Quantity > 0 {
+			sleeveWeight[sleeveOf(h)] += h.WeightPercent
+		}
+	}
+
+	targets := make(map[string]float64)
+	for _, h := range n.Holdings {
+		if h.AssetClass == "cash" || h.Quantity <= 0 {
+			continue
+		}
+		if w := sleeveWeight[sleeveOf(h)]; w > 0 {
+			targets[h.Symbol] += target[sleeveOf(h)] * h.WeightPercent / w
+		}
+	}
+
+	var unreachable []string
+	for _, sleeve := range slices.Sorted(maps.Keys(target)) {
+		if target[sleeve] > 0 && sleeveWeight[sleeve] == 0 && sleeve != mapping["cash"] {
+			unreachable = append(unreachable, sleeve)
+		}
+	}
+	return targets, unreachable
+}
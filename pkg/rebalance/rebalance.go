@@ -0,0 +1,502 @@
+// Package rebalance turns target weights into a practical trade plan.
+package rebalance
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/amaurybrisou/mosychlos/pkg/errors"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+// Position is a tradable holding
+type Position struct {
+	Symbol   string
+	Quantity float64
+	// Price is the unit price in the base currency
+	Price float64
+	// WholeShares is set for instruments traded in whole units (stocks, ETFs)
+	WholeShares bool
+	// Class is the asset class (stock, bond...), placing the position in the
+	// accounts suited to it
+	Class string
+	// Lots are the purchases of the position, unit costs in the base currency;
+	// units not covered by a lot are sold at no gain
+	Lots []models.TaxLot
+}
+
+// Value returns the market value of the position
+func (p Position) Value() float64 { return p.Quantity * p.Price }
+
+// Cost is the cost assumption of trading an instrument
+type Cost struct {
+	// SpreadBps is the half spread paid on the traded value, in basis points
+	SpreadBps float64
+	// Commission is the fixed fee per trade in the base currency
+	Commission float64
+}
+
+// Of returns the cost of trading value
+func (c Cost) Of(value float64) float64 { return value*c.SpreadBps/10000 + c.Commission }
+
+// Options tune the plan
+type Options struct {
+	// MinTradeValue drops trades smaller than this value (base currency)
+	MinTradeValue float64
+	// MinTradePct drops trades smaller than this share (percent) of the portfolio;
+	// the larger of the two minimums applies
+	MinTradePct float64
+	// RoundLots trades whole-share instruments in whole units, rounded to the
+	// nearest unit
+	RoundLots bool
+	// DefaultCost applies to the instruments missing from Costs
+	DefaultCost Cost
+	// Costs are the cost assumptions by symbol
+	Costs map[string]Cost
+	// TrackingErrorPct makes the plan cost aware: a position off target by some
+	// value is expected to cost this share (percent) of it, so a trade is worth
+	// the drift it closes times TrackingErrorPct. Trades whose worth does not
+	// exceed their cost by MinNetBenefit are suppressed. Zero ignores costs.
+	TrackingErrorPct float64
+	// MinNetBenefit is the least a trade must be worth over its cost (base currency)
+	MinNetBenefit float64
+	// Tax are the capital gains rules taxing the sells of positions with lots
+	Tax models.TaxRules
+	// TaxAware sells the lots realizing the least tax first, losses before gains
+	// and long-term gains before short-term ones, instead of first in, first out.
+	// Cost aware, the tax of a sell also counts against it.
+	TaxAware bool
+	// AsOf is the sale date telling short from long-term lots (now when zero)
+	AsOf time.Time
+	// DoNotSell are the symbols held as they are (inherited or employer shares):
+	// never traded, their weight is kept and the other targets, cash included,
+	// are scaled to fit around them
+	DoNotSell []string
+	// Sleeves map asset classes to sleeves, ranking them for asset location
+	// (the default sleeves when nil)
+	Sleeves models.SleeveMapping
+}
+
+// cost returns the cost assumption of a symbol
+func (o Options) cost(symbol string) Cost {
+	if c, ok := o.Costs[symbol]; ok {
+		return c
+	}
+	return o.DefaultCost
+}
+
+// Validate checks the options
+func (o Options) Validate() error {
+	if o.MinTradeValue < 0 {
+		return errors.NegativeValueError("min_trade_value", o.MinTradeValue)
+	}
+	if o.MinTradePct < 0 || o.MinTradePct > 100 {
+		return errors.ToleranceOutOfRangeError(o.MinTradePct)
+	}
+	if o.TrackingErrorPct < 0 || o.TrackingErrorPct > 100 {
+		return errors.ToleranceOutOfRangeError(o.TrackingErrorPct)
+	}
+	if o.MinNetBenefit < 0 {
+		return errors.NegativeValueError("min_net_benefit", o.MinNetBenefit)
+	}
+	if slices.Contains(o.DoNotSell, "") {
+		return errors.InvalidInputsError("do-not-sell list has an empty symbol")
+	}
+	costs := maps.Clone(o.Costs)
+	if costs == nil {
+		costs = make(map[string]Cost)
+	}
+	costs["default"] = o.DefaultCost
+	for _, symbol := range slices.Sorted(maps.Keys(costs)) {
+		if c := costs[symbol]; c.SpreadBps < 0 || c.Commission < 0 {
+			return errors.InvalidInputsError(fmt.Sprintf("%s cost must be non-negative", symbol))
+		}
+	}
+	return nil
+}
+
+// Calculate plans the trades moving positions and cash to the target weights
+// (percent of the total value; the rest is held in cash). Positions missing from
+// targets are sold. Trades under the minimum trade size, with round lots under
+// one share or, cost aware, not worth their cost are suppressed; buys are scaled
+// down when the sells and the cash do not fund them. Positions of the do-not-sell
+// list are left alone and the rest is rebalanced around them.
+func Calculate(positions []Position, cash float64, targets map[string]float64, opts Options) (*models.RebalancePlan, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	if len(positions) == 0 {
+		return nil, errors.EmptyUniverseError()
+	}
+	if cash < 0 {
+		return nil, errors.NegativeValueError("cash", cash)
+	}
+
+	total := cash
+	bySymbol := make(map[string]Position, len(positions))
+	for _, p := range positions {
+		if _, ok := bySymbol[p.Symbol]; ok {
+			return nil, errors.InvalidInputsError(fmt.Sprintf("duplicate position %s", p.Symbol))
+		}
+		if p.Quantity < 0 {
+			return nil, errors.NegativeValueError(p.Symbol+" quantity", p.Quantity)
+		}
+		if p.Price <= 0 {
+			return nil, errors.InvalidInputsError(fmt.Sprintf("%s has no price", p.Symbol))
+		}
+		bySymbol[p.Symbol] = p
+		total += p.Value()
+	}
+	if err := checkTargets(targets, bySymbol); err != nil {
+		return nil, err
+	}
+	if total <= 0 {
+		return nil, errors.InvalidInputsError("portfolio has no value")
+	}
+
+	opts.AsOf = cmp.Or(opts.AsOf, time.Now())
+	minTrade := max(opts.MinTradeValue, opts.MinTradePct*total/100)
+	plan := &models.RebalancePlan{TotalValue: total, TaxAware: opts.TaxAware}
+	targets, plan.Locked = lockTargets(bySymbol, total, targets, opts.DoNotSell)
+	plan.Targets = targets
+	locked := make(map[string]bool, len(plan.Locked))
+	for _, l := range plan.Locked {
+		locked[l.Symbol] = true
+	}
+	var buys []models.Trade
+	sold := 0.0
+	gaps := make(map[string]float64, len(bySymbol))
+	for _, symbol := range slices.Sorted(maps.Keys(bySymbol)) {
+		p := bySymbol[symbol]
+		if locked[symbol] {
+			continue
+		}
+		gaps[symbol] = targets[symbol]*total/100 - p.Value()
+		t, ok := sizeTrade(p, gaps[symbol]/p.Price, gaps[symbol], minTrade, math.Round, opts)
+		switch {
+		case t.Quantity == 0:
+			continue
+		case !ok:
+			plan.Suppressed = append(plan.Suppressed, t)
+		case t.Side == models.TradeSell:
+			plan.Trades = append(plan.Trades, t)
+			sold += t.Value
+		default:
+			buys = append(buys, t)
+		}
+	}
+
+	// buys spend the cash and the sale proceeds, no more
+	bought := 0.0
+	for _, t := range buys {
+		bought += t.Value
+	}
+	if available := cash + sold; bought > available+1e-9 {
+		scale := available / bought
+		scaled := buys[:0]
+		for _, t := range buys {
+			// rounding down keeps the scaled round lots within the funds
+			s, ok := sizeTrade(bySymbol[t.Symbol], t.Quantity*scale, gaps[t.Symbol], minTrade, math.Floor, opts)
+			switch {
+			case s.Quantity == 0:
+			case !ok:
+				plan.Suppressed = append(plan.Suppressed, s)
+			default:
+				scaled = append(scaled, s)
+			}
+		}
+		buys = scaled
+	}
+	plan.Trades = append(plan.Trades, buys...)
+	slices.SortStableFunc(plan.Trades, func(a, b models.Trade) int {
+		// sells first, they fund the buys
+		return cmp.Or(cmp.Compare(sideOrder(a.Side), sideOrder(b.Side)), cmp.Compare(b.Value, a.Value))
+	})
+
+	plan.CashAfter = cash
+	after := make(map[string]float64, len(bySymbol))
+	for symbol, p := range bySymbol {
+		after[symbol] = p.Value()
+	}
+	for _, t := range plan.Trades {
+		if t.Side == models.TradeSell {
+			after[t.Symbol] -= t.Value
+			plan.CashAfter += t.Value
+		} else {
+			after[t.Symbol] += t.Value
+			plan.CashAfter -= t.Value
+		}
+		plan.Cost += t.Cost
+		plan.Benefit += t.Benefit
+		plan.RealizedGain += t.RealizedGain
+		plan.Tax += t.Tax
+	}
+	plan.Weights = make(map[string]float64, len(after))
+	for symbol, v := range after {
+		plan.Weights[symbol] = v / total * 100
+	}
+	return plan, nil
+}
+
+// checkTargets checks the targets are positive weights of known positions
+// summing to 100 at most
+func checkTargets(targets map[string]float64, bySymbol map[string]Position) error {
+	sum := 0.0
+	for symbol, pct := range targets {
+		if pct < 0 {
+			return errors.NegativeValueError(symbol+" target", pct)
+		}
+		if _, ok := bySymbol[symbol]; !ok {
+			return errors.InvalidInputsError(fmt.Sprintf("no priced position for target %s", symbol))
+		}
+		sum += pct
+	}
+	if sum > 100+1e-6 {
+		return errors.InvalidInputsError(fmt.Sprintf("targets sum to %.2f%%", sum))
+	}
+	return nil
+}
+
+// lockTargets holds the do-not-sell positions at their current weight and scales
+// the other targets, and the cash left over, to the rest of the portfolio
+func lockTargets(bySymbol map[string]Position, total float64, targets map[string]float64, doNotSell []string) (map[string]float64, []models.LockedPosition) {
+	out := maps.Clone(targets)
+	if out == nil {
+		out = make(map[string]float64)
+	}
+	var locked []models.LockedPosition
+	seen := make(map[string]bool, len(doNotSell))
+	lockedPct, lockedTarget := 0.0, 0.0
+	for _, symbol := range slices.Sorted(slices.Values(doNotSell)) {
+		p, ok := bySymbol[symbol]
+		if !ok || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		l := models.LockedPosition{Symbol: symbol, WeightPct: p.Value() / total * 100, TargetPct: targets[symbol]}
+		locked = append(locked, l)
+		lockedPct += l.WeightPct
+		lockedTarget += l.TargetPct
+		out[symbol] = l.WeightPct
+	}
+	if len(locked) == 0 {
+		return out, nil
+	}
+
+	scale := 0.0
+	if free := 100 - lockedTarget; free > 1e-9 {
+		scale = max(0, 100-lockedPct) / free
+	}
+	for symbol, pct := range targets {
+		if !seen[symbol] {
+			out[symbol] = pct * scale
+		}
+	}
+	return out, locked
+}
+
+// sizeTrade turns a signed quantity into a trade toward a position's gap to
+// target (value), rounded to whole shares with round when needed; ok is false
+// when the trade is too small or too costly to place
+func sizeTrade(p Position, quantity, gap, minTrade float64, round func(float64) float64, opts Options) (models.Trade, bool) {
+	t := models.Trade{Symbol: p.Symbol, Side: models.TradeBuy, Price: p.Price}
+	if quantity < 0 {
+		t.Side = models.TradeSell
+		// never sell more than held
+		quantity = min(-quantity, p.Quantity)
+	}
+	t.Quantity = quantity
+	t.Value = quantity * p.Price
+	if t.Value < 1e-6 {
+		// already on target, up to rounding errors
+		t.Quantity, t.Value = 0, 0
+		return t, false
+	}
+	if t.Value < minTrade {
+		t.SuppressedReason = models.SuppressedMinTrade
+		return t, false
+	}
+	if opts.RoundLots && p.WholeShares {
+		whole := round(quantity)
+		if t.Side == models.TradeSell {
+			whole = min(whole, math.Floor(p.Quantity))
+		}
+		if whole < 1 {
+			t.SuppressedReason = models.SuppressedRoundLot
+			return t, false
+		}
+		t.Quantity, t.Value = whole, whole*p.Price
+		if t.Value < minTrade {
+			t.SuppressedReason = models.SuppressedMinTrade
+			return t, false
+		}
+	}
+
+	t.Cost = opts.cost(p.Symbol).Of(t.Value)
+	if t.Side == models.TradeSell && len(p.Lots) > 0 {
+		t.Lots, t.Warnings = sellLots(p, t.Quantity, opts)
+		for _, l := range t.Lots {
+			t.RealizedGain += l.Gain
+			t.Tax += l.Tax
+		}
+	}
+	if opts.TrackingErrorPct > 0 {
+		signed := t.Value
+		if t.Side == models.TradeSell {
+			signed = -signed
+		}
+		// rounding may overshoot the target, only the drift closed counts
+		closed := math.Abs(gap) - math.Abs(gap-signed)
+		t.Benefit = closed * opts.TrackingErrorPct / 100
+		net := t.Benefit - t.Cost
+		if opts.TaxAware {
+			net -= t.Tax
+		}
+		if net < opts.MinNetBenefit {
+			t.SuppressedReason = models.SuppressedNetBenefit
+			return t, false
+		}
+	}
+	return t, true
+}
+
+// sellLots takes quantity from the lots of a position: first in, first out, or
+// the least taxed first when tax aware. Lots held in a tax-advantaged wrapper
+// are sold tax free. Taxable lots sold at a gain inside a holding
+// period, or at a loss while other units bought within the wash-sale window stay
+// held, are flagged.
+func sellLots(p Position, quantity float64, opts Options) ([]models.LotSale, []models.TaxWarning) {
+	lots := slices.Clone(p.Lots)
+	held := 0.0
+	for _, l := range lots {
+		held += l.Quantity
+	}
+	if held < p.Quantity {
+		// units without a lot have an unknown basis, taken as no gain
+		lots = append(lots, models.TaxLot{Quantity: p.Quantity - held, UnitCost: p.Price})
+	}
+
+	// replacement returns the latest purchase, within the wash-sale window around
+	// the sale, of the units left in the other lots: a lot is no replacement of
+	// itself, nor are the units sold along with it
+	window := time.Duration(opts.Tax.WashSaleDays) * 24 * time.Hour
+	replacement := func(sold int, left []float64) (time.Time, bool) {
+		var bought time.Time
+		if opts.Tax.WashSaleDays <= 0 {
+			return bought, false
+		}
+		for i, l := range lots {
+			if i == sold || left[i] <= 1e-12 || l.Acquired.IsZero() {
+				continue
+			}
+			if d := opts.AsOf.Sub(l.Acquired); d <= window && d >= -window && l.Acquired.After(bought) {
+				bought = l.Acquired
+			}
+		}
+		return bought, !bought.IsZero()
+	}
+	// lots held in a tax-advantaged wrapper realize no taxed gain or loss
+	sheltered := func(i int) bool {
+		return opts.Tax.Wrapper(lots[i].Wrapper).Treatment.Advantaged()
+	}
+	taxOf := func(i int, gain float64, left []float64) float64 {
+		if sheltered(i) {
+			return 0
+		}
+		if _, washed := replacement(i, left); gain < 0 && washed {
+			return 0
+		}
+		return gain * opts.Tax.Rate(lots[i].Acquired, opts.AsOf)
+	}
+
+	all := make([]float64, len(lots))
+	order := make([]int, len(lots))
+	for i, l := range lots {
+		all[i] = l.Quantity
+		order[i] = i
+	}
+	if opts.TaxAware {
+		taxPerUnit := func(i int) float64 {
+			return taxOf(i, p.Price-lots[i].UnitCost, all)
+		}
+		slices.SortStableFunc(order, func(a, b int) int {
+			// same tax: the smallest gain first
+			return cmp.Or(cmp.Compare(taxPerUnit(a), taxPerUnit(b)), cmp.Compare(lots[b].UnitCost, lots[a].UnitCost))
+		})
+	} else {
+		// undated lots last
+		slices.SortStableFunc(order, func(a, b int) int {
+			x, y := lots[a].Acquired, lots[b].Acquired
+			if x.IsZero() != y.IsZero() {
+				if x.IsZero() {
+					return 1
+				}
+				return -1
+			}
+			return x.Compare(y)
+		})
+	}
+
+	// take the units first: whether a loss is washed depends on the lots left
+	left := slices.Clone(all)
+	taken := make([]float64, len(lots))
+	for _, i := range order {
+		if quantity <= 1e-12 {
+			break
+		}
+		q := min(quantity, lots[i].Quantity)
+		if q <= 0 {
+			continue
+		}
+		taken[i] = q
+		left[i] -= q
+		quantity -= q
+	}
+
+	var sales []models.LotSale
+	var warnings []models.TaxWarning
+	for _, i := range order {
+		l, q := lots[i], taken[i]
+		if q <= 0 {
+			continue
+		}
+		gain := (p.Price - l.UnitCost) * q
+		sales = append(sales, models.LotSale{
+			Acquired: l.Acquired,
+			Quantity: q,
+			UnitCost: l.UnitCost,
+			Gain:     gain,
+			LongTerm: opts.Tax.IsLongTerm(l.Acquired, opts.AsOf),
+			Tax:      taxOf(i, gain, left),
+		})
+		if sheltered(i) {
+			// a sale inside a tax wrapper withdraws nothing from it
+			continue
+		}
+		warnings = append(warnings, opts.Tax.HoldingWarnings(p.Symbol, l, q, gain, opts.AsOf)...)
+		if bought, washed := replacement(i, left); gain < 0 && washed {
+			days := max(1, opts.Tax.WashSaleDays-int(opts.AsOf.Sub(bought).Hours()/24))
+			warnings = append(warnings, models.TaxWarning{
+				Symbol:   p.Symbol,
+				Period:   models.WashSalePeriod,
+				Acquired: l.Acquired,
+				Quantity: q,
+				Gain:     gain,
+				DaysLeft: days,
+				Message:  fmt.Sprintf("loss disallowed: %s was bought within %d days (%d days left)", p.Symbol, opts.Tax.WashSaleDays, days),
+			})
+		}
+	}
+	return sales, warnings
+}
+
+func sideOrder(s models.TradeSide) int {
+	if s == models.TradeSell {
+		return 0
+	}
+	return 1
+}
@@ -0,0 +1,500 @@
+package rebalance
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/errors"
+	"github.com/amaurybrisou/mosychlos/pkg/models"
+)
+
+func TestCalculate_MinTradeSize(t *testing.T) {
+	t.Parallel()
+
+	// 10,040 invested: VOO and BND drift by about 2,000, AGG, GLD and BTC by less than 40
+	positions := []Position{
+		{Symbol: "VOO", Quantity: 12, Price: 500, WholeShares: true}, // 6,000
+		{Symbol: "BND", Quantity: 25, Price: 80, WholeShares: true},  // 2,000
+		{Symbol: "AGG", Quantity: 10, Price: 104, WholeShares: true}, // 1,040
+		{Symbol: "GLD", Quantity: 5, Price: 194, WholeShares: true},  // 970
+		{Symbol: "BTC", Quantity: 0.0005, Price: 60000},              // 30
+	}
+	targets := map[string]float64{"VOO": 40, "BND": 40, "AGG": 10, "GLD": 10}
+
+	cases := []struct {
+		name           string
+		opts           Options
+		wantTrades     []string
+		wantSuppressed []string
+		maxDrift       float64
+	}{
+		{
+			name:       "no minimum trades every drift",
+			opts:       Options{},
+			wantTrades: []string{"VOO", "AGG", "BTC", "BND", "GLD"},
+			maxDrift:   1e-9,
+		},
+		{
+			name:           "absolute minimum",
+			opts:           Options{MinTradeValue: 100},
+			wantTrades:     []string{"VOO", "BND"},
+			wantSuppressed: []string{"AGG", "BTC", "GLD"},
+			maxDrift:       0.5,
+		},
+		{
+			name:           "minimum in percent of the portfolio",
+			opts:           Options{MinTradePct: 1},
+			wantTrades:     []string{"VOO", "BND"},
+			wantSuppressed: []string{"AGG", "BTC", "GLD"},
+			maxDrift:       0.5,
+		},
+		{
+			name:           "round lots",
+			opts:           Options{RoundLots: true},
+			wantTrades:     []string{"VOO", "BTC", "BND"},
+			wantSuppressed: []string{"AGG", "GLD"},
+			maxDrift:       0.5,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			plan, err := Calculate(positions, 0, targets, c.opts)
+			require.NoError(t, err)
+
+			assert.Equal(t, c.wantTrades, symbols(plan.Trades))
+			assert.ElementsMatch(t, c.wantSuppressed, symbols(plan.Suppressed))
+			assert.LessOrEqual(t, plan.MaxDrift(), c.maxDrift)
+			assert.GreaterOrEqual(t, plan.CashAfter, -1e-9, "buys are funded")
+			for _, tr := range plan.Suppressed {
+				assert.NotEmpty(t, tr.SuppressedReason)
+			}
+			if c.opts.RoundLots {
+				for _, tr := range plan.Trades {
+					if tr.Symbol != "BTC" {
+						assert.Equal(t, float64(int(tr.Quantity)), tr.Quantity, tr.Symbol)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestCalculate_CostAware(t *testing.T) {
+	t.Parallel()
+
+	// the same drift as TestCalculate_MinTradeSize: two large gaps, three small ones
+	positions := []Position{
+		{Symbol: "VOO", Quantity: 12, Price: 500},
+		{Symbol: "BND", Quantity: 25, Price: 80},
+		{Symbol: "AGG", Quantity: 10, Price: 104},
+		{Symbol: "GLD", Quantity: 5, Price: 194},
+		{Symbol: "BTC", Quantity: 0.0005, Price: 60000},
+	}
+	targets := map[string]float64{"VOO": 40, "BND": 40, "AGG": 10, "GLD": 10}
+	costs := Options{
+		DefaultCost: Cost{SpreadBps: 5, Commission: 2},
+		Costs:       map[string]Cost{"BTC": {SpreadBps: 50, Commission: 2}},
+	}
+
+	naive, err := Calculate(positions, 0, targets, costs)
+	require.NoError(t, err)
+	aware := costs
+	aware.TrackingErrorPct = 2
+	costAware, err := Calculate(positions, 0, targets, aware)
+	require.NoError(t, err)
+
+	// the naive plan pays a commission on every small trade
+	assert.Len(t, naive.Trades, 5)
+	assert.InDelta(t, (1984+2016+36+34)*5/10000.0+30*50/10000.0+5*2, naive.Cost, 1e-6)
+	assert.Zero(t, naive.Benefit)
+
+	// the cost-aware plan only places the trades worth their cost, for about the
+	// same drift reduction
+	assert.Equal(t, []string{"VOO", "BND"}, symbols(costAware.Trades))
+	assert.ElementsMatch(t, []string{"AGG", "GLD", "BTC"}, symbols(costAware.Suppressed))
+	for _, tr := range costAware.Suppressed {
+		assert.Equal(t, models.SuppressedNetBenefit, tr.SuppressedReason)
+		assert.Less(t, tr.Benefit, tr.Cost)
+	}
+	assert.Less(t, costAware.Cost, naive.Cost)
+	naiveBenefit := naive.Turnover() * aware.TrackingErrorPct / 100
+	assert.Greater(t, costAware.Benefit-costAware.Cost, naiveBenefit-naive.Cost)
+	assert.LessOrEqual(t, costAware.MaxDrift(), 0.5)
+	assert.Greater(t, costAware.Turnover(), 0.95*naive.Turnover())
+
+	// a higher bar skips the large trades too
+	aware.MinNetBenefit = 100
+	none, err := Calculate(positions, 0, targets, aware)
+	require.NoError(t, err)
+	assert.Empty(t, none.Trades)
+}
+
+func TestCalculate_TaxAware(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	us := models.TaxRules{Country: "US", ShortTermRate: 0.37, LongTermRate: 0.2, LongTermAfterDays: 365}
+	// VOO at 100: an old lot far in gain, a recent one in gain, a recent one at a loss
+	positions := []Position{
+		{Symbol: "VOO", Quantity: 30, Price: 100, WholeShares: true, Lots: []models.TaxLot{
+			{Quantity: 10, UnitCost: 40, Acquired: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)},
+			{Quantity: 10, UnitCost: 90, Acquired: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)},
+			{Quantity: 10, UnitCost: 120, Acquired: time.Date(2026, 2, 2, 0, 0, 0, 0, time.UTC)},
+		}},
+		{Symbol: "BND", Quantity: 0, Price: 80, WholeShares: true},
+	}
+	// selling 15 VOO
+	targets := map[string]float64{"VOO": 50, "BND": 50}
+
+	naive, err := Calculate(positions, 0, targets, Options{Tax: us, AsOf: asOf})
+	require.NoError(t, err)
+	aware, err := Calculate(positions, 0, targets, Options{Tax: us, AsOf: asOf, TaxAware: true})
+	require.NoError(t, err)
+
+	// both plans hit the same target
+	assert.Equal(t, symbols(naive.Trades), symbols(aware.Trades))
+	assert.InDelta(t, naive.MaxDrift(), aware.MaxDrift(), 1e-9)
+
+	// first in, first out sells the 2019 lot: 10 x 60 long term, then 5 x -20 short term
+	require.Equal(t, "VOO", naive.Trades[0].Symbol)
+	assert.InDelta(t, 600-100, naive.RealizedGain, 1e-9)
+	assert.InDelta(t, 600*0.2-100*0.37, naive.Tax, 1e-9)
+	assert.False(t, naive.TaxAware)
+
+	// tax aware harvests the loss first, then the cheapest gain: 10 x -20 and 5 x 10 short term
+	lots := aware.Trades[0].Lots
+	require.Len(t, lots, 2)
+	assert.InDelta(t, 120, lots[0].UnitCost, 1e-9)
+	assert.InDelta(t, 90, lots[1].UnitCost, 1e-9)
+	assert.False(t, lots[1].LongTerm)
+	assert.InDelta(t, -200+50, aware.RealizedGain, 1e-9)
+	assert.InDelta(t, (-200+50)*0.37, aware.Tax, 1e-9)
+	assert.True(t, aware.TaxAware)
+
+	assert.Less(t, aware.RealizedGain, naive.RealizedGain)
+	assert.Less(t, aware.Tax, naive.Tax)
+}
+
+func TestCalculate_HoldingPeriodWarnings(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	us := models.TaxRules{
+		Country: "US", ShortTermRate: 0.37, LongTermRate: 0.2, LongTermAfterDays: 365,
+		HoldingPeriods: []models.HoldingPeriod{{Name: "short_term", Days: 365, Warning: "taxed as ordinary income"}},
+		WashSaleDays:   30,
+	}
+	targets := map[string]float64{"VOO": 50, "BND": 50}
+
+	t.Run("sell inside the short-term window", func(t *testing.T) {
+		t.Parallel()
+
+		// first in, first out sells the 2019 lot, then 5 of the lot bought 165 days ago
+		plan, err := Calculate([]Position{
+			{Symbol: "VOO", Quantity: 20, Price: 100, WholeShares: true, Lots: []models.TaxLot{
+				{Quantity: 10, UnitCost: 40, Acquired: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)},
+				{Quantity: 10, UnitCost: 90, Acquired: time.Date(2026, 5, 4, 0, 0, 0, 0, time.UTC)},
+			}},
+			{Symbol: "BND", Quantity: 0, Price: 80, WholeShares: true},
+		}, 0, map[string]float64{"VOO": 25, "BND": 75}, Options{Tax: us, AsOf: asOf})
+		require.NoError(t, err)
+
+		warnings := plan.TaxWarnings()
+		require.Len(t, warnings, 1)
+		assert.Equal(t, "VOO", warnings[0].Symbol)
+		assert.Equal(t, "short_term", warnings[0].Period)
+		assert.InDelta(t, 5, warnings[0].Quantity, 1e-9)
+		assert.InDelta(t, 50, warnings[0].Gain, 1e-9)
+		assert.Equal(t, 200, warnings[0].DaysLeft)
+		assert.Contains(t, warnings[0].Message, "taxed as ordinary income")
+	})
+
+	t.Run("long-term sell", func(t *testing.T) {
+		t.Parallel()
+
+		plan, err := Calculate([]Position{
+			{Symbol: "VOO", Quantity: 20, Price: 100, WholeShares: true, Lots: []models.TaxLot{
+				{Quantity: 20, UnitCost: 40, Acquired: time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC)},
+			}},
+			{Symbol: "BND", Quantity: 0, Price: 80, WholeShares: true},
+		}, 0, targets, Options{Tax: us, AsOf: asOf})
+		require.NoError(t, err)
+		assert.Empty(t, plan.TaxWarnings())
+	})
+
+	t.Run("loss inside the wash-sale window", func(t *testing.T) {
+		t.Parallel()
+
+		// VOO was bought 10 days ago: harvesting the loss of the older lot saves nothing
+		plan, err := Calculate([]Position{
+			{Symbol: "VOO", Quantity: 20, Price: 100, WholeShares: true, Lots: []models.TaxLot{
+				{Quantity: 10, UnitCost: 120, Acquired: time.Date(2023, 2, 2, 0, 0, 0, 0, time.UTC)},
+				{Quantity: 10, UnitCost: 95, Acquired: time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)},
+			}},
+			{Symbol: "BND", Quantity: 0, Price: 80, WholeShares: true},
+		}, 0, targets, Options{Tax: us, AsOf: asOf, TaxAware: true})
+		require.NoError(t, err)
+
+		warnings := plan.TaxWarnings()
+		require.Len(t, warnings, 1)
+		assert.Equal(t, models.WashSalePeriod, warnings[0].Period)
+		assert.InDelta(t, -200, warnings[0].Gain, 1e-9)
+		assert.Equal(t, 20, warnings[0].DaysLeft)
+		assert.InDelta(t, -200, plan.RealizedGain, 1e-9)
+		assert.InDelta(t, 0, plan.Tax, 1e-9)
+	})
+
+	t.Run("loss on the only recent lot", func(t *testing.T) {
+		t.Parallel()
+
+		// the lot bought 10 days ago is the one sold: no other purchase replaces it
+		plan, err := Calculate([]Position{
+			{Symbol: "VOO", Quantity: 20, Price: 100, WholeShares: true, Lots: []models.TaxLot{
+				{Quantity: 20, UnitCost: 120, Acquired: time.Date(2026, 10, 6, 0, 0, 0, 0, time.UTC)},
+			}},
+			{Symbol: "BND", Quantity: 0, Price: 80, WholeShares: true},
+		}, 0, targets, Options{Tax: us, AsOf: asOf, TaxAware: true})
+		require.NoError(t, err)
+
+		assert.Empty(t, plan.TaxWarnings())
+		assert.InDelta(t, -200, plan.RealizedGain, 1e-9)
+		assert.InDelta(t, -200*0.37, plan.Tax, 1e-9)
+	})
+}
+
+func TestCalculate_DoNotSell(t *testing.T) {
+	t.Parallel()
+
+	// AAPL is inherited: 60% of the portfolio against a 20% target
+	positions := []Position{
+		{Symbol: "AAPL", Quantity: 60, Price: 100, WholeShares: true},
+		{Symbol: "VOO", Quantity: 20, Price: 100, WholeShares: true},
+		{Symbol: "BND", Quantity: 0, Price: 50, WholeShares: true},
+	}
+	targets := map[string]float64{"AAPL": 20, "VOO": 50, "BND": 30}
+
+	free, err := Calculate(positions, 2000, targets, Options{})
+	require.NoError(t, err)
+	assert.Contains(t, symbols(free.Trades), "AAPL")
+	assert.Empty(t, free.Locked)
+
+	plan, err := Calculate(positions, 2000, targets, Options{DoNotSell: []string{"AAPL", "TSLA"}})
+	require.NoError(t, err)
+
+	// AAPL is untouched and keeps its weight
+	assert.NotContains(t, symbols(plan.Trades), "AAPL")
+	assert.NotContains(t, symbols(plan.Suppressed), "AAPL")
+	assert.InDelta(t, 60, plan.Weights["AAPL"], 1e-9)
+	require.Len(t, plan.Locked, 1)
+	assert.Equal(t, models.LockedPosition{Symbol: "AAPL", WeightPct: 60, TargetPct: 20}, plan.Locked[0])
+	assert.InDelta(t, 60, plan.LockedWeight(), 1e-9)
+	assert.InDelta(t, 20, plan.LockedTarget(), 1e-9)
+
+	// VOO and BND share the remaining 40% in their 50:30 proportion
+	assert.Equal(t, []string{"BND", "VOO"}, symbols(plan.Trades))
+	assert.InDelta(t, 25, plan.Targets["VOO"], 1e-9)
+	assert.InDelta(t, 15, plan.Targets["BND"], 1e-9)
+	assert.InDelta(t, 25, plan.Weights["VOO"], 1e-9)
+	assert.InDelta(t, 15, plan.Weights["BND"], 1e-9)
+	assert.InDelta(t, 0, plan.CashAfter, 1e-9)
+	assert.InDelta(t, 0, plan.MaxDrift(), 1e-9)
+}
+
+func TestCalculate_ScalesUnfundedBuys(t *testing.T) {
+	t.Parallel()
+
+	// the VOO sale is too small to place, so BND can only be bought with the cash
+	plan, err := Calculate([]Position{
+		{Symbol: "VOO", Quantity: 10, Price: 100},
+		{Symbol: "BND", Quantity: 0, Price: 50},
+	}, 100, map[string]float64{"VOO": 70, "BND": 30}, Options{MinTradeValue: 400})
+	require.NoError(t, err)
+
+	require.Len(t, plan.Trades, 0)
+	assert.ElementsMatch(t, []string{"VOO", "BND"}, symbols(plan.Suppressed))
+
+	plan, err = Calculate([]Position{
+		{Symbol: "VOO", Quantity: 10, Price: 100},
+		{Symbol: "BND", Quantity: 0, Price: 50},
+	}, 100, map[string]float64{"VOO": 70, "BND": 30}, Options{MinTradeValue: 50})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"VOO", "BND"}, symbols(plan.Trades))
+	assert.InDelta(t, 0, plan.CashAfter, 1e-9)
+}
+
+func TestCalculate_ScaledRoundLotsStayFunded(t *testing.T) {
+	t.Parallel()
+
+	// both buys round up to one share, 200 for 150 of cash; scaled down they
+	// must not round back up
+	plan, err := Calculate([]Position{
+		{Symbol: "A", Quantity: 10, Price: 100, WholeShares: true},
+		{Symbol: "B", Quantity: 0.0001, Price: 100, WholeShares: true},
+		{Symbol: "C", Quantity: 0.0001, Price: 100, WholeShares: true},
+	}, 150, map[string]float64{"A": 86.2, "B": 6.9, "C": 6.9}, Options{RoundLots: true})
+	require.NoError(t, err)
+
+	assert.GreaterOrEqual(t, plan.CashAfter, 0.0)
+	spent := 0.0
+	for _, tr := range plan.Trades {
+		assert.Equal(t, math.Trunc(tr.Quantity), tr.Quantity, "%s trades whole shares", tr.Symbol)
+		if tr.Side == models.TradeBuy {
+			spent += tr.Value
+		}
+	}
+	assert.LessOrEqual(t, spent, 150.0)
+}
+
+func TestCalculate_Errors(t *testing.T) {
+	t.Parallel()
+
+	voo := Position{Symbol: "VOO", Quantity: 1, Price: 500}
+	cases := []struct {
+		name      string
+		positions []Position
+		cash      float64
+		targets   map[string]float64
+		opts      Options
+		want      error
+	}{
+		{name: "no positions", want: errors.ErrEmptyUniverse},
+		{name: "negative cash", positions: []Position{voo}, cash: -1, want: errors.ErrNegativeValue},
+		{name: "unpriced position", positions: []Position{{Symbol: "VOO", Quantity: 1}}, want: errors.ErrInvalidInputs},
+		{name: "target without position", positions: []Position{voo}, targets: map[string]float64{"BND": 10}, want: errors.ErrInvalidInputs},
+		{name: "targets over 100", positions: []Position{voo}, targets: map[string]float64{"VOO": 120}, want: errors.ErrInvalidInputs},
+		{name: "minimum percent out of range", positions: []Position{voo}, opts: Options{MinTradePct: 150}, want: errors.ErrToleranceOutOfRange},
+		{name: "empty do-not-sell symbol", positions: []Position{voo}, opts: Options{DoNotSell: []string{""}}, want: errors.ErrInvalidInputs},
+		{name: "negative cost", positions: []Position{voo}, opts: Options{Costs: map[string]Cost{"VOO": {Commission: -1}}}, want: errors.ErrInvalidInputs},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := Calculate(c.positions, c.cash, c.targets, c.opts)
+			assert.ErrorIs(t, err, c.want)
+		})
+	}
+}
+
+func TestSleeveTargets(t *testing.T) {
+	t.Parallel()
+
+	n := &models.NormalizedPortfolio{Holdings: []models.NormalizedHolding{
+		{Symbol: "VOO", AssetClass: "etf", Quantity: 10, Value: 4000, WeightPercent: 40},
+		{Symbol: "AAPL", AssetClass: "stock", Quantity: 20, Value: 2000, WeightPercent: 20},
+		{Symbol: "BTC", AssetClass: "crypto", Quantity: 0.05, Value: 3000, WeightPercent: 30},
+		{Symbol: "USD", AssetClass: "cash", Quantity: 1000, Value: 1000, WeightPercent: 10},
+	}}
+
+	targets, unreachable := SleeveTargets(n, models.SleeveAllocation{
+		models.SleeveEquity: 60, models.SleeveFixedIncome: 25, models.SleeveAlternatives: 5, models.SleeveCash: 10,
+	}, nil)
+	assert.InDeltaMapValues(t, map[string]float64{"VOO": 40, "AAPL": 20, "BTC": 5}, targets, 1e-9)
+	assert.Equal(t, []string{models.SleeveFixedIncome}, unreachable)
+
+	positions, cash := Positions(n, nil)
+	assert.InDelta(t, 1000, cash, 1e-9)
+	assert.Equal(t, []Position{
+		{Symbol: "AAPL", Quantity: 20, Price: 100, WholeShares: true, Class: "stock"},
+		{Symbol: "BTC", Quantity: 0.05, Price: 60000, Class: "crypto"},
+		{Symbol: "VOO", Quantity: 10, Price: 400, WholeShares: true, Class: "etf"},
+	}, positions)
+}
+
+func TestPositions_MarketPrice(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	us := models.TaxRules{Country: "US", ShortTermRate: 0.37, LongTermRate: 0.2, LongTermAfterDays: 365}
+	// valued at cost: 10 VOO bought at 300, now quoted 400
+	n := &models.NormalizedPortfolio{Holdings: []models.NormalizedHolding{
+		{Symbol: "VOO", AssetClass: "etf", Quantity: 10, Value: 3000, Lots: []models.TaxLot{
+			{Quantity: 10, UnitCost: 300, Acquired: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		}},
+		{Symbol: "BND", AssetClass: "bond", Quantity: 10, Value: 800},
+	}}
+
+	cases := []struct {
+		name      string
+		prices    map[string]float64
+		wantPrice float64
+		wantGain  float64
+	}{
+		{name: "average cost without quote", wantPrice: 300},
+		{name: "market price", prices: map[string]float64{"VOO": 400, "BND": 80}, wantPrice: 400, wantGain: 5 * 100},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			positions, _ := Positions(n, c.prices)
+			require.Len(t, positions, 2)
+			assert.InDelta(t, c.wantPrice, positions[1].Price, 1e-9)
+
+			// halve VOO: the sold lot gains the quote over its cost
+			weight := positions[1].Value() / (positions[0].Value() + positions[1].Value()) * 100
+			plan, err := Calculate(positions, 0, map[string]float64{"VOO": weight / 2, "BND": 100 - weight/2}, Options{Tax: us, AsOf: asOf})
+			require.NoError(t, err)
+			require.Equal(t, models.TradeSell, plan.Trades[0].Side)
+			assert.InDelta(t, 5, plan.Trades[0].Quantity, 1e-9)
+			assert.InDelta(t, c.wantGain, plan.RealizedGain, 1e-9)
+			assert.InDelta(t, c.wantGain*0.2, plan.Tax, 1e-9)
+		})
+	}
+}
+
+func TestPositions_MixedWrapperLots(t *testing.T) {
+	t.Parallel()
+
+	asOf := time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)
+	us := models.TaxRules{
+		Country: "US", ShortTermRate: 0.37, LongTermRate: 0.2, LongTermAfterDays: 365,
+		Wrappers: []models.TaxWrapper{{Name: "ira", Treatment: models.TaxDeferred}},
+	}
+	// without asset location the IRA and brokerage VOO merge into one position
+	n := &models.NormalizedPortfolio{Holdings: []models.NormalizedHolding{
+		{Symbol: "VOO", AssetClass: "etf", Quantity: 10, Value: 3000, Account: "Brokerage", Lots: []models.TaxLot{
+			{Quantity: 10, UnitCost: 300, Acquired: time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		}},
+		{Symbol: "VOO", AssetClass: "etf", Quantity: 10, Value: 1000, Account: "IRA", Wrapper: "ira", Lots: []models.TaxLot{
+			{Quantity: 10, UnitCost: 100, Acquired: time.Date(2015, 1, 2, 0, 0, 0, 0, time.UTC)},
+		}},
+	}}
+	positions, _ := Positions(n, map[string]float64{"VOO": 400})
+	require.Len(t, positions, 1)
+	positions = append(positions, Position{Symbol: "BND", Price: 80, WholeShares: true})
+
+	cases := []struct {
+		name     string
+		taxAware bool
+	}{
+		{name: "first in first out"},
+		{name: "tax aware", taxAware: true},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			t.Parallel()
+
+			// sell 15: the 10 IRA units gain 3000 untaxed, 5 brokerage units gain 500
+			plan, err := Calculate(positions, 0, map[string]float64{"VOO": 25, "BND": 75}, Options{Tax: us, AsOf: asOf, TaxAware: c.taxAware})
+			require.NoError(t, err)
+			require.Equal(t, models.TradeSell, plan.Trades[0].Side)
+			require.Len(t, plan.Trades[0].Lots, 2)
+			assert.InDelta(t, 0, plan.Trades[0].Lots[0].Tax, 1e-9)
+			assert.InDelta(t, 3000+500, plan.RealizedGain, 1e-9)
+			assert.InDelta(t, 500*0.2, plan.Tax, 1e-9)
+		})
+	}
+}
+
+func symbols(trades []models.Trade) []string {
+	out := make([]string, 0, len(trades))
+	for _, t := range trades {
+		out = append(out, t.Symbol)
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+// Package sandbox guarantees that no outbound HTTP request leaves the process.
+// Every external client wraps its transport with Guard; once Enable is called,
+// guarded transports answer known API hosts from canned stubs and reject
+// everything else with ErrNetworkDisabled.
+package sandbox
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrNetworkDisabled is returned for any request made while sandbox mode is on
+// and no stub serves the target host
+var ErrNetworkDisabled = errors.New("sandbox mode: outbound network access is disabled")
+
+// StubFunc answers a request without touching the network
+type StubFunc func(req *http.Request) (*http.Response, error)
+
+var (
+	enabled atomic.Bool
+
+	mu    sync.RWMutex
+	stubs = map[string]StubFunc{}
+)
+
+// Enable turns sandbox mode on for every guarded transport and installs the
+// canned stubs for the LLM and news APIs
+func Enable() {
+	for host, stub := range defaultStubs() {
+		Register(host, stub)
+	}
+	enabled.Store(true)
+	slog.Info("Sandbox mode enabled: outbound network calls are blocked")
+}
+
+// Disable turns sandbox mode off and drops all registered stubs
+func Disable() {
+	enabled.Store(false)
+	mu.Lock()
+	defer mu.Unlock()
+	stubs = map[string]StubFunc{}
+}
+
+// Enabled reports whether sandbox mode is on
+func Enabled() bool {
+	return enabled.Load()
+}
+
+// Register serves requests to host (without port) from stub while sandbox mode is on
+func Register(host string, stub StubFunc) {
+	mu.Lock()
+	defer mu.Unlock()
+	stubs[strings.ToLower(host)] = stub
+}
+
+// Guard wraps base so requests are checked against sandbox mode at send time.
+// A nil base uses http.DefaultTransport.
+func Guard(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &guardedTransport{base: base}
+}
+
+// guardedTransport forwards to base unless sandbox mode is on
+type guardedTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *guardedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !Enabled() {
+		return t.base.RoundTrip(req)
+	}
+	if req.Body != nil {
+		defer req.Body.Close()
+	}
+
+	mu.RLock()
+	stub, ok := stubs[strings.ToLower(req.URL.Hostname())]
+	mu.RUnlock()
+	if !ok {
+		slog.Warn("Sandbox mode blocked outbound request",
+			"method", req.Method,
+			"host", req.URL.Host,
+			"path", req.URL.Path,
+		)
+		return nil, fmt.Errorf("%w: %s %s://%s%s", ErrNetworkDisabled, req.Method, req.URL.Scheme, req.URL.Host, req.URL.Path)
+	}
+
+	resp, err := stub(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Request == nil {
+		resp.Request = req
+	}
+	return resp, nil
+}
@@ -0,0 +1,145 @@
+package sandbox_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fred"
+	"github.com/amaurybrisou/mosychlos/pkg/newsapi"
+	pkgopenai "github.com/amaurybrisou/mosychlos/pkg/openai"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
+)
+
+func TestSandbox_NoRealHTTP(t *testing.T) {
+	var hits atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	sandbox.Enable()
+	t.Cleanup(sandbox.Disable)
+
+	fredClient, err := fred.NewClient(fred.Config{APIKey: "key", BaseURL: srv.URL})
+	require.NoError(t, err)
+	newsClient := newsapi.NewClient("key")
+	newsClient.SetBaseURL(srv.URL)
+	llmHTTP := pkgopenai.NewHTTPClient()
+
+	cases := []struct {
+		name string
+		call func() error
+	}{
+		{name: "fred", call: func() error {
+			_, err := fredClient.GetSeries(context.Background(), "GDP")
+			return err
+		}},
+		{name: "newsapi", call: func() error {
+			_, err := newsClient.GetTopHeadlines(context.Background(), newsapi.TopHeadlinesParams{Country: "us"})
+			return err
+		}},
+		{name: "llm transport", call: func() error {
+			req, err := http.NewRequest(http.MethodPost, srv.URL+"/v1/responses", bytes.NewBufferString(`{}`))
+			require.NoError(t, err)
+			resp, err := llmHTTP.Do(req)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return err
+		}},
+		{name: "default transport", call: func() error {
+			resp, err := (&http.Client{Transport: sandbox.Guard(nil)}).Get(srv.URL)
+			if resp != nil {
+				resp.Body.Close()
+			}
+			return err
+		}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			require.ErrorIs(t, c.call(), sandbox.ErrNetworkDisabled)
+		})
+	}
+	assert.Zero(t, hits.Load(), "no request may reach the network in sandbox mode")
+
+	t.Run("disabled sandbox lets requests through", func(t *testing.T) {
+		sandbox.Disable()
+		defer sandbox.Enable()
+		_, err := fredClient.GetSeries(context.Background(), "GDP")
+		assert.NotErrorIs(t, err, sandbox.ErrNetworkDisabled)
+		assert.Equal(t, int32(1), hits.Load())
+	})
+}
+
+func TestSandbox_CannedStubs(t *testing.T) {
+	sandbox.Enable()
+	t.Cleanup(sandbox.Disable)
+
+	client := pkgopenai.NewHTTPClient()
+
+	cases := []struct {
+		name    string
+		url     string
+		body    string
+		check   func(t *testing.T, body map[string]any)
+		wantErr bool
+	}{
+		{
+			name: "responses",
+			url:  "https://api.openai.com/v1/responses",
+			body: `{"model":"gpt-4o","input":"hi"}`,
+			check: func(t *testing.T, body map[string]any) {
+				assert.Equal(t, "completed", body["status"])
+				assert.Len(t, body["output"], 1)
+			},
+		},
+		{
+			name: "embeddings keep input order and are deterministic",
+			url:  "https://api.openai.com/v1/embeddings",
+			body: `{"model":"text-embedding-3-small","input":["a","b","a"]}`,
+			check: func(t *testing.T, body map[string]any) {
+				data := body["data"].([]any)
+				require.Len(t, data, 3)
+				first := data[0].(map[string]any)["embedding"]
+				assert.Equal(t, first, data[2].(map[string]any)["embedding"])
+				assert.NotEqual(t, first, data[1].(map[string]any)["embedding"])
+			},
+		},
+		{
+			name:    "unstubbed endpoint",
+			url:     "https://api.openai.com/v1/batches",
+			body:    `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodPost, c.url, bytes.NewBufferString(c.body))
+			require.NoError(t, err)
+			resp, err := client.Do(req)
+			if c.wantErr {
+				require.ErrorIs(t, err, sandbox.ErrNetworkDisabled)
+				return
+			}
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+			var body map[string]any
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+			c.check(t, body)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package sandbox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// cannedText is the assistant answer returned by the LLM stubs
+const cannedText = "Sandbox mode: this answer is canned, no model was called."
+
+// stubEmbeddingDim is the dimension of the deterministic stub embeddings
+const stubEmbeddingDim = 8
+
+const (
+	cannedResponse = `{"id":"resp_sandbox","object":"response","status":"completed","model":"sandbox","output":[` +
+		`{"type":"message","id":"msg_sandbox","role":"assistant","status":"completed",` +
+		`"content":[{"type":"output_text","text":%q,"annotations":[]}]}],` +
+		`"usage":{"input_tokens":0,"output_tokens":0,"total_tokens":0}}`
+	cannedChatCompletion = `{"id":"chatcmpl_sandbox","object":"chat.completion","model":"sandbox","choices":[` +
+		`{"index":0,"message":{"role":"assistant","content":%q},"finish_reason":"stop"}],` +
+		`"usage":{"prompt_tokens":0,"completion_tokens":0,"total_tokens":0}}`
+	cannedNews = `{"status":"ok","totalResults":0,"articles":[]}`
+)
+
+// defaultStubs returns the canned API stubs keyed by host
+func defaultStubs() map[string]StubFunc {
+	return map[string]StubFunc{
+		"api.openai.com": openAIStub,
+		"newsapi.org":    func(req *http.Request) (*http.Response, error) { return jsonResponse(cannedNews), nil },
+	}
+}
+
+// openAIStub answers the Responses, Chat Completions and Embeddings endpoints;
+// any other OpenAI endpoint (files, batches...) is rejected
+func openAIStub(req *http.Request) (*http.Response, error) {
+	switch {
+	case strings.HasSuffix(req.URL.Path, "/responses"):
+		return jsonResponse(fmt.Sprintf(cannedResponse, cannedText)), nil
+	case strings.HasSuffix(req.URL.Path, "/chat/completions"):
+		return jsonResponse(fmt.Sprintf(cannedChatCompletion, cannedText)), nil
+	case strings.HasSuffix(req.URL.Path, "/embeddings"):
+		return embeddingsStub(req)
+	default:
+		return nil, fmt.Errorf("%w: %s %s has no sandbox stub", ErrNetworkDisabled, req.Method, req.URL.Path)
+	}
+}
+
+// embeddingsStub returns one deterministic vector per input, derived from the input text
+func embeddingsStub(req *http.Request) (*http.Response, error) {
+	var body struct {
+		Input json.RawMessage `json:"input"`
+	}
+	if req.Body != nil {
+		if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+			return nil, fmt.Errorf("sandbox: failed to decode embeddings request: %w", err)
+		}
+	}
+
+	var inputs []string
+	if err := json.Unmarshal(body.Input, &inputs); err != nil {
+		var single string
+		if err := json.Unmarshal(body.Input, &single); err != nil {
+			return nil, fmt.Errorf("sandbox: unsupported embeddings input: %w", err)
+		}
+		inputs = []string{single}
+	}
+
+	data := make([]map[string]any, 0, len(inputs))
+	for i, in := range inputs {
+		data = append(data, map[string]any{"object": "embedding", "index": i, "embedding": stubEmbedding(in)})
+	}
+	out, err := json.Marshal(map[string]any{"object": "list", "model": "sandbox", "data": data})
+	if err != nil {
+		return nil, err
+	}
+	return jsonResponse(string(out)), nil
+}
+
+// stubEmbedding spreads an FNV hash of text over a small vector
+func stubEmbedding(text string) []float64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(text))
+	sum := h.Sum64()
+
+	vec := make([]float64, stubEmbeddingDim)
+	for i := range vec {
+		vec[i] = float64((sum>>(i*8))&0xff)/255 - 0.5
+	}
+	return vec
+}
+
+// jsonResponse builds a 200 response with a JSON body
+func jsonResponse(body string) *http.Response {
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+	}
+}
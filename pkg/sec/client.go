@@ -12,6 +12,7 @@ import (
 	"log/slog"
 
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
 )
 
 // Client provides access to SEC EDGAR API
@@ -49,11 +50,11 @@ func NewClient(cfg Config) (*Client, error) {
 		userAgent: cfg.UserAgent,
 		http: &http.Client{
 			Timeout: timeout,
-			Transport: &http.Transport{
+			Transport: sandbox.Guard(&http.Transport{
 				MaxIdleConns:        10,
 				MaxIdleConnsPerHost: 5,
 				IdleConnTimeout:     30 * time.Second,
-			},
+			}),
 		},
 	}, nil
 }
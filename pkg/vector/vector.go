@@ -0,0 +1,208 @@
+// Package vector provides a small in-memory vector index with cosine similarity
+// search, persisted as JSON under the application cache directory.
+package vector
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+)
+
+// indexDir is the cache-relative directory holding persisted indexes
+const indexDir = "vector"
+
+// ErrDimensionMismatch is returned when a vector does not match the index dimension
+var ErrDimensionMismatch = errors.New("vector dimension mismatch")
+
+// Item is a stored vector with its identifier and free-form metadata
+type Item struct {
+	ID       string            `json:"id"`
+	Vector   []float64         `json:"vector"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Match is a search hit with its cosine similarity to the query (-1..1)
+type Match struct {
+	Item
+	Score float64 `json:"score"`
+}
+
+// Index is a brute-force cosine similarity index, safe for concurrent use
+type Index struct {
+	mu    sync.RWMutex
+	dim   int
+	items []Item
+	byID  map[string]int
+
+	fs   fs.FS
+	path string
+}
+
+// NewIndex creates an empty index persisted to <cacheDir>/vector/<name>.json
+func NewIndex(filesystem fs.FS, cacheDir, name string) *Index {
+	return &Index{
+		byID: map[string]int{},
+		fs:   filesystem,
+		path: filepath.Join(cacheDir, indexDir, name+".json"),
+	}
+}
+
+// Len returns the number of stored items
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.items)
+}
+
+// Has reports whether an item with the given ID is stored
+func (ix *Index) Has(id string) bool {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	_, ok := ix.byID[id]
+	return ok
+}
+
+// Get returns the item stored under id
+func (ix *Index) Get(id string) (Item, bool) {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	i, ok := ix.byID[id]
+	if !ok {
+		return Item{}, false
+	}
+	return ix.items[i], true
+}
+
+// Add stores an item, replacing any existing item with the same ID. The first
+// vector added fixes the dimension of the index.
+func (ix *Index) Add(item Item) error {
+	if item.ID == "" {
+		return fmt.Errorf("vector item ID cannot be empty")
+	}
+	if len(item.Vector) == 0 {
+		return fmt.Errorf("vector item %s has no vector", item.ID)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if ix.dim == 0 {
+		ix.dim = len(item.Vector)
+	}
+	if len(item.Vector) != ix.dim {
+		return fmt.Errorf("%w: item %s has %d dimensions, index has %d", ErrDimensionMismatch, item.ID, len(item.Vector), ix.dim)
+	}
+
+	if i, ok := ix.byID[item.ID]; ok {
+		ix.items[i] = item
+		return nil
+	}
+	ix.byID[item.ID] = len(ix.items)
+	ix.items = append(ix.items, item)
+	return nil
+}
+
+// Search returns the topK items most similar to query, best first. Ties keep
+// insertion order so results are deterministic.
+func (ix *Index) Search(query []float64, topK int) ([]Match, error) {
+	if topK <= 0 {
+		return nil, nil
+	}
+
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	if len(ix.items) == 0 {
+		return nil, nil
+	}
+	if len(query) != ix.dim {
+		return nil, fmt.Errorf("%w: query has %d dimensions, index has %d", ErrDimensionMismatch, len(query), ix.dim)
+	}
+
+	matches := make([]Match, 0, len(ix.items))
+	for _, it := range ix.items {
+		matches = append(matches, Match{Item: it, Score: CosineSimilarity(query, it.Vector)})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+
+	if len(matches) > topK {
+		matches = matches[:topK]
+	}
+	return matches, nil
+}
+
+// persistedIndex is the on-disk representation of an index
+type persistedIndex struct {
+	Dim   int    `json:"dim"`
+	Items []Item `json:"items"`
+}
+
+// Save writes the index to the cache directory
+func (ix *Index) Save() error {
+	ix.mu.RLock()
+	data, err := json.Marshal(persistedIndex{Dim: ix.dim, Items: ix.items})
+	ix.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode vector index: %w", err)
+	}
+
+	if err := ix.fs.MkdirAll(filepath.Dir(ix.path), 0o755); err != nil {
+		return fmt.Errorf("failed to create vector index directory: %w", err)
+	}
+	if err := ix.fs.WriteFile(ix.path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write vector index: %w", err)
+	}
+	return nil
+}
+
+// Load replaces the index content with the persisted one; a missing file leaves
+// the index empty
+func (ix *Index) Load() error {
+	data, err := ix.fs.ReadFile(ix.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read vector index: %w", err)
+	}
+
+	var p persistedIndex
+	if err := json.Unmarshal(data, &p); err != nil {
+		return fmt.Errorf("failed to decode vector index: %w", err)
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.dim = p.Dim
+	ix.items = p.Items
+	ix.byID = make(map[string]int, len(p.Items))
+	for i, it := range p.Items {
+		ix.byID[it.ID] = i
+	}
+	return nil
+}
+
+// CosineSimilarity returns the cosine of the angle between a and b, or 0 when
+// either vector is zero or their lengths differ
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,131 @@
+package vector
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/amaurybrisou/mosychlos/pkg/fs"
+)
+
+func TestCosineSimilarity(t *testing.T) {
+	cases := []struct {
+		name string
+		a, b []float64
+		want float64
+	}{
+		{name: "identical", a: []float64{1, 2, 3}, b: []float64{1, 2, 3}, want: 1},
+		{name: "scaled", a: []float64{1, 0}, b: []float64{5, 0}, want: 1},
+		{name: "orthogonal", a: []float64{1, 0}, b: []float64{0, 1}, want: 0},
+		{name: "opposite", a: []float64{1, 1}, b: []float64{-1, -1}, want: -1},
+		{name: "45 degrees", a: []float64{1, 0}, b: []float64{1, 1}, want: 1 / math.Sqrt2},
+		{name: "zero vector", a: []float64{0, 0}, b: []float64{1, 1}, want: 0},
+		{name: "length mismatch", a: []float64{1}, b: []float64{1, 1}, want: 0},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			assert.InDelta(t, c.want, CosineSimilarity(c.a, c.b), 1e-9)
+		})
+	}
+}
+
+// syntheticIndex builds an index of 2D unit-ish vectors at known angles
+func syntheticIndex(t *testing.T, cacheDir string) *Index {
+	t.Helper()
+	ix := NewIndex(fs.OS{}, cacheDir, "test")
+	for _, it := range []Item{
+		{ID: "east", Vector: []float64{1, 0}},
+		{ID: "north-east", Vector: []float64{1, 1}},
+		{ID: "north", Vector: []float64{0, 1}},
+		{ID: "west", Vector: []float64{-1, 0}},
+		{ID: "east-north-east", Vector: []float64{2, 1}, Metadata: map[string]string{"title": "ene"}},
+	} {
+		require.NoError(t, ix.Add(it))
+	}
+	return ix
+}
+
+func ids(matches []Match) []string {
+	out := make([]string, 0, len(matches))
+	for _, m := range matches {
+		out = append(out, m.ID)
+	}
+	return out
+}
+
+func TestIndex_Search(t *testing.T) {
+	cases := []struct {
+		name    string
+		query   []float64
+		topK    int
+		want    []string
+		wantErr bool
+	}{
+		{name: "nearest to east", query: []float64{1, 0}, topK: 3, want: []string{"east", "east-north-east", "north-east"}},
+		{name: "nearest to north", query: []float64{0, 3}, topK: 2, want: []string{"north", "north-east"}},
+		{name: "nearest to west", query: []float64{-1, 0.1}, topK: 1, want: []string{"west"}},
+		{name: "topK larger than index", query: []float64{1, 0}, topK: 10, want: []string{"east", "east-north-east", "north-east", "north", "west"}},
+		{name: "zero topK", query: []float64{1, 0}, topK: 0, want: []string{}},
+		{name: "dimension mismatch", query: []float64{1, 0, 0}, topK: 1, wantErr: true},
+	}
+
+	ix := syntheticIndex(t, t.TempDir())
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ix.Search(c.query, c.topK)
+			if c.wantErr {
+				require.ErrorIs(t, err, ErrDimensionMismatch)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.want, ids(got))
+			for i := 1; i < len(got); i++ {
+				assert.GreaterOrEqual(t, got[i-1].Score, got[i].Score)
+			}
+		})
+	}
+}
+
+func TestIndex_Add(t *testing.T) {
+	ix := NewIndex(fs.OS{}, t.TempDir(), "test")
+	require.NoError(t, ix.Add(Item{ID: "a", Vector: []float64{1, 0}}))
+	assert.True(t, ix.Has("a"))
+	assert.False(t, ix.Has("b"))
+	got, ok := ix.Get("a")
+	require.True(t, ok)
+	assert.Equal(t, []float64{1, 0}, got.Vector)
+
+	// same ID replaces the stored vector
+	require.NoError(t, ix.Add(Item{ID: "a", Vector: []float64{0, 1}}))
+	assert.Equal(t, 1, ix.Len())
+	matches, err := ix.Search([]float64{0, 1}, 1)
+	require.NoError(t, err)
+	assert.InDelta(t, 1, matches[0].Score, 1e-9)
+
+	require.ErrorIs(t, ix.Add(Item{ID: "b", Vector: []float64{1, 0, 0}}), ErrDimensionMismatch)
+	require.Error(t, ix.Add(Item{ID: "", Vector: []float64{1, 0}}))
+	require.Error(t, ix.Add(Item{ID: "c"}))
+}
+
+func TestIndex_SaveLoad(t *testing.T) {
+	cacheDir := t.TempDir()
+	require.NoError(t, syntheticIndex(t, cacheDir).Save())
+
+	// a fresh index simulates the next run
+	loaded := NewIndex(fs.OS{}, cacheDir, "test")
+	require.NoError(t, loaded.Load())
+	assert.Equal(t, 5, loaded.Len())
+
+	got, err := loaded.Search([]float64{1, 0}, 2)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"east", "east-north-east"}, ids(got))
+	assert.Equal(t, "ene", got[1].Metadata["title"])
+
+	// missing file leaves the index empty
+	empty := NewIndex(fs.OS{}, cacheDir, "missing")
+	require.NoError(t, empty.Load())
+	assert.Equal(t, 0, empty.Len())
+}
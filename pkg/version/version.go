@@ -0,0 +1,66 @@
+// Package version is the single source of truth for the binary, engine and SDK
+// versions shown by the CLI and stamped in prompts and reports.
+package version
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Engine is the analysis engine version stamped in prompts and report metadata
+const Engine = "v2.0"
+
+// openAISDKModule is the module path of the OpenAI Go SDK
+const openAISDKModule = "github.com/openai/openai-go/v2"
+
+// unknown is reported when a version cannot be determined
+const unknown = "unknown"
+
+// Version overrides the binary version read from build info, e.g.
+// -ldflags "-X github.com/amaurybrisou/mosychlos/pkg/version.Version=v1.2.3"
+var Version = ""
+
+// Info describes the running binary
+type Info struct {
+	Binary    string `json:"binary"`
+	Engine    string `json:"engine"`
+	OpenAISDK string `json:"openai_sdk"`
+	GoVersion string `json:"go_version"`
+	Commit    string `json:"commit,omitempty"`
+}
+
+// Get returns the versions of the running binary
+func Get() Info {
+	info := Info{
+		Binary:    unknown,
+		Engine:    Engine,
+		OpenAISDK: unknown,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if ok {
+		if bi.Main.Version != "" {
+			info.Binary = bi.Main.Version
+		}
+		for _, dep := range bi.Deps {
+			if dep.Path == openAISDKModule {
+				info.OpenAISDK = dep.Version
+				if dep.Replace != nil {
+					info.OpenAISDK = dep.Replace.Version
+				}
+				break
+			}
+		}
+		for _, s := range bi.Settings {
+			if s.Key == "vcs.revision" {
+				info.Commit = s.Value
+			}
+		}
+	}
+
+	if Version != "" {
+		info.Binary = Version
+	}
+	return info
+}
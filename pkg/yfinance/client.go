@@ -12,6 +12,7 @@ import (
 	"log/slog"
 
 	"github.com/amaurybrisou/mosychlos/pkg/models"
+	"github.com/amaurybrisou/mosychlos/pkg/sandbox"
 )
 
 // Client provides access to Yahoo Finance API
@@ -42,11 +43,11 @@ func NewClient(cfg Config) (*Client, error) {
 		baseURL: baseURL,
 		http: &http.Client{
 			Timeout: timeout,
-			Transport: &http.Transport{
+			Transport: sandbox.Guard(&http.Transport{
 				MaxIdleConns:        10,
 				MaxIdleConnsPerHost: 5,
 				IdleConnTimeout:     30 * time.Second,
-			},
+			}),
 		},
 	}, nil
 }